@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -10,6 +11,7 @@ import (
 	"github.com/ammm56/lings/infrastructure/config"
 	"github.com/ammm56/lings/infrastructure/db/database"
 	"github.com/ammm56/lings/infrastructure/db/database/ldb"
+	"github.com/ammm56/lings/infrastructure/db/database/logdb"
 	"github.com/ammm56/lings/infrastructure/logger"
 	"github.com/ammm56/lings/infrastructure/os/execenv"
 	"github.com/ammm56/lings/infrastructure/os/limits"
@@ -18,6 +20,7 @@ import (
 	"github.com/ammm56/lings/util/panics"
 	"github.com/ammm56/lings/util/profiling"
 	"github.com/ammm56/lings/version"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -94,6 +97,14 @@ func (app *lingsApp) main(startedChan chan<- struct{}) error {
 		return nil
 	}
 
+	if len(app.cfg.Command) > 0 && isCommandWithoutDatabase(app.cfg) {
+		err := runCommand(app.cfg, nil)
+		if err != nil {
+			log.Error(err)
+		}
+		return err
+	}
+
 	if app.cfg.ResetDatabase {
 		err := removeDatabase(app.cfg)
 		if err != nil {
@@ -102,6 +113,14 @@ func (app *lingsApp) main(startedChan chan<- struct{}) error {
 		}
 	}
 
+	if app.cfg.RestoreBackup != "" {
+		err := restoreDatabaseBackup(app.cfg)
+		if err != nil {
+			log.Errorf("Restoring the database backup failed: %+v", err)
+			return err
+		}
+	}
+
 	// Open the database
 	databaseContext, err := openDB(app.cfg)
 	if err != nil {
@@ -117,11 +136,24 @@ func (app *lingsApp) main(startedChan chan<- struct{}) error {
 		}
 	}()
 
+	err = logDatabaseSize(databaseContext)
+	if err != nil {
+		log.Warnf("Failed to get the database size: %s", err)
+	}
+
 	// Return now if an interrupt signal was triggered.
 	if signal.InterruptRequested(interrupt) {
 		return nil
 	}
 
+	if len(app.cfg.Command) > 0 {
+		err := runCommand(app.cfg, databaseContext)
+		if err != nil {
+			log.Error(err)
+		}
+		return err
+	}
+
 	// Create componentManager and start it.
 	componentManager, err := NewComponentManager(app.cfg, databaseContext, interrupt)
 	if err != nil {
@@ -161,29 +193,199 @@ func (app *lingsApp) main(startedChan chan<- struct{}) error {
 	return nil
 }
 
-// dbPath returns the path to the block database given a database type.
-func databasePath(cfg *config.Config) string {
-	return filepath.Join(cfg.AppDir, defaultDataDirname)
+// databasePath returns the path to the block database given a database type.
+func databasePath(cfg *config.Config, dbType string) string {
+	// LevelDB keeps the original directory name, so that existing
+	// databases are still found
+	if dbType == config.DbTypeLevelDB {
+		return filepath.Join(cfg.AppDir, defaultDataDirname)
+	}
+	return filepath.Join(cfg.AppDir, defaultDataDirname+"-"+dbType)
 }
 
 func removeDatabase(cfg *config.Config) error {
-	dbPath := databasePath(cfg)
+	dbPath := databasePath(cfg, cfg.DbType)
 	return os.RemoveAll(dbPath)
 }
 
+// restoredBackupFileName is the name of the file inside a restored database
+// that holds the checksum of the backup it was restored from
+const restoredBackupFileName = "restored-backup"
+
+// restoreDatabaseBackup verifies the integrity of the backup given in the
+// configuration and replaces the database with a copy of it. A database that
+// was already restored from the same backup is left as is, so that the option
+// can safely stay in the config file after the first run.
+func restoreDatabaseBackup(cfg *config.Config) error {
+	if cfg.DbType != config.DbTypeLevelDB {
+		return errors.Errorf("backups can only be restored into a %s database", config.DbTypeLevelDB)
+	}
+
+	dbPath := databasePath(cfg, cfg.DbType)
+	manifest, err := ldb.ReadBackupManifest(cfg.RestoreBackup)
+	if err != nil {
+		return err
+	}
+	restoredChecksum, err := os.ReadFile(filepath.Join(dbPath, restoredBackupFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if string(restoredChecksum) == manifest.Checksum {
+		log.Infof("Ignoring --restore-backup since the database was already restored from '%s'",
+			cfg.RestoreBackup)
+		return nil
+	}
+
+	log.Infof("Verifying the database backup at '%s'", cfg.RestoreBackup)
+	manifest, err = ldb.VerifyBackup(cfg.RestoreBackup)
+	if err != nil {
+		return err
+	}
+	log.Infof("The database backup from %s is valid (%d entries, checksum %s)",
+		manifest.CreatedAt, manifest.EntryCount, manifest.Checksum)
+
+	// The backup is copied into a temporary directory that replaces the
+	// database once the copy is complete, so that a failed copy leaves the
+	// database as it was
+	temporaryPath := dbPath + ".restoring"
+	err = os.RemoveAll(temporaryPath)
+	if err != nil {
+		return err
+	}
+	err = copyDatabaseBackup(cfg.RestoreBackup, temporaryPath, manifest)
+	if err != nil {
+		removeErr := os.RemoveAll(temporaryPath)
+		if removeErr != nil {
+			log.Errorf("Failed to remove the partial database at %s: %s", temporaryPath, removeErr)
+		}
+		return err
+	}
+
+	log.Warnf("Replacing the database at '%s' with the backup", dbPath)
+	err = os.RemoveAll(dbPath)
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, dbPath)
+}
+
+// copyDatabaseBackup copies the database files of the backup at backupPath
+// into a new directory at dbPath, and records the backup it was restored from
+func copyDatabaseBackup(backupPath string, dbPath string, manifest *database.BackupManifest) error {
+	err := os.MkdirAll(dbPath, 0700)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(backupPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || isSkippedBackupFile(entry.Name()) {
+			continue
+		}
+		err := copyFile(filepath.Join(backupPath, entry.Name()), filepath.Join(dbPath, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filepath.Join(dbPath, restoredBackupFileName), []byte(manifest.Checksum), 0600)
+}
+
+// isSkippedBackupFile returns whether the backup file with the given name is
+// left out of the restored database. Besides the manifest, these are the lock
+// and log files of the LevelDB instance the backup was written with, which
+// LevelDB recreates when it opens the database.
+func isSkippedBackupFile(name string) bool {
+	switch name {
+	case ldb.BackupManifestFileName, "LOCK", "LOG", "LOG.old":
+		return true
+	}
+	return false
+}
+
+func copyFile(sourcePath, destinationPath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.OpenFile(destinationPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer destination.Close()
+
+	_, err = io.Copy(destination, source)
+	if err != nil {
+		return err
+	}
+	return destination.Sync()
+}
+
 func openDB(cfg *config.Config) (database.Database, error) {
-	dbPath := databasePath(cfg)
+	dbPath := databasePath(cfg, cfg.DbType)
 
 	err := checkDatabaseVersion(dbPath)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Infof("Loading database from '%s'", dbPath)
-	db, err := ldb.NewLevelDB(dbPath, leveldbCacheSizeMiB)
+	log.Infof("Loading %s database from '%s'", cfg.DbType, dbPath)
+	db, err := openDatabase(cfg, cfg.DbType, dbPath)
 	if err != nil {
 		return nil, err
 	}
 
+	if cfg.DurableWrites {
+		err := enableDurableWrites(db)
+		if err != nil {
+			closeErr := db.Close()
+			if closeErr != nil {
+				log.Errorf("Failed to close the database: %s", closeErr)
+			}
+			return nil, err
+		}
+	}
 	return db, nil
 }
+
+// enableDurableWrites makes the database sync every write before it returns
+func enableDurableWrites(db database.Database) error {
+	writeSyncer, ok := db.(database.WriteSyncer)
+	if !ok {
+		return errors.Errorf("the database doesn't support durable writes")
+	}
+	log.Infof("Database writes are synced to disk")
+	return writeSyncer.SetSyncWrites(true)
+}
+
+// openDatabase opens the database of the given type at the given path, with
+// the database options in the configuration
+func openDatabase(cfg *config.Config, dbType string, dbPath string) (database.Database, error) {
+	switch dbType {
+	case config.DbTypeLevelDB:
+		db, err := ldb.NewLevelDBWithConfig(dbPath, &ldb.Config{
+			CacheSizeMiB:    leveldbCacheSizeMiB,
+			WriteBufferMiB:  cfg.LevelDBWriteBufferMiB,
+			TableSizeMiB:    cfg.LevelDBTableSizeMiB,
+			BloomFilterBits: cfg.LevelDBBloomFilterBits,
+			Compression:     cfg.LevelDBCompression,
+			Sync:            cfg.LevelDBSync || cfg.DurableWrites,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	case config.DbTypeLogDB:
+		db, err := logdb.NewLogDB(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	default:
+		return nil, errors.Errorf("unknown database type %s", dbType)
+	}
+}
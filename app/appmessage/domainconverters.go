@@ -345,40 +345,67 @@ func DomainOutpointAndUTXOEntryPairsToOutpointAndUTXOEntryPairs(
 
 // DomainBlockToRPCBlock converts DomainBlocks to RPCBlocks
 func DomainBlockToRPCBlock(block *externalapi.DomainBlock) *RPCBlock {
-	parents := make([]*RPCBlockLevelParents, len(block.Header.Parents()))
-	for i, blockLevelParents := range block.Header.Parents() {
+	transactions := make([]*RPCTransaction, len(block.Transactions))
+	for i, transaction := range block.Transactions {
+		transactions[i] = DomainTransactionToRPCTransaction(transaction)
+	}
+	return &RPCBlock{
+		Header:       DomainBlockHeaderToRPCBlockHeader(block.Header),
+		Transactions: transactions,
+	}
+}
+
+// DomainBlockHeaderToRPCBlockHeader converts a BlockHeader to an RPCBlockHeader
+func DomainBlockHeaderToRPCBlockHeader(header externalapi.BlockHeader) *RPCBlockHeader {
+	parents := make([]*RPCBlockLevelParents, len(header.Parents()))
+	for i, blockLevelParents := range header.Parents() {
 		parents[i] = &RPCBlockLevelParents{
 			ParentHashes: hashes.ToStrings(blockLevelParents),
 		}
 	}
-	header := &RPCBlockHeader{
-		Version:              uint32(block.Header.Version()),
+	return &RPCBlockHeader{
+		Version:              uint32(header.Version()),
 		Parents:              parents,
-		HashMerkleRoot:       block.Header.HashMerkleRoot().String(),
-		AcceptedIDMerkleRoot: block.Header.AcceptedIDMerkleRoot().String(),
-		UTXOCommitment:       block.Header.UTXOCommitment().String(),
-		Timestamp:            block.Header.TimeInMilliseconds(),
-		Bits:                 block.Header.Bits(),
-		Nonce:                block.Header.Nonce(),
-		DAAScore:             block.Header.DAAScore(),
-		BlueScore:            block.Header.BlueScore(),
-		BlueWork:             block.Header.BlueWork().Text(16),
-		PruningPoint:         block.Header.PruningPoint().String(),
+		HashMerkleRoot:       header.HashMerkleRoot().String(),
+		AcceptedIDMerkleRoot: header.AcceptedIDMerkleRoot().String(),
+		UTXOCommitment:       header.UTXOCommitment().String(),
+		Timestamp:            header.TimeInMilliseconds(),
+		Bits:                 header.Bits(),
+		Nonce:                header.Nonce(),
+		DAAScore:             header.DAAScore(),
+		BlueScore:            header.BlueScore(),
+		BlueWork:             header.BlueWork().Text(16),
+		PruningPoint:         header.PruningPoint().String(),
 	}
-	transactions := make([]*RPCTransaction, len(block.Transactions))
+}
+
+// RPCBlockToDomainBlock converts `block` into a DomainBlock
+func RPCBlockToDomainBlock(block *RPCBlock) (*externalapi.DomainBlock, error) {
+	header, err := RPCBlockHeaderToDomainBlockHeader(block.Header)
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]*externalapi.DomainTransaction, len(block.Transactions))
 	for i, transaction := range block.Transactions {
-		transactions[i] = DomainTransactionToRPCTransaction(transaction)
+		domainTransaction, err := RPCTransactionToDomainTransaction(transaction)
+		if err != nil {
+			return nil, err
+		}
+		transactions[i] = domainTransaction
 	}
-	return &RPCBlock{
+	return &externalapi.DomainBlock{
 		Header:       header,
 		Transactions: transactions,
-	}
+	}, nil
 }
 
-// RPCBlockToDomainBlock converts `block` into a DomainBlock
-func RPCBlockToDomainBlock(block *RPCBlock) (*externalapi.DomainBlock, error) {
-	parents := make([]externalapi.BlockLevelParents, len(block.Header.Parents))
-	for i, blockLevelParents := range block.Header.Parents {
+// RPCBlockHeaderToDomainBlockHeader converts `header` into a BlockHeader
+func RPCBlockHeaderToDomainBlockHeader(header *RPCBlockHeader) (externalapi.BlockHeader, error) {
+	if header == nil {
+		return nil, errors.Errorf("block header is missing")
+	}
+	parents := make([]externalapi.BlockLevelParents, len(header.Parents))
+	for i, blockLevelParents := range header.Parents {
 		parents[i] = make(externalapi.BlockLevelParents, len(blockLevelParents.ParentHashes))
 		for j, parentHash := range blockLevelParents.ParentHashes {
 			var err error
@@ -388,51 +415,39 @@ func RPCBlockToDomainBlock(block *RPCBlock) (*externalapi.DomainBlock, error) {
 			}
 		}
 	}
-	hashMerkleRoot, err := externalapi.NewDomainHashFromString(block.Header.HashMerkleRoot)
+	hashMerkleRoot, err := externalapi.NewDomainHashFromString(header.HashMerkleRoot)
 	if err != nil {
 		return nil, err
 	}
-	acceptedIDMerkleRoot, err := externalapi.NewDomainHashFromString(block.Header.AcceptedIDMerkleRoot)
+	acceptedIDMerkleRoot, err := externalapi.NewDomainHashFromString(header.AcceptedIDMerkleRoot)
 	if err != nil {
 		return nil, err
 	}
-	utxoCommitment, err := externalapi.NewDomainHashFromString(block.Header.UTXOCommitment)
+	utxoCommitment, err := externalapi.NewDomainHashFromString(header.UTXOCommitment)
 	if err != nil {
 		return nil, err
 	}
-	blueWork, success := new(big.Int).SetString(block.Header.BlueWork, 16)
+	blueWork, success := new(big.Int).SetString(header.BlueWork, 16)
 	if !success {
-		return nil, errors.Errorf("failed to parse blue work: %s", block.Header.BlueWork)
+		return nil, errors.Errorf("failed to parse blue work: %s", header.BlueWork)
 	}
-	pruningPoint, err := externalapi.NewDomainHashFromString(block.Header.PruningPoint)
+	pruningPoint, err := externalapi.NewDomainHashFromString(header.PruningPoint)
 	if err != nil {
 		return nil, err
 	}
-	header := blockheader.NewImmutableBlockHeader(
-		uint16(block.Header.Version),
+	return blockheader.NewImmutableBlockHeader(
+		uint16(header.Version),
 		parents,
 		hashMerkleRoot,
 		acceptedIDMerkleRoot,
 		utxoCommitment,
-		block.Header.Timestamp,
-		block.Header.Bits,
-		block.Header.Nonce,
-		block.Header.DAAScore,
-		block.Header.BlueScore,
+		header.Timestamp,
+		header.Bits,
+		header.Nonce,
+		header.DAAScore,
+		header.BlueScore,
 		blueWork,
-		pruningPoint)
-	transactions := make([]*externalapi.DomainTransaction, len(block.Transactions))
-	for i, transaction := range block.Transactions {
-		domainTransaction, err := RPCTransactionToDomainTransaction(transaction)
-		if err != nil {
-			return nil, err
-		}
-		transactions[i] = domainTransaction
-	}
-	return &externalapi.DomainBlock{
-		Header:       header,
-		Transactions: transactions,
-	}, nil
+		pruningPoint), nil
 }
 
 // BlockWithTrustedDataToDomainBlockWithTrustedData converts *MsgBlockWithTrustedData to *externalapi.BlockWithTrustedData
@@ -600,3 +615,36 @@ func DomainPruningPointProofToMsgPruningPointProof(pruningPointProof *externalap
 		Headers: headers,
 	}
 }
+
+// DomainPruningPointProofToRPCPruningPointProofLevels converts *externalapi.PruningPointProof to the levels of
+// a GetPruningPointProofResponseMessage
+func DomainPruningPointProofToRPCPruningPointProofLevels(pruningPointProof *externalapi.PruningPointProof) []*RPCPruningPointProofLevel {
+	levels := make([]*RPCPruningPointProofLevel, len(pruningPointProof.Headers))
+	for blockLevel, blockLevelHeaders := range pruningPointProof.Headers {
+		headers := make([]*RPCBlockHeader, len(blockLevelHeaders))
+		for i, header := range blockLevelHeaders {
+			headers[i] = DomainBlockHeaderToRPCBlockHeader(header)
+		}
+		levels[blockLevel] = &RPCPruningPointProofLevel{Headers: headers}
+	}
+	return levels
+}
+
+// RPCPruningPointProofLevelsToDomainPruningPointProof converts the levels of a GetPruningPointProofResponseMessage
+// to *externalapi.PruningPointProof
+func RPCPruningPointProofLevelsToDomainPruningPointProof(levels []*RPCPruningPointProofLevel) (*externalapi.PruningPointProof, error) {
+	headers := make([][]externalapi.BlockHeader, len(levels))
+	for blockLevel, level := range levels {
+		headers[blockLevel] = make([]externalapi.BlockHeader, len(level.Headers))
+		for i, header := range level.Headers {
+			var err error
+			headers[blockLevel][i], err = RPCBlockHeaderToDomainBlockHeader(header)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid header %d at level %d", i, blockLevel)
+			}
+		}
+	}
+	return &externalapi.PruningPointProof{
+		Headers: headers,
+	}, nil
+}
@@ -73,6 +73,7 @@ const (
 	CmdRequestIBDChainBlockLocator
 	CmdIBDChainBlockLocator
 	CmdRequestAnticone
+	CmdStemTransaction
 
 	// rpc
 	CmdGetCurrentNetworkRequestMessage
@@ -163,6 +164,20 @@ const (
 	CmdGetMempoolEntriesByAddressesResponseMessage
 	CmdGetCoinSupplyRequestMessage
 	CmdGetCoinSupplyResponseMessage
+	CmdBackupDatabaseRequestMessage
+	CmdBackupDatabaseResponseMessage
+	CmdSetDatabaseOptionsRequestMessage
+	CmdSetDatabaseOptionsResponseMessage
+	CmdGetCacheStatsRequestMessage
+	CmdGetCacheStatsResponseMessage
+	CmdGenerateBlocksRequestMessage
+	CmdGenerateBlocksResponseMessage
+	CmdSetNodeTimeRequestMessage
+	CmdSetNodeTimeResponseMessage
+	CmdGetDAGGraphRequestMessage
+	CmdGetDAGGraphResponseMessage
+	CmdGetPruningPointProofRequestMessage
+	CmdGetPruningPointProofResponseMessage
 )
 
 // ProtocolMessageCommandToString maps all MessageCommands to their string representation
@@ -210,6 +225,7 @@ var ProtocolMessageCommandToString = map[MessageCommand]string{
 	CmdRequestIBDChainBlockLocator:                 "RequestIBDChainBlockLocator",
 	CmdIBDChainBlockLocator:                        "IBDChainBlockLocator",
 	CmdRequestAnticone:                             "RequestAnticone",
+	CmdStemTransaction:                             "StemTransaction",
 }
 
 // RPCMessageCommandToString maps all MessageCommands to their string representation
@@ -300,6 +316,20 @@ var RPCMessageCommandToString = map[MessageCommand]string{
 	CmdGetMempoolEntriesByAddressesResponseMessage:                "GetMempoolEntriesByAddressesResponse",
 	CmdGetCoinSupplyRequestMessage:                                "GetCoinSupplyRequest",
 	CmdGetCoinSupplyResponseMessage:                               "GetCoinSupplyResponse",
+	CmdBackupDatabaseRequestMessage:                               "BackupDatabaseRequest",
+	CmdBackupDatabaseResponseMessage:                              "BackupDatabaseResponse",
+	CmdSetDatabaseOptionsRequestMessage:                           "SetDatabaseOptionsRequest",
+	CmdSetDatabaseOptionsResponseMessage:                          "SetDatabaseOptionsResponse",
+	CmdGetCacheStatsRequestMessage:                                "GetCacheStatsRequest",
+	CmdGetCacheStatsResponseMessage:                               "GetCacheStatsResponse",
+	CmdGenerateBlocksRequestMessage:                               "GenerateBlocksRequest",
+	CmdGenerateBlocksResponseMessage:                              "GenerateBlocksResponse",
+	CmdSetNodeTimeRequestMessage:                                  "SetNodeTimeRequest",
+	CmdSetNodeTimeResponseMessage:                                 "SetNodeTimeResponse",
+	CmdGetDAGGraphRequestMessage:                                  "GetDAGGraphRequest",
+	CmdGetDAGGraphResponseMessage:                                 "GetDAGGraphResponse",
+	CmdGetPruningPointProofRequestMessage:                         "GetPruningPointProofRequest",
+	CmdGetPruningPointProofResponseMessage:                        "GetPruningPointProofResponse",
 }
 
 // Message is an interface that describes a lings message. A type that
@@ -0,0 +1,22 @@
+package appmessage
+
+// MsgStemTransaction implements the Message interface and represents a lings
+// stemtransaction message. It is used to forward a transaction to a single peer
+// during the stem phase of Dandelion-style transaction relay, before the
+// transaction is announced to the rest of the network.
+type MsgStemTransaction struct {
+	baseMessage
+	*MsgTx
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgStemTransaction) Command() MessageCommand {
+	return CmdStemTransaction
+}
+
+// NewMsgStemTransaction returns a new lings stemtransaction message that conforms
+// to the Message interface. See MsgStemTransaction for details.
+func NewMsgStemTransaction(msgTx *MsgTx) *MsgStemTransaction {
+	return &MsgStemTransaction{MsgTx: msgTx}
+}
@@ -11,6 +11,10 @@ import (
 )
 
 const (
+	// FeatureNegotiationProtocolVersion is the first protocol version in
+	// which peers negotiate NegotiableFeatures during the handshake.
+	FeatureNegotiationProtocolVersion uint32 = 6
+
 	// DefaultServices describes the default services that are supported by
 	// the server.
 	DefaultServices = SFNodeNetwork | SFNodeBloom | SFNodeCF
@@ -41,16 +45,31 @@ const (
 	// SFNodeCF is a flag used to indicate a peer supports committed
 	// filters (CFs).
 	SFNodeCF
+
+	// SFNodeTxRelay is a flag used to indicate a peer accepts and relays
+	// transactions.
+	SFNodeTxRelay
+
+	// SFNodeStemRelay is a flag used to indicate a peer accepts transactions
+	// in the stem phase of Dandelion-style transaction relay.
+	SFNodeStemRelay
 )
 
+// NegotiableFeatures is the set of service flags that are negotiated
+// between peers starting from protocol version 6. A feature is enabled
+// for a connection only if both sides advertise it.
+const NegotiableFeatures = SFNodeTxRelay | SFNodeStemRelay
+
 // Map of service flags back to their constant names for pretty printing.
 var sfStrings = map[ServiceFlag]string{
-	SFNodeNetwork: "SFNodeNetwork",
-	SFNodeGetUTXO: "SFNodeGetUTXO",
-	SFNodeBloom:   "SFNodeBloom",
-	SFNodeXthin:   "SFNodeXthin",
-	SFNodeBit5:    "SFNodeBit5",
-	SFNodeCF:      "SFNodeCF",
+	SFNodeNetwork:   "SFNodeNetwork",
+	SFNodeGetUTXO:   "SFNodeGetUTXO",
+	SFNodeBloom:     "SFNodeBloom",
+	SFNodeXthin:     "SFNodeXthin",
+	SFNodeBit5:      "SFNodeBit5",
+	SFNodeCF:        "SFNodeCF",
+	SFNodeTxRelay:   "SFNodeTxRelay",
+	SFNodeStemRelay: "SFNodeStemRelay",
 }
 
 // orderedSFStrings is an ordered list of service flags from highest to
@@ -62,6 +81,8 @@ var orderedSFStrings = []ServiceFlag{
 	SFNodeXthin,
 	SFNodeBit5,
 	SFNodeCF,
+	SFNodeTxRelay,
+	SFNodeStemRelay,
 }
 
 // String returns the ServiceFlag in human-readable form.
@@ -71,24 +92,35 @@ func (f ServiceFlag) String() string {
 		return "0x0"
 	}
 
-	// Add individual bit flags.
-	s := ""
-	for _, flag := range orderedSFStrings {
-		if f&flag == flag {
-			s += sfStrings[flag] + "|"
-			f -= flag
-		}
-	}
+	names, remaining := f.names()
+	s := strings.Join(names, "|")
 
 	// Add any remaining flags which aren't accounted for as hex.
-	s = strings.TrimRight(s, "|")
-	if f != 0 {
-		s += "|0x" + strconv.FormatUint(uint64(f), 16)
+	if remaining != 0 {
+		s += "|0x" + strconv.FormatUint(uint64(remaining), 16)
 	}
 	s = strings.TrimLeft(s, "|")
 	return s
 }
 
+// Names returns the names of the known service flags that are set in f,
+// ordered from lowest to highest bit. Unknown bits are ignored.
+func (f ServiceFlag) Names() []string {
+	names, _ := f.names()
+	return names
+}
+
+func (f ServiceFlag) names() (names []string, remaining ServiceFlag) {
+	names = []string{}
+	for _, flag := range orderedSFStrings {
+		if f&flag == flag {
+			names = append(names, sfStrings[flag])
+			f -= flag
+		}
+	}
+	return names, f
+}
+
 // LingsNet represents which lings network a message belongs to.
 type LingsNet uint32
 
@@ -19,7 +19,10 @@ func TestServiceFlagStringer(t *testing.T) {
 		{SFNodeXthin, "SFNodeXthin"},
 		{SFNodeBit5, "SFNodeBit5"},
 		{SFNodeCF, "SFNodeCF"},
-		{0xffffffff, "SFNodeNetwork|SFNodeGetUTXO|SFNodeBloom|SFNodeXthin|SFNodeBit5|SFNodeCF|0xffffffc0"},
+		{SFNodeTxRelay, "SFNodeTxRelay"},
+		{SFNodeStemRelay, "SFNodeStemRelay"},
+		{0xffffffff, "SFNodeNetwork|SFNodeGetUTXO|SFNodeBloom|SFNodeXthin|SFNodeBit5|SFNodeCF|SFNodeTxRelay|" +
+			"SFNodeStemRelay|0xffffff00"},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -0,0 +1,45 @@
+package appmessage
+
+// BackupDatabaseRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type BackupDatabaseRequestMessage struct {
+	baseMessage
+	Path string
+}
+
+// Command returns the protocol command string for the message
+func (msg *BackupDatabaseRequestMessage) Command() MessageCommand {
+	return CmdBackupDatabaseRequestMessage
+}
+
+// NewBackupDatabaseRequestMessage returns a instance of the message
+func NewBackupDatabaseRequestMessage(path string) *BackupDatabaseRequestMessage {
+	return &BackupDatabaseRequestMessage{
+		Path: path,
+	}
+}
+
+// BackupDatabaseResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type BackupDatabaseResponseMessage struct {
+	baseMessage
+	EntryCount uint64
+	Size       uint64
+	Checksum   string
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *BackupDatabaseResponseMessage) Command() MessageCommand {
+	return CmdBackupDatabaseResponseMessage
+}
+
+// NewBackupDatabaseResponseMessage returns a instance of the message
+func NewBackupDatabaseResponseMessage(entryCount uint64, size uint64, checksum string) *BackupDatabaseResponseMessage {
+	return &BackupDatabaseResponseMessage{
+		EntryCount: entryCount,
+		Size:       size,
+		Checksum:   checksum,
+	}
+}
@@ -0,0 +1,45 @@
+package appmessage
+
+// GenerateBlocksRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GenerateBlocksRequestMessage struct {
+	baseMessage
+	Count        uint32
+	PayAddress   string
+	ParentHashes []string
+}
+
+// Command returns the protocol command string for the message
+func (msg *GenerateBlocksRequestMessage) Command() MessageCommand {
+	return CmdGenerateBlocksRequestMessage
+}
+
+// NewGenerateBlocksRequestMessage returns a instance of the message
+func NewGenerateBlocksRequestMessage(count uint32, payAddress string, parentHashes []string) *GenerateBlocksRequestMessage {
+	return &GenerateBlocksRequestMessage{
+		Count:        count,
+		PayAddress:   payAddress,
+		ParentHashes: parentHashes,
+	}
+}
+
+// GenerateBlocksResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GenerateBlocksResponseMessage struct {
+	baseMessage
+	BlockHashes []string
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GenerateBlocksResponseMessage) Command() MessageCommand {
+	return CmdGenerateBlocksResponseMessage
+}
+
+// NewGenerateBlocksResponseMessage returns a instance of the message
+func NewGenerateBlocksResponseMessage(blockHashes []string) *GenerateBlocksResponseMessage {
+	return &GenerateBlocksResponseMessage{
+		BlockHashes: blockHashes,
+	}
+}
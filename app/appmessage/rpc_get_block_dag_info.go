@@ -30,6 +30,13 @@ type GetBlockDAGInfoResponseMessage struct {
 	PruningPointHash    string
 	VirtualDAAScore     uint64
 
+	// The dev fee that blocks pay at VirtualDAAScore. DevFeeAddress is empty
+	// if no dev fee is paid.
+	DevFeeAddress            string
+	DevFeePercent            uint64
+	DevFeeActivationDAAScore uint64
+	DevFeeExpiryDAAScore     uint64
+
 	Error *RPCError
 }
 
@@ -0,0 +1,49 @@
+package appmessage
+
+// GetCacheStatsRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetCacheStatsRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetCacheStatsRequestMessage) Command() MessageCommand {
+	return CmdGetCacheStatsRequestMessage
+}
+
+// NewGetCacheStatsRequestMessage returns a instance of the message
+func NewGetCacheStatsRequestMessage() *GetCacheStatsRequestMessage {
+	return &GetCacheStatsRequestMessage{}
+}
+
+// GetCacheStatsResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetCacheStatsResponseMessage struct {
+	baseMessage
+	MemoryBudgetMiB uint64
+	CacheStats      []*CacheStats
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetCacheStatsResponseMessage) Command() MessageCommand {
+	return CmdGetCacheStatsResponseMessage
+}
+
+// NewGetCacheStatsResponseMessage returns a instance of the message
+func NewGetCacheStatsResponseMessage(memoryBudgetMiB uint64, cacheStats []*CacheStats) *GetCacheStatsResponseMessage {
+	return &GetCacheStatsResponseMessage{
+		MemoryBudgetMiB: memoryBudgetMiB,
+		CacheStats:      cacheStats,
+	}
+}
+
+// CacheStats describes the usage of the cache of a consensus store
+type CacheStats struct {
+	Name     string
+	Entries  uint64
+	Capacity uint64
+	Hits     uint64
+	Misses   uint64
+}
@@ -23,6 +23,11 @@ type GetCoinSupplyResponseMessage struct {
 	MaxSompi         uint64
 	CirculatingSompi uint64
 
+	// The dev fee that blocks pay at the virtual DAA score. DevFeeAddress is
+	// empty if no dev fee is paid.
+	DevFeeAddress string
+	DevFeePercent uint64
+
 	Error *RPCError
 }
 
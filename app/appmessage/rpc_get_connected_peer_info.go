@@ -47,4 +47,6 @@ type GetConnectedPeerInfoMessage struct {
 	AdvertisedProtocolVersion uint32
 	TimeConnected             int64
 	IsIBDPeer                 bool
+	Services                  uint64
+	NegotiatedFeatures        []string
 }
@@ -0,0 +1,49 @@
+package appmessage
+
+// GetDAGGraphRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetDAGGraphRequestMessage struct {
+	baseMessage
+	LowHash    string
+	HighHash   string
+	BlockCount uint32
+	Format     string
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetDAGGraphRequestMessage) Command() MessageCommand {
+	return CmdGetDAGGraphRequestMessage
+}
+
+// NewGetDAGGraphRequestMessage returns a instance of the message
+func NewGetDAGGraphRequestMessage(lowHash string, highHash string, blockCount uint32, format string) *GetDAGGraphRequestMessage {
+	return &GetDAGGraphRequestMessage{
+		LowHash:    lowHash,
+		HighHash:   highHash,
+		BlockCount: blockCount,
+		Format:     format,
+	}
+}
+
+// GetDAGGraphResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetDAGGraphResponseMessage struct {
+	baseMessage
+	Graph      string
+	BlockCount uint32
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetDAGGraphResponseMessage) Command() MessageCommand {
+	return CmdGetDAGGraphResponseMessage
+}
+
+// NewGetDAGGraphResponseMessage returns a instance of the message
+func NewGetDAGGraphResponseMessage(graph string, blockCount uint32) *GetDAGGraphResponseMessage {
+	return &GetDAGGraphResponseMessage{
+		Graph:      graph,
+		BlockCount: blockCount,
+	}
+}
@@ -0,0 +1,48 @@
+package appmessage
+
+// GetPruningPointProofRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetPruningPointProofRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetPruningPointProofRequestMessage) Command() MessageCommand {
+	return CmdGetPruningPointProofRequestMessage
+}
+
+// NewGetPruningPointProofRequestMessage returns a instance of the message
+func NewGetPruningPointProofRequestMessage() *GetPruningPointProofRequestMessage {
+	return &GetPruningPointProofRequestMessage{}
+}
+
+// GetPruningPointProofResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetPruningPointProofResponseMessage struct {
+	baseMessage
+	PruningPointHash string
+	Levels           []*RPCPruningPointProofLevel
+
+	Error *RPCError
+}
+
+// RPCPruningPointProofLevel holds the headers of a single level of a pruning
+// point proof
+type RPCPruningPointProofLevel struct {
+	Headers []*RPCBlockHeader
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetPruningPointProofResponseMessage) Command() MessageCommand {
+	return CmdGetPruningPointProofResponseMessage
+}
+
+// NewGetPruningPointProofResponseMessage returns a instance of the message
+func NewGetPruningPointProofResponseMessage(pruningPointHash string,
+	levels []*RPCPruningPointProofLevel) *GetPruningPointProofResponseMessage {
+
+	return &GetPruningPointProofResponseMessage{
+		PruningPointHash: pruningPointHash,
+		Levels:           levels,
+	}
+}
@@ -0,0 +1,37 @@
+package appmessage
+
+// SetDatabaseOptionsRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type SetDatabaseOptionsRequestMessage struct {
+	baseMessage
+	SyncWrites bool
+}
+
+// Command returns the protocol command string for the message
+func (msg *SetDatabaseOptionsRequestMessage) Command() MessageCommand {
+	return CmdSetDatabaseOptionsRequestMessage
+}
+
+// NewSetDatabaseOptionsRequestMessage returns a instance of the message
+func NewSetDatabaseOptionsRequestMessage(syncWrites bool) *SetDatabaseOptionsRequestMessage {
+	return &SetDatabaseOptionsRequestMessage{
+		SyncWrites: syncWrites,
+	}
+}
+
+// SetDatabaseOptionsResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type SetDatabaseOptionsResponseMessage struct {
+	baseMessage
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *SetDatabaseOptionsResponseMessage) Command() MessageCommand {
+	return CmdSetDatabaseOptionsResponseMessage
+}
+
+// NewSetDatabaseOptionsResponseMessage returns a instance of the message
+func NewSetDatabaseOptionsResponseMessage() *SetDatabaseOptionsResponseMessage {
+	return &SetDatabaseOptionsResponseMessage{}
+}
@@ -0,0 +1,47 @@
+package appmessage
+
+// SetNodeTimeRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type SetNodeTimeRequestMessage struct {
+	baseMessage
+	TimeInMilliseconds  int64
+	AdvanceMilliseconds int64
+	Reset               bool
+}
+
+// Command returns the protocol command string for the message
+func (msg *SetNodeTimeRequestMessage) Command() MessageCommand {
+	return CmdSetNodeTimeRequestMessage
+}
+
+// NewSetNodeTimeRequestMessage returns a instance of the message
+func NewSetNodeTimeRequestMessage(timeInMilliseconds int64, advanceMilliseconds int64, reset bool) *SetNodeTimeRequestMessage {
+	return &SetNodeTimeRequestMessage{
+		TimeInMilliseconds:  timeInMilliseconds,
+		AdvanceMilliseconds: advanceMilliseconds,
+		Reset:               reset,
+	}
+}
+
+// SetNodeTimeResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type SetNodeTimeResponseMessage struct {
+	baseMessage
+	TimeInMilliseconds   int64
+	OffsetInMilliseconds int64
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *SetNodeTimeResponseMessage) Command() MessageCommand {
+	return CmdSetNodeTimeResponseMessage
+}
+
+// NewSetNodeTimeResponseMessage returns a instance of the message
+func NewSetNodeTimeResponseMessage(timeInMilliseconds int64, offsetInMilliseconds int64) *SetNodeTimeResponseMessage {
+	return &SetNodeTimeResponseMessage{
+		TimeInMilliseconds:   timeInMilliseconds,
+		OffsetInMilliseconds: offsetInMilliseconds,
+	}
+}
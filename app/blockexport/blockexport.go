@@ -0,0 +1,34 @@
+/*
+Package blockexport implements exporting the blocks of the DAG into a portable
+file, and importing them into another node without using the P2P protocol.
+
+Blocks are written in topological order, so that every block in the file
+appears after all of its parents, and are imported through the regular block
+validation. An export starts above the pruning point, or above genesis on
+archival nodes, so the importing node must already have the starting point of
+the export, e.g. by having imported a snapshot of the same pruning point.
+*/
+package blockexport
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+const (
+	// fileKind is the kind written to the header of block export files
+	fileKind = "blocks"
+
+	// progressFileSuffix is appended to the path of an imported file to get
+	// the path of the file that records how much of it was already imported
+	progressFileSuffix = ".progress"
+)
+
+// progressInterval is the number of blocks after which progress is reported
+// and, while importing, persisted. It's a variable so that tests can lower it.
+var progressInterval uint64 = 1000
+
+func unexpectedMessageError(message appmessage.Message) error {
+	return errors.Errorf("unexpected message in block export file. expected: %s, got: %s",
+		appmessage.CmdBlock, message.Command())
+}
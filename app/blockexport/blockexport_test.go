@@ -0,0 +1,300 @@
+package blockexport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/model/testapi"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+// testProgressInterval replaces progressInterval in the tests, so that
+// progress is persisted several times over a small DAG
+const testProgressInterval = 5
+
+var errInterrupted = errors.New("interrupted")
+
+// countingConsensus counts the blocks that Import processes and inserts, and
+// lets a test run code before every insertion
+type countingConsensus struct {
+	externalapi.Consensus
+	processedCount int
+	insertedCount  int
+	beforeInsert   func(insertedCount int) error
+}
+
+func (c *countingConsensus) GetBlockInfo(blockHash *externalapi.DomainHash) (*externalapi.BlockInfo, error) {
+	c.processedCount++
+	return c.Consensus.GetBlockInfo(blockHash)
+}
+
+func (c *countingConsensus) ValidateAndInsertBlock(block *externalapi.DomainBlock, updateVirtual bool) error {
+	if c.beforeInsert != nil {
+		err := c.beforeInsert(c.insertedCount)
+		if err != nil {
+			return err
+		}
+	}
+	c.insertedCount++
+	return c.Consensus.ValidateAndInsertBlock(block, updateVirtual)
+}
+
+func setProgressInterval(t *testing.T, interval uint64) {
+	originalProgressInterval := progressInterval
+	progressInterval = interval
+	t.Cleanup(func() { progressInterval = originalProgressInterval })
+}
+
+func newTestConsensus(t *testing.T, name string) (testapi.TestConsensus, *consensus.Config) {
+	consensusConfig := &consensus.Config{Params: dagconfig.MainnetParams}
+	consensusConfig.SkipProofOfWork = true
+	factory := consensus.NewFactory()
+	tc, teardown, err := factory.NewTestConsensus(consensusConfig, name)
+	if err != nil {
+		t.Fatalf("Error setting up consensus: %+v", err)
+	}
+	t.Cleanup(func() { teardown(false) })
+	return tc, consensusConfig
+}
+
+// exportTestDAG builds a DAG with a side chain next to the selected chain and
+// a tip in the anticone of the virtual selected parent, and exports it. It
+// returns the path of the export and the hashes of the exported blocks.
+func exportTestDAG(t *testing.T, tc testapi.TestConsensus, params *dagconfig.Params) (string, []*externalapi.DomainHash) {
+	addBlock := func(parentHashes ...*externalapi.DomainHash) *externalapi.DomainHash {
+		blockHash, _, err := tc.AddBlock(parentHashes, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		return blockHash
+	}
+
+	// genesis <- chain[0] <- ... <- chain[19]
+	//                 ^
+	//                 side[0] <- ... <- side[4]
+	// with chain[10] merging side[4], and a tip on chain[17] next to chain[18]
+	var blockHashes []*externalapi.DomainHash
+	chainTip := params.GenesisHash
+	var sideTip *externalapi.DomainHash
+	for i := 0; i < 20; i++ {
+		parentHashes := []*externalapi.DomainHash{chainTip}
+		if i == 10 {
+			parentHashes = append(parentHashes, sideTip)
+		}
+		chainTip = addBlock(parentHashes...)
+		blockHashes = append(blockHashes, chainTip)
+
+		if i == 0 {
+			sideTip = chainTip
+			for j := 0; j < 5; j++ {
+				sideTip = addBlock(sideTip)
+				blockHashes = append(blockHashes, sideTip)
+			}
+		}
+		if i == 17 {
+			blockHashes = append(blockHashes, addBlock(chainTip))
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "blocks")
+	err := Export(tc, params, path, true)
+	if err != nil {
+		t.Fatalf("Export: %+v", err)
+	}
+	return path, blockHashes
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	setProgressInterval(t, testProgressInterval)
+
+	exportingConsensus, consensusConfig := newTestConsensus(t, "TestExportImportRoundTrip-export")
+	path, blockHashes := exportTestDAG(t, exportingConsensus, &consensusConfig.Params)
+
+	importingConsensus, _ := newTestConsensus(t, "TestExportImportRoundTrip-import")
+	err := Import(importingConsensus, &consensusConfig.Params, path)
+	if err != nil {
+		t.Fatalf("Import: %+v", err)
+	}
+
+	for _, blockHash := range blockHashes {
+		blockInfo, err := importingConsensus.GetBlockInfo(blockHash)
+		if err != nil {
+			t.Fatalf("GetBlockInfo: %+v", err)
+		}
+		if !blockInfo.HasBody() {
+			t.Fatalf("block %s was not imported", blockHash)
+		}
+	}
+
+	expectedTips, err := exportingConsensus.Tips()
+	if err != nil {
+		t.Fatalf("Tips: %+v", err)
+	}
+	tips, err := importingConsensus.Tips()
+	if err != nil {
+		t.Fatalf("Tips: %+v", err)
+	}
+	if !sameHashSet(expectedTips, tips) {
+		t.Fatalf("expected the tips %s but got %s", expectedTips, tips)
+	}
+
+	expectedVirtualSelectedParent, err := exportingConsensus.GetVirtualSelectedParent()
+	if err != nil {
+		t.Fatalf("GetVirtualSelectedParent: %+v", err)
+	}
+	virtualSelectedParent, err := importingConsensus.GetVirtualSelectedParent()
+	if err != nil {
+		t.Fatalf("GetVirtualSelectedParent: %+v", err)
+	}
+	if !virtualSelectedParent.Equal(expectedVirtualSelectedParent) {
+		t.Fatalf("expected the virtual selected parent %s but got %s",
+			expectedVirtualSelectedParent, virtualSelectedParent)
+	}
+
+	_, err = os.Stat(path + progressFileSuffix)
+	if !os.IsNotExist(err) {
+		t.Fatalf("expected the progress file to be removed after the import, but got: %v", err)
+	}
+
+	// Importing the same blocks again skips all of them
+	reimportingConsensus := &countingConsensus{Consensus: importingConsensus}
+	err = Import(reimportingConsensus, &consensusConfig.Params, path)
+	if err != nil {
+		t.Fatalf("Import: %+v", err)
+	}
+	if reimportingConsensus.insertedCount != 0 {
+		t.Fatalf("expected no blocks to be inserted again, but %d were", reimportingConsensus.insertedCount)
+	}
+}
+
+func TestImportResumesAfterInterruption(t *testing.T) {
+	setProgressInterval(t, testProgressInterval)
+
+	exportingConsensus, consensusConfig := newTestConsensus(t, "TestImportResumesAfterInterruption-export")
+	path, blockHashes := exportTestDAG(t, exportingConsensus, &consensusConfig.Params)
+
+	// Interrupt the import in the middle of the third progress interval.
+	// The progress that was persisted last is of the second one.
+	const interruptedAt = 2*testProgressInterval + 2
+	const persistedProgress = 2 * testProgressInterval
+	importingConsensus, _ := newTestConsensus(t, "TestImportResumesAfterInterruption-import")
+	interruptedConsensus := &countingConsensus{
+		Consensus: importingConsensus,
+		beforeInsert: func(insertedCount int) error {
+			if insertedCount == interruptedAt {
+				return errInterrupted
+			}
+			return nil
+		},
+	}
+	err := Import(interruptedConsensus, &consensusConfig.Params, path)
+	if !errors.Is(err, errInterrupted) {
+		t.Fatalf("expected the import to be interrupted, but got: %+v", err)
+	}
+
+	progress, err := readProgress(path + progressFileSuffix)
+	if err != nil {
+		t.Fatalf("readProgress: %+v", err)
+	}
+	if progress != persistedProgress {
+		t.Fatalf("expected the progress %d to be persisted, but got %d", persistedProgress, progress)
+	}
+
+	// The resumed import starts right after the persisted progress. The
+	// blocks that were imported after it are only skipped.
+	resumedConsensus := &countingConsensus{Consensus: importingConsensus}
+	err = Import(resumedConsensus, &consensusConfig.Params, path)
+	if err != nil {
+		t.Fatalf("Import: %+v", err)
+	}
+	expectedProcessedCount := len(blockHashes) - persistedProgress
+	if resumedConsensus.processedCount != expectedProcessedCount {
+		t.Fatalf("expected the resumed import to process %d blocks, but it processed %d",
+			expectedProcessedCount, resumedConsensus.processedCount)
+	}
+	expectedInsertedCount := len(blockHashes) - interruptedAt
+	if resumedConsensus.insertedCount != expectedInsertedCount {
+		t.Fatalf("expected the resumed import to insert %d blocks, but it inserted %d",
+			expectedInsertedCount, resumedConsensus.insertedCount)
+	}
+
+	for _, blockHash := range blockHashes {
+		blockInfo, err := importingConsensus.GetBlockInfo(blockHash)
+		if err != nil {
+			t.Fatalf("GetBlockInfo: %+v", err)
+		}
+		if !blockInfo.HasBody() {
+			t.Fatalf("block %s was not imported", blockHash)
+		}
+	}
+	_, err = os.Stat(path + progressFileSuffix)
+	if !os.IsNotExist(err) {
+		t.Fatalf("expected the progress file to be removed after the import, but got: %v", err)
+	}
+}
+
+func TestImportProgress(t *testing.T) {
+	setProgressInterval(t, testProgressInterval)
+
+	exportingConsensus, consensusConfig := newTestConsensus(t, "TestImportProgress-export")
+	path, blockHashes := exportTestDAG(t, exportingConsensus, &consensusConfig.Params)
+	progressPath := path + progressFileSuffix
+
+	// Before every insertion, the persisted progress is the last full
+	// progress interval of the blocks that were already inserted
+	importingConsensus, _ := newTestConsensus(t, "TestImportProgress-import")
+	progressCheckingConsensus := &countingConsensus{
+		Consensus: importingConsensus,
+		beforeInsert: func(insertedCount int) error {
+			expectedProgress := uint64(insertedCount) / testProgressInterval * testProgressInterval
+			progress, err := readProgress(progressPath)
+			if err != nil {
+				return err
+			}
+			if progress != expectedProgress {
+				return errors.Errorf("expected the progress %d after %d blocks, but got %d",
+					expectedProgress, insertedCount, progress)
+			}
+			return nil
+		},
+	}
+	err := Import(progressCheckingConsensus, &consensusConfig.Params, path)
+	if err != nil {
+		t.Fatalf("Import: %+v", err)
+	}
+	if progressCheckingConsensus.insertedCount != len(blockHashes) {
+		t.Fatalf("expected %d blocks to be inserted, but %d were",
+			len(blockHashes), progressCheckingConsensus.insertedCount)
+	}
+
+	// A progress file that can't be parsed fails the import rather than
+	// restarting it from the first block
+	err = os.WriteFile(progressPath, []byte{1, 2, 3}, 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %+v", err)
+	}
+	err = Import(importingConsensus, &consensusConfig.Params, path)
+	if err == nil {
+		t.Fatalf("expected the import to fail with a corrupt progress file")
+	}
+}
+
+func sameHashSet(a, b []*externalapi.DomainHash) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	hashes := make(map[externalapi.DomainHash]struct{}, len(a))
+	for _, hash := range a {
+		hashes[*hash] = struct{}{}
+	}
+	for _, hash := range b {
+		if _, ok := hashes[*hash]; !ok {
+			return false
+		}
+	}
+	return true
+}
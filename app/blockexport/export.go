@@ -0,0 +1,120 @@
+package blockexport
+
+import (
+	"sort"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/messagefile"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/infrastructure/logger"
+	"github.com/pkg/errors"
+)
+
+// Export writes the blocks of the given consensus to the given path in
+// topological order. If fromGenesis is set all the blocks above genesis are
+// exported, which is only possible on archival nodes. Otherwise, the export
+// starts above the pruning point.
+func Export(consensus externalapi.Consensus, params *dagconfig.Params, path string, fromGenesis bool) error {
+	onEnd := logger.LogAndMeasureExecutionTime(log, "blockexport.Export")
+	defer onEnd()
+
+	writer, err := messagefile.Create(path, &messagefile.Header{Kind: fileKind, Network: params.Name})
+	if err != nil {
+		return err
+	}
+
+	err = exportToWriter(consensus, params, writer, fromGenesis)
+	if err != nil {
+		writer.Abort()
+		return err
+	}
+	return writer.Close()
+}
+
+func exportToWriter(consensus externalapi.Consensus, params *dagconfig.Params,
+	writer *messagefile.Writer, fromGenesis bool) error {
+
+	lowHash := params.GenesisHash
+	if !fromGenesis {
+		pruningPoint, err := consensus.PruningPoint()
+		if err != nil {
+			return err
+		}
+		lowHash = pruningPoint
+	}
+	virtualSelectedParent, err := consensus.GetVirtualSelectedParent()
+	if err != nil {
+		return err
+	}
+	log.Infof("Exporting blocks from %s to the tips of the DAG", lowHash)
+
+	for !lowHash.Equal(virtualSelectedParent) {
+		// maxBlocks MUST be >= MergeSetSizeLimit + 1
+		const maxBlocks = 1 << 10
+		blockHashes, _, err := consensus.GetHashesBetween(lowHash, virtualSelectedParent, maxBlocks)
+		if err != nil {
+			return err
+		}
+
+		err = exportBlocks(consensus, writer, blockHashes)
+		if err != nil {
+			return err
+		}
+		lowHash = blockHashes[len(blockHashes)-1]
+	}
+
+	// GetHashesBetween only covers the past of the virtual selected parent, so
+	// the rest of the blocks are the ones in its anticone. These are sorted by
+	// blue work, which is always greater than the blue work of any parent.
+	anticone, err := consensus.Anticone(virtualSelectedParent)
+	if err != nil {
+		return err
+	}
+	blockInfos := make(map[externalapi.DomainHash]*externalapi.BlockInfo, len(anticone))
+	for _, blockHash := range anticone {
+		blockInfo, err := consensus.GetBlockInfo(blockHash)
+		if err != nil {
+			return err
+		}
+		blockInfos[*blockHash] = blockInfo
+	}
+	sort.Slice(anticone, func(i, j int) bool {
+		blueWorkI, blueWorkJ := blockInfos[*anticone[i]].BlueWork, blockInfos[*anticone[j]].BlueWork
+		if cmp := blueWorkI.Cmp(blueWorkJ); cmp != 0 {
+			return cmp < 0
+		}
+		return anticone[i].Less(anticone[j])
+	})
+	err = exportBlocks(consensus, writer, anticone)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Exported %d blocks", writer.RecordCount())
+	return nil
+}
+
+func exportBlocks(consensus externalapi.Consensus, writer *messagefile.Writer,
+	blockHashes []*externalapi.DomainHash) error {
+
+	for _, blockHash := range blockHashes {
+		block, found, err := consensus.GetBlock(blockHash)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.Errorf("the body of block %s is missing", blockHash)
+		}
+
+		err = writer.Write(appmessage.DomainBlockToMsgBlock(block))
+		if err != nil {
+			return err
+		}
+
+		if writer.RecordCount()%progressInterval == 0 {
+			log.Infof("Exported %d blocks so far (DAA score %d)", writer.RecordCount(), block.Header.DAAScore())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package blockexport
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/messagefile"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/ruleerrors"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/infrastructure/logger"
+	"github.com/pkg/errors"
+)
+
+// Import validates and inserts the blocks in the file at the given path into
+// the given consensus. The number of imported records is persisted next to the
+// file every progressInterval blocks, so an interrupted import resumes where it
+// stopped when it's run again.
+func Import(consensus externalapi.Consensus, params *dagconfig.Params, path string) error {
+	onEnd := logger.LogAndMeasureExecutionTime(log, "blockexport.Import")
+	defer onEnd()
+
+	reader, err := messagefile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	header := reader.Header()
+	if header.Kind != fileKind {
+		return errors.Errorf("%s is not a block export file (kind: %s)", path, header.Kind)
+	}
+	if header.Network != params.Name {
+		return errors.Errorf("block export %s belongs to network %s, but the node is running on %s",
+			path, header.Network, params.Name)
+	}
+
+	progressPath := path + progressFileSuffix
+	importedRecordCount, err := readProgress(progressPath)
+	if err != nil {
+		return err
+	}
+	if importedRecordCount > 0 {
+		log.Infof("Resuming the import of %s after %d blocks", path, importedRecordCount)
+		err := reader.Skip(importedRecordCount)
+		if err != nil {
+			return err
+		}
+	}
+
+	var highestDAAScore uint64
+	for {
+		message, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		msgBlock, ok := message.(*appmessage.MsgBlock)
+		if !ok {
+			return unexpectedMessageError(message)
+		}
+		block := appmessage.MsgBlockToDomainBlock(msgBlock)
+		err = importBlock(consensus, block)
+		if err != nil {
+			return err
+		}
+		highestDAAScore = block.Header.DAAScore()
+
+		if reader.RecordCount()%progressInterval == 0 {
+			err := writeProgress(progressPath, reader.RecordCount())
+			if err != nil {
+				return err
+			}
+			log.Infof("Imported %d blocks (%.2f%%, DAA score %d)",
+				reader.RecordCount(), reader.Progress()*100, highestDAAScore)
+		}
+	}
+	log.Infof("Imported all %d blocks from %s. Resolving the virtual", reader.RecordCount(), path)
+
+	err = consensus.ResolveVirtual(func(virtualDAAScoreStart uint64, virtualDAAScore uint64) {
+		log.Infof("Resolving virtual. Current virtual DAA score: %d", virtualDAAScore)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(progressPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func importBlock(consensus externalapi.Consensus, block *externalapi.DomainBlock) error {
+	blockHash := consensushashing.BlockHash(block)
+	if len(block.Transactions) == 0 {
+		return errors.Errorf("block %s has no body", blockHash)
+	}
+
+	blockInfo, err := consensus.GetBlockInfo(blockHash)
+	if err != nil {
+		return err
+	}
+	if blockInfo.HasBody() {
+		log.Debugf("Skipping block %s as it's already in the DAG", blockHash)
+		return nil
+	}
+
+	// The virtual is resolved once at the end of the import, same as in IBD
+	err = consensus.ValidateAndInsertBlock(block, false)
+	if err != nil {
+		if errors.Is(err, ruleerrors.ErrDuplicateBlock) {
+			log.Debugf("Skipping block %s as it's already in the DAG", blockHash)
+			return nil
+		}
+		if errors.As(err, &ruleerrors.ErrMissingParents{}) {
+			return errors.Wrapf(err, "block %s is missing parents. The node must contain the block "+
+				"the export started from", blockHash)
+		}
+		return errors.Wrapf(err, "failed to insert block %s", blockHash)
+	}
+	return nil
+}
+
+func readProgress(progressPath string) (uint64, error) {
+	serializedProgress, err := os.ReadFile(progressPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if len(serializedProgress) != 8 {
+		return 0, errors.Errorf("progress file %s is corrupt", progressPath)
+	}
+	return binary.LittleEndian.Uint64(serializedProgress), nil
+}
+
+func writeProgress(progressPath string, importedRecordCount uint64) error {
+	serializedProgress := make([]byte, 8)
+	binary.LittleEndian.PutUint64(serializedProgress, importedRecordCount)
+
+	temporaryPath := progressPath + ".tmp"
+	err := os.WriteFile(temporaryPath, serializedProgress, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, progressPath)
+}
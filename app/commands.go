@@ -0,0 +1,359 @@
+package app
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/blockexport"
+	"github.com/ammm56/lings/app/snapshot"
+	"github.com/ammm56/lings/domain"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/pow"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/infrastructure/config"
+	"github.com/ammm56/lings/infrastructure/db/database"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/ammm56/lings/util/mstime"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// command is a one-shot operation on the node's database that runs instead of
+// the node itself. It's selected by the first positional command line argument,
+// and receives the rest of the positional arguments.
+type command struct {
+	usage string
+	run   func(cfg *config.Config, db database.Database, args []string) error
+
+	// withoutDatabase commands run before the database is opened, and
+	// receive a nil database
+	withoutDatabase bool
+}
+
+var commands = map[string]*command{
+	"export-snapshot": {
+		usage: "export-snapshot <file>",
+		run:   exportSnapshot,
+	},
+	"export-blocks": {
+		usage: "export-blocks <file>",
+		run:   exportBlocks,
+	},
+	"import-blocks": {
+		usage: "import-blocks <file>",
+		run:   importBlocks,
+	},
+	"migrate-db": {
+		usage: "migrate-db <dbtype>",
+		run:   migrateDatabase,
+	},
+	"gen-genesis": {
+		usage:           "gen-genesis <network-definition-file>",
+		run:             generateGenesis,
+		withoutDatabase: true,
+	},
+	"validate-pruning-proof": {
+		usage:           "validate-pruning-proof <file> [verbose]",
+		run:             validatePruningProof,
+		withoutDatabase: true,
+	},
+}
+
+// isCommandWithoutDatabase returns whether the command in the configuration
+// runs without the database
+func isCommandWithoutDatabase(cfg *config.Config) bool {
+	command, ok := commands[cfg.Command[0]]
+	return ok && command.withoutDatabase
+}
+
+func runCommand(cfg *config.Config, db database.Database) error {
+	name, args := cfg.Command[0], cfg.Command[1:]
+	command, ok := commands[name]
+	if !ok {
+		return errors.Errorf("unknown command '%s'. Available commands: %s", name, commandUsages())
+	}
+
+	log.Infof("Running command %s", name)
+	err := command.run(cfg, db, args)
+	if err != nil {
+		return errors.Wrapf(err, "command %s failed (usage: %s)", name, command.usage)
+	}
+	return nil
+}
+
+func commandUsages() string {
+	usages := make([]string, 0, len(commands))
+	for _, command := range commands {
+		usages = append(usages, command.usage)
+	}
+	sort.Strings(usages)
+	return strings.Join(usages, ", ")
+}
+
+func exportSnapshot(cfg *config.Config, db database.Database, args []string) error {
+	if len(args) != 1 {
+		return errors.Errorf("expected exactly one argument")
+	}
+
+	domain, err := newDomain(cfg, db, mstime.SystemClock)
+	if err != nil {
+		return err
+	}
+	return snapshot.Export(domain.Consensus(), cfg.ActiveNetParams, args[0])
+}
+
+// exportBlocks exports the blocks above the pruning point, or all the blocks
+// above genesis on archival nodes
+func exportBlocks(cfg *config.Config, db database.Database, args []string) error {
+	if len(args) != 1 {
+		return errors.Errorf("expected exactly one argument")
+	}
+
+	domain, err := newDomain(cfg, db, mstime.SystemClock)
+	if err != nil {
+		return err
+	}
+	return blockexport.Export(domain.Consensus(), cfg.ActiveNetParams, args[0], cfg.IsArchivalNode)
+}
+
+func importBlocks(cfg *config.Config, db database.Database, args []string) error {
+	if len(args) != 1 {
+		return errors.Errorf("expected exactly one argument")
+	}
+
+	domain, err := newDomain(cfg, db, mstime.SystemClock)
+	if err != nil {
+		return err
+	}
+	return blockexport.Import(domain.Consensus(), cfg.ActiveNetParams, args[0])
+}
+
+// migrateDatabase copies the database into a new database of the given type.
+// The new database is used once the node is started with the new dbtype, and
+// the old one is left untouched.
+func migrateDatabase(cfg *config.Config, db database.Database, args []string) error {
+	if len(args) != 1 {
+		return errors.Errorf("expected exactly one argument")
+	}
+	targetType := args[0]
+	if targetType == cfg.DbType {
+		return errors.Errorf("the database is already of type %s", targetType)
+	}
+
+	targetPath := databasePath(cfg, targetType)
+	_, err := os.Stat(targetPath)
+	if err == nil {
+		return errors.Errorf("%s already exists. Remove it to migrate the database again", targetPath)
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	// The database is copied into a temporary directory that is renamed
+	// once the copy is complete, so that an interrupted migration is never
+	// mistaken for a valid database
+	temporaryPath := targetPath + ".migrating"
+	err = os.RemoveAll(temporaryPath)
+	if err != nil {
+		return err
+	}
+	copiedEntries, err := copyDatabase(cfg, db, targetType, temporaryPath)
+	if err != nil {
+		removeErr := os.RemoveAll(temporaryPath)
+		if removeErr != nil {
+			log.Errorf("Failed to remove the partial database at %s: %s", temporaryPath, removeErr)
+		}
+		return err
+	}
+	err = os.Rename(temporaryPath, targetPath)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Copied %d entries to the %s database at %s. Start lings with --dbtype=%s to use it. "+
+		"The %s database at %s can be removed afterwards", copiedEntries, targetType, targetPath,
+		targetType, cfg.DbType, databasePath(cfg, cfg.DbType))
+	return nil
+}
+
+func copyDatabase(cfg *config.Config, source database.Database, targetType string, targetPath string) (uint64, error) {
+	err := checkDatabaseVersion(targetPath)
+	if err != nil {
+		return 0, err
+	}
+	target, err := openDatabase(cfg, targetType, targetPath)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Infof("Copying the database to the %s database at %s", targetType, targetPath)
+	copiedEntries, err := database.Copy(source, target, func(copiedEntries uint64) {
+		log.Infof("Copied %d entries", copiedEntries)
+	})
+	if err != nil {
+		closeErr := target.Close()
+		if closeErr != nil {
+			log.Errorf("Failed to close the database at %s: %s", targetPath, closeErr)
+		}
+		return 0, err
+	}
+	err = target.Close()
+	if err != nil {
+		return 0, err
+	}
+	return copiedEntries, nil
+}
+
+// generateGenesis mines the genesis block of the custom network defined in the
+// given network definition file, and logs the nonce and hash to set in it
+func generateGenesis(_ *config.Config, _ database.Database, args []string) error {
+	if len(args) != 1 {
+		return errors.Errorf("expected exactly one argument")
+	}
+
+	definition, err := dagconfig.LoadNetworkDefinition(args[0])
+	if err != nil {
+		return err
+	}
+	// The hash of the definition is the one being generated, so it's ignored
+	definition.Genesis.Hash = ""
+	params, err := definition.Params()
+	if err != nil {
+		return err
+	}
+
+	header := params.GenesisBlock.Header.ToMutable()
+	log.Infof("Mining the genesis block of %s from nonce %d", params.Name, header.Nonce())
+	state := pow.NewState(header)
+	for !state.CheckProofOfWork() {
+		state.IncrementNonce()
+		if state.Nonce == definition.Genesis.Nonce {
+			return errors.Errorf("no nonce satisfies the genesis bits %x", header.Bits())
+		}
+	}
+	header.SetNonce(state.Nonce)
+	genesisHash := consensushashing.HeaderHash(header)
+
+	log.Infof("Mined the genesis block of %s. Set the following in the genesis section of %s:\n"+
+		"nonce = %d\nhash = %s", params.Name, args[0], state.Nonce, genesisHash)
+	return nil
+}
+
+// validatePruningProof validates a pruning point proof that was saved with
+// `lingsctl --output-file=<file> GetPruningPointProof` against the parameters
+// of the network, the same way a new node validates the proof it receives
+// during IBD. With "verbose", the selected tip, blue score and blue work of
+// every level of the proof are logged as well.
+func validatePruningProof(cfg *config.Config, _ database.Database, args []string) error {
+	if len(args) != 1 && (len(args) != 2 || args[1] != "verbose") {
+		return errors.Errorf("expected a file and an optional 'verbose'")
+	}
+	isVerbose := len(args) == 2
+
+	pruningPointProof, err := readPruningPointProof(args[0])
+	if err != nil {
+		return err
+	}
+
+	// The proof is validated by a new consensus, so that it isn't compared to
+	// the DAG of this node
+	dataDir, err := os.MkdirTemp("", "lings-validate-pruning-proof")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := os.RemoveAll(dataDir)
+		if err != nil {
+			log.Errorf("Failed to remove %s: %s", dataDir, err)
+		}
+	}()
+	db, err := openDatabase(cfg, config.DbTypeLevelDB, dataDir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			log.Errorf("Failed to close the database at %s: %s", dataDir, err)
+		}
+	}()
+	domain, err := newDomain(cfg, db, mstime.SystemClock)
+	if err != nil {
+		return err
+	}
+
+	report, err := domain.Consensus().ValidatePruningPointProofWithReport(pruningPointProof)
+	if isVerbose {
+		log.Infof("%s", report)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "the pruning point proof of %s is invalid for %s", report.PruningPoint,
+			cfg.ActiveNetParams.Name)
+	}
+	log.Infof("The pruning point proof of %s is valid for %s", report.PruningPoint, cfg.ActiveNetParams.Name)
+	return nil
+}
+
+// readPruningPointProof reads a GetPruningPointProof response in JSON from
+// the given file
+func readPruningPointProof(path string) (*externalapi.PruningPointProof, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lingsMessage := &protowire.LingsMessage{}
+	err = protojson.Unmarshal(content, lingsMessage)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	message, err := lingsMessage.ToAppMessage()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	response, ok := message.(*appmessage.GetPruningPointProofResponseMessage)
+	if !ok {
+		return nil, errors.Errorf("%s holds a %s message rather than a %s message", path, message.Command(),
+			appmessage.CmdGetPruningPointProofResponseMessage)
+	}
+	if response.Error != nil {
+		return nil, errors.Errorf("%s holds an error response: %s", path, response.Error.Message)
+	}
+
+	pruningPointProof, err := appmessage.RPCPruningPointProofLevelsToDomainPruningPointProof(response.Levels)
+	if err != nil {
+		return nil, err
+	}
+	if len(pruningPointProof.Headers) == 0 || len(pruningPointProof.Headers[0]) == 0 {
+		return nil, errors.Errorf("the pruning point proof in %s is empty", path)
+	}
+	level0Headers := pruningPointProof.Headers[0]
+	pruningPoint := consensushashing.HeaderHash(level0Headers[len(level0Headers)-1])
+	if pruningPoint.String() != response.PruningPointHash {
+		return nil, errors.Errorf("the last header of level 0 in %s is %s rather than the pruning point %s",
+			path, pruningPoint, response.PruningPointHash)
+	}
+	return pruningPointProof, nil
+}
+
+// importSnapshot bootstraps the consensus from the snapshot file given in the
+// configuration. Nodes that already have a non-empty DAG ignore the snapshot,
+// so that the option can safely stay in the config file after the first run.
+func importSnapshot(cfg *config.Config, domain domain.Domain) error {
+	headersSelectedTip, err := domain.Consensus().GetHeadersSelectedTip()
+	if err != nil {
+		return err
+	}
+	if !headersSelectedTip.Equal(cfg.ActiveNetParams.GenesisHash) {
+		log.Infof("Ignoring --import-snapshot since the DAG is not empty")
+		return nil
+	}
+
+	err = snapshot.Import(domain, cfg.ActiveNetParams, cfg.ImportSnapshot)
+	if err != nil {
+		return errors.Wrapf(err, "failed to import snapshot %s", cfg.ImportSnapshot)
+	}
+	return nil
+}
@@ -19,7 +19,9 @@ import (
 	"github.com/ammm56/lings/infrastructure/network/connmanager"
 	"github.com/ammm56/lings/infrastructure/network/netadapter"
 	"github.com/ammm56/lings/infrastructure/network/netadapter/id"
+	"github.com/ammm56/lings/util/mstime"
 	"github.com/ammm56/lings/util/panics"
+	"github.com/pkg/errors"
 )
 
 // ComponentManager is a wrapper for all the lings services
@@ -79,20 +81,26 @@ func (a *ComponentManager) Stop() {
 func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database, interrupt chan<- struct{}) (
 	*ComponentManager, error) {
 
-	consensusConfig := consensus.Config{
-		Params:                          *cfg.ActiveNetParams,
-		IsArchival:                      cfg.IsArchivalNode,
-		EnableSanityCheckPruningUTXOSet: cfg.EnableSanityCheckPruningUTXOSet,
-	}
-	mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
-	mempoolConfig.MaximumOrphanTransactionCount = cfg.MaxOrphanTxs
-	mempoolConfig.MinimumRelayTransactionFee = cfg.MinRelayTxFee
-
-	domain, err := domain.New(&consensusConfig, mempoolConfig, db)
+	clock := mstime.NewAdjustableClock()
+	domain, err := newDomain(cfg, db, clock)
 	if err != nil {
 		return nil, err
 	}
 
+	if cfg.ConsistencyCheckDepth > 0 {
+		err := checkConsistency(cfg, domain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.ImportSnapshot != "" {
+		err := importSnapshot(cfg, domain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	netAdapter, err := netadapter.NewNetAdapter(cfg)
 	if err != nil {
 		return nil, err
@@ -121,7 +129,7 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 	if err != nil {
 		return nil, err
 	}
-	rpcManager := setupRPC(cfg, domain, netAdapter, protocolManager, connectionManager, addressManager, utxoIndex, domain.ConsensusEventsChannel(), interrupt)
+	rpcManager := setupRPC(cfg, domain, netAdapter, protocolManager, connectionManager, addressManager, utxoIndex, db, clock, domain.ConsensusEventsChannel(), interrupt)
 
 	return &ComponentManager{
 		cfg:               cfg,
@@ -134,6 +142,33 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 
 }
 
+// checkConsistency runs the consistency check of the consensus, and points
+// the user to --repair-consensus if it finds inconsistencies that it wasn't
+// allowed to repair
+func checkConsistency(cfg *config.Config, domainInstance domain.Domain) error {
+	err := domainInstance.CheckConsistency(cfg.ConsistencyCheckDepth, cfg.RepairConsensus)
+	if errors.Is(err, domain.ErrInconsistentConsensus) {
+		return errors.Wrap(err, "restart with --repair-consensus to rebuild the consensus from its intact data")
+	}
+	return err
+}
+
+func newDomain(cfg *config.Config, db infrastructuredatabase.Database, clock mstime.Clock) (domain.Domain, error) {
+	consensusConfig := consensus.Config{
+		Params:                          *cfg.ActiveNetParams,
+		IsArchival:                      cfg.IsArchivalNode,
+		EnableSanityCheckPruningUTXOSet: cfg.EnableSanityCheckPruningUTXOSet,
+		CacheMemoryBudgetMiB:            cfg.MemoryBudgetMiB,
+		Clock:                           clock,
+	}
+	mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+	mempoolConfig.Clock = clock
+	mempoolConfig.MaximumOrphanTransactionCount = cfg.MaxOrphanTxs
+	mempoolConfig.MinimumRelayTransactionFee = cfg.MinRelayTxFee
+
+	return domain.New(&consensusConfig, mempoolConfig, db)
+}
+
 func setupRPC(
 	cfg *config.Config,
 	domain domain.Domain,
@@ -142,6 +177,8 @@ func setupRPC(
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
+	db infrastructuredatabase.Database,
+	clock *mstime.AdjustableClock,
 	consensusEventsChan chan externalapi.ConsensusEvent,
 	shutDownChan chan<- struct{},
 ) *rpc.Manager {
@@ -154,6 +191,8 @@ func setupRPC(
 		connectionManager,
 		addressManager,
 		utxoIndex,
+		db,
+		clock,
 		consensusEventsChan,
 		shutDownChan,
 	)
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ammm56/lings/domain/prefixmanager"
+	"github.com/ammm56/lings/infrastructure/db/database"
+)
+
+// consensusBucketNames are the names of the largest buckets of the consensus
+// stores, in the order they're reported by logDatabaseSize
+var consensusBucketNames = []string{
+	"block-headers",
+	"blocks",
+	"virtual-utxo-set",
+	"pruning-point-utxo-set",
+	"utxo-diffs",
+	"acceptance-data",
+	"reachability-data",
+	"block-ghostdag-data",
+	"block-relations",
+	"daa-window",
+	"multisets",
+}
+
+var utxoIndexBucket = database.MakeBucket([]byte("utxo-index"))
+
+// logDatabaseSize logs the approximate disk space taken by the database and
+// by its largest buckets. Databases that can't report their size are skipped.
+func logDatabaseSize(db database.Database) error {
+	sizer, ok := db.(database.BucketSizer)
+	if !ok {
+		return nil
+	}
+
+	names := []string{"total"}
+	buckets := []*database.Bucket{database.MakeBucket(nil)}
+
+	activePrefix, exists, err := prefixmanager.ActivePrefix(db)
+	if err != nil {
+		return err
+	}
+	if exists {
+		prefixBucket := database.MakeBucket(activePrefix.Serialize())
+		for _, name := range consensusBucketNames {
+			names = append(names, name)
+			buckets = append(buckets, prefixBucket.Bucket([]byte(name)))
+		}
+	}
+	names = append(names, "utxo-index")
+	buckets = append(buckets, utxoIndexBucket)
+
+	sizes, err := sizer.BucketSizes(buckets)
+	if err != nil {
+		return err
+	}
+
+	bucketSizes := make([]string, 0, len(names)-1)
+	for i := 1; i < len(names); i++ {
+		bucketSizes = append(bucketSizes, fmt.Sprintf("%s: %s", names[i], formatBytes(sizes[i])))
+	}
+	log.Infof("Database size is %s (%s)", formatBytes(sizes[0]), strings.Join(bucketSizes, ", "))
+	return nil
+}
+
+func formatBytes(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	divisor, exponent := uint64(unit), 0
+	for quotient := size / unit; quotient >= unit; quotient /= unit {
+		divisor *= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(divisor), "KMGTPE"[exponent])
+}
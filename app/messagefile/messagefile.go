@@ -0,0 +1,372 @@
+/*
+Package messagefile implements a portable, checksummed file format that holds
+a sequence of appmessage messages.
+
+A message file starts with a header that holds a magic, a format version, the
+kind of data stored in the file and the name of the network it belongs to. The
+header is followed by records, each holding a single message serialized the same
+way it is serialized on the wire, and a CRC-32 checksum of it. The last record is
+an empty end marker followed by the total number of records, so that a truncated
+file is always detected.
+
+Files are written to a temporary path and moved into place only once they were
+fully written.
+*/
+package messagefile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	formatVersion = 1
+
+	// maxRecordLength is the maximum allowed length of a single serialized
+	// message. It protects the reader from allocating huge buffers on corrupt
+	// files.
+	maxRecordLength = 1 << 30
+
+	// maxHeaderFieldLength is the maximum allowed length of a string field in
+	// the file header.
+	maxHeaderFieldLength = 1 << 10
+
+	temporaryFileSuffix = ".tmp"
+)
+
+var magic = [8]byte{'L', 'I', 'N', 'G', 'S', 'M', 'S', 'G'}
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorruptFile is returned when a message file does not pass validation
+var ErrCorruptFile = errors.New("corrupt message file")
+
+// Header describes the contents of a message file
+type Header struct {
+	Kind    string
+	Network string
+}
+
+// Writer writes messages into a new message file
+type Writer struct {
+	path        string
+	file        *os.File
+	writer      *bufio.Writer
+	recordCount uint64
+}
+
+// Create creates a new message file with the given header at the given path.
+// The file only appears at the given path once Close is called.
+func Create(path string, header *Header) (*Writer, error) {
+	file, err := os.OpenFile(path+temporaryFileSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{
+		path:   path,
+		file:   file,
+		writer: bufio.NewWriter(file),
+	}
+
+	err = w.writeHeader(header)
+	if err != nil {
+		w.Abort()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) writeHeader(header *Header) error {
+	_, err := w.writer.Write(magic[:])
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w.writer, binary.LittleEndian, uint16(formatVersion))
+	if err != nil {
+		return err
+	}
+	for _, field := range []string{header.Kind, header.Network} {
+		if len(field) > maxHeaderFieldLength {
+			return errors.Errorf("header field %s is too long", field)
+		}
+		err = binary.Write(w.writer, binary.LittleEndian, uint16(len(field)))
+		if err != nil {
+			return err
+		}
+		_, err = w.writer.WriteString(field)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write appends the given message to the file
+func (w *Writer) Write(message appmessage.Message) error {
+	lingsMessage, err := protowire.FromAppMessage(message)
+	if err != nil {
+		return err
+	}
+	serializedMessage, err := proto.Marshal(lingsMessage)
+	if err != nil {
+		return err
+	}
+	if len(serializedMessage) == 0 || len(serializedMessage) > maxRecordLength {
+		return errors.Errorf("message %s has an invalid serialized length %d",
+			message.Command(), len(serializedMessage))
+	}
+
+	err = w.writeRecord(serializedMessage)
+	if err != nil {
+		return err
+	}
+	w.recordCount++
+	return nil
+}
+
+func (w *Writer) writeRecord(serializedMessage []byte) error {
+	err := binary.Write(w.writer, binary.LittleEndian, uint32(len(serializedMessage)))
+	if err != nil {
+		return err
+	}
+	_, err = w.writer.Write(serializedMessage)
+	if err != nil {
+		return err
+	}
+	return binary.Write(w.writer, binary.LittleEndian, crc32.Checksum(serializedMessage, crcTable))
+}
+
+// RecordCount returns the number of messages written so far
+func (w *Writer) RecordCount() uint64 {
+	return w.recordCount
+}
+
+// Close writes the end marker, flushes the file to disk and moves it to its
+// final path
+func (w *Writer) Close() error {
+	err := binary.Write(w.writer, binary.LittleEndian, uint32(0))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w.writer, binary.LittleEndian, w.recordCount)
+	if err != nil {
+		return err
+	}
+	err = w.writer.Flush()
+	if err != nil {
+		return err
+	}
+	err = w.file.Sync()
+	if err != nil {
+		return err
+	}
+	err = w.file.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(w.path+temporaryFileSuffix, w.path)
+}
+
+// Abort closes the writer and removes the partially written file
+func (w *Writer) Abort() {
+	_ = w.file.Close()
+	_ = os.Remove(w.path + temporaryFileSuffix)
+}
+
+// Reader reads messages from a message file
+type Reader struct {
+	file        *os.File
+	reader      *bufio.Reader
+	header      *Header
+	recordCount uint64
+	isDone      bool
+	fileSize    int64
+	bytesRead   int64
+}
+
+// Open opens the message file at the given path and reads its header
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fileInfo, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	r := &Reader{
+		file:     file,
+		reader:   bufio.NewReader(file),
+		fileSize: fileInfo.Size(),
+	}
+
+	r.header, err = r.readHeader()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reader) readHeader() (*Header, error) {
+	var fileMagic [len(magic)]byte
+	_, err := io.ReadFull(r.reader, fileMagic[:])
+	if err != nil {
+		return nil, corruptionError(err, "could not read the magic")
+	}
+	if fileMagic != magic {
+		return nil, errors.Wrapf(ErrCorruptFile, "unexpected magic %x", fileMagic)
+	}
+
+	var version uint16
+	err = binary.Read(r.reader, binary.LittleEndian, &version)
+	if err != nil {
+		return nil, corruptionError(err, "could not read the format version")
+	}
+	if version != formatVersion {
+		return nil, errors.Errorf("unsupported message file format version %d", version)
+	}
+
+	fields := make([]string, 2)
+	for i := range fields {
+		var length uint16
+		err = binary.Read(r.reader, binary.LittleEndian, &length)
+		if err != nil {
+			return nil, corruptionError(err, "could not read the header")
+		}
+		if length > maxHeaderFieldLength {
+			return nil, errors.Wrapf(ErrCorruptFile, "header field length %d is too long", length)
+		}
+		field := make([]byte, length)
+		_, err = io.ReadFull(r.reader, field)
+		if err != nil {
+			return nil, corruptionError(err, "could not read the header")
+		}
+		fields[i] = string(field)
+	}
+
+	r.bytesRead = int64(len(magic)) + 2 + 2*int64(len(fields)) + int64(len(fields[0])+len(fields[1]))
+	return &Header{
+		Kind:    fields[0],
+		Network: fields[1],
+	}, nil
+}
+
+// Header returns the header of the file
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// RecordCount returns the number of messages read so far
+func (r *Reader) RecordCount() uint64 {
+	return r.recordCount
+}
+
+// Progress returns the fraction of the file that was read so far, between 0 and 1
+func (r *Reader) Progress() float64 {
+	if r.isDone || r.fileSize == 0 {
+		return 1
+	}
+	return float64(r.bytesRead) / float64(r.fileSize)
+}
+
+// Read reads the next message from the file. It returns io.EOF once the end
+// marker had been reached.
+func (r *Reader) Read() (appmessage.Message, error) {
+	serializedMessage, err := r.readRecord()
+	if err != nil {
+		return nil, err
+	}
+
+	lingsMessage := &protowire.LingsMessage{}
+	err = proto.Unmarshal(serializedMessage, lingsMessage)
+	if err != nil {
+		return nil, errors.Wrapf(ErrCorruptFile, "could not deserialize record %d: %s", r.recordCount, err)
+	}
+	message, err := lingsMessage.ToAppMessage()
+	if err != nil {
+		return nil, errors.Wrapf(ErrCorruptFile, "could not convert record %d: %s", r.recordCount, err)
+	}
+	r.recordCount++
+	return message, nil
+}
+
+// Skip skips the given number of messages without deserializing them. Checksums
+// are still validated.
+func (r *Reader) Skip(count uint64) error {
+	for i := uint64(0); i < count; i++ {
+		_, err := r.readRecord()
+		if err != nil {
+			return err
+		}
+		r.recordCount++
+	}
+	return nil
+}
+
+func (r *Reader) readRecord() ([]byte, error) {
+	if r.isDone {
+		return nil, io.EOF
+	}
+
+	var length uint32
+	err := binary.Read(r.reader, binary.LittleEndian, &length)
+	if err != nil {
+		return nil, corruptionError(err, "could not read the length of record %d", r.recordCount)
+	}
+
+	if length == 0 {
+		var expectedRecordCount uint64
+		err := binary.Read(r.reader, binary.LittleEndian, &expectedRecordCount)
+		if err != nil {
+			return nil, corruptionError(err, "could not read the record count")
+		}
+		if expectedRecordCount != r.recordCount {
+			return nil, errors.Wrapf(ErrCorruptFile, "the file should contain %d records but %d were read",
+				expectedRecordCount, r.recordCount)
+		}
+		r.isDone = true
+		return nil, io.EOF
+	}
+
+	if length > maxRecordLength {
+		return nil, errors.Wrapf(ErrCorruptFile, "record %d has length %d which is too long", r.recordCount, length)
+	}
+	serializedMessage := make([]byte, length)
+	_, err = io.ReadFull(r.reader, serializedMessage)
+	if err != nil {
+		return nil, corruptionError(err, "could not read record %d", r.recordCount)
+	}
+
+	var checksum uint32
+	err = binary.Read(r.reader, binary.LittleEndian, &checksum)
+	if err != nil {
+		return nil, corruptionError(err, "could not read the checksum of record %d", r.recordCount)
+	}
+	if checksum != crc32.Checksum(serializedMessage, crcTable) {
+		return nil, errors.Wrapf(ErrCorruptFile, "checksum mismatch in record %d", r.recordCount)
+	}
+	r.bytesRead += int64(4 + len(serializedMessage) + 4)
+	return serializedMessage, nil
+}
+
+// Close closes the file
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+func corruptionError(err error, format string, args ...interface{}) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errors.Wrapf(ErrCorruptFile, "unexpected end of file: "+format, args...)
+	}
+	return errors.Wrapf(err, format, args...)
+}
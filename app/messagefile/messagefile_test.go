@@ -0,0 +1,154 @@
+package messagefile
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func writeTestFile(t *testing.T, path string, nonces []uint64) {
+	writer, err := Create(path, &Header{Kind: "test", Network: "lings-simnet"})
+	if err != nil {
+		t.Fatalf("Create: %s", err)
+	}
+	for _, nonce := range nonces {
+		err := writer.Write(appmessage.NewMsgPing(nonce))
+		if err != nil {
+			t.Fatalf("Write: %s", err)
+		}
+	}
+	err = writer.Close()
+	if err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages")
+	nonces := []uint64{1, 2, 3, 1 << 60}
+	writeTestFile(t, path, nonces)
+
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	defer reader.Close()
+
+	if reader.Header().Kind != "test" || reader.Header().Network != "lings-simnet" {
+		t.Fatalf("unexpected header %+v", reader.Header())
+	}
+
+	for i, nonce := range nonces {
+		message, err := reader.Read()
+		if err != nil {
+			t.Fatalf("Read %d: %s", i, err)
+		}
+		ping, ok := message.(*appmessage.MsgPing)
+		if !ok {
+			t.Fatalf("unexpected message type %T", message)
+		}
+		if ping.Nonce != nonce {
+			t.Fatalf("expected nonce %d but got %d", nonce, ping.Nonce)
+		}
+	}
+	_, err = reader.Read()
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF but got %v", err)
+	}
+}
+
+func TestSkip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages")
+	writeTestFile(t, path, []uint64{10, 20, 30})
+
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	defer reader.Close()
+
+	err = reader.Skip(2)
+	if err != nil {
+		t.Fatalf("Skip: %s", err)
+	}
+	message, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read: %s", err)
+	}
+	if message.(*appmessage.MsgPing).Nonce != 30 {
+		t.Fatalf("unexpected message after skipping %+v", message)
+	}
+	if reader.RecordCount() != 3 {
+		t.Fatalf("expected record count 3 but got %d", reader.RecordCount())
+	}
+}
+
+func TestCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages")
+	writeTestFile(t, path, []uint64{1, 2, 3})
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{
+			name:    "truncated",
+			content: content[:len(content)-10],
+		},
+		{
+			name: "flipped bit",
+			content: func() []byte {
+				corrupted := append([]byte{}, content...)
+				corrupted[len(corrupted)-20] ^= 1
+				return corrupted
+			}(),
+		},
+		{
+			name: "bad magic",
+			content: func() []byte {
+				corrupted := append([]byte{}, content...)
+				corrupted[0] = 'X'
+				return corrupted
+			}(),
+		},
+	}
+
+	for _, test := range tests {
+		corruptedPath := filepath.Join(t.TempDir(), "corrupted")
+		err := os.WriteFile(corruptedPath, test.content, 0600)
+		if err != nil {
+			t.Fatalf("WriteFile: %s", err)
+		}
+
+		err = readAll(corruptedPath)
+		if !errors.Is(err, ErrCorruptFile) {
+			t.Errorf("%s: expected ErrCorruptFile but got %v", test.name, err)
+		}
+	}
+}
+
+func readAll(path string) error {
+	reader, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for {
+		_, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
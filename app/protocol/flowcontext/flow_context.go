@@ -62,6 +62,13 @@ type FlowContext struct {
 	lastTransactionIDPropagationTime time.Time
 	transactionIDPropagationLock     sync.Mutex
 
+	currentStemPeer       *peerpkg.Peer
+	stemPeerSelectionTime mstime.Time
+	stemPool              map[externalapi.DomainTransactionID]*stemTransaction
+	stemLock              sync.Mutex
+	stemClock             mstime.Clock
+	stemRandom            stemRandomSource
+
 	shutdownChan chan struct{}
 }
 
@@ -69,7 +76,7 @@ type FlowContext struct {
 func New(cfg *config.Config, domain domain.Domain, addressManager *addressmanager.AddressManager,
 	netAdapter *netadapter.NetAdapter, connectionManager *connmanager.ConnectionManager) *FlowContext {
 
-	return &FlowContext{
+	flowContext := &FlowContext{
 		cfg:                              cfg,
 		netAdapter:                       netAdapter,
 		domain:                           domain,
@@ -82,8 +89,17 @@ func New(cfg *config.Config, domain domain.Domain, addressManager *addressmanage
 		timeStarted:                      mstime.Now().UnixMilliseconds(),
 		transactionIDsToPropagate:        []*externalapi.DomainTransactionID{},
 		lastTransactionIDPropagationTime: time.Now(),
+		stemPool:                         make(map[externalapi.DomainTransactionID]*stemTransaction),
+		stemClock:                        mstime.SystemClock,
+		stemRandom:                       globalRandomSource{},
 		shutdownChan:                     make(chan struct{}),
 	}
+
+	if cfg.StemRelay {
+		spawn("FlowContext-fluffExpiredStemTransactionsLoop", flowContext.fluffExpiredStemTransactionsLoop)
+	}
+
+	return flowContext
 }
 
 // Close signals to all flows the the protocol manager is closed.
@@ -2,6 +2,8 @@ package flowcontext
 
 import (
 	"github.com/ammm56/lings/infrastructure/logger"
+	"github.com/ammm56/lings/util/panics"
 )
 
 var log = logger.RegisterSubSystem("PROT")
+var spawn = panics.GoroutineWrapperFunc(log)
@@ -68,6 +68,20 @@ func (f *FlowContext) Broadcast(message appmessage.Message) error {
 	return f.netAdapter.P2PBroadcast(f.readyPeerConnections(), message)
 }
 
+// transactionRelayPeerConnections returns the NetConnections of all the ready
+// peers that transactions are relayed to.
+func (f *FlowContext) transactionRelayPeerConnections() []*netadapter.NetConnection {
+	f.peersMutex.RLock()
+	defer f.peersMutex.RUnlock()
+	peerConnections := make([]*netadapter.NetConnection, 0, len(f.peers))
+	for _, peer := range f.peers {
+		if peer.IsTransactionRelayEnabled() {
+			peerConnections = append(peerConnections, peer.Connection())
+		}
+	}
+	return peerConnections
+}
+
 // Peers returns the currently active peers
 func (f *FlowContext) Peers() []*peerpkg.Peer {
 	f.peersMutex.RLock()
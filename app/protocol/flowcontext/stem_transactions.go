@@ -0,0 +1,262 @@
+package flowcontext
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/ammm56/lings/app/appmessage"
+	peerpkg "github.com/ammm56/lings/app/protocol/peer"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/miningmanager/mempool"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
+	"github.com/ammm56/lings/util/mstime"
+	"github.com/pkg/errors"
+)
+
+const (
+	// stemFluffProbabilityPercent is the probability, in percent, that a node
+	// that received a stem transaction broadcasts it instead of forwarding it
+	// to its own stem peer. This makes the stem length geometrically distributed.
+	stemFluffProbabilityPercent = 10
+
+	// stemEmbargoMinimum and stemEmbargoRandomRange define the time a node waits
+	// for a stem transaction it forwarded to be broadcast by someone else, before
+	// it broadcasts the transaction itself.
+	stemEmbargoMinimum     = 10 * time.Second
+	stemEmbargoRandomRange = 20 * time.Second
+
+	// stemEmbargoCheckInterval is the interval in which stem transactions whose
+	// embargo has expired are broadcast.
+	stemEmbargoCheckInterval = time.Second
+
+	// stemPoolMaximumSize is the maximum number of transactions in the stem
+	// pool. Stem transactions that don't fit in it are broadcast right away.
+	stemPoolMaximumSize = 1000
+
+	// stemPeerEpoch is the interval after which a new stem peer is selected.
+	stemPeerEpoch = 10 * time.Minute
+)
+
+// stemRandomSource is the source of the random decisions of the stem phase:
+// whether to fluff, how long an embargo lasts and which peer is the stem peer
+type stemRandomSource interface {
+	Intn(n int) int
+	Int63n(n int64) int64
+}
+
+// globalRandomSource is a stemRandomSource over the global source of
+// math/rand, which is safe for concurrent use by the flows
+type globalRandomSource struct{}
+
+func (globalRandomSource) Intn(n int) int {
+	return rand.Intn(n)
+}
+
+func (globalRandomSource) Int63n(n int64) int64 {
+	return rand.Int63n(n)
+}
+
+// stemTransaction is a transaction in the stem pool. Stem transactions are kept
+// out of the mempool until they're fluffed, since the mempool exposes its
+// transactions to RPC clients, to peers that request them and to block templates.
+type stemTransaction struct {
+	transaction    *externalapi.DomainTransaction
+	isHighPriority bool
+	embargoEnd     mstime.Time
+}
+
+// RelayStemTransaction validates the given transaction, which was received in
+// the stem phase, and then either forwards it to the stem peer or broadcasts
+// it to the rest of the network.
+func (f *FlowContext) RelayStemTransaction(tx *externalapi.DomainTransaction) error {
+	txID := consensushashing.TransactionID(tx)
+	if f.isInStemPool(txID) {
+		log.Debugf("Ignoring stem transaction %s that is already in the stem pool", txID)
+		return nil
+	}
+
+	err := f.Domain().MiningManager().ValidateTransaction(tx.Clone())
+	if err != nil {
+		return err
+	}
+
+	if f.stemRandom.Intn(100) < stemFluffProbabilityPercent {
+		log.Debugf("Fluffing stem transaction %s", txID)
+		return f.fluffTransactions([]*stemTransaction{{transaction: tx}})
+	}
+	return f.stemTransaction(tx, false)
+}
+
+// addStemTransaction validates the given transaction, which was submitted to
+// this node, and starts its stem phase. It returns false if the transaction
+// can't be relayed in the stem phase because it's an orphan, since the stem
+// peer wouldn't be able to validate it either.
+func (f *FlowContext) addStemTransaction(tx *externalapi.DomainTransaction) (isStemmed bool, err error) {
+	err = f.Domain().MiningManager().ValidateTransaction(tx.Clone())
+	if err != nil {
+		txRuleErr := &mempool.TxRuleError{}
+		if errors.As(err, txRuleErr) && txRuleErr.RejectCode == mempool.RejectBadOrphan {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, f.stemTransaction(tx, true)
+}
+
+// stemTransaction forwards the given transaction to the stem peer and puts it
+// in the stem pool under embargo. If there's no stem peer or the stem pool is
+// full the transaction is broadcast right away.
+func (f *FlowContext) stemTransaction(tx *externalapi.DomainTransaction, isHighPriority bool) error {
+	txID := consensushashing.TransactionID(tx)
+	stemTx := &stemTransaction{transaction: tx, isHighPriority: isHighPriority}
+
+	stemPeer := f.stemPeer()
+	if stemPeer == nil {
+		log.Debugf("No stem peer is available, fluffing transaction %s", txID)
+		return f.fluffTransactions([]*stemTransaction{stemTx})
+	}
+
+	f.stemLock.Lock()
+	if len(f.stemPool) >= stemPoolMaximumSize {
+		f.stemLock.Unlock()
+		log.Debugf("The stem pool is full, fluffing transaction %s", txID)
+		return f.fluffTransactions([]*stemTransaction{stemTx})
+	}
+	// The embargo is drawn in whole milliseconds, the precision of mstime
+	embargo := stemEmbargoMinimum +
+		time.Duration(f.stemRandom.Int63n(stemEmbargoRandomRange.Milliseconds()))*time.Millisecond
+	stemTx.embargoEnd = f.stemClock.Now().Add(embargo)
+	f.stemPool[*txID] = stemTx
+	f.stemLock.Unlock()
+
+	msgStemTransaction := appmessage.NewMsgStemTransaction(appmessage.DomainTransactionToMsgTx(tx))
+	err := f.netAdapter.P2PSend(stemPeer.Connection(), msgStemTransaction)
+	if err != nil {
+		if !errors.Is(err, router.ErrRouteClosed) {
+			return err
+		}
+		log.Debugf("Stem peer %s disconnected, fluffing transaction %s", stemPeer, txID)
+		f.removeFromStemPool(txID)
+		return f.fluffTransactions([]*stemTransaction{stemTx})
+	}
+	log.Debugf("Forwarded transaction %s to stem peer %s with an embargo of %s", txID, stemPeer, embargo)
+	return nil
+}
+
+// fluffTransactions ends the stem phase of the given transactions by adding
+// them to the mempool and broadcasting them. Transactions that are no longer
+// valid, for example because they were meanwhile included in a block or
+// received from another node, are skipped.
+func (f *FlowContext) fluffTransactions(stemTransactions []*stemTransaction) error {
+	var acceptedTransactionIDs []*externalapi.DomainTransactionID
+	for _, stemTx := range stemTransactions {
+		acceptedTransactions, err := f.Domain().MiningManager().ValidateAndInsertTransaction(
+			stemTx.transaction, stemTx.isHighPriority, false)
+		if err != nil {
+			if !errors.As(err, &mempool.RuleError{}) {
+				return err
+			}
+			log.Debugf("Not fluffing stem transaction %s: %s",
+				consensushashing.TransactionID(stemTx.transaction), err)
+			continue
+		}
+		acceptedTransactionIDs = append(acceptedTransactionIDs, consensushashing.TransactionIDs(acceptedTransactions)...)
+	}
+	if len(acceptedTransactionIDs) == 0 {
+		return nil
+	}
+
+	f.OnTransactionAddedToMempool()
+	return f.EnqueueTransactionIDsForPropagation(acceptedTransactionIDs)
+}
+
+// stemPeer returns the peer that stem transactions are currently forwarded to,
+// selecting a new one if the epoch had ended or the previous stem peer has
+// disconnected. Returns nil if no peer supports stem relay.
+func (f *FlowContext) stemPeer() *peerpkg.Peer {
+	f.stemLock.Lock()
+	defer f.stemLock.Unlock()
+
+	if f.currentStemPeer != nil && f.HasPeer(f.currentStemPeer) &&
+		f.stemClock.Now().Sub(f.stemPeerSelectionTime) < stemPeerEpoch {
+
+		return f.currentStemPeer
+	}
+
+	// Only outbound peers are eligible, so that an attacker can't become
+	// our stem peer simply by connecting to us.
+	var candidates []*peerpkg.Peer
+	for _, peer := range f.Peers() {
+		if peer.IsOutbound() && peer.HasNegotiatedFeature(appmessage.SFNodeStemRelay) {
+			candidates = append(candidates, peer)
+		}
+	}
+	if len(candidates) == 0 {
+		f.currentStemPeer = nil
+		return nil
+	}
+
+	f.currentStemPeer = candidates[f.stemRandom.Intn(len(candidates))]
+	f.stemPeerSelectionTime = f.stemClock.Now()
+	log.Debugf("Selected %s as the stem peer", f.currentStemPeer)
+	return f.currentStemPeer
+}
+
+func (f *FlowContext) isInStemPool(txID *externalapi.DomainTransactionID) bool {
+	f.stemLock.Lock()
+	defer f.stemLock.Unlock()
+
+	_, ok := f.stemPool[*txID]
+	return ok
+}
+
+func (f *FlowContext) removeFromStemPool(txID *externalapi.DomainTransactionID) {
+	f.stemLock.Lock()
+	defer f.stemLock.Unlock()
+
+	delete(f.stemPool, *txID)
+}
+
+// fluffExpiredStemTransactionsLoop periodically fluffs the stem transactions
+// whose embargo has expired, until the flow context is closed
+func (f *FlowContext) fluffExpiredStemTransactionsLoop() {
+	ticker := time.NewTicker(stemEmbargoCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.shutdownChan:
+			return
+		case <-ticker.C:
+			err := f.fluffExpiredStemTransactions(f.stemClock.Now())
+			if err != nil {
+				log.Errorf("Failed to fluff the stem transactions whose embargo expired: %+v", err)
+			}
+		}
+	}
+}
+
+// fluffExpiredStemTransactions removes every stem transaction whose embargo
+// has expired by the given time from the stem pool, and fluffs it. This is the
+// fallback for a stem path that failed somewhere down the line. If the
+// transaction was already fluffed by another node it's already in the mempool,
+// and is skipped.
+func (f *FlowContext) fluffExpiredStemTransactions(now mstime.Time) error {
+	f.stemLock.Lock()
+	var expiredTransactions []*stemTransaction
+	for txID, stemTx := range f.stemPool {
+		if now.Before(stemTx.embargoEnd) {
+			continue
+		}
+		delete(f.stemPool, txID)
+		expiredTransactions = append(expiredTransactions, stemTx)
+	}
+	f.stemLock.Unlock()
+
+	if len(expiredTransactions) == 0 {
+		return nil
+	}
+	log.Debugf("Embargo expired for %d stem transactions, fluffing them", len(expiredTransactions))
+	return f.fluffTransactions(expiredTransactions)
+}
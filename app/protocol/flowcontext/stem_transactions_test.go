@@ -0,0 +1,463 @@
+package flowcontext
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ammm56/lings/app/appmessage"
+	peerpkg "github.com/ammm56/lings/app/protocol/peer"
+	"github.com/ammm56/lings/domain"
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/constants"
+	"github.com/ammm56/lings/domain/consensus/utils/testutils"
+	"github.com/ammm56/lings/domain/consensus/utils/txscript"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/domain/miningmanager/mempool"
+	"github.com/ammm56/lings/infrastructure/config"
+	"github.com/ammm56/lings/infrastructure/db/database/ldb"
+	"github.com/ammm56/lings/infrastructure/network/netadapter"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/id"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/server"
+	"github.com/ammm56/lings/util/mstime"
+	"github.com/pkg/errors"
+)
+
+// testRandomSource is a stemRandomSource that returns fixed values, capped
+// to the requested range
+type testRandomSource struct {
+	intn   int
+	int63n int64
+}
+
+func (r *testRandomSource) Intn(n int) int {
+	if r.intn >= n {
+		return n - 1
+	}
+	return r.intn
+}
+
+func (r *testRandomSource) Int63n(n int64) int64 {
+	if r.int63n >= n {
+		return n - 1
+	}
+	return r.int63n
+}
+
+// testConnection is a server.Connection that isn't connected to anything. The
+// messages that are sent over it stay in its router's outgoing route.
+type testConnection struct {
+	address    *net.TCPAddr
+	isOutbound bool
+	router     *router.Router
+}
+
+func (c *testConnection) String() string {
+	return c.address.String()
+}
+
+func (c *testConnection) Start(router *router.Router) {
+	c.router = router
+}
+
+func (c *testConnection) Disconnect() {}
+
+func (c *testConnection) IsConnected() bool {
+	return true
+}
+
+func (c *testConnection) IsOutbound() bool {
+	return c.isOutbound
+}
+
+func (c *testConnection) SetOnDisconnectedHandler(server.OnDisconnectedHandler) {}
+
+func (c *testConnection) SetOnInvalidMessageHandler(server.OnInvalidMessageHandler) {}
+
+func (c *testConnection) Address() *net.TCPAddr {
+	return c.address
+}
+
+// testP2PServer is a server.P2PServer that only accepts the connections that
+// the test hands it
+type testP2PServer struct {
+	onConnectedHandler server.OnConnectedHandler
+}
+
+func (s *testP2PServer) Start() error {
+	return nil
+}
+
+func (s *testP2PServer) Stop() error {
+	return nil
+}
+
+func (s *testP2PServer) SetOnConnectedHandler(onConnectedHandler server.OnConnectedHandler) {
+	s.onConnectedHandler = onConnectedHandler
+}
+
+func (s *testP2PServer) Connect(string) (server.Connection, error) {
+	return nil, errors.New("the test server doesn't dial out")
+}
+
+type stemTestContext struct {
+	t           *testing.T
+	flowContext *FlowContext
+	server      *testP2PServer
+	clock       *mstime.AdjustableClock
+	random      *testRandomSource
+
+	// transactions are valid transactions that don't depend on each other
+	transactions []*externalapi.DomainTransaction
+	peerCount    int
+}
+
+// newStemTestContext sets up a flow context over a DAG with several spendable
+// outputs, with a clock and random source that are controlled by the test
+func newStemTestContext(t *testing.T) *stemTestContext {
+	consensusConfig := &consensus.Config{Params: dagconfig.MainnetParams}
+	consensusConfig.SkipProofOfWork = true
+	consensusConfig.BlockCoinbaseMaturity = 0
+
+	db, err := ldb.NewLevelDB(t.TempDir(), 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), db)
+	if err != nil {
+		t.Fatalf("New: %+v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ActiveNetParams = &consensusConfig.Params
+	cfg.Listeners = nil
+	cfg.RPCListeners = nil
+	p2pServer := &testP2PServer{}
+	netAdapter, err := netadapter.NewNetAdapterWithP2PServer(cfg, p2pServer)
+	if err != nil {
+		t.Fatalf("NewNetAdapterWithP2PServer: %+v", err)
+	}
+	netAdapter.SetP2PRouterInitializer(func(*router.Router, *netadapter.NetConnection) {})
+
+	flowContext := New(cfg, domainInstance, nil, netAdapter, nil)
+	t.Cleanup(flowContext.Close)
+	clock := mstime.NewAdjustableClock()
+	random := &testRandomSource{}
+	flowContext.stemClock = clock
+	flowContext.stemRandom = random
+
+	context := &stemTestContext{
+		t:           t,
+		flowContext: flowContext,
+		server:      p2pServer,
+		clock:       clock,
+		random:      random,
+	}
+	context.createTransactions()
+	return context
+}
+
+// createTransactions mines blocks that pay OpTrue, and creates a transaction
+// that spends each of their outputs. The blocks are mined before any peer is
+// added, so that the peers' routes only hold what the stem phase sends.
+func (c *stemTestContext) createTransactions() {
+	opTrueScriptPublicKey, opTrueRedeemScript := testutils.OpTrueScript()
+	coinbaseData := &externalapi.DomainCoinbaseData{ScriptPublicKey: opTrueScriptPublicKey, ExtraData: []byte{}}
+	for i := 0; i < 6; i++ {
+		block, _, err := c.flowContext.Domain().MiningManager().GetBlockTemplate(coinbaseData)
+		if err != nil {
+			c.t.Fatalf("GetBlockTemplate: %+v", err)
+		}
+		err = c.flowContext.AddBlock(block)
+		if err != nil {
+			c.t.Fatalf("AddBlock: %+v", err)
+		}
+	}
+
+	consensusInstance := c.flowContext.Domain().Consensus()
+	virtualInfo, err := consensusInstance.GetVirtualInfo()
+	if err != nil {
+		c.t.Fatalf("GetVirtualInfo: %+v", err)
+	}
+	utxos, err := consensusInstance.GetVirtualUTXOs(virtualInfo.ParentHashes, nil, 100)
+	if err != nil {
+		c.t.Fatalf("GetVirtualUTXOs: %+v", err)
+	}
+	signatureScript, err := txscript.PayToScriptHashSignatureScript(opTrueRedeemScript, nil)
+	if err != nil {
+		c.t.Fatalf("PayToScriptHashSignatureScript: %+v", err)
+	}
+	for _, utxo := range utxos {
+		if !utxo.UTXOEntry.ScriptPublicKey().Equal(opTrueScriptPublicKey) || utxo.UTXOEntry.Amount() == 0 {
+			continue
+		}
+		c.transactions = append(c.transactions, &externalapi.DomainTransaction{
+			Version: constants.MaxTransactionVersion,
+			Inputs: []*externalapi.DomainTransactionInput{{
+				PreviousOutpoint: *utxo.Outpoint,
+				SignatureScript:  signatureScript,
+				Sequence:         constants.MaxTxInSequenceNum,
+			}},
+			Outputs: []*externalapi.DomainTransactionOutput{{
+				ScriptPublicKey: opTrueScriptPublicKey,
+				Value:           utxo.UTXOEntry.Amount() - 10_000,
+			}},
+			Payload: []byte{},
+		})
+	}
+	if len(c.transactions) < 4 {
+		c.t.Fatalf("expected at least 4 spendable outputs, got %d", len(c.transactions))
+	}
+}
+
+// addPeer adds a ready peer whose connection is in the given direction, and
+// that negotiated the stem relay feature if supportsStemRelay is set
+func (c *stemTestContext) addPeer(isOutbound bool, supportsStemRelay bool) (*peerpkg.Peer, *testConnection) {
+	c.peerCount++
+	connection := &testConnection{
+		address:    &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 16000 + c.peerCount},
+		isOutbound: isOutbound,
+	}
+	var netConnection *netadapter.NetConnection
+	c.flowContext.netAdapter.SetP2PRouterInitializer(func(_ *router.Router, connection *netadapter.NetConnection) {
+		netConnection = connection
+	})
+	err := c.server.onConnectedHandler(connection)
+	if err != nil {
+		c.t.Fatalf("onConnectedHandler: %+v", err)
+	}
+	peerID, err := id.GenerateID()
+	if err != nil {
+		c.t.Fatalf("GenerateID: %+v", err)
+	}
+	netConnection.SetID(peerID)
+
+	services := appmessage.SFNodeNetwork
+	if supportsStemRelay {
+		services |= appmessage.SFNodeStemRelay
+	}
+	peer := peerpkg.New(netConnection)
+	peer.UpdateFieldsFromMsgVersion(&appmessage.MsgVersion{
+		ProtocolVersion: appmessage.FeatureNegotiationProtocolVersion,
+		Services:        services,
+		Timestamp:       mstime.Now(),
+	}, appmessage.FeatureNegotiationProtocolVersion, appmessage.SFNodeNetwork|appmessage.SFNodeStemRelay)
+	err = c.flowContext.AddToPeers(peer)
+	if err != nil {
+		c.t.Fatalf("AddToPeers: %+v", err)
+	}
+	return peer, connection
+}
+
+func (c *stemTestContext) isInMempool(tx *externalapi.DomainTransaction) bool {
+	_, _, found := c.flowContext.Domain().MiningManager().GetTransaction(consensushashing.TransactionID(tx), true, false)
+	return found
+}
+
+func (c *stemTestContext) isInStemPool(tx *externalapi.DomainTransaction) bool {
+	return c.flowContext.isInStemPool(consensushashing.TransactionID(tx))
+}
+
+// requireStemmed fails the test if tx isn't held in the stem pool, out of the
+// mempool, after it was forwarded over connection
+func (c *stemTestContext) requireStemmed(tx *externalapi.DomainTransaction, connection *testConnection) {
+	txID := consensushashing.TransactionID(tx)
+	if !c.isInStemPool(tx) || c.isInMempool(tx) {
+		c.t.Fatalf("expected transaction %s to be in the stem pool and out of the mempool", txID)
+	}
+	message, err := connection.router.OutgoingRoute().DequeueWithTimeout(time.Second)
+	if err != nil {
+		c.t.Fatalf("expected transaction %s to be forwarded to the stem peer: %+v", txID, err)
+	}
+	msgStemTransaction, ok := message.(*appmessage.MsgStemTransaction)
+	if !ok {
+		c.t.Fatalf("expected a stem transaction to be forwarded to the stem peer, got %s", message.Command())
+	}
+	forwardedTxID := consensushashing.TransactionID(appmessage.MsgTxToDomainTransaction(msgStemTransaction.MsgTx))
+	if !forwardedTxID.Equal(txID) {
+		c.t.Fatalf("expected transaction %s to be forwarded, got %s", txID, forwardedTxID)
+	}
+}
+
+// requireFluffed fails the test if tx isn't in the mempool, out of the stem pool
+func (c *stemTestContext) requireFluffed(tx *externalapi.DomainTransaction) {
+	if c.isInStemPool(tx) || !c.isInMempool(tx) {
+		c.t.Fatalf("expected transaction %s to be in the mempool and out of the stem pool",
+			consensushashing.TransactionID(tx))
+	}
+}
+
+// requireNothingSent fails the test if anything was sent over connection
+func (c *stemTestContext) requireNothingSent(connection *testConnection) {
+	message, err := connection.router.OutgoingRoute().DequeueWithTimeout(10 * time.Millisecond)
+	if err == nil {
+		c.t.Fatalf("expected nothing to be sent to %s, but got %s", connection, message.Command())
+	}
+}
+
+func TestRelayStemTransactionFluffProbability(t *testing.T) {
+	context := newStemTestContext(t)
+	_, stemPeerConnection := context.addPeer(true, true)
+
+	// A draw below stemFluffProbabilityPercent ends the stem phase here
+	context.random.intn = stemFluffProbabilityPercent - 1
+	fluffedTransaction := context.transactions[0]
+	err := context.flowContext.RelayStemTransaction(fluffedTransaction)
+	if err != nil {
+		t.Fatalf("RelayStemTransaction: %+v", err)
+	}
+	context.requireFluffed(fluffedTransaction)
+	context.requireNothingSent(stemPeerConnection)
+
+	// Any other draw forwards the transaction to the stem peer
+	context.random.intn = stemFluffProbabilityPercent
+	stemmedTransaction := context.transactions[1]
+	err = context.flowContext.RelayStemTransaction(stemmedTransaction)
+	if err != nil {
+		t.Fatalf("RelayStemTransaction: %+v", err)
+	}
+	context.requireStemmed(stemmedTransaction, stemPeerConnection)
+
+	// A stem transaction that is already in the stem pool isn't relayed again
+	err = context.flowContext.RelayStemTransaction(stemmedTransaction)
+	if err != nil {
+		t.Fatalf("RelayStemTransaction: %+v", err)
+	}
+	context.requireNothingSent(stemPeerConnection)
+}
+
+func TestStemPeerSelection(t *testing.T) {
+	context := newStemTestContext(t)
+
+	// Inbound peers and peers that didn't negotiate stem relay are never
+	// selected, so a stem transaction is fluffed right away
+	_, inboundConnection := context.addPeer(false, true)
+	_, outboundWithoutStemRelayConnection := context.addPeer(true, false)
+	if stemPeer := context.flowContext.stemPeer(); stemPeer != nil {
+		t.Fatalf("expected no stem peer to be selected, but got %s", stemPeer)
+	}
+	context.random.intn = stemFluffProbabilityPercent
+	err := context.flowContext.RelayStemTransaction(context.transactions[0])
+	if err != nil {
+		t.Fatalf("RelayStemTransaction: %+v", err)
+	}
+	context.requireFluffed(context.transactions[0])
+	context.requireNothingSent(inboundConnection)
+	context.requireNothingSent(outboundWithoutStemRelayConnection)
+
+	// Whatever the random draw, the only eligible peer is selected
+	firstStemPeer, firstStemPeerConnection := context.addPeer(true, true)
+	for _, intn := range []int{0, 1, 2} {
+		context.flowContext.currentStemPeer = nil
+		context.random.intn = intn
+		if stemPeer := context.flowContext.stemPeer(); stemPeer != firstStemPeer {
+			t.Fatalf("expected %s to be selected as the stem peer, but got %s", firstStemPeer, stemPeer)
+		}
+	}
+	context.random.intn = stemFluffProbabilityPercent
+	err = context.flowContext.RelayStemTransaction(context.transactions[1])
+	if err != nil {
+		t.Fatalf("RelayStemTransaction: %+v", err)
+	}
+	context.requireStemmed(context.transactions[1], firstStemPeerConnection)
+	context.requireNothingSent(inboundConnection)
+	context.requireNothingSent(outboundWithoutStemRelayConnection)
+
+	// The stem peer is kept until the epoch ends, even when another eligible
+	// peer connects
+	secondStemPeer, _ := context.addPeer(true, true)
+	context.clock.Advance(stemPeerEpoch / 2)
+	if stemPeer := context.flowContext.stemPeer(); stemPeer != firstStemPeer {
+		t.Fatalf("expected %s to stay the stem peer until the epoch ends, but got %s", firstStemPeer, stemPeer)
+	}
+	context.clock.Advance(stemPeerEpoch / 2)
+	previousSelectionTime := context.flowContext.stemPeerSelectionTime
+	if stemPeer := context.flowContext.stemPeer(); stemPeer != firstStemPeer && stemPeer != secondStemPeer {
+		t.Fatalf("expected one of the eligible peers to be selected, but got %s", stemPeer)
+	}
+	if !context.flowContext.stemPeerSelectionTime.After(previousSelectionTime) {
+		t.Fatalf("expected a new stem peer to be selected once the epoch ended")
+	}
+
+	// A stem peer that disconnects is replaced before the epoch ends
+	context.flowContext.currentStemPeer = firstStemPeer
+	context.flowContext.stemPeerSelectionTime = context.clock.Now()
+	context.flowContext.RemoveFromPeers(firstStemPeer)
+	if stemPeer := context.flowContext.stemPeer(); stemPeer != secondStemPeer {
+		t.Fatalf("expected %s to replace the disconnected stem peer, but got %s", secondStemPeer, stemPeer)
+	}
+}
+
+func TestStemEmbargo(t *testing.T) {
+	context := newStemTestContext(t)
+	_, stemPeerConnection := context.addPeer(true, true)
+	context.random.intn = stemFluffProbabilityPercent
+
+	// The embargo is stemEmbargoMinimum plus the random draw, in milliseconds
+	const randomEmbargoMilliseconds = 5000
+	context.random.int63n = randomEmbargoMilliseconds
+	embargo := stemEmbargoMinimum + randomEmbargoMilliseconds*time.Millisecond
+
+	stemmedTransaction := context.transactions[0]
+	stemmedAt := context.clock.Now()
+	err := context.flowContext.RelayStemTransaction(stemmedTransaction)
+	if err != nil {
+		t.Fatalf("RelayStemTransaction: %+v", err)
+	}
+	context.requireStemmed(stemmedTransaction, stemPeerConnection)
+
+	// A transaction that was fluffed by another node is already in the
+	// mempool once its embargo expires, and is skipped
+	fluffedElsewhereTransaction := context.transactions[1]
+	err = context.flowContext.RelayStemTransaction(fluffedElsewhereTransaction)
+	if err != nil {
+		t.Fatalf("RelayStemTransaction: %+v", err)
+	}
+	context.requireStemmed(fluffedElsewhereTransaction, stemPeerConnection)
+	_, err = context.flowContext.Domain().MiningManager().ValidateAndInsertTransaction(
+		fluffedElsewhereTransaction, false, false)
+	if err != nil {
+		t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+	}
+
+	err = context.flowContext.fluffExpiredStemTransactions(stemmedAt.Add(embargo - time.Millisecond))
+	if err != nil {
+		t.Fatalf("fluffExpiredStemTransactions: %+v", err)
+	}
+	if !context.isInStemPool(stemmedTransaction) || context.isInMempool(stemmedTransaction) {
+		t.Fatalf("expected the transaction to stay in the stem pool until its embargo expires")
+	}
+
+	err = context.flowContext.fluffExpiredStemTransactions(context.clock.Now().Add(embargo))
+	if err != nil {
+		t.Fatalf("fluffExpiredStemTransactions: %+v", err)
+	}
+	context.requireFluffed(stemmedTransaction)
+	context.requireFluffed(fluffedElsewhereTransaction)
+	context.requireNothingSent(stemPeerConnection)
+}
+
+func TestStemPoolMaximumSize(t *testing.T) {
+	context := newStemTestContext(t)
+	_, stemPeerConnection := context.addPeer(true, true)
+	context.random.intn = stemFluffProbabilityPercent
+
+	context.flowContext.stemLock.Lock()
+	for i := 0; len(context.flowContext.stemPool) < stemPoolMaximumSize; i++ {
+		txIDBytes := [externalapi.DomainHashSize]byte{byte(i), byte(i >> 8)}
+		context.flowContext.stemPool[*externalapi.NewDomainTransactionIDFromByteArray(&txIDBytes)] = &stemTransaction{embargoEnd: context.clock.Now().Add(time.Hour)}
+	}
+	context.flowContext.stemLock.Unlock()
+
+	// A transaction that doesn't fit in the stem pool is fluffed right away
+	err := context.flowContext.RelayStemTransaction(context.transactions[0])
+	if err != nil {
+		t.Fatalf("RelayStemTransaction: %+v", err)
+	}
+	context.requireFluffed(context.transactions[0])
+	context.requireNothingSent(stemPeerConnection)
+}
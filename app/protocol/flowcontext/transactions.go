@@ -12,7 +12,16 @@ import (
 const TransactionIDPropagationInterval = 500 * time.Millisecond
 
 // AddTransaction adds transaction to the mempool and propagates it.
+// If stem relay is enabled, the transaction is first forwarded through the stem phase,
+// during which it's kept out of the mempool.
 func (f *FlowContext) AddTransaction(tx *externalapi.DomainTransaction, allowOrphan bool) error {
+	if f.cfg.StemRelay {
+		isStemmed, err := f.addStemTransaction(tx)
+		if err != nil || isStemmed {
+			return err
+		}
+	}
+
 	acceptedTransactions, err := f.Domain().MiningManager().ValidateAndInsertTransaction(tx, true, allowOrphan)
 	if err != nil {
 		return err
@@ -42,7 +51,7 @@ func (f *FlowContext) OnTransactionAddedToMempool() {
 }
 
 // EnqueueTransactionIDsForPropagation add the given transactions IDs to a set of IDs to
-// propagate. The IDs will be broadcast to all peers within a single transaction Inv message.
+// propagate. The IDs will be broadcast to all transaction relaying peers within a single transaction Inv message.
 // The broadcast itself may happen only during a subsequent call to this method
 func (f *FlowContext) EnqueueTransactionIDsForPropagation(transactionIDs []*externalapi.DomainTransactionID) error {
 	f.transactionIDPropagationLock.Lock()
@@ -67,7 +76,7 @@ func (f *FlowContext) maybePropagateTransactions() error {
 		log.Debugf("Transaction propagation: broadcasting %d transactions", len(transactionIDsToBroadcast))
 
 		inv := appmessage.NewMsgInvTransaction(transactionIDsToBroadcast)
-		err := f.Broadcast(inv)
+		err := f.netAdapter.P2PBroadcast(f.transactionRelayPeerConnections(), inv)
 		if err != nil {
 			return err
 		}
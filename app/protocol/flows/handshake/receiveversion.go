@@ -20,7 +20,7 @@ var (
 	// connected peer may support.
 	minAcceptableProtocolVersion = uint32(5)
 
-	maxAcceptableProtocolVersion = uint32(5)
+	maxAcceptableProtocolVersion = uint32(6)
 )
 
 type receiveVersionFlow struct {
@@ -105,7 +105,7 @@ func (flow *receiveVersionFlow) start() (*appmessage.NetAddress, error) {
 	}
 
 	maxProtocolVersion := flow.Config().ProtocolVersion
-	flow.peer.UpdateFieldsFromMsgVersion(msgVersion, maxProtocolVersion)
+	flow.peer.UpdateFieldsFromMsgVersion(msgVersion, maxProtocolVersion, localServices(flow.Config()))
 	err = flow.outgoingRoute.Enqueue(appmessage.NewMsgVerAck())
 	if err != nil {
 		return nil, err
@@ -4,6 +4,7 @@ import (
 	"github.com/ammm56/lings/app/appmessage"
 	"github.com/ammm56/lings/app/protocol/common"
 	peerpkg "github.com/ammm56/lings/app/protocol/peer"
+	"github.com/ammm56/lings/infrastructure/config"
 	"github.com/ammm56/lings/infrastructure/logger"
 	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
 	"github.com/ammm56/lings/version"
@@ -28,6 +29,19 @@ var (
 	defaultRequiredServices = appmessage.SFNodeNetwork
 )
 
+// localServices returns the services advertised by this node, which are
+// the default services together with the features enabled in cfg.
+func localServices(cfg *config.Config) appmessage.ServiceFlag {
+	services := defaultServices
+	if !cfg.BlocksOnly {
+		services |= appmessage.SFNodeTxRelay
+	}
+	if cfg.StemRelay {
+		services |= appmessage.SFNodeStemRelay
+	}
+	return services
+}
+
 type sendVersionFlow struct {
 	HandleHandshakeContext
 
@@ -65,7 +79,7 @@ func (flow *sendVersionFlow) start() error {
 	msg.AddUserAgent(userAgentName, userAgentVersion, flow.Config().UserAgentComments...)
 
 	// Advertise the services flag
-	msg.Services = defaultServices
+	msg.Services = localServices(flow.Config())
 
 	// Advertise our max supported protocol version.
 	msg.ProtocolVersion = flow.Config().ProtocolVersion
@@ -131,13 +131,15 @@ func (flow *handleIBDFlow) syncAndValidatePruningPointProof() (*externalapi.Doma
 			"expected: %s, got: %s", appmessage.CmdPruningPointProof, message.Command())
 	}
 	pruningPointProof := appmessage.MsgPruningPointProofToDomainPruningPointProof(pruningPointProofMessage)
-	err = flow.Domain().Consensus().ValidatePruningPointProof(pruningPointProof)
+	report, err := flow.Domain().Consensus().ValidatePruningPointProofWithReport(pruningPointProof)
 	if err != nil {
 		if errors.As(err, &ruleerrors.RuleError{}) {
+			log.Infof("Rejected the pruning point proof from %s: %s\n%s", flow.peer, err, report)
 			return nil, protocolerrors.Wrapf(true, err, "pruning point proof validation failed")
 		}
 		return nil, err
 	}
+	log.Debugf("Validated the pruning point proof from %s:\n%s", flow.peer, report)
 
 	err = flow.Domain().StagingConsensus().ApplyPruningPointProof(pruningPointProof)
 	if err != nil {
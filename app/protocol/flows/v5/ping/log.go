@@ -0,0 +1,9 @@
+package ping
+
+import (
+	"github.com/ammm56/lings/infrastructure/logger"
+	"github.com/ammm56/lings/util/panics"
+)
+
+var log = logger.RegisterSubSystem("PROT")
+var spawn = panics.GoroutineWrapperFunc(log)
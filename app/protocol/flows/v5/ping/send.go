@@ -43,10 +43,22 @@ func (flow *sendPingsFlow) start() error {
 	ticker := time.NewTicker(pingInterval)
 	defer ticker.Stop()
 
+	// incomingRoute is read in a separate goroutine, so that the flow ends as
+	// soon as the peer disconnects rather than at the next ping. The peer is
+	// only removed once all of its flows end, and reconnecting to it fails
+	// until then.
+	done := make(chan struct{})
+	defer close(done)
+	pongChan, errChan := flow.receivePongs(done)
+
 	for {
 		select {
 		case <-flow.ShutdownChan():
 			return nil
+		case err := <-errChan:
+			return err
+		case pongMessage := <-pongChan:
+			return protocolerrors.Errorf(false, "unexpected pong message with nonce %d", pongMessage.Nonce)
 		case <-ticker.C:
 		}
 
@@ -62,17 +74,41 @@ func (flow *sendPingsFlow) start() error {
 			return err
 		}
 
-		message, err := flow.incomingRoute.DequeueWithTimeout(common.DefaultTimeout)
-		if err != nil {
-			if errors.Is(err, router.ErrTimeout) {
-				return errors.Wrapf(flowcontext.ErrPingTimeout, err.Error())
-			}
+		select {
+		case <-flow.ShutdownChan():
+			return nil
+		case err := <-errChan:
 			return err
-		}
-		pongMessage := message.(*appmessage.MsgPong)
-		if pongMessage.Nonce != pingMessage.Nonce {
-			return protocolerrors.New(true, "nonce mismatch between ping and pong")
+		case pongMessage := <-pongChan:
+			if pongMessage.Nonce != pingMessage.Nonce {
+				return protocolerrors.New(true, "nonce mismatch between ping and pong")
+			}
+		case <-time.After(common.DefaultTimeout):
+			return errors.Wrapf(flowcontext.ErrPingTimeout, "no pong received within %s", common.DefaultTimeout)
 		}
 		flow.peer.SetPingIdle()
 	}
 }
+
+// receivePongs dequeues the messages of incomingRoute until it's closed or
+// done is closed, and sends them to the returned pong channel. The error that
+// stopped it is sent to the returned error channel.
+func (flow *sendPingsFlow) receivePongs(done <-chan struct{}) (<-chan *appmessage.MsgPong, <-chan error) {
+	pongChan := make(chan *appmessage.MsgPong)
+	errChan := make(chan error, 1)
+	spawn("sendPingsFlow-receivePongs", func() {
+		for {
+			message, err := flow.incomingRoute.Dequeue()
+			if err != nil {
+				errChan <- err
+				return
+			}
+			select {
+			case pongChan <- message.(*appmessage.MsgPong):
+			case <-done:
+				return
+			}
+		}
+	})
+	return pongChan, errChan
+}
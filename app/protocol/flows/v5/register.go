@@ -13,7 +13,10 @@ import (
 	routerpkg "github.com/ammm56/lings/infrastructure/network/netadapter/router"
 )
 
-type protocolManager interface {
+// ProtocolManager is the interface of the protocol manager that the flows are
+// registered with. It's shared by the later protocol versions, which build on
+// the flows of this one.
+type ProtocolManager interface {
 	RegisterFlow(name string, router *routerpkg.Router, messageTypes []appmessage.MessageCommand, isStopping *uint32,
 		errChan chan error, initializeFunc common.FlowInitializeFunc) *common.Flow
 	RegisterOneTimeFlow(name string, router *routerpkg.Router, messageTypes []appmessage.MessageCommand,
@@ -25,17 +28,26 @@ type protocolManager interface {
 }
 
 // Register is used in order to register all the protocol flows to the given router.
-func Register(m protocolManager, router *routerpkg.Router, errChan chan error, isStopping *uint32) (flows []*common.Flow) {
+func Register(m ProtocolManager, router *routerpkg.Router, errChan chan error, isStopping *uint32) (flows []*common.Flow) {
+	flows = RegisterCoreFlows(m, router, errChan, isStopping)
+	flows = append(flows, RegisterTransactionRelayFlow(m, router, isStopping, errChan)...)
+
+	return flows
+}
+
+// RegisterCoreFlows registers the flows that are run with every peer, as
+// opposed to the transaction relay flows, which later protocol versions only
+// run with peers that negotiated them.
+func RegisterCoreFlows(m ProtocolManager, router *routerpkg.Router, errChan chan error, isStopping *uint32) (flows []*common.Flow) {
 	flows = registerAddressFlows(m, router, isStopping, errChan)
 	flows = append(flows, registerBlockRelayFlows(m, router, isStopping, errChan)...)
 	flows = append(flows, registerPingFlows(m, router, isStopping, errChan)...)
-	flows = append(flows, registerTransactionRelayFlow(m, router, isStopping, errChan)...)
 	flows = append(flows, registerRejectsFlow(m, router, isStopping, errChan)...)
 
 	return flows
 }
 
-func registerAddressFlows(m protocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
+func registerAddressFlows(m ProtocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
 	outgoingRoute := router.OutgoingRoute()
 
 	return []*common.Flow{
@@ -53,7 +65,7 @@ func registerAddressFlows(m protocolManager, router *routerpkg.Router, isStoppin
 	}
 }
 
-func registerBlockRelayFlows(m protocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
+func registerBlockRelayFlows(m ProtocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
 	outgoingRoute := router.OutgoingRoute()
 
 	return []*common.Flow{
@@ -158,7 +170,7 @@ func registerBlockRelayFlows(m protocolManager, router *routerpkg.Router, isStop
 	}
 }
 
-func registerPingFlows(m protocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
+func registerPingFlows(m ProtocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
 	outgoingRoute := router.OutgoingRoute()
 
 	return []*common.Flow{
@@ -176,7 +188,9 @@ func registerPingFlows(m protocolManager, router *routerpkg.Router, isStopping *
 	}
 }
 
-func registerTransactionRelayFlow(m protocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
+// RegisterTransactionRelayFlow registers the flows that relay transactions
+// that are broadcast to the network
+func RegisterTransactionRelayFlow(m ProtocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
 	outgoingRoute := router.OutgoingRoute()
 
 	return []*common.Flow{
@@ -195,7 +209,7 @@ func registerTransactionRelayFlow(m protocolManager, router *routerpkg.Router, i
 	}
 }
 
-func registerRejectsFlow(m protocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
+func registerRejectsFlow(m ProtocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
 	outgoingRoute := router.OutgoingRoute()
 
 	return []*common.Flow{
@@ -0,0 +1,7 @@
+package v6
+
+import (
+	"github.com/ammm56/lings/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("PROT")
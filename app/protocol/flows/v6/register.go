@@ -0,0 +1,54 @@
+package v6
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/protocol/common"
+	v5 "github.com/ammm56/lings/app/protocol/flows/v5"
+	"github.com/ammm56/lings/app/protocol/flows/v6/stemrelay"
+	peerpkg "github.com/ammm56/lings/app/protocol/peer"
+	routerpkg "github.com/ammm56/lings/infrastructure/network/netadapter/router"
+)
+
+type registerFlowsFunc func(m v5.ProtocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow
+
+// featureFlows maps every negotiable feature to the flows that are registered
+// only when the feature was negotiated with the peer. Since both sides of the
+// connection negotiate the same set of features, neither side sends messages
+// that the other side has no flow for.
+var featureFlows = []struct {
+	feature  appmessage.ServiceFlag
+	register registerFlowsFunc
+}{
+	{feature: appmessage.SFNodeTxRelay, register: v5.RegisterTransactionRelayFlow},
+	{feature: appmessage.SFNodeStemRelay, register: registerStemRelayFlow},
+}
+
+// Register is used in order to register all the protocol flows to the given router.
+// These are the flows of v5, except that flows that depend on a negotiable feature
+// are registered only if the feature was negotiated with the given peer.
+func Register(m v5.ProtocolManager, router *routerpkg.Router, errChan chan error, isStopping *uint32,
+	peer *peerpkg.Peer) (flows []*common.Flow) {
+
+	flows = v5.RegisterCoreFlows(m, router, errChan, isStopping)
+
+	for _, featureFlow := range featureFlows {
+		if !peer.HasNegotiatedFeature(featureFlow.feature) {
+			log.Debugf("Feature %s was not negotiated with peer %s, skipping its flows", featureFlow.feature, peer)
+			continue
+		}
+		flows = append(flows, featureFlow.register(m, router, isStopping, errChan)...)
+	}
+
+	return flows
+}
+
+func registerStemRelayFlow(m v5.ProtocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
+	return []*common.Flow{
+		m.RegisterFlow("HandleStemTransactions", router,
+			[]appmessage.MessageCommand{appmessage.CmdStemTransaction}, isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return stemrelay.HandleStemTransactions(m.Context(), incomingRoute)
+			},
+		),
+	}
+}
@@ -0,0 +1,62 @@
+package stemrelay
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/protocol/protocolerrors"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/miningmanager/mempool"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// HandleStemTransactionsContext is the interface for the context needed for the HandleStemTransactions flow.
+type HandleStemTransactionsContext interface {
+	RelayStemTransaction(tx *externalapi.DomainTransaction) error
+	IsNearlySynced() (bool, error)
+}
+
+// HandleStemTransactions listens to appmessage.MsgStemTransaction messages, adds the
+// transactions to the mempool and relays them further in the stem phase or broadcasts them.
+func HandleStemTransactions(context HandleStemTransactionsContext, incomingRoute *router.Route) error {
+	for {
+		message, err := incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+		msgStemTransaction := message.(*appmessage.MsgStemTransaction)
+
+		isNearlySynced, err := context.IsNearlySynced()
+		if err != nil {
+			return err
+		}
+		// Transaction relay is disabled if the node is out of sync and thus not mining
+		if !isNearlySynced {
+			continue
+		}
+
+		tx := appmessage.MsgTxToDomainTransaction(msgStemTransaction.MsgTx)
+		txID := consensushashing.TransactionID(tx)
+		err = context.RelayStemTransaction(tx)
+		if err != nil {
+			ruleErr := &mempool.RuleError{}
+			if !errors.As(err, ruleErr) {
+				return errors.Wrapf(err, "failed to process stem transaction %s", txID)
+			}
+
+			shouldBan := false
+			if txRuleErr := (&mempool.TxRuleError{}); errors.As(ruleErr.Err, txRuleErr) {
+				if txRuleErr.RejectCode == mempool.RejectInvalid {
+					shouldBan = true
+				}
+			}
+
+			if !shouldBan {
+				log.Debugf("Ignoring stem transaction %s: %s", txID, ruleErr)
+				continue
+			}
+
+			return protocolerrors.Errorf(true, "rejected stem transaction %s: %s", txID, ruleErr)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package stemrelay
+
+import (
+	"github.com/ammm56/lings/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("PROT")
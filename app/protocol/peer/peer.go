@@ -18,9 +18,10 @@ type Peer struct {
 	connection *netadapter.NetConnection
 
 	userAgent                string
-	services                 appmessage.ServiceFlag
-	advertisedProtocolVerion uint32 // protocol version advertised by remote
-	protocolVersion          uint32 // negotiated protocol version
+	services                 appmessage.ServiceFlag // services advertised by remote
+	negotiatedFeatures       appmessage.ServiceFlag // features supported by both sides
+	advertisedProtocolVerion uint32                 // protocol version advertised by remote
+	protocolVersion          uint32                 // negotiated protocol version
 	disableRelayTx           bool
 	subnetworkID             *externalapi.DomainSubnetworkID
 
@@ -80,6 +81,33 @@ func (p *Peer) ProtocolVersion() uint32 {
 	return p.protocolVersion
 }
 
+// Services returns the services advertised by the peer.
+func (p *Peer) Services() appmessage.ServiceFlag {
+	return p.services
+}
+
+// NegotiatedFeatures returns the features that are enabled for the connection
+// with this peer, i.e. the features advertised by both sides.
+func (p *Peer) NegotiatedFeatures() appmessage.ServiceFlag {
+	return p.negotiatedFeatures
+}
+
+// HasNegotiatedFeature returns whether the given feature is enabled for the
+// connection with this peer.
+func (p *Peer) HasNegotiatedFeature(feature appmessage.ServiceFlag) bool {
+	return p.negotiatedFeatures&feature == feature
+}
+
+// IsTransactionRelayEnabled returns whether transactions are relayed to and
+// from this peer. Peers that negotiated a protocol version older than
+// appmessage.FeatureNegotiationProtocolVersion always relay transactions.
+func (p *Peer) IsTransactionRelayEnabled() bool {
+	if p.protocolVersion < appmessage.FeatureNegotiationProtocolVersion {
+		return true
+	}
+	return p.HasNegotiatedFeature(appmessage.SFNodeTxRelay)
+}
+
 // TimeConnected returns the time since the connection to this been has been started.
 func (p *Peer) TimeConnected() time.Duration {
 	return time.Since(p.connectionStarted)
@@ -91,7 +119,11 @@ func (p *Peer) IsOutbound() bool {
 }
 
 // UpdateFieldsFromMsgVersion updates the peer with the data from the version message.
-func (p *Peer) UpdateFieldsFromMsgVersion(msg *appmessage.MsgVersion, maxProtocolVersion uint32) {
+// localServices are the services advertised by the local node, and are used to
+// negotiate the features enabled for this connection.
+func (p *Peer) UpdateFieldsFromMsgVersion(msg *appmessage.MsgVersion, maxProtocolVersion uint32,
+	localServices appmessage.ServiceFlag) {
+
 	// Negotiate the protocol version.
 	p.advertisedProtocolVerion = msg.ProtocolVersion
 	p.protocolVersion = mathUtil.MinUint32(maxProtocolVersion, p.advertisedProtocolVerion)
@@ -102,6 +134,12 @@ func (p *Peer) UpdateFieldsFromMsgVersion(msg *appmessage.MsgVersion, maxProtoco
 	// advertised.
 	p.services = msg.Services
 
+	// Features are only negotiated from the protocol version that introduced them.
+	if p.protocolVersion >= appmessage.FeatureNegotiationProtocolVersion {
+		p.negotiatedFeatures = localServices & msg.Services & appmessage.NegotiableFeatures
+		log.Debugf("Negotiated features %s for peer %s", p.negotiatedFeatures, p)
+	}
+
 	// Set the remote peer's user agent.
 	p.userAgent = msg.UserAgent
 
@@ -7,6 +7,7 @@ import (
 	"github.com/ammm56/lings/app/protocol/common"
 	"github.com/ammm56/lings/app/protocol/flows/ready"
 	v5 "github.com/ammm56/lings/app/protocol/flows/v5"
+	v6 "github.com/ammm56/lings/app/protocol/flows/v6"
 
 	"github.com/ammm56/lings/app/appmessage"
 	"github.com/ammm56/lings/app/protocol/flows/handshake"
@@ -75,10 +76,13 @@ func (m *Manager) routerInitializer(router *routerpkg.Router, netConnection *net
 		defer m.context.RemoveFromPeers(peer)
 
 		var flows []*common.Flow
-		log.Infof("Registering p2p flows for peer %s for protocol version %d", peer, peer.ProtocolVersion())
+		log.Infof("Registering p2p flows for peer %s for protocol version %d (features: %s)",
+			peer, peer.ProtocolVersion(), peer.NegotiatedFeatures())
 		switch peer.ProtocolVersion() {
 		case 5:
 			flows = v5.Register(m, router, errChan, &isStopping)
+		case 6:
+			flows = v6.Register(m, router, errChan, &isStopping, peer)
 		default:
 			panic(errors.Errorf("no way to handle protocol version %d", peer.ProtocolVersion()))
 		}
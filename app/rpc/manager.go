@@ -8,10 +8,12 @@ import (
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
 	"github.com/ammm56/lings/domain/utxoindex"
 	"github.com/ammm56/lings/infrastructure/config"
+	"github.com/ammm56/lings/infrastructure/db/database"
 	"github.com/ammm56/lings/infrastructure/logger"
 	"github.com/ammm56/lings/infrastructure/network/addressmanager"
 	"github.com/ammm56/lings/infrastructure/network/connmanager"
 	"github.com/ammm56/lings/infrastructure/network/netadapter"
+	"github.com/ammm56/lings/util/mstime"
 	"github.com/pkg/errors"
 )
 
@@ -29,6 +31,8 @@ func NewManager(
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
+	db database.Database,
+	clock *mstime.AdjustableClock,
 	consensusEventsChan chan externalapi.ConsensusEvent,
 	shutDownChan chan<- struct{}) *Manager {
 
@@ -41,6 +45,8 @@ func NewManager(
 			connectionManager,
 			addressManager,
 			utxoIndex,
+			db,
+			clock,
 			shutDownChan,
 		),
 	}
@@ -51,6 +51,13 @@ var handlers = map[appmessage.MessageCommand]handler{
 	appmessage.CmdNotifyNewBlockTemplateRequestMessage:                      rpchandlers.HandleNotifyNewBlockTemplate,
 	appmessage.CmdGetCoinSupplyRequestMessage:                               rpchandlers.HandleGetCoinSupply,
 	appmessage.CmdGetMempoolEntriesByAddressesRequestMessage:                rpchandlers.HandleGetMempoolEntriesByAddresses,
+	appmessage.CmdBackupDatabaseRequestMessage:                              rpchandlers.HandleBackupDatabase,
+	appmessage.CmdSetDatabaseOptionsRequestMessage:                          rpchandlers.HandleSetDatabaseOptions,
+	appmessage.CmdGetCacheStatsRequestMessage:                               rpchandlers.HandleGetCacheStats,
+	appmessage.CmdGenerateBlocksRequestMessage:                              rpchandlers.HandleGenerateBlocks,
+	appmessage.CmdSetNodeTimeRequestMessage:                                 rpchandlers.HandleSetNodeTime,
+	appmessage.CmdGetDAGGraphRequestMessage:                                 rpchandlers.HandleGetDAGGraph,
+	appmessage.CmdGetPruningPointProofRequestMessage:                        rpchandlers.HandleGetPruningPointProof,
 }
 
 func (m *Manager) routerInitializer(router *router.Router, netConnection *netadapter.NetConnection) {
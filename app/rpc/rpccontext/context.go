@@ -5,9 +5,11 @@ import (
 	"github.com/ammm56/lings/domain"
 	"github.com/ammm56/lings/domain/utxoindex"
 	"github.com/ammm56/lings/infrastructure/config"
+	"github.com/ammm56/lings/infrastructure/db/database"
 	"github.com/ammm56/lings/infrastructure/network/addressmanager"
 	"github.com/ammm56/lings/infrastructure/network/connmanager"
 	"github.com/ammm56/lings/infrastructure/network/netadapter"
+	"github.com/ammm56/lings/util/mstime"
 )
 
 // Context represents the RPC context
@@ -19,6 +21,8 @@ type Context struct {
 	ConnectionManager *connmanager.ConnectionManager
 	AddressManager    *addressmanager.AddressManager
 	UTXOIndex         *utxoindex.UTXOIndex
+	Database          database.Database
+	Clock             *mstime.AdjustableClock
 	ShutDownChan      chan<- struct{}
 
 	NotificationManager *NotificationManager
@@ -32,6 +36,8 @@ func NewContext(cfg *config.Config,
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
+	db database.Database,
+	clock *mstime.AdjustableClock,
 	shutDownChan chan<- struct{}) *Context {
 
 	context := &Context{
@@ -42,6 +48,8 @@ func NewContext(cfg *config.Config,
 		ConnectionManager: connectionManager,
 		AddressManager:    addressManager,
 		UTXOIndex:         utxoIndex,
+		Database:          db,
+		Clock:             clock,
 		ShutDownChan:      shutDownChan,
 	}
 	context.NotificationManager = NewNotificationManager(cfg.ActiveNetParams)
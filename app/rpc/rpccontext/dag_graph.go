@@ -0,0 +1,205 @@
+package rpccontext
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ammm56/lings/domain/consensus/model"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/hashes"
+)
+
+// The colors of the blocks in a DAGGraph
+const (
+	DAGGraphColorBlue = "blue"
+	DAGGraphColorRed  = "red"
+)
+
+// DAGGraph is a sub-DAG along with the GHOSTDAG data of its blocks
+type DAGGraph struct {
+	LowHash  string           `json:"lowHash"`
+	HighHash string           `json:"highHash"`
+	Blocks   []*DAGGraphBlock `json:"blocks"`
+}
+
+// DAGGraphBlock is a block in a DAGGraph. Its color, and whether it's a chain
+// block, are determined by the selected parent chain of the graph's high block.
+// The color of a low block that isn't in that chain is left empty.
+type DAGGraphBlock struct {
+	Hash           string   `json:"hash"`
+	Parents        []string `json:"parents"`
+	SelectedParent string   `json:"selectedParent"`
+	MergeSetBlues  []string `json:"mergeSetBlues"`
+	MergeSetReds   []string `json:"mergeSetReds"`
+	Color          string   `json:"color"`
+	IsChainBlock   bool     `json:"isChainBlock"`
+	BlueScore      uint64   `json:"blueScore"`
+	BlueWork       string   `json:"blueWork"`
+	DAAScore       uint64   `json:"daaScore"`
+	Timestamp      int64    `json:"timestamp"`
+}
+
+// BuildDAGGraph builds the DAGGraph of lowHash and of the blocks between lowHash
+// and highHash. If maxBlocks is not 0, the graph is cut at the first
+// merge set that would make it larger than maxBlocks, in which case its high
+// block is lower than highHash.
+func (ctx *Context) BuildDAGGraph(lowHash, highHash *externalapi.DomainHash, maxBlocks uint64) (*DAGGraph, error) {
+	consensus := ctx.Domain.Consensus()
+
+	blockHashes, highHash, err := consensus.GetHashesBetween(lowHash, highHash, maxBlocks)
+	if err != nil {
+		return nil, err
+	}
+	blockHashes = append([]*externalapi.DomainHash{lowHash}, blockHashes...)
+
+	blockInfos := make(map[externalapi.DomainHash]*externalapi.BlockInfo, len(blockHashes))
+	for _, blockHash := range blockHashes {
+		blockInfo, err := consensus.GetBlockInfo(blockHash)
+		if err != nil {
+			return nil, err
+		}
+		blockInfos[*blockHash] = blockInfo
+	}
+
+	// Every block in the graph, except for lowHash, is in the merge set of
+	// a block in the selected parent chain of highHash, so the chain is walked
+	// down until all of them are colored, and until it leaves the graph
+	colors := make(map[externalapi.DomainHash]string, len(blockHashes))
+	chainBlocks := make(map[externalapi.DomainHash]struct{})
+	uncoloredBlockCount := len(blockHashes) - 1
+	chainBlockHash := highHash
+	for {
+		chainBlockInfo, err := consensus.GetBlockInfo(chainBlockHash)
+		if err != nil {
+			return nil, err
+		}
+		if !chainBlockInfo.HasHeader() {
+			break
+		}
+		if _, isInGraph := blockInfos[*chainBlockHash]; isInGraph {
+			chainBlocks[*chainBlockHash] = struct{}{}
+		}
+		colorBlock := func(blockHash *externalapi.DomainHash, color string) {
+			_, isInGraph := blockInfos[*blockHash]
+			_, isColored := colors[*blockHash]
+			if !isInGraph || isColored {
+				return
+			}
+			colors[*blockHash] = color
+			if !blockHash.Equal(lowHash) {
+				uncoloredBlockCount--
+			}
+		}
+		colorBlock(chainBlockHash, DAGGraphColorBlue)
+		for _, blueHash := range chainBlockInfo.MergeSetBlues {
+			colorBlock(blueHash, DAGGraphColorBlue)
+		}
+		for _, redHash := range chainBlockInfo.MergeSetReds {
+			colorBlock(redHash, DAGGraphColorRed)
+		}
+
+		if chainBlockHash.Equal(lowHash) || !hasSelectedParent(chainBlockInfo) {
+			break
+		}
+		if _, isInGraph := blockInfos[*chainBlockInfo.SelectedParent]; !isInGraph && uncoloredBlockCount == 0 {
+			break
+		}
+		chainBlockHash = chainBlockInfo.SelectedParent
+	}
+
+	graph := &DAGGraph{
+		LowHash:  lowHash.String(),
+		HighHash: highHash.String(),
+		Blocks:   make([]*DAGGraphBlock, len(blockHashes)),
+	}
+	for i, blockHash := range blockHashes {
+		blockHeader, err := consensus.GetBlockHeader(blockHash)
+		if err != nil {
+			return nil, err
+		}
+		blockInfo := blockInfos[*blockHash]
+		_, isChainBlock := chainBlocks[*blockHash]
+		block := &DAGGraphBlock{
+			Hash:          blockHash.String(),
+			Parents:       hashes.ToStrings(blockHeader.DirectParents()),
+			MergeSetBlues: hashes.ToStrings(blockInfo.MergeSetBlues),
+			MergeSetReds:  hashes.ToStrings(blockInfo.MergeSetReds),
+			Color:         colors[*blockHash],
+			IsChainBlock:  isChainBlock,
+			BlueScore:     blockInfo.BlueScore,
+			DAAScore:      blockHeader.DAAScore(),
+			Timestamp:     blockHeader.TimeInMilliseconds(),
+		}
+		if hasSelectedParent(blockInfo) {
+			block.SelectedParent = blockInfo.SelectedParent.String()
+		}
+		if blockInfo.BlueWork != nil {
+			block.BlueWork = blockInfo.BlueWork.Text(16)
+		}
+		graph.Blocks[i] = block
+	}
+
+	return graph, nil
+}
+
+// hasSelectedParent returns whether the block has a selected parent, which
+// isn't the case for the genesis and for blocks below the pruning point
+func hasSelectedParent(blockInfo *externalapi.BlockInfo) bool {
+	return blockInfo.SelectedParent != nil && !blockInfo.SelectedParent.Equal(model.VirtualGenesisBlockHash)
+}
+
+// JSON returns the graph in JSON format
+func (g *DAGGraph) JSON() (string, error) {
+	graphJSON, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(graphJSON), nil
+}
+
+// DOT returns the graph in the DOT language of Graphviz. Blue and red
+// blocks are filled with their color, chain blocks have a thick border, and
+// every block points to its parents, with a thick edge to its selected parent.
+// Parents that aren't in the graph are omitted.
+func (g *DAGGraph) DOT() string {
+	fillColors := map[string]string{
+		DAGGraphColorBlue: "lightblue",
+		DAGGraphColorRed:  "lightpink",
+		"":                "white",
+	}
+
+	blocksInGraph := make(map[string]struct{}, len(g.Blocks))
+	for _, block := range g.Blocks {
+		blocksInGraph[block.Hash] = struct{}{}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("digraph DAG {\n")
+	builder.WriteString("\trankdir = BT;\n")
+	builder.WriteString("\tnode [shape = box, style = filled, fontname = monospace];\n\n")
+	for _, block := range g.Blocks {
+		penWidth := 1
+		if block.IsChainBlock {
+			penWidth = 3
+		}
+		builder.WriteString(fmt.Sprintf("\t\"%s\" [label = \"%s\\nblue score %d\\nDAA score %d\", fillcolor = %s, penwidth = %d];\n",
+			block.Hash, block.Hash[:8], block.BlueScore, block.DAAScore, fillColors[block.Color], penWidth))
+	}
+	builder.WriteString("\n")
+	for _, block := range g.Blocks {
+		for _, parent := range block.Parents {
+			if _, ok := blocksInGraph[parent]; !ok {
+				continue
+			}
+			if parent == block.SelectedParent {
+				builder.WriteString(fmt.Sprintf("\t\"%s\" -> \"%s\" [penwidth = 3];\n", block.Hash, parent))
+			} else {
+				builder.WriteString(fmt.Sprintf("\t\"%s\" -> \"%s\";\n", block.Hash, parent))
+			}
+		}
+	}
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
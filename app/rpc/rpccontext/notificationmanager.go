@@ -452,7 +452,7 @@ func (nl *NotificationListener) scriptPubKeyStringToAddressString(scriptPublicKe
 	}
 
 	var addressString string
-	if scriptType == txscript.NonStandardTy {
+	if scriptType == txscript.NonStandardTy || address == nil {
 		addressString = ""
 	} else {
 		addressString = address.String()
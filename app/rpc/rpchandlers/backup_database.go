@@ -0,0 +1,48 @@
+package rpchandlers
+
+import (
+	"path/filepath"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/rpc/rpccontext"
+	"github.com/ammm56/lings/infrastructure/db/database"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
+)
+
+// HandleBackupDatabase handles the respectively named RPC command
+func HandleBackupDatabase(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if context.Config.SafeRPC {
+		log.Warn("BackupDatabase RPC command called while node in safe RPC mode -- ignoring.")
+		errorMessage := &appmessage.BackupDatabaseResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("BackupDatabase RPC command called while node in safe RPC mode")
+		return errorMessage, nil
+	}
+
+	backupDatabaseRequest := request.(*appmessage.BackupDatabaseRequestMessage)
+	if !filepath.IsAbs(backupDatabaseRequest.Path) {
+		errorMessage := &appmessage.BackupDatabaseResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("The backup path must be absolute")
+		return errorMessage, nil
+	}
+
+	backupable, ok := context.Database.(database.Backupable)
+	if !ok {
+		errorMessage := &appmessage.BackupDatabaseResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("The database does not support online backups")
+		return errorMessage, nil
+	}
+
+	log.Infof("Backing up the database to %s", backupDatabaseRequest.Path)
+	manifest, err := backupable.Backup(backupDatabaseRequest.Path, func(copiedEntries uint64) {
+		log.Infof("Database backup in progress: copied %d entries", copiedEntries)
+	})
+	if err != nil {
+		errorMessage := &appmessage.BackupDatabaseResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not back up the database: %s", err)
+		return errorMessage, nil
+	}
+	log.Infof("Finished backing up the database to %s: %d entries, checksum %s",
+		backupDatabaseRequest.Path, manifest.EntryCount, manifest.Checksum)
+
+	return appmessage.NewBackupDatabaseResponseMessage(manifest.EntryCount, manifest.Size, manifest.Checksum), nil
+}
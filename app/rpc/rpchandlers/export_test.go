@@ -0,0 +1,11 @@
+package rpchandlers
+
+import "testing"
+
+// SetMaxSolveBlockAttempts replaces maxSolveBlockAttempts until the end of the
+// given test, so that failing to solve a block doesn't take long to test
+func SetMaxSolveBlockAttempts(t *testing.T, attempts int) {
+	originalMaxSolveBlockAttempts := maxSolveBlockAttempts
+	maxSolveBlockAttempts = attempts
+	t.Cleanup(func() { maxSolveBlockAttempts = originalMaxSolveBlockAttempts })
+}
@@ -0,0 +1,140 @@
+package rpchandlers
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/protocol/protocolerrors"
+	"github.com/ammm56/lings/app/rpc/rpccontext"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/ruleerrors"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/pow"
+	"github.com/ammm56/lings/domain/consensus/utils/txscript"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
+	"github.com/ammm56/lings/util"
+	"github.com/ammm56/lings/version"
+	"github.com/pkg/errors"
+)
+
+// maxGenerateBlocksCount is the maximum number of blocks that can be
+// generated by a single GenerateBlocks request
+const maxGenerateBlocksCount = 1000
+
+// maxSolveBlockAttempts is the number of nonces that are tried before giving
+// up on solving a generated block. Block generation is only allowed on
+// networks where far fewer attempts are expected to be needed, but their
+// difficulty may still be raised, e.g. by a custom network definition.
+var maxSolveBlockAttempts = 1 << 24
+
+// HandleGenerateBlocks handles the respectively named RPC command
+func HandleGenerateBlocks(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	generateBlocksRequest := request.(*appmessage.GenerateBlocksRequestMessage)
+
+	params := context.Config.NetParams()
+	if !params.AllowsBlockGeneration() {
+		errorMessage := &appmessage.GenerateBlocksResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Generating blocks is not allowed on %s", params.Name)
+		return errorMessage, nil
+	}
+
+	if generateBlocksRequest.Count == 0 || generateBlocksRequest.Count > maxGenerateBlocksCount {
+		errorMessage := &appmessage.GenerateBlocksResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Count must be between 1 and %d", maxGenerateBlocksCount)
+		return errorMessage, nil
+	}
+
+	payAddress, err := util.DecodeAddress(generateBlocksRequest.PayAddress, params.Prefix)
+	if err != nil {
+		errorMessage := &appmessage.GenerateBlocksResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not decode address: %s", err)
+		return errorMessage, nil
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(payAddress)
+	if err != nil {
+		return nil, err
+	}
+	coinbaseData := &externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey, ExtraData: []byte(version.Version())}
+
+	var parentHashes []*externalapi.DomainHash
+	for _, parentHashString := range generateBlocksRequest.ParentHashes {
+		parentHash, err := externalapi.NewDomainHashFromString(parentHashString)
+		if err != nil {
+			errorMessage := &appmessage.GenerateBlocksResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Could not parse parent hash %s: %s", parentHashString, err)
+			return errorMessage, nil
+		}
+		parentHashes = append(parentHashes, parentHash)
+	}
+
+	blockHashes := make([]string, 0, generateBlocksRequest.Count)
+	for i := uint32(0); i < generateBlocksRequest.Count; i++ {
+		var block *externalapi.DomainBlock
+		if len(parentHashes) == 0 {
+			block, _, err = context.Domain.MiningManager().GetBlockTemplate(coinbaseData)
+		} else {
+			block, _, err = context.Domain.MiningManager().GetBlockTemplateOnParents(parentHashes, coinbaseData)
+		}
+		if err != nil {
+			errorMessage := appmessage.NewGenerateBlocksResponseMessage(blockHashes)
+			errorMessage.Error = appmessage.RPCErrorf("Could not build block template: %s", err)
+			return errorMessage, nil
+		}
+
+		block, err = solveBlock(block, params.SkipProofOfWork)
+		if err != nil {
+			errorMessage := appmessage.NewGenerateBlocksResponseMessage(blockHashes)
+			errorMessage.Error = appmessage.RPCErrorf("Could not solve block: %s", err)
+			return errorMessage, nil
+		}
+		err = context.ProtocolManager.AddBlock(block)
+		if err != nil {
+			isProtocolOrRuleError := errors.As(err, &ruleerrors.RuleError{}) || errors.As(err, &protocolerrors.ProtocolError{})
+			if !isProtocolOrRuleError {
+				return nil, err
+			}
+			errorMessage := appmessage.NewGenerateBlocksResponseMessage(blockHashes)
+			errorMessage.Error = appmessage.RPCErrorf("Generated block rejected. Reason: %s", err)
+			return errorMessage, nil
+		}
+
+		blockHash := consensushashing.BlockHash(block)
+		log.Debugf("Accepted generated block %s", blockHash)
+		blockHashes = append(blockHashes, blockHash.String())
+
+		// Every block after the first one is built on top of its predecessor,
+		// so that explicit parents result in a chain rather than siblings
+		if len(parentHashes) > 0 {
+			parentHashes = []*externalapi.DomainHash{blockHash}
+		}
+	}
+
+	return appmessage.NewGenerateBlocksResponseMessage(blockHashes), nil
+}
+
+// solveBlock returns a copy of the given block template with a nonce that
+// satisfies its difficulty target, or an error if no such nonce was found
+// within maxSolveBlockAttempts attempts. The template is copied because it may
+// be shared with the mining manager's template cache, and because the UTXO
+// entries its transactions were populated with must not be submitted.
+func solveBlock(block *externalapi.DomainBlock, skipProofOfWork bool) (*externalapi.DomainBlock, error) {
+	solvedBlock := block.Clone()
+	for _, transaction := range solvedBlock.Transactions {
+		for _, input := range transaction.Inputs {
+			input.UTXOEntry = nil
+		}
+	}
+	if skipProofOfWork {
+		return solvedBlock, nil
+	}
+	header := solvedBlock.Header.ToMutable()
+	state := pow.NewState(header)
+	for attempts := 1; !state.CheckProofOfWork(); attempts++ {
+		if attempts == maxSolveBlockAttempts {
+			return nil, errors.Errorf("found no nonce that satisfies difficulty bits %d in %d attempts",
+				header.Bits(), maxSolveBlockAttempts)
+		}
+		state.IncrementNonce()
+	}
+	header.SetNonce(state.Nonce)
+	solvedBlock.Header = header.ToImmutable()
+	return solvedBlock, nil
+}
@@ -0,0 +1,188 @@
+package rpchandlers_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/rpc/rpccontext"
+	"github.com/ammm56/lings/app/rpc/rpchandlers"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/infrastructure/config"
+)
+
+func TestHandleGenerateBlocks(t *testing.T) {
+	mainnetContext := &rpccontext.Context{
+		Config: &config.Config{Flags: &config.Flags{NetworkFlags: config.NetworkFlags{ActiveNetParams: &dagconfig.MainnetParams}}},
+	}
+	response := generateBlocks(t, mainnetContext, &appmessage.GenerateBlocksRequestMessage{
+		Count:      1,
+		PayAddress: opTrueAddress(t, &dagconfig.MainnetParams),
+	})
+	if response.Error == nil {
+		t.Fatalf("expected generating blocks to be rejected on mainnet")
+	}
+
+	params := simnetParams()
+	context := newTestNodeContext(t, params)
+	payAddress := opTrueAddress(t, params)
+
+	parseHashes := func(hashStrings []string) []*externalapi.DomainHash {
+		hashes := make([]*externalapi.DomainHash, len(hashStrings))
+		for i, hashString := range hashStrings {
+			hash, err := externalapi.NewDomainHashFromString(hashString)
+			if err != nil {
+				t.Fatalf("NewDomainHashFromString: %+v", err)
+			}
+			hashes[i] = hash
+		}
+		return hashes
+	}
+	directParents := func(blockHash *externalapi.DomainHash) []*externalapi.DomainHash {
+		header, err := context.Domain.Consensus().GetBlockHeader(blockHash)
+		if err != nil {
+			t.Fatalf("GetBlockHeader: %+v", err)
+		}
+		return header.DirectParents()
+	}
+	isChain := func(blockHashes []*externalapi.DomainHash, firstParent *externalapi.DomainHash) bool {
+		parent := firstParent
+		for _, blockHash := range blockHashes {
+			parents := directParents(blockHash)
+			if len(parents) != 1 || !parents[0].Equal(parent) {
+				return false
+			}
+			parent = blockHash
+		}
+		return true
+	}
+
+	tests := []struct {
+		name          string
+		request       *appmessage.GenerateBlocksRequestMessage
+		expectedError string
+	}{
+		{
+			name:          "no blocks",
+			request:       &appmessage.GenerateBlocksRequestMessage{Count: 0, PayAddress: payAddress},
+			expectedError: "Count must be between 1 and 1000",
+		},
+		{
+			name:          "too many blocks",
+			request:       &appmessage.GenerateBlocksRequestMessage{Count: 1001, PayAddress: payAddress},
+			expectedError: "Count must be between 1 and 1000",
+		},
+		{
+			name: "address of another network",
+			request: &appmessage.GenerateBlocksRequestMessage{
+				Count:      1,
+				PayAddress: opTrueAddress(t, &dagconfig.MainnetParams),
+			},
+			expectedError: "Could not decode address",
+		},
+		{
+			name: "malformed parent hash",
+			request: &appmessage.GenerateBlocksRequestMessage{
+				Count:        1,
+				PayAddress:   payAddress,
+				ParentHashes: []string{"abcd"},
+			},
+			expectedError: "Could not parse parent hash abcd",
+		},
+		{
+			name: "unknown parent",
+			request: &appmessage.GenerateBlocksRequestMessage{
+				Count:        1,
+				PayAddress:   payAddress,
+				ParentHashes: []string{strings.Repeat("ab", externalapi.DomainHashSize)},
+			},
+			expectedError: "Could not build block template",
+		},
+	}
+	for _, test := range tests {
+		response := generateBlocks(t, context, test.request)
+		if response.Error == nil || !strings.Contains(response.Error.Message, test.expectedError) {
+			t.Fatalf("%s: expected an error containing %q but got %v", test.name, test.expectedError, response.Error)
+		}
+		if len(response.BlockHashes) != 0 {
+			t.Fatalf("%s: expected no blocks to be generated, but got %s", test.name, response.BlockHashes)
+		}
+	}
+
+	// Without explicit parents, every block is built on the virtual, which is
+	// the block generated before it when nothing else is mined
+	response = generateBlocks(t, context, &appmessage.GenerateBlocksRequestMessage{Count: 3, PayAddress: payAddress})
+	if response.Error != nil {
+		t.Fatalf("HandleGenerateBlocks: %s", response.Error.Message)
+	}
+	if len(response.BlockHashes) != 3 {
+		t.Fatalf("expected 3 generated blocks, got %d", len(response.BlockHashes))
+	}
+	mainChain := parseHashes(response.BlockHashes)
+	if !isChain(mainChain, params.GenesisHash) {
+		t.Fatalf("expected the generated blocks to form a chain on top of genesis")
+	}
+
+	// With explicit parents, the first block is built on them and every other
+	// block on the block generated before it, so that they form a side chain
+	response = generateBlocks(t, context, &appmessage.GenerateBlocksRequestMessage{
+		Count:        2,
+		PayAddress:   payAddress,
+		ParentHashes: []string{mainChain[0].String()},
+	})
+	if response.Error != nil {
+		t.Fatalf("HandleGenerateBlocks: %s", response.Error.Message)
+	}
+	sideChain := parseHashes(response.BlockHashes)
+	if len(sideChain) != 2 || !isChain(sideChain, mainChain[0]) {
+		t.Fatalf("expected the generated blocks to form a chain on top of %s", mainChain[0])
+	}
+
+	// Explicit parents may be several blocks, which the first block merges
+	response = generateBlocks(t, context, &appmessage.GenerateBlocksRequestMessage{
+		Count:        1,
+		PayAddress:   payAddress,
+		ParentHashes: []string{mainChain[2].String(), sideChain[1].String()},
+	})
+	if response.Error != nil {
+		t.Fatalf("HandleGenerateBlocks: %s", response.Error.Message)
+	}
+	mergingBlock := parseHashes(response.BlockHashes)[0]
+	if len(directParents(mergingBlock)) != 2 {
+		t.Fatalf("expected the generated block to have 2 parents, got %s", directParents(mergingBlock))
+	}
+	tips, err := context.Domain.Consensus().Tips()
+	if err != nil {
+		t.Fatalf("Tips: %+v", err)
+	}
+	if len(tips) != 1 || !tips[0].Equal(mergingBlock) {
+		t.Fatalf("expected the merging block to be the only tip, got %s", tips)
+	}
+}
+
+func TestHandleGenerateBlocksMaxSolveBlockAttempts(t *testing.T) {
+	// Difficulty adjustment is disabled on simnet, so every block has the
+	// difficulty of the genesis block, which is mainnet's. A block that
+	// requires proof of work at that difficulty isn't solved in a few attempts.
+	params := simnetParams()
+	params.SkipProofOfWork = false
+	context := newTestNodeContext(t, params)
+
+	const maxSolveBlockAttempts = 10
+	rpchandlers.SetMaxSolveBlockAttempts(t, maxSolveBlockAttempts)
+
+	response := generateBlocks(t, context, &appmessage.GenerateBlocksRequestMessage{
+		Count:      1,
+		PayAddress: opTrueAddress(t, params),
+	})
+	if response.Error == nil || !strings.Contains(response.Error.Message, "Could not solve block") {
+		t.Fatalf("expected an error about solving the block, but got %v", response.Error)
+	}
+	if !strings.Contains(response.Error.Message, "in 10 attempts") {
+		t.Fatalf("expected the error to mention the %d attempts, but got %s", maxSolveBlockAttempts, response.Error.Message)
+	}
+	if len(response.BlockHashes) != 0 {
+		t.Fatalf("expected no blocks to be generated, but got %s", response.BlockHashes)
+	}
+}
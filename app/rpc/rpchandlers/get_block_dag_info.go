@@ -37,6 +37,14 @@ func HandleGetBlockDAGInfo(context *rpccontext.Context, _ *router.Router, _ appm
 	response.PastMedianTime = virtualInfo.PastMedianTime
 	response.VirtualDAAScore = virtualInfo.DAAScore
 
+	devFeePeriod := params.DevFeeSchedule.PeriodAt(virtualInfo.DAAScore)
+	if devFeePeriod != nil {
+		response.DevFeeAddress = devFeePeriod.Address
+		response.DevFeePercent = devFeePeriod.Percent
+		response.DevFeeActivationDAAScore = devFeePeriod.ActivationDAAScore
+		response.DevFeeExpiryDAAScore = devFeePeriod.ExpiryDAAScore
+	}
+
 	pruningPoint, err := context.Domain.Consensus().PruningPoint()
 	if err != nil {
 		return nil, err
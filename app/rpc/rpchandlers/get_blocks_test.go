@@ -43,6 +43,10 @@ func (d fakeDomain) CommitStagingConsensus() error {
 	panic("implement me")
 }
 
+func (d fakeDomain) CheckConsistency(depth uint64, repair bool) error {
+	panic("implement me")
+}
+
 func (d fakeDomain) Consensus() externalapi.Consensus           { return d }
 func (d fakeDomain) MiningManager() miningmanager.MiningManager { return nil }
 
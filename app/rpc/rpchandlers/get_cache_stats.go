@@ -0,0 +1,32 @@
+package rpchandlers
+
+import (
+	"sort"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/rpc/rpccontext"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
+)
+
+// HandleGetCacheStats handles the respective RPC command
+func HandleGetCacheStats(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	consensusCacheStats := context.Domain.Consensus().CacheStats()
+	names := make([]string, 0, len(consensusCacheStats))
+	for name := range consensusCacheStats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cacheStats := make([]*appmessage.CacheStats, len(names))
+	for i, name := range names {
+		stats := consensusCacheStats[name]
+		cacheStats[i] = &appmessage.CacheStats{
+			Name:     stats.Name,
+			Entries:  uint64(stats.Entries),
+			Capacity: uint64(stats.Capacity),
+			Hits:     stats.Hits,
+			Misses:   stats.Misses,
+		}
+	}
+	return appmessage.NewGetCacheStatsResponseMessage(context.Config.MemoryBudgetMiB, cacheStats), nil
+}
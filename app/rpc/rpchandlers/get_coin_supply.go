@@ -25,5 +25,15 @@ func HandleGetCoinSupply(context *rpccontext.Context, _ *router.Router, _ appmes
 		circulatingSompiSupply,
 	)
 
+	virtualInfo, err := context.Domain.Consensus().GetVirtualInfo()
+	if err != nil {
+		return nil, err
+	}
+	devFeePeriod := context.Config.ActiveNetParams.DevFeeSchedule.PeriodAt(virtualInfo.DAAScore)
+	if devFeePeriod != nil {
+		response.DevFeeAddress = devFeePeriod.Address
+		response.DevFeePercent = devFeePeriod.Percent
+	}
+
 	return response, nil
 }
@@ -22,6 +22,8 @@ func HandleGetConnectedPeerInfo(context *rpccontext.Context, _ *router.Router, _
 			AdvertisedProtocolVersion: peer.AdvertisedProtocolVersion(),
 			TimeConnected:             peer.TimeConnected().Milliseconds(),
 			IsIBDPeer:                 peer == ibdPeer,
+			Services:                  uint64(peer.Services()),
+			NegotiatedFeatures:        peer.NegotiatedFeatures().Names(),
 		}
 		infos = append(infos, info)
 	}
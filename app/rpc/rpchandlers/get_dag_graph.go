@@ -0,0 +1,177 @@
+package rpchandlers
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/rpc/rpccontext"
+	"github.com/ammm56/lings/domain/consensus/model"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
+)
+
+const (
+	// defaultGetDAGGraphBlockCount is the number of blocks returned by a
+	// GetDAGGraph request that doesn't specify a block count
+	defaultGetDAGGraphBlockCount = 100
+
+	// maxGetDAGGraphBlockCount is the maximum number of blocks that can be
+	// returned by a single GetDAGGraph request
+	maxGetDAGGraphBlockCount = 10000
+)
+
+// The formats supported by GetDAGGraph
+const (
+	dagGraphFormatJSON = "json"
+	dagGraphFormatDOT  = "dot"
+)
+
+// HandleGetDAGGraph handles the respectively named RPC command
+func HandleGetDAGGraph(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	getDAGGraphRequest := request.(*appmessage.GetDAGGraphRequestMessage)
+
+	format := getDAGGraphRequest.Format
+	if format == "" {
+		format = dagGraphFormatJSON
+	}
+	if format != dagGraphFormatJSON && format != dagGraphFormatDOT {
+		errorMessage := &appmessage.GetDAGGraphResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Unknown format %s -- Valid formats are %s and %s",
+			format, dagGraphFormatJSON, dagGraphFormatDOT)
+		return errorMessage, nil
+	}
+
+	blockCount := uint64(getDAGGraphRequest.BlockCount)
+	if blockCount == 0 {
+		blockCount = defaultGetDAGGraphBlockCount
+	}
+	if blockCount > maxGetDAGGraphBlockCount {
+		errorMessage := &appmessage.GetDAGGraphResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Block count must be at most %d", maxGetDAGGraphBlockCount)
+		return errorMessage, nil
+	}
+
+	highHash, err := context.Domain.Consensus().GetVirtualSelectedParent()
+	if err != nil {
+		return nil, err
+	}
+	if getDAGGraphRequest.HighHash != "" {
+		var errorMessage *appmessage.GetDAGGraphResponseMessage
+		highHash, errorMessage, err = parseDAGGraphHash(context, getDAGGraphRequest.HighHash, "highHash")
+		if err != nil {
+			return nil, err
+		}
+		if errorMessage != nil {
+			return errorMessage, nil
+		}
+	}
+
+	var maxBlocks uint64
+	var lowHash *externalapi.DomainHash
+	if getDAGGraphRequest.LowHash != "" {
+		var errorMessage *appmessage.GetDAGGraphResponseMessage
+		lowHash, errorMessage, err = parseDAGGraphHash(context, getDAGGraphRequest.LowHash, "lowHash")
+		if err != nil {
+			return nil, err
+		}
+		if errorMessage != nil {
+			return errorMessage, nil
+		}
+
+		lowBlockInfo, err := context.Domain.Consensus().GetBlockInfo(lowHash)
+		if err != nil {
+			return nil, err
+		}
+		highBlockInfo, err := context.Domain.Consensus().GetBlockInfo(highHash)
+		if err != nil {
+			return nil, err
+		}
+		if lowBlockInfo.BlueScore > highBlockInfo.BlueScore {
+			errorMessage := &appmessage.GetDAGGraphResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("lowHash %s has a higher blue score than highHash %s",
+				lowHash, highHash)
+			return errorMessage, nil
+		}
+
+		// The graph is cut at merge set granularity, so maxBlocks
+		// MUST be >= MergeSetSizeLimit + 1
+		maxBlocks = blockCount
+		if maxBlocks < context.Config.NetParams().MergeSetSizeLimit+1 {
+			maxBlocks = context.Config.NetParams().MergeSetSizeLimit + 1
+		}
+	} else {
+		lowHash, err = findDAGGraphLowHash(context, highHash, blockCount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	graph, err := context.BuildDAGGraph(lowHash, highHash, maxBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	var renderedGraph string
+	switch format {
+	case dagGraphFormatJSON:
+		renderedGraph, err = graph.JSON()
+		if err != nil {
+			return nil, err
+		}
+	case dagGraphFormatDOT:
+		renderedGraph = graph.DOT()
+	}
+
+	return appmessage.NewGetDAGGraphResponseMessage(renderedGraph, uint32(len(graph.Blocks))), nil
+}
+
+func parseDAGGraphHash(context *rpccontext.Context, hashString string, name string) (
+	*externalapi.DomainHash, *appmessage.GetDAGGraphResponseMessage, error) {
+
+	hash, err := externalapi.NewDomainHashFromString(hashString)
+	if err != nil {
+		errorMessage := &appmessage.GetDAGGraphResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not decode %s %s: %s", name, hashString, err)
+		return nil, errorMessage, nil
+	}
+	blockInfo, err := context.Domain.Consensus().GetBlockInfo(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !blockInfo.HasHeader() {
+		errorMessage := &appmessage.GetDAGGraphResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not find %s %s", name, hashString)
+		return nil, errorMessage, nil
+	}
+	return hash, nil, nil
+}
+
+// findDAGGraphLowHash walks down the selected parent chain of highHash until
+// the blocks between the returned hash and highHash, along with the
+// returned hash itself, are at least blockCount blocks. The walk stops
+// earlier if it reaches the genesis or the pruning point.
+func findDAGGraphLowHash(context *rpccontext.Context, highHash *externalapi.DomainHash, blockCount uint64) (
+	*externalapi.DomainHash, error) {
+
+	lowHash := highHash
+	collectedBlockCount := uint64(1)
+	for collectedBlockCount < blockCount {
+		lowBlockInfo, err := context.Domain.Consensus().GetBlockInfo(lowHash)
+		if err != nil {
+			return nil, err
+		}
+		if lowBlockInfo.SelectedParent == nil || lowBlockInfo.SelectedParent.Equal(model.VirtualGenesisBlockHash) {
+			break
+		}
+		selectedParentInfo, err := context.Domain.Consensus().GetBlockInfo(lowBlockInfo.SelectedParent)
+		if err != nil {
+			return nil, err
+		}
+		if !selectedParentInfo.HasHeader() {
+			break
+		}
+		// The merge set of a block includes its selected parent, which
+		// takes the place of the block as the lowest block of the graph
+		collectedBlockCount += uint64(len(lowBlockInfo.MergeSetBlues) + len(lowBlockInfo.MergeSetReds))
+		lowHash = lowBlockInfo.SelectedParent
+	}
+	return lowHash, nil
+}
@@ -0,0 +1,155 @@
+package rpchandlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/rpc/rpccontext"
+	"github.com/ammm56/lings/app/rpc/rpchandlers"
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/infrastructure/config"
+)
+
+func TestHandleGetDAGGraph(t *testing.T) {
+	consensusConfig := &consensus.Config{Params: dagconfig.MainnetParams}
+	consensusConfig.SkipProofOfWork = true
+	consensusConfig.K = 4
+	factory := consensus.NewFactory()
+	tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestHandleGetDAGGraph")
+	if err != nil {
+		t.Fatalf("Error setting up consensus: %+v", err)
+	}
+	defer teardown(false)
+
+	fakeContext := rpccontext.Context{
+		Config: &config.Config{Flags: &config.Flags{NetworkFlags: config.NetworkFlags{ActiveNetParams: &consensusConfig.Params}}},
+		Domain: fakeDomain{tc},
+	}
+
+	getDAGGraph := func(request *appmessage.GetDAGGraphRequestMessage) *appmessage.GetDAGGraphResponseMessage {
+		response, err := rpchandlers.HandleGetDAGGraph(&fakeContext, nil, request)
+		if err != nil {
+			t.Fatalf("HandleGetDAGGraph: %s", err)
+		}
+		return response.(*appmessage.GetDAGGraphResponseMessage)
+	}
+	getJSONGraph := func(request *appmessage.GetDAGGraphRequestMessage) *rpccontext.DAGGraph {
+		response := getDAGGraph(request)
+		if response.Error != nil {
+			t.Fatalf("HandleGetDAGGraph: %s", response.Error.Message)
+		}
+		graph := &rpccontext.DAGGraph{}
+		err := json.Unmarshal([]byte(response.Graph), graph)
+		if err != nil {
+			t.Fatalf("Unmarshal: %s", err)
+		}
+		if int(response.BlockCount) != len(graph.Blocks) {
+			t.Fatalf("expected a block count of %d, got %d", len(graph.Blocks), response.BlockCount)
+		}
+		return graph
+	}
+
+	// Create a DAG with the following structure, where the split is wider than
+	// K, so that some of the split blocks are red:
+	//
+	//              merging block
+	//         /      |      ...     \
+	//     split1  split2    ...  split(K+2)
+	//         \      |      ...     /
+	//                 root
+	//                   |
+	//                genesis
+	rootHash, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash}, nil, nil)
+	if err != nil {
+		t.Fatalf("AddBlock: %+v", err)
+	}
+	splitHashes := make([]*externalapi.DomainHash, consensusConfig.K+2)
+	for i := range splitHashes {
+		splitHashes[i], _, err = tc.AddBlock([]*externalapi.DomainHash{rootHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+	}
+	mergingHash, _, err := tc.AddBlock(splitHashes, nil, nil)
+	if err != nil {
+		t.Fatalf("AddBlock: %+v", err)
+	}
+	mergingBlockInfo, err := tc.GetBlockInfo(mergingHash)
+	if err != nil {
+		t.Fatalf("GetBlockInfo: %+v", err)
+	}
+	if len(mergingBlockInfo.MergeSetReds) == 0 {
+		t.Fatalf("expected the merging block to have red blocks in its merge set")
+	}
+
+	graph := getJSONGraph(appmessage.NewGetDAGGraphRequestMessage(rootHash.String(), "", 0, ""))
+	if graph.LowHash != rootHash.String() || graph.HighHash != mergingHash.String() {
+		t.Fatalf("expected the graph to be between %s and %s, got %s and %s",
+			rootHash, mergingHash, graph.LowHash, graph.HighHash)
+	}
+	if len(graph.Blocks) != len(splitHashes)+2 {
+		t.Fatalf("expected %d blocks, got %d", len(splitHashes)+2, len(graph.Blocks))
+	}
+	expectedColors := map[string]string{
+		rootHash.String():    rpccontext.DAGGraphColorBlue,
+		mergingHash.String(): rpccontext.DAGGraphColorBlue,
+	}
+	for _, blueHash := range mergingBlockInfo.MergeSetBlues {
+		expectedColors[blueHash.String()] = rpccontext.DAGGraphColorBlue
+	}
+	for _, redHash := range mergingBlockInfo.MergeSetReds {
+		expectedColors[redHash.String()] = rpccontext.DAGGraphColorRed
+	}
+	for _, block := range graph.Blocks {
+		if block.Color != expectedColors[block.Hash] {
+			t.Errorf("expected block %s to be %s, got %s", block.Hash, expectedColors[block.Hash], block.Color)
+		}
+		isChainBlock := block.Hash == rootHash.String() || block.Hash == mergingHash.String() ||
+			block.Hash == mergingBlockInfo.SelectedParent.String()
+		if block.IsChainBlock != isChainBlock {
+			t.Errorf("expected block %s to have isChainBlock %t", block.Hash, isChainBlock)
+		}
+	}
+	mergingBlock := graph.Blocks[len(graph.Blocks)-1]
+	if mergingBlock.Hash != mergingHash.String() || len(mergingBlock.Parents) != len(splitHashes) ||
+		mergingBlock.SelectedParent != mergingBlockInfo.SelectedParent.String() ||
+		mergingBlock.BlueScore != mergingBlockInfo.BlueScore {
+		t.Fatalf("unexpected GHOSTDAG data for the merging block: %+v", mergingBlock)
+	}
+
+	// Without a low hash, the graph covers the last blocks below the high hash
+	graph = getJSONGraph(appmessage.NewGetDAGGraphRequestMessage("", mergingHash.String(), 3, ""))
+	if graph.LowHash != mergingBlockInfo.SelectedParent.String() || graph.HighHash != mergingHash.String() {
+		t.Fatalf("expected the graph to be between %s and %s, got %s and %s",
+			mergingBlockInfo.SelectedParent, mergingHash, graph.LowHash, graph.HighHash)
+	}
+	graph = getJSONGraph(appmessage.NewGetDAGGraphRequestMessage("", "", 1000, ""))
+	if graph.LowHash != consensusConfig.GenesisHash.String() || len(graph.Blocks) != len(splitHashes)+3 {
+		t.Fatalf("expected the graph to cover the whole DAG, got %d blocks from %s", len(graph.Blocks), graph.LowHash)
+	}
+
+	response := getDAGGraph(appmessage.NewGetDAGGraphRequestMessage(rootHash.String(), "", 0, "dot"))
+	if response.Error != nil {
+		t.Fatalf("HandleGetDAGGraph: %s", response.Error.Message)
+	}
+	selectedParentEdge := fmt.Sprintf("\"%s\" -> \"%s\" [penwidth = 3];", mergingHash, mergingBlockInfo.SelectedParent)
+	if !strings.HasPrefix(response.Graph, "digraph") || !strings.Contains(response.Graph, selectedParentEdge) {
+		t.Fatalf("unexpected DOT graph:\n%s", response.Graph)
+	}
+
+	for _, request := range []*appmessage.GetDAGGraphRequestMessage{
+		appmessage.NewGetDAGGraphRequestMessage("", "", 0, "svg"),
+		appmessage.NewGetDAGGraphRequestMessage("", "", 10001, ""),
+		appmessage.NewGetDAGGraphRequestMessage("invalid", "", 0, ""),
+		appmessage.NewGetDAGGraphRequestMessage(mergingHash.String(), rootHash.String(), 0, ""),
+	} {
+		if getDAGGraph(request).Error == nil {
+			t.Errorf("expected an error for request %+v", request)
+		}
+	}
+}
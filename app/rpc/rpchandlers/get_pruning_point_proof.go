@@ -0,0 +1,26 @@
+package rpchandlers
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/rpc/rpccontext"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
+)
+
+// HandleGetPruningPointProof handles the respectively named RPC command
+func HandleGetPruningPointProof(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	pruningPointProof, err := context.Domain.Consensus().BuildPruningPointProof()
+	if err != nil {
+		return nil, err
+	}
+	if len(pruningPointProof.Headers) == 0 || len(pruningPointProof.Headers[0]) == 0 {
+		errorMessage := &appmessage.GetPruningPointProofResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("The pruning point proof of the node is empty")
+		return errorMessage, nil
+	}
+
+	level0Headers := pruningPointProof.Headers[0]
+	pruningPointHash := consensushashing.HeaderHash(level0Headers[len(level0Headers)-1])
+	return appmessage.NewGetPruningPointProofResponseMessage(pruningPointHash.String(),
+		appmessage.DomainPruningPointProofToRPCPruningPointProofLevels(pruningPointProof)), nil
+}
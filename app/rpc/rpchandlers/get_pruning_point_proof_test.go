@@ -0,0 +1,157 @@
+package rpchandlers_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/rpc/rpccontext"
+	"github.com/ammm56/lings/app/rpc/rpchandlers"
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/ruleerrors"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestHandleGetPruningPointProof(t *testing.T) {
+	consensusConfig := &consensus.Config{Params: dagconfig.MainnetParams}
+	consensusConfig.SkipProofOfWork = true
+
+	// This is done to reduce the pruning depth to 6 blocks
+	finalityDepth := 5
+	consensusConfig.FinalityDuration = time.Duration(finalityDepth) * consensusConfig.TargetTimePerBlock
+	consensusConfig.K = 0
+	consensusConfig.PruningProofM = 1
+
+	factory := consensus.NewFactory()
+	tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestHandleGetPruningPointProof")
+	if err != nil {
+		t.Fatalf("Error setting up consensus: %+v", err)
+	}
+	defer teardown(false)
+
+	tipHash := consensusConfig.GenesisHash
+	for i := 0; i < 20; i++ {
+		tipHash, _, err = tc.AddBlock([]*externalapi.DomainHash{tipHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+	}
+	pruningPoint, err := tc.PruningPoint()
+	if err != nil {
+		t.Fatalf("PruningPoint: %+v", err)
+	}
+	if pruningPoint.Equal(consensusConfig.GenesisHash) {
+		t.Fatalf("The pruning point didn't move")
+	}
+
+	fakeContext := rpccontext.Context{Domain: fakeDomain{tc}}
+	response, err := rpchandlers.HandleGetPruningPointProof(&fakeContext, nil, appmessage.NewGetPruningPointProofRequestMessage())
+	if err != nil {
+		t.Fatalf("HandleGetPruningPointProof: %+v", err)
+	}
+	getPruningPointProofResponse := response.(*appmessage.GetPruningPointProofResponseMessage)
+	if getPruningPointProofResponse.Error != nil {
+		t.Fatalf("HandleGetPruningPointProof: %s", getPruningPointProofResponse.Error.Message)
+	}
+	if getPruningPointProofResponse.PruningPointHash != pruningPoint.String() {
+		t.Fatalf("Expected the proof of pruning point %s, got %s", pruningPoint,
+			getPruningPointProofResponse.PruningPointHash)
+	}
+
+	// Pass the response through JSON, the way lingsctl saves it to a file
+	responseMessage, err := protowire.FromAppMessage(getPruningPointProofResponse)
+	if err != nil {
+		t.Fatalf("FromAppMessage: %+v", err)
+	}
+	responseJSON, err := protojson.Marshal(responseMessage)
+	if err != nil {
+		t.Fatalf("Marshal: %+v", err)
+	}
+	parsedResponseMessage := &protowire.LingsMessage{}
+	err = protojson.Unmarshal(responseJSON, parsedResponseMessage)
+	if err != nil {
+		t.Fatalf("Unmarshal: %+v", err)
+	}
+	parsedResponse, err := parsedResponseMessage.ToAppMessage()
+	if err != nil {
+		t.Fatalf("ToAppMessage: %+v", err)
+	}
+	pruningPointProof, err := appmessage.RPCPruningPointProofLevelsToDomainPruningPointProof(
+		parsedResponse.(*appmessage.GetPruningPointProofResponseMessage).Levels)
+	if err != nil {
+		t.Fatalf("RPCPruningPointProofLevelsToDomainPruningPointProof: %+v", err)
+	}
+
+	expectedPruningPointProof, err := tc.BuildPruningPointProof()
+	if err != nil {
+		t.Fatalf("BuildPruningPointProof: %+v", err)
+	}
+	if len(pruningPointProof.Headers) != len(expectedPruningPointProof.Headers) {
+		t.Fatalf("Expected %d levels, got %d", len(expectedPruningPointProof.Headers), len(pruningPointProof.Headers))
+	}
+	for blockLevel, headers := range expectedPruningPointProof.Headers {
+		if len(pruningPointProof.Headers[blockLevel]) != len(headers) {
+			t.Fatalf("Expected %d headers at level %d, got %d", len(headers), blockLevel,
+				len(pruningPointProof.Headers[blockLevel]))
+		}
+		for i, header := range headers {
+			if !pruningPointProof.Headers[blockLevel][i].Equal(header) {
+				t.Fatalf("Header %d at level %d changed on the way", i, blockLevel)
+			}
+		}
+	}
+
+	// A new node accepts the proof, and reports every level of it
+	validateWithNewConsensus := func(pruningPointProof *externalapi.PruningPointProof) (
+		*externalapi.PruningPointProofReport, error) {
+
+		tcSyncee, teardownSyncee, err := factory.NewTestConsensus(consensusConfig, "TestHandleGetPruningPointProofSyncee")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardownSyncee(false)
+
+		return tcSyncee.ValidatePruningPointProofWithReport(pruningPointProof)
+	}
+
+	report, err := validateWithNewConsensus(pruningPointProof)
+	if err != nil {
+		t.Fatalf("ValidatePruningPointProofWithReport: %+v\n%s", err, report)
+	}
+	if !report.PruningPoint.Equal(pruningPoint) {
+		t.Fatalf("Expected the report of pruning point %s, got %s", pruningPoint, report.PruningPoint)
+	}
+	if len(report.Levels) == 0 || len(report.Levels) > len(pruningPointProof.Headers) {
+		t.Fatalf("Unexpected number of levels in the report:\n%s", report)
+	}
+	level0Report := report.Levels[len(report.Levels)-1]
+	if level0Report.Level != 0 || level0Report.HeaderCount != len(pruningPointProof.Headers[0]) ||
+		!level0Report.SelectedTip.Equal(pruningPoint) || level0Report.BlueWork.Sign() <= 0 {
+
+		t.Fatalf("Unexpected report of level 0:\n%s", report)
+	}
+
+	// A proof whose level 0 misses the headers below the pruning point is
+	// rejected with a rule error
+	level0Headers := pruningPointProof.Headers[0]
+	if len(level0Headers) < 3 {
+		t.Fatalf("Expected at least 3 headers at level 0, got %d", len(level0Headers))
+	}
+	truncatedPruningPointProof := &externalapi.PruningPointProof{
+		Headers: append([][]externalapi.BlockHeader{
+			{level0Headers[0], level0Headers[len(level0Headers)-1]},
+		}, pruningPointProof.Headers[1:]...),
+	}
+	report, err = validateWithNewConsensus(truncatedPruningPointProof)
+	if !errors.As(err, &ruleerrors.RuleError{}) {
+		t.Fatalf("Expected a rule error, got: %+v", err)
+	}
+	if !report.PruningPoint.Equal(consensushashing.HeaderHash(level0Headers[len(level0Headers)-1])) {
+		t.Fatalf("Expected the report of pruning point %s, got %s", pruningPoint, report.PruningPoint)
+	}
+}
@@ -0,0 +1,45 @@
+package rpchandlers
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/rpc/rpccontext"
+	"github.com/ammm56/lings/infrastructure/db/database"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
+)
+
+// HandleSetDatabaseOptions handles the respectively named RPC command
+func HandleSetDatabaseOptions(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if context.Config.SafeRPC {
+		log.Warn("SetDatabaseOptions RPC command called while node in safe RPC mode -- ignoring.")
+		errorMessage := &appmessage.SetDatabaseOptionsResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("SetDatabaseOptions RPC command called while node in safe RPC mode")
+		return errorMessage, nil
+	}
+
+	setDatabaseOptionsRequest := request.(*appmessage.SetDatabaseOptionsRequestMessage)
+
+	// --durable-writes takes priority over the RPC, so that a client can't
+	// turn off the guarantee that the node operator asked for
+	if !setDatabaseOptionsRequest.SyncWrites && context.Config.DurableWrites {
+		errorMessage := &appmessage.SetDatabaseOptionsResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Database write sync can't be disabled while the node " +
+			"runs with --durable-writes")
+		return errorMessage, nil
+	}
+
+	writeSyncer, ok := context.Database.(database.WriteSyncer)
+	if !ok {
+		errorMessage := &appmessage.SetDatabaseOptionsResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("The database does not support synced writes")
+		return errorMessage, nil
+	}
+	err := writeSyncer.SetSyncWrites(setDatabaseOptionsRequest.SyncWrites)
+	if err != nil {
+		errorMessage := &appmessage.SetDatabaseOptionsResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not set the database write sync mode: %s", err)
+		return errorMessage, nil
+	}
+	log.Infof("Database write sync set to %t", setDatabaseOptionsRequest.SyncWrites)
+
+	return appmessage.NewSetDatabaseOptionsResponseMessage(), nil
+}
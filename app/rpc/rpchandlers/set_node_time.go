@@ -0,0 +1,57 @@
+package rpchandlers
+
+import (
+	"time"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/rpc/rpccontext"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
+	"github.com/ammm56/lings/util/mstime"
+)
+
+// HandleSetNodeTime handles the respectively named RPC command
+func HandleSetNodeTime(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	setNodeTimeRequest := request.(*appmessage.SetNodeTimeRequestMessage)
+
+	params := context.Config.NetParams()
+	if !params.AllowsTimeControl() {
+		errorMessage := &appmessage.SetNodeTimeResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Setting the node time is not allowed on %s", params.Name)
+		return errorMessage, nil
+	}
+
+	changeCount := 0
+	if setNodeTimeRequest.TimeInMilliseconds != 0 {
+		changeCount++
+	}
+	if setNodeTimeRequest.AdvanceMilliseconds != 0 {
+		changeCount++
+	}
+	if setNodeTimeRequest.Reset {
+		changeCount++
+	}
+	if changeCount > 1 {
+		errorMessage := &appmessage.SetNodeTimeResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Only one of timeInMilliseconds, advanceMilliseconds and reset may be set")
+		return errorMessage, nil
+	}
+
+	switch {
+	case setNodeTimeRequest.TimeInMilliseconds != 0:
+		context.Clock.Set(mstime.UnixMilliseconds(setNodeTimeRequest.TimeInMilliseconds))
+	case setNodeTimeRequest.AdvanceMilliseconds != 0:
+		context.Clock.Advance(time.Duration(setNodeTimeRequest.AdvanceMilliseconds) * time.Millisecond)
+	case setNodeTimeRequest.Reset:
+		context.Clock.Reset()
+	}
+
+	if changeCount > 0 {
+		log.Infof("Node time set to %s (offset %s)", context.Clock.Now(), context.Clock.Offset())
+
+		// The cached block template carries a timestamp taken from the old time
+		context.Domain.MiningManager().ClearBlockTemplate()
+	}
+
+	return appmessage.NewSetNodeTimeResponseMessage(
+		context.Clock.Now().UnixMilliseconds(), context.Clock.Offset().Milliseconds()), nil
+}
@@ -0,0 +1,372 @@
+package rpchandlers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/protocol"
+	"github.com/ammm56/lings/app/rpc/rpccontext"
+	"github.com/ammm56/lings/app/rpc/rpchandlers"
+	"github.com/ammm56/lings/domain"
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/constants"
+	"github.com/ammm56/lings/domain/consensus/utils/testutils"
+	"github.com/ammm56/lings/domain/consensus/utils/txscript"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/domain/miningmanager/mempool"
+	"github.com/ammm56/lings/infrastructure/config"
+	"github.com/ammm56/lings/infrastructure/db/database/ldb"
+	"github.com/ammm56/lings/infrastructure/network/addressmanager"
+	"github.com/ammm56/lings/infrastructure/network/connmanager"
+	"github.com/ammm56/lings/infrastructure/network/netadapter"
+	"github.com/ammm56/lings/util"
+	"github.com/ammm56/lings/util/mstime"
+)
+
+// newTestNodeContext sets up a node with the given network parameters that
+// doesn't listen on any address, and returns an RPC context over it. The
+// node's time is controlled by the context's clock.
+func newTestNodeContext(t *testing.T, params *dagconfig.Params) *rpccontext.Context {
+	cfg := config.DefaultConfig()
+	cfg.ActiveNetParams = params
+	cfg.AppDir = t.TempDir()
+	cfg.Listeners = nil
+	cfg.RPCListeners = nil
+	cfg.DisableDNSSeed = true
+
+	db, err := ldb.NewLevelDB(t.TempDir(), 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	clock := mstime.NewAdjustableClock()
+	consensusConfig := &consensus.Config{Params: *params, Clock: clock}
+	mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+	mempoolConfig.Clock = clock
+	domainInstance, err := domain.New(consensusConfig, mempoolConfig, db)
+	if err != nil {
+		t.Fatalf("New: %+v", err)
+	}
+
+	netAdapter, err := netadapter.NewNetAdapter(cfg)
+	if err != nil {
+		t.Fatalf("NewNetAdapter: %+v", err)
+	}
+	addressManager, err := addressmanager.New(addressmanager.NewConfig(cfg), db)
+	if err != nil {
+		t.Fatalf("addressmanager.New: %+v", err)
+	}
+	connectionManager, err := connmanager.New(cfg, netAdapter, addressManager)
+	if err != nil {
+		t.Fatalf("connmanager.New: %+v", err)
+	}
+	protocolManager, err := protocol.NewManager(cfg, domainInstance, netAdapter, addressManager, connectionManager)
+	if err != nil {
+		t.Fatalf("NewManager: %+v", err)
+	}
+	t.Cleanup(protocolManager.Close)
+
+	return rpccontext.NewContext(cfg, domainInstance, netAdapter, protocolManager, connectionManager, addressManager,
+		nil, db, clock, nil)
+}
+
+// simnetParams returns the simnet parameters with the genesis block of mainnet,
+// since the simnet genesis block fails the block version check. Proof of work
+// is skipped, since the difficulty starts at the one of the genesis block.
+func simnetParams() *dagconfig.Params {
+	params := dagconfig.SimnetParams
+	params.GenesisBlock = dagconfig.MainnetParams.GenesisBlock
+	params.GenesisHash = dagconfig.MainnetParams.GenesisHash
+	params.SkipProofOfWork = true
+	return &params
+}
+
+// opTrueAddress returns the address of the pay-to-script-hash of OpTrue, whose
+// outputs are spendable without a signature
+func opTrueAddress(t *testing.T, params *dagconfig.Params) string {
+	_, redeemScript := testutils.OpTrueScript()
+	address, err := util.NewAddressScriptHash(redeemScript, params.Prefix)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHash: %+v", err)
+	}
+	return address.String()
+}
+
+func generateBlocks(t *testing.T, context *rpccontext.Context,
+	request *appmessage.GenerateBlocksRequestMessage) *appmessage.GenerateBlocksResponseMessage {
+
+	response, err := rpchandlers.HandleGenerateBlocks(context, nil, request)
+	if err != nil {
+		t.Fatalf("HandleGenerateBlocks: %+v", err)
+	}
+	return response.(*appmessage.GenerateBlocksResponseMessage)
+}
+
+func setNodeTime(t *testing.T, context *rpccontext.Context,
+	request *appmessage.SetNodeTimeRequestMessage) *appmessage.SetNodeTimeResponseMessage {
+
+	response, err := rpchandlers.HandleSetNodeTime(context, nil, request)
+	if err != nil {
+		t.Fatalf("HandleSetNodeTime: %+v", err)
+	}
+	return response.(*appmessage.SetNodeTimeResponseMessage)
+}
+
+func submitTransaction(t *testing.T, context *rpccontext.Context,
+	transaction *externalapi.DomainTransaction) *appmessage.SubmitTransactionResponseMessage {
+
+	request := appmessage.NewSubmitTransactionRequestMessage(appmessage.DomainTransactionToRPCTransaction(transaction), false)
+	response, err := rpchandlers.HandleSubmitTransaction(context, nil, request)
+	if err != nil {
+		t.Fatalf("HandleSubmitTransaction: %+v", err)
+	}
+	return response.(*appmessage.SubmitTransactionResponseMessage)
+}
+
+func TestHandleSetNodeTime(t *testing.T) {
+	mainnetContext := &rpccontext.Context{
+		Config: &config.Config{Flags: &config.Flags{NetworkFlags: config.NetworkFlags{ActiveNetParams: &dagconfig.MainnetParams}}},
+		Clock:  mstime.NewAdjustableClock(),
+	}
+	response := setNodeTime(t, mainnetContext, &appmessage.SetNodeTimeRequestMessage{AdvanceMilliseconds: 1000})
+	if response.Error == nil {
+		t.Fatalf("expected setting the node time to be rejected on mainnet")
+	}
+	if mainnetContext.Clock.Offset() != 0 {
+		t.Fatalf("expected the clock to stay unchanged, but its offset is %s", mainnetContext.Clock.Offset())
+	}
+
+	params := simnetParams()
+	context := newTestNodeContext(t, params)
+
+	response = setNodeTime(t, context, &appmessage.SetNodeTimeRequestMessage{})
+	if response.Error != nil {
+		t.Fatalf("HandleSetNodeTime: %s", response.Error.Message)
+	}
+	if response.OffsetInMilliseconds != 0 {
+		t.Fatalf("expected no offset before the node time is changed, got %d", response.OffsetInMilliseconds)
+	}
+
+	const advance = 24 * time.Hour
+	response = setNodeTime(t, context, &appmessage.SetNodeTimeRequestMessage{AdvanceMilliseconds: advance.Milliseconds()})
+	if response.Error != nil {
+		t.Fatalf("HandleSetNodeTime: %s", response.Error.Message)
+	}
+	if response.OffsetInMilliseconds != advance.Milliseconds() {
+		t.Fatalf("expected an offset of %d, got %d", advance.Milliseconds(), response.OffsetInMilliseconds)
+	}
+
+	// Blocks generated after the change are timestamped with the new time, and
+	// are accepted although they're far ahead of the local time
+	generateResponse := generateBlocks(t, context, &appmessage.GenerateBlocksRequestMessage{
+		Count:      1,
+		PayAddress: opTrueAddress(t, params),
+	})
+	if generateResponse.Error != nil {
+		t.Fatalf("HandleGenerateBlocks: %s", generateResponse.Error.Message)
+	}
+	blockHash, err := externalapi.NewDomainHashFromString(generateResponse.BlockHashes[0])
+	if err != nil {
+		t.Fatalf("NewDomainHashFromString: %+v", err)
+	}
+	blockHeader, err := context.Domain.Consensus().GetBlockHeader(blockHash)
+	if err != nil {
+		t.Fatalf("GetBlockHeader: %+v", err)
+	}
+	if blockHeader.TimeInMilliseconds() < response.TimeInMilliseconds {
+		t.Fatalf("expected the block timestamp to be at least %d, got %d",
+			response.TimeInMilliseconds, blockHeader.TimeInMilliseconds())
+	}
+
+	response = setNodeTime(t, context, &appmessage.SetNodeTimeRequestMessage{Reset: true})
+	if response.Error != nil {
+		t.Fatalf("HandleSetNodeTime: %s", response.Error.Message)
+	}
+	if response.OffsetInMilliseconds != 0 {
+		t.Fatalf("expected no offset after a reset, got %d", response.OffsetInMilliseconds)
+	}
+
+	response = setNodeTime(t, context, &appmessage.SetNodeTimeRequestMessage{
+		TimeInMilliseconds:  response.TimeInMilliseconds,
+		AdvanceMilliseconds: advance.Milliseconds(),
+	})
+	if response.Error == nil {
+		t.Fatalf("expected an error when both setting and advancing the node time")
+	}
+	if context.Clock.Offset() != 0 {
+		t.Fatalf("expected the clock to stay unchanged, but its offset is %s", context.Clock.Offset())
+	}
+}
+
+// TestHandleSetNodeTimeCheckLockTimeVerify reproduces TestCheckLockTimeVerifyConditionedByAbsoluteTime
+// over RPC: an output locked by CLTV until an absolute time becomes spendable once the node time
+// is advanced past it, without waiting in real time.
+func TestHandleSetNodeTimeCheckLockTimeVerify(t *testing.T) {
+	params := simnetParams()
+	params.BlockCoinbaseMaturity = 0
+	context := newTestNodeContext(t, params)
+	payAddress := opTrueAddress(t, params)
+
+	generateResponse := generateBlocks(t, context, &appmessage.GenerateBlocksRequestMessage{Count: 2, PayAddress: payAddress})
+	if generateResponse.Error != nil {
+		t.Fatalf("HandleGenerateBlocks: %s", generateResponse.Error.Message)
+	}
+	spendableUTXO := findOpTrueUTXO(t, context.Domain.Consensus())
+
+	nodeTime := setNodeTime(t, context, &appmessage.SetNodeTimeRequestMessage{})
+	lockTimeTarget := uint64(nodeTime.TimeInMilliseconds + time.Hour.Milliseconds())
+	redeemScriptCLTV, err := createScriptCLTV(lockTimeTarget)
+	if err != nil {
+		t.Fatalf("createScriptCLTV: %+v", err)
+	}
+
+	lockingTransaction := createLockingTransaction(t, spendableUTXO, redeemScriptCLTV)
+	submitResponse := submitTransaction(t, context, lockingTransaction)
+	if submitResponse.Error != nil {
+		t.Fatalf("HandleSubmitTransaction: %s", submitResponse.Error.Message)
+	}
+	generateResponse = generateBlocks(t, context, &appmessage.GenerateBlocksRequestMessage{Count: 1, PayAddress: payAddress})
+	if generateResponse.Error != nil {
+		t.Fatalf("HandleGenerateBlocks: %s", generateResponse.Error.Message)
+	}
+
+	spendingTransaction := createSpendingTransaction(t, lockingTransaction, redeemScriptCLTV, lockTimeTarget)
+	submitResponse = submitTransaction(t, context, spendingTransaction)
+	if submitResponse.Error == nil || !strings.Contains(submitResponse.Error.Message, "ErrUnfinalizedTx") {
+		t.Fatalf("expected the spending transaction to be rejected with ErrUnfinalizedTx, got: %v", submitResponse.Error)
+	}
+
+	// Move past the lock time, and generate enough blocks for the past median
+	// time to follow
+	nodeTime = setNodeTime(t, context, &appmessage.SetNodeTimeRequestMessage{AdvanceMilliseconds: 2 * time.Hour.Milliseconds()})
+	if nodeTime.Error != nil {
+		t.Fatalf("HandleSetNodeTime: %s", nodeTime.Error.Message)
+	}
+	generateResponse = generateBlocks(t, context, &appmessage.GenerateBlocksRequestMessage{
+		Count:      uint32(params.TimestampDeviationTolerance),
+		PayAddress: payAddress,
+	})
+	if generateResponse.Error != nil {
+		t.Fatalf("HandleGenerateBlocks: %s", generateResponse.Error.Message)
+	}
+
+	submitResponse = submitTransaction(t, context, spendingTransaction)
+	if submitResponse.Error != nil {
+		t.Fatalf("expected the spending transaction to be accepted once the lock time passed, got: %s",
+			submitResponse.Error.Message)
+	}
+	generateResponse = generateBlocks(t, context, &appmessage.GenerateBlocksRequestMessage{Count: 1, PayAddress: payAddress})
+	if generateResponse.Error != nil {
+		t.Fatalf("HandleGenerateBlocks: %s", generateResponse.Error.Message)
+	}
+	blockHash, err := externalapi.NewDomainHashFromString(generateResponse.BlockHashes[0])
+	if err != nil {
+		t.Fatalf("NewDomainHashFromString: %+v", err)
+	}
+	block, _, err := context.Domain.Consensus().GetBlock(blockHash)
+	if err != nil {
+		t.Fatalf("GetBlock: %+v", err)
+	}
+	spendingTransactionID := consensushashing.TransactionID(spendingTransaction)
+	isIncluded := false
+	for _, transaction := range block.Transactions {
+		if consensushashing.TransactionID(transaction).Equal(spendingTransactionID) {
+			isIncluded = true
+			break
+		}
+	}
+	if !isIncluded {
+		t.Fatalf("expected the spending transaction to be included in block %s", blockHash)
+	}
+}
+
+// findOpTrueUTXO returns a UTXO of the virtual that pays the OpTrue address
+func findOpTrueUTXO(t *testing.T, consensus externalapi.Consensus) *externalapi.OutpointAndUTXOEntryPair {
+	virtualInfo, err := consensus.GetVirtualInfo()
+	if err != nil {
+		t.Fatalf("GetVirtualInfo: %+v", err)
+	}
+	utxos, err := consensus.GetVirtualUTXOs(virtualInfo.ParentHashes, nil, 100)
+	if err != nil {
+		t.Fatalf("GetVirtualUTXOs: %+v", err)
+	}
+	opTrueScriptPublicKey, _ := testutils.OpTrueScript()
+	for _, utxo := range utxos {
+		if utxo.UTXOEntry.ScriptPublicKey().Equal(opTrueScriptPublicKey) && utxo.UTXOEntry.Amount() > 0 {
+			return utxo
+		}
+	}
+	t.Fatalf("expected the generated blocks to pay the OpTrue address")
+	return nil
+}
+
+func createScriptCLTV(absoluteTimeOrDAAScoreTarget uint64) ([]byte, error) {
+	scriptBuilder := txscript.NewScriptBuilder()
+	scriptBuilder.AddLockTimeNumber(absoluteTimeOrDAAScoreTarget)
+	scriptBuilder.AddOp(txscript.OpCheckLockTimeVerify)
+	scriptBuilder.AddOp(txscript.OpTrue)
+	return scriptBuilder.Script()
+}
+
+// createLockingTransaction spends the given OpTrue UTXO to an output that is
+// locked by redeemScript
+func createLockingTransaction(t *testing.T, utxo *externalapi.OutpointAndUTXOEntryPair,
+	redeemScript []byte) *externalapi.DomainTransaction {
+
+	_, opTrueRedeemScript := testutils.OpTrueScript()
+	signatureScript, err := txscript.PayToScriptHashSignatureScript(opTrueRedeemScript, nil)
+	if err != nil {
+		t.Fatalf("PayToScriptHashSignatureScript: %+v", err)
+	}
+	lockingScript, err := txscript.PayToScriptHashScript(redeemScript)
+	if err != nil {
+		t.Fatalf("PayToScriptHashScript: %+v", err)
+	}
+	return &externalapi.DomainTransaction{
+		Version: constants.MaxTransactionVersion,
+		Inputs: []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: *utxo.Outpoint,
+			SignatureScript:  signatureScript,
+			Sequence:         constants.MaxTxInSequenceNum,
+		}},
+		Outputs: []*externalapi.DomainTransactionOutput{{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{Script: lockingScript, Version: constants.MaxScriptPublicKeyVersion},
+			Value:           utxo.UTXOEntry.Amount() - 10_000,
+		}},
+		Payload: []byte{},
+	}
+}
+
+// createSpendingTransaction spends the locked output of lockingTransaction
+// with the given lock time
+func createSpendingTransaction(t *testing.T, lockingTransaction *externalapi.DomainTransaction, redeemScript []byte,
+	lockTime uint64) *externalapi.DomainTransaction {
+
+	signatureScript, err := txscript.PayToScriptHashSignatureScript(redeemScript, []byte{})
+	if err != nil {
+		t.Fatalf("PayToScriptHashSignatureScript: %+v", err)
+	}
+	scriptPublicKey, _ := testutils.OpTrueScript()
+	return &externalapi.DomainTransaction{
+		Version: constants.MaxTransactionVersion,
+		Inputs: []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: externalapi.DomainOutpoint{
+				TransactionID: *consensushashing.TransactionID(lockingTransaction),
+				Index:         0,
+			},
+			SignatureScript: signatureScript,
+			Sequence:        constants.MaxTxInSequenceNum - 1,
+		}},
+		Outputs: []*externalapi.DomainTransactionOutput{{
+			ScriptPublicKey: scriptPublicKey,
+			Value:           lockingTransaction.Outputs[0].Value - 10_000,
+		}},
+		Payload:  []byte{},
+		LockTime: lockTime,
+	}
+}
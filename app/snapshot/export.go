@@ -0,0 +1,233 @@
+package snapshot
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/messagefile"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/infrastructure/logger"
+	"github.com/pkg/errors"
+)
+
+// Export writes a snapshot of the current pruning point state of the given
+// consensus to the given path
+func Export(consensus externalapi.Consensus, params *dagconfig.Params, path string) error {
+	onEnd := logger.LogAndMeasureExecutionTime(log, "snapshot.Export")
+	defer onEnd()
+
+	writer, err := messagefile.Create(path, &messagefile.Header{Kind: fileKind, Network: params.Name})
+	if err != nil {
+		return err
+	}
+
+	err = exportToWriter(consensus, params, writer)
+	if err != nil {
+		writer.Abort()
+		return err
+	}
+	return writer.Close()
+}
+
+func exportToWriter(consensus externalapi.Consensus, params *dagconfig.Params, writer *messagefile.Writer) error {
+	pruningPoint, err := consensus.PruningPoint()
+	if err != nil {
+		return err
+	}
+	if pruningPoint.Equal(params.GenesisHash) {
+		return errors.Errorf("cannot export a snapshot while the pruning point is the genesis")
+	}
+	log.Infof("Exporting a snapshot of pruning point %s", pruningPoint)
+
+	pruningPointProof, err := consensus.BuildPruningPointProof()
+	if err != nil {
+		return err
+	}
+	err = writer.Write(appmessage.DomainPruningPointProofToMsgPruningPointProof(pruningPointProof))
+	if err != nil {
+		return err
+	}
+
+	pruningPointHeaders, err := consensus.PruningPointHeaders()
+	if err != nil {
+		return err
+	}
+	msgPruningPointHeaders := make([]*appmessage.MsgBlockHeader, len(pruningPointHeaders))
+	for i, header := range pruningPointHeaders {
+		msgPruningPointHeaders[i] = appmessage.DomainBlockHeaderToBlockHeader(header)
+	}
+	err = writer.Write(appmessage.NewMsgPruningPoints(msgPruningPointHeaders))
+	if err != nil {
+		return err
+	}
+
+	err = exportPruningPointAndItsAnticone(consensus, params, writer)
+	if err != nil {
+		return err
+	}
+
+	err = exportPruningPointFutureHeaders(consensus, pruningPoint, writer)
+	if err != nil {
+		return err
+	}
+
+	return exportPruningPointUTXOSet(consensus, pruningPoint, writer)
+}
+
+func exportPruningPointAndItsAnticone(consensus externalapi.Consensus, params *dagconfig.Params,
+	writer *messagefile.Writer) error {
+
+	pointAndItsAnticone, err := consensus.PruningPointAndItsAnticone()
+	if err != nil {
+		return err
+	}
+
+	windowSize := params.DifficultyAdjustmentWindowSize
+	daaWindowBlocks := make([]*externalapi.TrustedDataDataDAAHeader, 0, windowSize)
+	daaWindowHashesToIndex := make(map[externalapi.DomainHash]int, windowSize)
+	trustedDataDAABlockIndexes := make(map[externalapi.DomainHash][]uint64)
+
+	ghostdagData := make([]*externalapi.BlockGHOSTDAGDataHashPair, 0)
+	ghostdagDataHashToIndex := make(map[externalapi.DomainHash]int)
+	trustedDataGHOSTDAGDataIndexes := make(map[externalapi.DomainHash][]uint64)
+	for _, blockHash := range pointAndItsAnticone {
+		blockDAAWindowHashes, err := consensus.BlockDAAWindowHashes(blockHash)
+		if err != nil {
+			return err
+		}
+
+		trustedDataDAABlockIndexes[*blockHash] = make([]uint64, 0, windowSize)
+		for i, daaBlockHash := range blockDAAWindowHashes {
+			index, exists := daaWindowHashesToIndex[*daaBlockHash]
+			if !exists {
+				trustedDataDataDAAHeader, err := consensus.TrustedDataDataDAAHeader(blockHash, daaBlockHash, uint64(i))
+				if err != nil {
+					return err
+				}
+				daaWindowBlocks = append(daaWindowBlocks, trustedDataDataDAAHeader)
+				index = len(daaWindowBlocks) - 1
+				daaWindowHashesToIndex[*daaBlockHash] = index
+			}
+
+			trustedDataDAABlockIndexes[*blockHash] = append(trustedDataDAABlockIndexes[*blockHash], uint64(index))
+		}
+
+		ghostdagDataBlockHashes, err := consensus.TrustedBlockAssociatedGHOSTDAGDataBlockHashes(blockHash)
+		if err != nil {
+			return err
+		}
+
+		trustedDataGHOSTDAGDataIndexes[*blockHash] = make([]uint64, 0, params.K)
+		for _, ghostdagDataBlockHash := range ghostdagDataBlockHashes {
+			index, exists := ghostdagDataHashToIndex[*ghostdagDataBlockHash]
+			if !exists {
+				data, err := consensus.TrustedGHOSTDAGData(ghostdagDataBlockHash)
+				if err != nil {
+					return err
+				}
+				ghostdagData = append(ghostdagData, &externalapi.BlockGHOSTDAGDataHashPair{
+					Hash:         ghostdagDataBlockHash,
+					GHOSTDAGData: data,
+				})
+				index = len(ghostdagData) - 1
+				ghostdagDataHashToIndex[*ghostdagDataBlockHash] = index
+			}
+
+			trustedDataGHOSTDAGDataIndexes[*blockHash] = append(trustedDataGHOSTDAGDataIndexes[*blockHash], uint64(index))
+		}
+	}
+
+	err = writer.Write(appmessage.DomainTrustedDataToTrustedData(daaWindowBlocks, ghostdagData))
+	if err != nil {
+		return err
+	}
+
+	for _, blockHash := range pointAndItsAnticone {
+		block, found, err := consensus.GetBlock(blockHash)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.Errorf("pruning point anticone block %s not found", blockHash)
+		}
+
+		err = writer.Write(appmessage.DomainBlockWithTrustedDataToBlockWithTrustedDataV4(
+			block, trustedDataDAABlockIndexes[*blockHash], trustedDataGHOSTDAGDataIndexes[*blockHash]))
+		if err != nil {
+			return err
+		}
+	}
+	log.Infof("Exported the pruning point and %d blocks in its anticone", len(pointAndItsAnticone)-1)
+
+	return writer.Write(appmessage.NewMsgDoneBlocksWithTrustedData())
+}
+
+func exportPruningPointFutureHeaders(consensus externalapi.Consensus, pruningPoint *externalapi.DomainHash,
+	writer *messagefile.Writer) error {
+
+	headersSelectedTip, err := consensus.GetHeadersSelectedTip()
+	if err != nil {
+		return err
+	}
+
+	headerCount := 0
+	lowHash := pruningPoint
+	for !lowHash.Equal(headersSelectedTip) {
+		// maxBlocks MUST be >= MergeSetSizeLimit + 1
+		const maxBlocks = 1 << 10
+		blockHashes, _, err := consensus.GetHashesBetween(lowHash, headersSelectedTip, maxBlocks)
+		if err != nil {
+			return err
+		}
+
+		blockHeaders := make([]*appmessage.MsgBlockHeader, len(blockHashes))
+		for i, blockHash := range blockHashes {
+			blockHeader, err := consensus.GetBlockHeader(blockHash)
+			if err != nil {
+				return err
+			}
+			blockHeaders[i] = appmessage.DomainBlockHeaderToBlockHeader(blockHeader)
+		}
+
+		err = writer.Write(appmessage.NewBlockHeadersMessage(blockHeaders))
+		if err != nil {
+			return err
+		}
+
+		headerCount += len(blockHashes)
+		lowHash = blockHashes[len(blockHashes)-1]
+	}
+	log.Infof("Exported %d headers above the pruning point", headerCount)
+
+	return writer.Write(appmessage.NewMsgDoneHeaders())
+}
+
+func exportPruningPointUTXOSet(consensus externalapi.Consensus, pruningPoint *externalapi.DomainHash,
+	writer *messagefile.Writer) error {
+
+	var fromOutpoint *externalapi.DomainOutpoint
+	utxoCount := 0
+	for {
+		pruningPointUTXOs, err := consensus.GetPruningPointUTXOs(pruningPoint, fromOutpoint, utxoChunkSize)
+		if err != nil {
+			return err
+		}
+
+		if len(pruningPointUTXOs) > 0 {
+			outpointAndUTXOEntryPairs :=
+				appmessage.DomainOutpointAndUTXOEntryPairsToOutpointAndUTXOEntryPairs(pruningPointUTXOs)
+			err = writer.Write(appmessage.NewMsgPruningPointUTXOSetChunk(outpointAndUTXOEntryPairs))
+			if err != nil {
+				return err
+			}
+			utxoCount += len(pruningPointUTXOs)
+			fromOutpoint = pruningPointUTXOs[len(pruningPointUTXOs)-1].Outpoint
+		}
+
+		if len(pruningPointUTXOs) < utxoChunkSize {
+			break
+		}
+	}
+	log.Infof("Exported %d UTXOs of the pruning point UTXO set", utxoCount)
+
+	return writer.Write(appmessage.NewMsgDonePruningPointUTXOSetChunks())
+}
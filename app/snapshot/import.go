@@ -0,0 +1,344 @@
+package snapshot
+
+import (
+	"io"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/app/messagefile"
+	"github.com/ammm56/lings/domain"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/ruleerrors"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/infrastructure/logger"
+	"github.com/pkg/errors"
+)
+
+// Import validates the snapshot at the given path and bootstraps the consensus
+// of the given domain from it. The snapshot is validated and inserted into a
+// staging consensus, which replaces the current consensus only if the whole
+// snapshot is valid.
+func Import(domain domain.Domain, params *dagconfig.Params, path string) error {
+	onEnd := logger.LogAndMeasureExecutionTime(log, "snapshot.Import")
+	defer onEnd()
+
+	reader, err := messagefile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	header := reader.Header()
+	if header.Kind != fileKind {
+		return errors.Errorf("%s is not a snapshot file (kind: %s)", path, header.Kind)
+	}
+	if header.Network != params.Name {
+		return errors.Errorf("snapshot %s belongs to network %s, but the node is running on %s",
+			path, header.Network, params.Name)
+	}
+
+	message, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	msgPruningPointProof, ok := message.(*appmessage.MsgPruningPointProof)
+	if !ok {
+		return unexpectedMessageError(message, appmessage.CmdPruningPointProof)
+	}
+	pruningPointProof := appmessage.MsgPruningPointProofToDomainPruningPointProof(msgPruningPointProof)
+	if len(pruningPointProof.Headers) == 0 || len(pruningPointProof.Headers[0]) == 0 {
+		return errors.Errorf("the snapshot pruning point proof is empty")
+	}
+	proofPruningPoint := consensushashing.HeaderHash(pruningPointProof.Headers[0][len(pruningPointProof.Headers[0])-1])
+
+	currentPruningPoint, err := domain.Consensus().PruningPoint()
+	if err != nil {
+		return err
+	}
+	if currentPruningPoint.Equal(proofPruningPoint) {
+		log.Infof("The pruning point of the snapshot %s is already the current pruning point. "+
+			"Skipping the import", proofPruningPoint)
+		return nil
+	}
+
+	log.Infof("Validating the pruning point proof of snapshot %s", path)
+	err = domain.Consensus().ValidatePruningPointProof(pruningPointProof)
+	if err != nil {
+		return errors.Wrapf(err, "the snapshot pruning point proof is invalid")
+	}
+
+	err = domain.InitStagingConsensusWithoutGenesis()
+	if err != nil {
+		return err
+	}
+
+	err = importIntoStagingConsensus(domain, params, reader, pruningPointProof, proofPruningPoint)
+	if err != nil {
+		log.Infof("Importing snapshot %s failed. Deleting the staging consensus", path)
+		deleteStagingConsensusErr := domain.DeleteStagingConsensus()
+		if deleteStagingConsensusErr != nil {
+			return deleteStagingConsensusErr
+		}
+		return err
+	}
+
+	log.Infof("Snapshot %s was imported successfully. Committing the staging consensus", path)
+	return domain.CommitStagingConsensus()
+}
+
+func importIntoStagingConsensus(domain domain.Domain, params *dagconfig.Params, reader *messagefile.Reader,
+	pruningPointProof *externalapi.PruningPointProof, proofPruningPoint *externalapi.DomainHash) error {
+
+	stagingConsensus := domain.StagingConsensus()
+	err := stagingConsensus.ApplyPruningPointProof(pruningPointProof)
+	if err != nil {
+		return err
+	}
+
+	err = importPruningPoints(domain, reader, proofPruningPoint)
+	if err != nil {
+		return err
+	}
+
+	if proofPruningPoint.Equal(params.GenesisHash) {
+		return errors.Errorf("the genesis pruning point violates finality")
+	}
+
+	err = importPruningPointAndItsAnticone(stagingConsensus, reader, proofPruningPoint)
+	if err != nil {
+		return err
+	}
+
+	err = importPruningPointFutureHeaders(stagingConsensus, reader)
+	if err != nil {
+		return err
+	}
+
+	isValid, err := stagingConsensus.IsValidPruningPoint(proofPruningPoint)
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return errors.Errorf("invalid pruning point %s", proofPruningPoint)
+	}
+
+	err = importPruningPointUTXOSet(stagingConsensus, reader, proofPruningPoint)
+	if err != nil {
+		return err
+	}
+
+	_, err = reader.Read()
+	if !errors.Is(err, io.EOF) {
+		if err != nil {
+			return err
+		}
+		return errors.Errorf("unexpected data after the end of the snapshot")
+	}
+	return nil
+}
+
+func importPruningPoints(domain domain.Domain, reader *messagefile.Reader,
+	proofPruningPoint *externalapi.DomainHash) error {
+
+	message, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	msgPruningPoints, ok := message.(*appmessage.MsgPruningPoints)
+	if !ok {
+		return unexpectedMessageError(message, appmessage.CmdPruningPoints)
+	}
+	if len(msgPruningPoints.Headers) == 0 {
+		return errors.Errorf("the snapshot doesn't contain any pruning points")
+	}
+
+	headers := make([]externalapi.BlockHeader, len(msgPruningPoints.Headers))
+	for i, header := range msgPruningPoints.Headers {
+		headers[i] = appmessage.BlockHeaderToDomainBlockHeader(header)
+	}
+
+	arePruningPointsViolatingFinality, err := domain.Consensus().ArePruningPointsViolatingFinality(headers)
+	if err != nil {
+		return err
+	}
+	if arePruningPointsViolatingFinality {
+		return errors.Errorf("the snapshot pruning points are violating finality")
+	}
+
+	lastPruningPoint := consensushashing.HeaderHash(headers[len(headers)-1])
+	if !lastPruningPoint.Equal(proofPruningPoint) {
+		return errors.Errorf("the proof pruning point is not equal to the last pruning point in the snapshot")
+	}
+
+	return domain.StagingConsensus().ImportPruningPoints(headers)
+}
+
+func importPruningPointAndItsAnticone(consensus externalapi.Consensus, reader *messagefile.Reader,
+	proofPruningPoint *externalapi.DomainHash) error {
+
+	message, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	msgTrustedData, ok := message.(*appmessage.MsgTrustedData)
+	if !ok {
+		return unexpectedMessageError(message, appmessage.CmdTrustedData)
+	}
+
+	blockCount := 0
+	for ; ; blockCount++ {
+		message, err := reader.Read()
+		if err != nil {
+			return err
+		}
+
+		switch message := message.(type) {
+		case *appmessage.MsgBlockWithTrustedDataV4:
+			if blockCount == 0 && !message.Block.Header.BlockHash().Equal(proofPruningPoint) {
+				return errors.Errorf("the first block with trusted data is not the pruning point")
+			}
+			err := insertBlockWithTrustedData(consensus, message, msgTrustedData)
+			if err != nil {
+				return err
+			}
+
+		case *appmessage.MsgDoneBlocksWithTrustedData:
+			if blockCount == 0 {
+				return errors.Errorf("the snapshot doesn't contain the pruning point block")
+			}
+			log.Infof("Imported the pruning point and %d blocks in its anticone", blockCount-1)
+			return nil
+
+		default:
+			return unexpectedMessageError(message, appmessage.CmdBlockWithTrustedDataV4,
+				appmessage.CmdDoneBlocksWithTrustedData)
+		}
+	}
+}
+
+func insertBlockWithTrustedData(consensus externalapi.Consensus,
+	block *appmessage.MsgBlockWithTrustedDataV4, data *appmessage.MsgTrustedData) error {
+
+	blockWithTrustedData := &externalapi.BlockWithTrustedData{
+		Block:        appmessage.MsgBlockToDomainBlock(block.Block),
+		DAAWindow:    make([]*externalapi.TrustedDataDataDAAHeader, 0, len(block.DAAWindowIndices)),
+		GHOSTDAGData: make([]*externalapi.BlockGHOSTDAGDataHashPair, 0, len(block.GHOSTDAGDataIndices)),
+	}
+
+	for _, index := range block.DAAWindowIndices {
+		if index >= uint64(len(data.DAAWindow)) {
+			return errors.Errorf("DAA window index %d is out of range", index)
+		}
+		blockWithTrustedData.DAAWindow = append(blockWithTrustedData.DAAWindow,
+			appmessage.TrustedDataDataDAABlockV4ToTrustedDataDataDAAHeader(data.DAAWindow[index]))
+	}
+
+	for _, index := range block.GHOSTDAGDataIndices {
+		if index >= uint64(len(data.GHOSTDAGData)) {
+			return errors.Errorf("GHOSTDAG data index %d is out of range", index)
+		}
+		blockWithTrustedData.GHOSTDAGData = append(blockWithTrustedData.GHOSTDAGData,
+			appmessage.GHOSTDAGHashPairToDomainGHOSTDAGHashPair(data.GHOSTDAGData[index]))
+	}
+
+	err := consensus.ValidateAndInsertBlockWithTrustedData(blockWithTrustedData, false)
+	if err != nil {
+		return errors.Wrapf(err, "failed validating block with trusted data %s",
+			consensushashing.BlockHash(blockWithTrustedData.Block))
+	}
+	return nil
+}
+
+func importPruningPointFutureHeaders(consensus externalapi.Consensus, reader *messagefile.Reader) error {
+	headerCount := 0
+	for {
+		message, err := reader.Read()
+		if err != nil {
+			return err
+		}
+
+		switch message := message.(type) {
+		case *appmessage.BlockHeadersMessage:
+			for _, msgBlockHeader := range message.BlockHeaders {
+				err := insertHeader(consensus, msgBlockHeader)
+				if err != nil {
+					return err
+				}
+			}
+			headerCount += len(message.BlockHeaders)
+			log.Debugf("Imported %d headers above the pruning point so far", headerCount)
+
+		case *appmessage.MsgDoneHeaders:
+			log.Infof("Imported %d headers above the pruning point", headerCount)
+			return nil
+
+		default:
+			return unexpectedMessageError(message, appmessage.CmdBlockHeaders, appmessage.CmdDoneHeaders)
+		}
+	}
+}
+
+func insertHeader(consensus externalapi.Consensus, msgBlockHeader *appmessage.MsgBlockHeader) error {
+	block := &externalapi.DomainBlock{
+		Header:       appmessage.BlockHeaderToDomainBlockHeader(msgBlockHeader),
+		Transactions: nil,
+	}
+
+	blockHash := consensushashing.BlockHash(block)
+	blockInfo, err := consensus.GetBlockInfo(blockHash)
+	if err != nil {
+		return err
+	}
+	if blockInfo.Exists {
+		return nil
+	}
+
+	err = consensus.ValidateAndInsertBlock(block, false)
+	if err != nil && !errors.Is(err, ruleerrors.ErrDuplicateBlock) {
+		return errors.Wrapf(err, "failed to process header %s", blockHash)
+	}
+	return nil
+}
+
+func importPruningPointUTXOSet(consensus externalapi.Consensus, reader *messagefile.Reader,
+	pruningPoint *externalapi.DomainHash) error {
+
+	defer func() {
+		err := consensus.ClearImportedPruningPointData()
+		if err != nil {
+			log.Errorf("Failed to clear imported pruning point data: %s", err)
+		}
+	}()
+
+	utxoCount := 0
+	for {
+		message, err := reader.Read()
+		if err != nil {
+			return err
+		}
+
+		switch message := message.(type) {
+		case *appmessage.MsgPruningPointUTXOSetChunk:
+			domainOutpointAndUTXOEntryPairs :=
+				appmessage.OutpointAndUTXOEntryPairsToDomainOutpointAndUTXOEntryPairs(message.OutpointAndUTXOEntryPairs)
+			err := consensus.AppendImportedPruningPointUTXOs(domainOutpointAndUTXOEntryPairs)
+			if err != nil {
+				return err
+			}
+			utxoCount += len(message.OutpointAndUTXOEntryPairs)
+
+		case *appmessage.MsgDonePruningPointUTXOSetChunks:
+			log.Infof("Imported %d UTXOs. Validating them against the UTXO commitment of pruning point %s",
+				utxoCount, pruningPoint)
+			err := consensus.ValidateAndInsertImportedPruningPoint(pruningPoint)
+			if err != nil {
+				return errors.Wrapf(err, "the snapshot UTXO set is invalid")
+			}
+			return nil
+
+		default:
+			return unexpectedMessageError(message, appmessage.CmdPruningPointUTXOSetChunk,
+				appmessage.CmdDonePruningPointUTXOSetChunks)
+		}
+	}
+}
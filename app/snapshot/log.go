@@ -0,0 +1,7 @@
+package snapshot
+
+import (
+	"github.com/ammm56/lings/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("SNAP")
@@ -0,0 +1,30 @@
+/*
+Package snapshot implements exporting the pruning point state of a node into a
+file, and bootstrapping the consensus of another node from it.
+
+A snapshot holds the same data a syncee receives during IBD with a headers
+proof: the pruning point proof, the past pruning points, the pruning point and
+its anticone with their trusted data, the headers above the pruning point, and
+the pruning point UTXO set. The file is validated exactly like data received
+from a peer, so the importing node doesn't have to trust its source: the UTXO set
+must match the UTXO commitment of the pruning point header.
+*/
+package snapshot
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+const (
+	// fileKind is the kind written to the header of snapshot files
+	fileKind = "snapshot"
+
+	// utxoChunkSize is the number of UTXOs written in each UTXO set record
+	utxoChunkSize = 1000
+)
+
+func unexpectedMessageError(message appmessage.Message, expected ...appmessage.MessageCommand) error {
+	return errors.Errorf("unexpected message in snapshot file. expected: %s, got: %s",
+		expected, message.Command())
+}
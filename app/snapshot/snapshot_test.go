@@ -0,0 +1,172 @@
+package snapshot_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ammm56/lings/app/snapshot"
+	"github.com/ammm56/lings/domain"
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/model/testapi"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/domain/miningmanager/mempool"
+	"github.com/ammm56/lings/infrastructure/db/database/ldb"
+)
+
+func newConsensusConfig() *consensus.Config {
+	consensusConfig := &consensus.Config{Params: dagconfig.MainnetParams}
+	consensusConfig.SkipProofOfWork = true
+
+	// This is done to reduce the pruning depth to 6 blocks
+	const finalityDepth = 5
+	consensusConfig.FinalityDuration = time.Duration(finalityDepth) * consensusConfig.TargetTimePerBlock
+	consensusConfig.K = 0
+	consensusConfig.PruningProofM = 1
+	return consensusConfig
+}
+
+// exportTestSnapshot mines blocks until the pruning point moves well above
+// genesis, and exports a snapshot of the resulting state
+func exportTestSnapshot(t *testing.T, consensusConfig *consensus.Config) (testapi.TestConsensus, string) {
+	factory := consensus.NewFactory()
+	tc, teardown, err := factory.NewTestConsensus(consensusConfig, t.Name())
+	if err != nil {
+		t.Fatalf("Error setting up consensus: %+v", err)
+	}
+	t.Cleanup(func() { teardown(false) })
+
+	for i := 0; i < 30; i++ {
+		_, _, err := tc.AddBlockOnTips(nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlockOnTips: %+v", err)
+		}
+	}
+	pruningPoint, err := tc.PruningPoint()
+	if err != nil {
+		t.Fatalf("PruningPoint: %+v", err)
+	}
+	if pruningPoint.Equal(consensusConfig.GenesisHash) {
+		t.Fatalf("the pruning point didn't move")
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot")
+	err = snapshot.Export(tc, &consensusConfig.Params, path)
+	if err != nil {
+		t.Fatalf("Export: %+v", err)
+	}
+	return tc, path
+}
+
+func newTestDomain(t *testing.T, consensusConfig *consensus.Config) domain.Domain {
+	db, err := ldb.NewLevelDB(t.TempDir(), 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), db)
+	if err != nil {
+		t.Fatalf("New: %+v", err)
+	}
+	return domainInstance
+}
+
+func pruningPointUTXOs(t *testing.T, consensus externalapi.Consensus,
+	pruningPoint *externalapi.DomainHash) []*externalapi.OutpointAndUTXOEntryPair {
+
+	utxos, err := consensus.GetPruningPointUTXOs(pruningPoint, nil, 1000)
+	if err != nil {
+		t.Fatalf("GetPruningPointUTXOs: %+v", err)
+	}
+	return utxos
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	consensusConfig := newConsensusConfig()
+	exportingConsensus, path := exportTestSnapshot(t, consensusConfig)
+
+	importingDomain := newTestDomain(t, consensusConfig)
+	err := snapshot.Import(importingDomain, &consensusConfig.Params, path)
+	if err != nil {
+		t.Fatalf("Import: %+v", err)
+	}
+	importingConsensus := importingDomain.Consensus()
+
+	expectedPruningPoint, err := exportingConsensus.PruningPoint()
+	if err != nil {
+		t.Fatalf("PruningPoint: %+v", err)
+	}
+	pruningPoint, err := importingConsensus.PruningPoint()
+	if err != nil {
+		t.Fatalf("PruningPoint: %+v", err)
+	}
+	if !pruningPoint.Equal(expectedPruningPoint) {
+		t.Fatalf("expected the pruning point %s but got %s", expectedPruningPoint, pruningPoint)
+	}
+
+	expectedHeadersSelectedTip, err := exportingConsensus.GetHeadersSelectedTip()
+	if err != nil {
+		t.Fatalf("GetHeadersSelectedTip: %+v", err)
+	}
+	headersSelectedTip, err := importingConsensus.GetHeadersSelectedTip()
+	if err != nil {
+		t.Fatalf("GetHeadersSelectedTip: %+v", err)
+	}
+	if !headersSelectedTip.Equal(expectedHeadersSelectedTip) {
+		t.Fatalf("expected the headers selected tip %s but got %s", expectedHeadersSelectedTip, headersSelectedTip)
+	}
+
+	expectedUTXOs := pruningPointUTXOs(t, exportingConsensus, expectedPruningPoint)
+	utxos := pruningPointUTXOs(t, importingConsensus, pruningPoint)
+	if len(utxos) == 0 || len(utxos) != len(expectedUTXOs) {
+		t.Fatalf("expected %d pruning point UTXOs but got %d", len(expectedUTXOs), len(utxos))
+	}
+	for i, utxo := range utxos {
+		if !utxo.Outpoint.Equal(expectedUTXOs[i].Outpoint) || !utxo.UTXOEntry.Equal(expectedUTXOs[i].UTXOEntry) {
+			t.Fatalf("pruning point UTXO %d differs from the exported one", i)
+		}
+	}
+
+	// Importing a snapshot of the current pruning point does nothing
+	err = snapshot.Import(importingDomain, &consensusConfig.Params, path)
+	if err != nil {
+		t.Fatalf("Import: %+v", err)
+	}
+}
+
+func TestImportTruncatedSnapshot(t *testing.T) {
+	consensusConfig := newConsensusConfig()
+	_, path := exportTestSnapshot(t, consensusConfig)
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %+v", err)
+	}
+	err = os.Truncate(path, fileInfo.Size()-10)
+	if err != nil {
+		t.Fatalf("Truncate: %+v", err)
+	}
+
+	importingDomain := newTestDomain(t, consensusConfig)
+	err = snapshot.Import(importingDomain, &consensusConfig.Params, path)
+	if err == nil {
+		t.Fatalf("expected importing a truncated snapshot to fail")
+	}
+
+	// The consensus is left as it was, and the staging consensus that the
+	// snapshot was imported into is deleted
+	pruningPoint, err := importingDomain.Consensus().PruningPoint()
+	if err != nil {
+		t.Fatalf("PruningPoint: %+v", err)
+	}
+	if !pruningPoint.Equal(consensusConfig.GenesisHash) {
+		t.Fatalf("expected the pruning point to stay genesis, but got %s", pruningPoint)
+	}
+	err = importingDomain.InitStagingConsensusWithoutGenesis()
+	if err != nil {
+		t.Fatalf("expected the staging consensus to be deleted, but got: %+v", err)
+	}
+}
@@ -15,14 +15,8 @@ import (
 func parseCommand(args []string, commandDescs []*commandDescription) (*protowire.LingsMessage, error) {
 	commandName, parameterStrings := args[0], args[1:]
 
-	var commandDesc *commandDescription
-	for _, cd := range commandDescs {
-		if cd.name == commandName {
-			commandDesc = cd
-			break
-		}
-	}
-	if commandDesc == nil {
+	commandDesc, ok := findCommandDescription(commandName, commandDescs)
+	if !ok {
 		return nil, errors.Errorf("unknown command: %s. Use --list-commands to list all commands", commandName)
 	}
 	if len(parameterStrings) != len(commandDesc.parameters) {
@@ -42,6 +36,15 @@ func parseCommand(args []string, commandDescs []*commandDescription) (*protowire
 	return generateLingsMessage(commandValue, commandDesc)
 }
 
+func findCommandDescription(commandName string, commandDescs []*commandDescription) (*commandDescription, bool) {
+	for _, commandDesc := range commandDescs {
+		if commandDesc.name == commandName {
+			return commandDesc, true
+		}
+	}
+	return nil, false
+}
+
 func setField(commandValue reflect.Value, parameterValue reflect.Value, parameterDesc *parameterDescription) {
 	parameterField := commandValue.Elem().FieldByName(parameterDesc.name)
 
@@ -41,6 +41,13 @@ var commandTypes = []reflect.Type{
 
 	reflect.TypeOf(protowire.LingsMessage_BanRequest{}),
 	reflect.TypeOf(protowire.LingsMessage_UnbanRequest{}),
+	reflect.TypeOf(protowire.LingsMessage_BackupDatabaseRequest{}),
+	reflect.TypeOf(protowire.LingsMessage_SetDatabaseOptionsRequest{}),
+	reflect.TypeOf(protowire.LingsMessage_GetCacheStatsRequest{}),
+	reflect.TypeOf(protowire.LingsMessage_GenerateBlocksRequest{}),
+	reflect.TypeOf(protowire.LingsMessage_SetNodeTimeRequest{}),
+	reflect.TypeOf(protowire.LingsMessage_GetDagGraphRequest{}),
+	reflect.TypeOf(protowire.LingsMessage_GetPruningPointProofRequest{}),
 }
 
 type commandDescription struct {
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"strings"
+
 	"github.com/ammm56/lings/infrastructure/config"
 	"github.com/jessevdk/go-flags"
 	"github.com/pkg/errors"
@@ -12,11 +14,17 @@ var (
 )
 
 type configFlags struct {
-	RPCServer                          string `short:"s" long:"rpcserver" description:"RPC server to connect to"`
-	Timeout                            uint64 `short:"t" long:"timeout" description:"Timeout for the request (in seconds)"`
-	RequestJSON                        string `short:"j" long:"json" description:"The request in JSON format"`
-	ListCommands                       bool   `short:"l" long:"list-commands" description:"List all commands and exit"`
-	AllowConnectionToDifferentVersions bool   `short:"a" long:"allow-connection-to-different-versions" description:"Allow connections to versions different than lingsctl's version'"`
+	RPCServer                          string   `short:"s" long:"rpcserver" description:"RPC server to connect to"`
+	Timeout                            uint64   `short:"t" long:"timeout" description:"Timeout for the request (in seconds)"`
+	RequestJSON                        string   `short:"j" long:"json" description:"The request in JSON format"`
+	ListCommands                       bool     `short:"l" long:"list-commands" description:"List all commands and exit"`
+	AllowConnectionToDifferentVersions bool     `short:"a" long:"allow-connection-to-different-versions" description:"Allow connections to versions different than lingsctl's version'"`
+	Subscribe                          []string `long:"subscribe" description:"Subscribe to a notification and print every notification as a line of JSON until interrupted, reconnecting if the connection is lost -- May be repeated -- Use --list-commands to get a list of all notifications"`
+	SubscribeAddresses                 []string `long:"subscribe-address" description:"Address whose UTXO changes are printed by --subscribe=utxos-changed -- May be repeated"`
+	IncludeAcceptedTransactionIDs      bool     `long:"include-accepted-transaction-ids" description:"Include the accepted transaction IDs in the notifications of --subscribe=virtual-selected-parent-chain-changed"`
+	Interactive                        bool     `short:"i" long:"interactive" description:"Run an interactive shell that sends commands over a single connection"`
+	BatchFile                          string   `short:"b" long:"batch" description:"Run the commands in the given file, one per line as in --interactive, and stop at the first one that fails"`
+	OutputFile                         string   `short:"o" long:"output-file" description:"Write the response to the given file instead of printing it -- For GetDagGraph, the graph itself is written, in the requested format"`
 	CommandAndParameters               []string
 	config.NetworkFlags
 }
@@ -27,7 +35,7 @@ func parseConfig() (*configFlags, error) {
 		Timeout:   defaultTimeout,
 	}
 	parser := flags.NewParser(cfg, flags.HelpFlag)
-	parser.Usage = "lingsctl [OPTIONS] [COMMAND] [COMMAND PARAMETERS].\n\nCommand can be supplied only if --json is not used." +
+	parser.Usage = "lingsctl [OPTIONS] [COMMAND] [COMMAND PARAMETERS].\n\nCommand can be supplied only if --json, --subscribe, --interactive and --batch are not used." +
 		"\n\nUse `lingsctl --list-commands` to get a list of all commands and their parameters." +
 		"\nFor optional parameters- use '-' without quotes to not pass the parameter.\n"
 	remainingArgs, err := parser.Parse()
@@ -45,11 +53,36 @@ func parseConfig() (*configFlags, error) {
 	}
 
 	cfg.CommandAndParameters = remainingArgs
-	if len(cfg.CommandAndParameters) == 0 && cfg.RequestJSON == "" ||
-		len(cfg.CommandAndParameters) > 0 && cfg.RequestJSON != "" {
+	modes := 0
+	for _, isSet := range []bool{len(cfg.CommandAndParameters) > 0, cfg.RequestJSON != "", len(cfg.Subscribe) > 0,
+		cfg.Interactive, cfg.BatchFile != ""} {
 
-		return nil, errors.New("Exactly one of --json or a command must be specified")
+		if isSet {
+			modes++
+		}
+	}
+	if modes != 1 {
+		return nil, errors.New("Exactly one of --json, --subscribe, --interactive, --batch or a command must be specified")
+	}
+	if cfg.OutputFile != "" && len(cfg.CommandAndParameters) == 0 && cfg.RequestJSON == "" {
+		return nil, errors.New("--output-file can only be used with --json or a command")
+	}
+	if len(cfg.Subscribe) > 0 {
+		return cfg, validateSubscriptions(cfg)
 	}
 
 	return cfg, nil
 }
+
+func validateSubscriptions(cfg *configFlags) error {
+	for _, name := range cfg.Subscribe {
+		if _, ok := findSubscription(name); !ok {
+			return errors.Errorf("unknown notification %s -- Valid notifications are %s",
+				name, strings.Join(subscriptionNames(), ", "))
+		}
+		if name == "utxos-changed" && len(cfg.SubscribeAddresses) == 0 {
+			return errors.New("--subscribe=utxos-changed requires at least one --subscribe-address")
+		}
+	}
+	return nil
+}
@@ -29,25 +29,24 @@ func main() {
 	if err != nil {
 		printErrorAndExit(fmt.Sprintf("error parsing RPC server address: %s", err))
 	}
-	client, err := grpcclient.Connect(rpcAddress)
+	client, err := connect(cfg, rpcAddress)
 	if err != nil {
-		printErrorAndExit(fmt.Sprintf("error connecting to the RPC server: %s", err))
+		printErrorAndExit(err.Error())
 	}
-	defer client.Disconnect()
-
-	if !cfg.AllowConnectionToDifferentVersions {
-		lingsMessage, err := client.Post(&protowire.LingsMessage{Payload: &protowire.LingsMessage_GetInfoRequest{GetInfoRequest: &protowire.GetInfoRequestMessage{}}})
-		if err != nil {
-			printErrorAndExit(fmt.Sprintf("Cannot post GetInfo message: %s", err))
-		}
 
-		localVersion := version.Version()
-		remoteVersion := lingsMessage.GetGetInfoResponse().ServerVersion
-
-		if localVersion != remoteVersion {
-			printErrorAndExit(fmt.Sprintf("Server version mismatch, expect: %s, got: %s", localVersion, remoteVersion))
-		}
+	if len(cfg.Subscribe) > 0 {
+		subscribe(cfg, rpcAddress, client)
+		return
 	}
+	if cfg.Interactive {
+		runShell(cfg, rpcAddress, client)
+		return
+	}
+	if cfg.BatchFile != "" {
+		runBatch(cfg, rpcAddress, client, cfg.BatchFile)
+		return
+	}
+	defer client.Disconnect()
 
 	responseChan := make(chan string)
 
@@ -60,6 +59,10 @@ func main() {
 	timeout := time.Duration(cfg.Timeout) * time.Second
 	select {
 	case responseString := <-responseChan:
+		if cfg.OutputFile != "" {
+			writeResponse(cfg.OutputFile, responseString)
+			return
+		}
 		prettyResponseString := prettifyResponse(responseString)
 		fmt.Println(prettyResponseString)
 	case <-time.After(timeout):
@@ -67,11 +70,42 @@ func main() {
 	}
 }
 
+// connect connects to the RPC server, and makes sure it runs the same version
+// as lingsctl unless --allow-connection-to-different-versions is set
+func connect(cfg *configFlags, rpcAddress string) (*grpcclient.GRPCClient, error) {
+	client, err := grpcclient.Connect(rpcAddress)
+	if err != nil {
+		return nil, errors.Errorf("error connecting to the RPC server: %s", err)
+	}
+
+	if !cfg.AllowConnectionToDifferentVersions {
+		lingsMessage, err := client.Post(&protowire.LingsMessage{Payload: &protowire.LingsMessage_GetInfoRequest{GetInfoRequest: &protowire.GetInfoRequestMessage{}}})
+		if err != nil {
+			client.Close()
+			return nil, errors.Errorf("Cannot post GetInfo message: %s", err)
+		}
+
+		localVersion := version.Version()
+		remoteVersion := lingsMessage.GetGetInfoResponse().ServerVersion
+
+		if localVersion != remoteVersion {
+			client.Close()
+			return nil, errors.Errorf("Server version mismatch, expect: %s, got: %s", localVersion, remoteVersion)
+		}
+	}
+	return client, nil
+}
+
 func printAllCommands() {
 	requestDescs := commandDescriptions()
 	for _, requestDesc := range requestDescs {
 		fmt.Printf("\t%s\n", requestDesc.help())
 	}
+
+	fmt.Println("\nNotifications for --subscribe:")
+	for _, name := range subscriptionNames() {
+		fmt.Printf("\t%s\n", name)
+	}
 }
 
 func postCommand(cfg *configFlags, client *grpcclient.GRPCClient, responseChan chan string) {
@@ -113,6 +147,35 @@ func prettifyResponse(response string) string {
 	return marshalOptions.Format(lingsMessage)
 }
 
+// writeResponse writes the given response to a file. The graph of a
+// successful GetDagGraph response is written as is, so that it can be passed
+// directly to Graphviz or to a JSON tool. Error responses of GetDagGraph and
+// GetPruningPointProof are printed instead, so that no file is written.
+func writeResponse(outputFile string, response string) {
+	lingsMessage := &protowire.LingsMessage{}
+	err := protojson.Unmarshal([]byte(response), lingsMessage)
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error parsing the response from the RPC server: %s", err))
+	}
+
+	content := prettifyResponse(response) + "\n"
+	if getDagGraphResponse := lingsMessage.GetGetDagGraphResponse(); getDagGraphResponse != nil {
+		if getDagGraphResponse.Error != nil {
+			printErrorAndExit(prettifyResponse(response))
+		}
+		content = getDagGraphResponse.Graph
+	}
+	if getPruningPointProofResponse := lingsMessage.GetGetPruningPointProofResponse(); getPruningPointProofResponse != nil &&
+		getPruningPointProofResponse.Error != nil {
+		printErrorAndExit(prettifyResponse(response))
+	}
+
+	err = os.WriteFile(outputFile, []byte(content), 0644)
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error writing the response to %s: %s", outputFile, err))
+	}
+}
+
 func printErrorAndExit(message string) {
 	fmt.Fprintf(os.Stderr, fmt.Sprintf("%s\n", message))
 	os.Exit(1)
@@ -28,6 +28,14 @@ func isFieldExported(field reflect.StructField) bool {
 	return unicode.IsUpper(rune(field.Name[0]))
 }
 
+// underlyingKind returns the kind of the given type, or of the type it points to if it's a pointer
+func underlyingKind(typeof reflect.Type) reflect.Kind {
+	for typeof.Kind() == reflect.Ptr {
+		typeof = typeof.Elem()
+	}
+	return typeof.Kind()
+}
+
 // generateLingsMessage generates a wrapped LingsMessage with the given `commandValue`
 func generateLingsMessage(commandValue reflect.Value, commandDesc *commandDescription) (*protowire.LingsMessage, error) {
 	commandWrapper := reflect.New(commandDesc.typeof)
@@ -0,0 +1,454 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ammm56/lings/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/ammm56/lings/infrastructure/network/rpcclient/grpcclient"
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const shellPrompt = "lingsctl> "
+
+const shellHelp = `Every line is a command with its parameters, as they're passed to lingsctl
+on the command line, or a request in JSON format. Parameters that contain
+spaces can be quoted with ' or ". Outside of single quotes, a backslash
+escapes the character that follows it.
+
+Builtin commands:
+	help [COMMAND]               List all commands, or the parameters of COMMAND
+	set VARIABLE FIELD           Store a field of the last response in VARIABLE.
+	                             FIELD is a path of JSON field names and list
+	                             indexes separated by dots, e.g. tipHashes.0
+	vars                         List all variables
+	history                      List the commands run in this session
+	exit                         Exit the shell
+
+$VARIABLE or ${VARIABLE} in a parameter is replaced with the value of the
+variable, and $$ with $, e.g.:
+	GetBlockDagInfo
+	set tip tipHashes.0
+	GetBlock $tip true false
+`
+
+// shell runs lingsctl commands one line at a time over a single connection to
+// the RPC server, and keeps the state that is shared by these commands
+type shell struct {
+	cfg          *configFlags
+	rpcAddress   string
+	client       *grpcclient.GRPCClient
+	commandDescs []*commandDescription
+	out          io.Writer
+	errOut       io.Writer
+
+	variables    map[string]string
+	lastResponse map[string]interface{}
+	history      []string
+}
+
+func newShell(cfg *configFlags, rpcAddress string, client *grpcclient.GRPCClient) *shell {
+	return &shell{
+		cfg:          cfg,
+		rpcAddress:   rpcAddress,
+		client:       client,
+		commandDescs: commandDescriptions(),
+		out:          os.Stdout,
+		errOut:       os.Stderr,
+		variables:    make(map[string]string),
+	}
+}
+
+// runShell reads commands from the standard input and runs them until it's
+// closed or exit is entered. Errors are printed and don't stop the shell.
+func runShell(cfg *configFlags, rpcAddress string, client *grpcclient.GRPCClient) {
+	s := newShell(cfg, rpcAddress, client)
+	defer s.close()
+
+	stdin := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdin) {
+		s.runLines(os.Stdin, false)
+		return
+	}
+
+	oldState, err := term.MakeRaw(stdin)
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error setting up the terminal: %s", err))
+	}
+	defer term.Restore(stdin, oldState)
+
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, shellPrompt)
+	if width, height, err := term.GetSize(stdin); err == nil {
+		_ = terminal.SetSize(width, height)
+	}
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return s.complete(line, pos, terminal)
+	}
+	s.out = terminal
+	s.errOut = terminal
+
+	for {
+		line, err := terminal.ReadLine()
+		if err != nil {
+			if err == io.EOF {
+				return
+			}
+			fmt.Fprintf(s.errOut, "error reading from the terminal: %s\n", err)
+			return
+		}
+		done, err := s.runLine(line)
+		if err != nil {
+			fmt.Fprintf(s.errOut, "%s\n", err)
+		}
+		if done {
+			return
+		}
+	}
+}
+
+// runBatch runs the commands in the file in the given path, one per line, and
+// exits at the first command that fails or returns an error
+func runBatch(cfg *configFlags, rpcAddress string, client *grpcclient.GRPCClient, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error opening the batch file: %s", err))
+	}
+	defer file.Close()
+
+	s := newShell(cfg, rpcAddress, client)
+	defer s.close()
+	s.runLines(file, true)
+}
+
+// runLines runs the commands read from the given reader. If stopOnError is
+// set the first failure is fatal.
+func (s *shell) runLines(reader io.Reader, stopOnError bool) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 1<<24)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		done, err := s.runLine(scanner.Text())
+		if err != nil {
+			if stopOnError {
+				s.close()
+				printErrorAndExit(fmt.Sprintf("line %d: %s", lineNumber, err))
+			}
+			fmt.Fprintf(s.errOut, "line %d: %s\n", lineNumber, err)
+		}
+		if done {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		s.close()
+		printErrorAndExit(fmt.Sprintf("error reading the commands: %s", err))
+	}
+}
+
+func (s *shell) close() {
+	if s.client != nil {
+		s.client.Disconnect()
+		s.client = nil
+	}
+}
+
+// runLine runs a single line of input. It returns true if the shell should
+// exit.
+func (s *shell) runLine(line string) (done bool, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return false, nil
+	}
+	s.history = append(s.history, line)
+
+	if strings.HasPrefix(line, "{") {
+		request := &protowire.LingsMessage{}
+		err := protojson.Unmarshal([]byte(line), request)
+		if err != nil {
+			return false, errors.Wrapf(err, "error parsing the JSON request")
+		}
+		return false, s.post(request)
+	}
+
+	args, err := splitLine(line)
+	if err != nil {
+		return false, err
+	}
+	switch args[0] {
+	case "exit", "quit":
+		return true, nil
+	case "help":
+		return false, s.help(args[1:])
+	case "set":
+		return false, s.set(args[1:])
+	case "vars":
+		s.printVariables()
+		return false, nil
+	case "history":
+		for i, historyLine := range s.history {
+			fmt.Fprintf(s.out, "%5d  %s\n", i+1, historyLine)
+		}
+		return false, nil
+	}
+
+	for i := 1; i < len(args); i++ {
+		args[i], err = s.expandVariables(args[i])
+		if err != nil {
+			return false, err
+		}
+	}
+	request, err := parseCommand(args, s.commandDescs)
+	if err != nil {
+		return false, err
+	}
+	return false, s.post(request)
+}
+
+// post sends the request to the RPC server, prints the response and keeps it
+// for the set command. If the request fails or times out the connection is
+// dropped, and a new one is made for the next request.
+func (s *shell) post(request *protowire.LingsMessage) error {
+	if s.client == nil {
+		client, err := connect(s.cfg, s.rpcAddress)
+		if err != nil {
+			return err
+		}
+		s.client = client
+	}
+
+	type result struct {
+		response *protowire.LingsMessage
+		err      error
+	}
+	resultChan := make(chan result, 1)
+	client := s.client
+	go func() {
+		response, err := client.Post(request)
+		resultChan <- result{response, err}
+	}()
+
+	var response *protowire.LingsMessage
+	timeout := time.Duration(s.cfg.Timeout) * time.Second
+	select {
+	case result := <-resultChan:
+		if result.err != nil {
+			s.dropConnection()
+			return errors.Errorf("error posting the request to the RPC server: %s", result.err)
+		}
+		response = result.response
+	case <-time.After(timeout):
+		s.dropConnection()
+		return errors.Errorf("timeout of %s has been exceeded", timeout)
+	}
+
+	responseBytes, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(response)
+	if err != nil {
+		return errors.Wrapf(err, "error parsing the response from the RPC server")
+	}
+	fmt.Fprintln(s.out, prettifyResponse(string(responseBytes)))
+
+	s.lastResponse = nil
+	var wrappedResponse map[string]map[string]interface{}
+	err = json.Unmarshal(responseBytes, &wrappedResponse)
+	if err != nil {
+		return errors.Wrapf(err, "error parsing the response from the RPC server")
+	}
+	for _, unwrappedResponse := range wrappedResponse {
+		s.lastResponse = unwrappedResponse
+	}
+
+	_, rpcError := responseError(response)
+	if rpcError != nil {
+		return errors.Errorf("the RPC server returned an error: %s", rpcError.Message)
+	}
+	return nil
+}
+
+func (s *shell) dropConnection() {
+	s.client.Close()
+	s.client = nil
+}
+
+func (s *shell) help(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(s.out, shellHelp)
+		fmt.Fprintln(s.out, "\nCommands:")
+		for _, commandDesc := range s.commandDescs {
+			fmt.Fprintf(s.out, "\t%s\n", commandDesc.help())
+		}
+		return nil
+	}
+
+	commandDesc, ok := findCommandDescription(args[0], s.commandDescs)
+	if !ok {
+		return errors.Errorf("unknown command: %s", args[0])
+	}
+	fmt.Fprintln(s.out, commandDesc.help())
+	for _, parameter := range commandDesc.parameters {
+		fmt.Fprintf(s.out, "\t%s: %s\n", parameter.name, parameter.typeof)
+	}
+	return nil
+}
+
+func (s *shell) set(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: set VARIABLE FIELD")
+	}
+	name, path := args[0], args[1]
+	if !isVariableName(name) {
+		return errors.Errorf("invalid variable name %s", name)
+	}
+	if s.lastResponse == nil {
+		return errors.New("there's no response to take the field from")
+	}
+
+	field, err := responseField(s.lastResponse, path)
+	if err != nil {
+		return err
+	}
+	value, ok := field.(string)
+	if !ok {
+		valueBytes, err := json.Marshal(field)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		value = string(valueBytes)
+	}
+	s.variables[name] = value
+	fmt.Fprintf(s.out, "%s = %s\n", name, value)
+	return nil
+}
+
+func (s *shell) printVariables() {
+	for _, name := range s.variableNames() {
+		fmt.Fprintf(s.out, "%s = %s\n", name, s.variables[name])
+	}
+}
+
+func (s *shell) variableNames() []string {
+	names := make([]string, 0, len(s.variables))
+	for name := range s.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expandVariables replaces every $VARIABLE and ${VARIABLE} in the given
+// parameter with the value of the variable, and every $$ with $
+func (s *shell) expandVariables(parameter string) (string, error) {
+	var err error
+	expanded := os.Expand(parameter, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		value, ok := s.variables[name]
+		if !ok && err == nil {
+			err = errors.Errorf("unknown variable %s", name)
+		}
+		return value
+	})
+	return expanded, err
+}
+
+func isVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		isLetter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		isDigit := r >= '0' && r <= '9'
+		if !isLetter && !(isDigit && i > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// responseField returns the field in the given dot separated path of JSON
+// field names and list indexes
+func responseField(response map[string]interface{}, path string) (interface{}, error) {
+	var field interface{} = response
+	for _, element := range strings.Split(path, ".") {
+		switch value := field.(type) {
+		case map[string]interface{}:
+			var ok bool
+			field, ok = value[element]
+			if !ok {
+				return nil, errors.Errorf("the response has no field %s", path)
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(element)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, errors.Errorf("%s in %s is not an index of a list of length %d", element, path, len(value))
+			}
+			field = value[index]
+		default:
+			return nil, errors.Errorf("the response has no field %s", path)
+		}
+	}
+	return field, nil
+}
+
+// splitLine splits the given line into words separated by spaces. Words may be
+// quoted with ' or " to include spaces. Outside of single quotes, a backslash
+// escapes the character that follows it.
+func splitLine(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped {
+		return nil, errors.New("the line ends with an unescaped backslash")
+	}
+	if quote != 0 {
+		return nil, errors.Errorf("unterminated quote %c", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var builtinCommands = []string{"exit", "help", "history", "quit", "set", "vars"}
+
+// complete is the tab completion of the shell. It completes the word before
+// the cursor to the longest common prefix of its candidates, and prints the
+// candidates if there's nothing to complete. Command names and parameters are
+// taken from the command descriptions, so they follow the request types.
+func (s *shell) complete(line string, pos int, out io.Writer) (newLine string, newPos int, ok bool) {
+	wordStart := strings.LastIndexAny(line[:pos], " \t") + 1
+	word := line[wordStart:pos]
+	previousWords := strings.Fields(line[:wordStart])
+
+	candidates, hint := s.completionCandidates(previousWords, word)
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	if len(matches) == 0 {
+		if hint != "" {
+			fmt.Fprintln(out, hint)
+		}
+		return "", 0, false
+	}
+
+	completion := matches[0]
+	if len(matches) == 1 {
+		if !strings.HasSuffix(completion, ".") {
+			completion += " "
+		}
+	} else {
+		completion = commonPrefix(matches)
+		if completion == word {
+			fmt.Fprintln(out, strings.Join(matches, "  "))
+			return "", 0, false
+		}
+	}
+	return line[:wordStart] + completion + line[pos:], wordStart + len(completion), true
+}
+
+// completionCandidates returns the words that can follow previousWords, and a
+// hint to print when none of them match
+func (s *shell) completionCandidates(previousWords []string, word string) (candidates []string, hint string) {
+	if len(previousWords) == 0 {
+		candidates = append(candidates, builtinCommands...)
+		for _, commandDesc := range s.commandDescs {
+			candidates = append(candidates, commandDesc.name)
+		}
+		sort.Strings(candidates)
+		return candidates, ""
+	}
+
+	if strings.HasPrefix(word, "$") {
+		for _, name := range s.variableNames() {
+			candidates = append(candidates, "$"+name)
+		}
+		return candidates, ""
+	}
+
+	commandName, parameterIndex := previousWords[0], len(previousWords)-1
+	switch commandName {
+	case "help":
+		if parameterIndex == 0 {
+			for _, commandDesc := range s.commandDescs {
+				candidates = append(candidates, commandDesc.name)
+			}
+		}
+		return candidates, ""
+	case "set":
+		if parameterIndex == 1 {
+			return s.fieldPathCandidates(word), ""
+		}
+		return nil, ""
+	}
+
+	commandDesc, ok := findCommandDescription(commandName, s.commandDescs)
+	if !ok || parameterIndex >= len(commandDesc.parameters) {
+		return nil, ""
+	}
+	parameter := commandDesc.parameters[parameterIndex]
+	if underlyingKind(parameter.typeof) == reflect.Bool {
+		candidates = []string{"true", "false"}
+	}
+	if parameter.typeof.Kind() == reflect.Ptr {
+		candidates = append(candidates, "-")
+	}
+	return candidates, fmt.Sprintf("%s: %s", parameter.name, parameter.typeof)
+}
+
+// fieldPathCandidates returns the paths of the fields of the last response
+// that extend the last complete element of the given path
+func (s *shell) fieldPathCandidates(path string) []string {
+	if s.lastResponse == nil {
+		return nil
+	}
+
+	parentPath := ""
+	var parent interface{} = s.lastResponse
+	if lastDot := strings.LastIndex(path, "."); lastDot >= 0 {
+		parentPath = path[:lastDot+1]
+		var err error
+		parent, err = responseField(s.lastResponse, path[:lastDot])
+		if err != nil {
+			return nil
+		}
+	}
+
+	var candidates []string
+	addCandidate := func(element string, value interface{}) {
+		candidate := parentPath + element
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			candidate += "."
+		}
+		candidates = append(candidates, candidate)
+	}
+	switch value := parent.(type) {
+	case map[string]interface{}:
+		for name, field := range value {
+			addCandidate(name, field)
+		}
+	case []interface{}:
+		for i, element := range value {
+			addCandidate(strconv.Itoa(i), element)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitLine(t *testing.T) {
+	tests := []struct {
+		line          string
+		expectedWords []string
+		expectedError string
+	}{
+		{line: "", expectedWords: nil},
+		{line: "GetBlockDagInfo", expectedWords: []string{"GetBlockDagInfo"}},
+		{line: "GetBlock  abc \t true", expectedWords: []string{"GetBlock", "abc", "true"}},
+		{line: "set tip tipHashes.0 ", expectedWords: []string{"set", "tip", "tipHashes.0"}},
+		{line: `a "b c" 'd e'`, expectedWords: []string{"a", "b c", "d e"}},
+		{line: `a "" ''`, expectedWords: []string{"a", "", ""}},
+		{line: `a"b c"d`, expectedWords: []string{"ab cd"}},
+		{line: `"it's" 'say "hi"'`, expectedWords: []string{"it's", `say "hi"`}},
+		{line: `a\ b`, expectedWords: []string{"a b"}},
+		{line: `\"a\" \'b\'`, expectedWords: []string{`"a"`, "'b'"}},
+		{line: `a\\b`, expectedWords: []string{`a\b`}},
+		{line: `"a \" b"`, expectedWords: []string{`a " b`}},
+		{line: `'a \' b`, expectedWords: []string{`a \`, "b"}},
+		{line: `'a\b'`, expectedWords: []string{`a\b`}},
+		{line: `\ `, expectedWords: []string{" "}},
+		{line: `a "b`, expectedError: `unterminated quote "`},
+		{line: `a\`, expectedError: "unescaped backslash"},
+	}
+
+	for _, test := range tests {
+		words, err := splitLine(test.line)
+		if test.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("splitLine(%q): expected an error containing %q but got %v",
+					test.line, test.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitLine(%q): unexpected error: %s", test.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(words, test.expectedWords) {
+			t.Errorf("splitLine(%q): expected %q but got %q", test.line, test.expectedWords, words)
+		}
+	}
+}
+
+func TestExpandVariables(t *testing.T) {
+	s := &shell{variables: map[string]string{
+		"tip":   "abcd",
+		"count": "10",
+		"empty": "",
+	}}
+
+	tests := []struct {
+		parameter     string
+		expected      string
+		expectedError string
+	}{
+		{parameter: "plain", expected: "plain"},
+		{parameter: "$tip", expected: "abcd"},
+		{parameter: "${tip}", expected: "abcd"},
+		{parameter: "${tip}ef", expected: "abcdef"},
+		{parameter: "x$tip.$count", expected: "xabcd.10"},
+		{parameter: "$empty", expected: ""},
+		{parameter: "$$tip", expected: "$tip"},
+		{parameter: "price$", expected: "price$"},
+		{parameter: "$missing", expectedError: "unknown variable missing"},
+		{parameter: "${tip}$missing", expectedError: "unknown variable missing"},
+		{parameter: "$tipx", expectedError: "unknown variable tipx"},
+	}
+
+	for _, test := range tests {
+		expanded, err := s.expandVariables(test.parameter)
+		if test.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("expandVariables(%q): expected an error containing %q but got %v",
+					test.parameter, test.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expandVariables(%q): unexpected error: %s", test.parameter, err)
+			continue
+		}
+		if expanded != test.expected {
+			t.Errorf("expandVariables(%q): expected %q but got %q", test.parameter, test.expected, expanded)
+		}
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	s := &shell{
+		commandDescs: commandDescriptions(),
+		variables:    map[string]string{"tip": "abcd", "tips": "ef", "count": "10"},
+		lastResponse: map[string]interface{}{
+			"tipHashes":   []interface{}{"abcd", "ef"},
+			"blockCount":  "10",
+			"networkName": "lings-mainnet",
+			"header":      map[string]interface{}{"version": 1.0},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		line           string
+		pos            int
+		expectedLine   string
+		expectedOutput string
+	}{
+		{
+			name:         "unique command",
+			line:         "GetBlockD",
+			expectedLine: "GetBlockDagInfo ",
+		},
+		{
+			name:         "common prefix of commands",
+			line:         "GetBloc",
+			expectedLine: "GetBlock",
+		},
+		{
+			name:           "ambiguous command",
+			line:           "GetBlock",
+			expectedOutput: "GetBlock  GetBlockCount  GetBlockDagInfo  GetBlockTemplate  GetBlocks\n",
+		},
+		{
+			name:         "builtin command",
+			line:         "hi",
+			expectedLine: "history ",
+		},
+		{
+			name:         "command in the middle of the line",
+			line:         "GetBlockD abc",
+			pos:          len("GetBlockD"),
+			expectedLine: "GetBlockDagInfo  abc",
+		},
+		{
+			name:         "help parameter",
+			line:         "help GetBlockD",
+			expectedLine: "help GetBlockDagInfo ",
+		},
+		{
+			name:         "bool parameter",
+			line:         "GetBlock abc t",
+			expectedLine: "GetBlock abc true ",
+		},
+		{
+			name:           "bool parameter candidates",
+			line:           "GetBlock abc ",
+			expectedOutput: "true  false\n",
+		},
+		{
+			name:           "hint of a parameter without candidates",
+			line:           "GetBlock a",
+			expectedOutput: "Hash: string\n",
+		},
+		{
+			name:         "pointer parameter",
+			line:         "SubmitTransaction ",
+			expectedLine: "SubmitTransaction - ",
+		},
+		{
+			name: "parameter beyond the last one",
+			line: "GetBlock abc true x",
+		},
+		{
+			name:         "variables",
+			line:         "GetBlock $ti",
+			expectedLine: "GetBlock $tip",
+		},
+		{
+			name:           "ambiguous variables",
+			line:           "GetBlock $tip",
+			expectedOutput: "$tip  $tips\n",
+		},
+		{
+			name:         "field path",
+			line:         "set tip tipH",
+			expectedLine: "set tip tipHashes.",
+		},
+		{
+			name:           "field path into a list",
+			line:           "set tip tipHashes.",
+			expectedOutput: "tipHashes.0  tipHashes.1\n",
+		},
+		{
+			name:         "field path into an object",
+			line:         "set v header.v",
+			expectedLine: "set v header.version ",
+		},
+		{
+			name:         "unique field path",
+			line:         "set x n",
+			expectedLine: "set x networkName ",
+		},
+		{
+			name: "unknown field path",
+			line: "set x missing.",
+		},
+		{
+			name: "unknown command",
+			line: "NoSuchCommand a",
+		},
+	}
+
+	for _, test := range tests {
+		pos := test.pos
+		if pos == 0 {
+			pos = len(test.line)
+		}
+		out := &strings.Builder{}
+		newLine, newPos, ok := s.complete(test.line, pos, out)
+
+		if test.expectedLine == "" {
+			if ok {
+				t.Errorf("%s: expected no completion but got %q", test.name, newLine)
+			}
+		} else {
+			if !ok || newLine != test.expectedLine {
+				t.Errorf("%s: expected the line %q but got %q (ok: %t)", test.name, test.expectedLine, newLine, ok)
+				continue
+			}
+			expectedPos := len(test.expectedLine) - (len(test.line) - pos)
+			if newPos != expectedPos {
+				t.Errorf("%s: expected the cursor at %d but got %d", test.name, expectedPos, newPos)
+			}
+		}
+		if out.String() != test.expectedOutput {
+			t.Errorf("%s: expected the output %q but got %q", test.name, test.expectedOutput, out.String())
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ammm56/lings/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/ammm56/lings/infrastructure/network/rpcclient/grpcclient"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// subscription is a notification that can be subscribed to with --subscribe
+type subscription struct {
+	name    string
+	request func(cfg *configFlags) *protowire.LingsMessage
+}
+
+var subscriptions = []*subscription{
+	{
+		name: "block-added",
+		request: func(_ *configFlags) *protowire.LingsMessage {
+			return &protowire.LingsMessage{Payload: &protowire.LingsMessage_NotifyBlockAddedRequest{
+				NotifyBlockAddedRequest: &protowire.NotifyBlockAddedRequestMessage{}}}
+		},
+	},
+	{
+		name: "utxos-changed",
+		request: func(cfg *configFlags) *protowire.LingsMessage {
+			return &protowire.LingsMessage{Payload: &protowire.LingsMessage_NotifyUtxosChangedRequest{
+				NotifyUtxosChangedRequest: &protowire.NotifyUtxosChangedRequestMessage{
+					Addresses: cfg.SubscribeAddresses,
+				}}}
+		},
+	},
+	{
+		name: "virtual-selected-parent-chain-changed",
+		request: func(cfg *configFlags) *protowire.LingsMessage {
+			return &protowire.LingsMessage{Payload: &protowire.LingsMessage_NotifyVirtualSelectedParentChainChangedRequest{
+				NotifyVirtualSelectedParentChainChangedRequest: &protowire.NotifyVirtualSelectedParentChainChangedRequestMessage{
+					IncludeAcceptedTransactionIds: cfg.IncludeAcceptedTransactionIDs,
+				}}}
+		},
+	},
+	{
+		name: "virtual-selected-parent-blue-score-changed",
+		request: func(_ *configFlags) *protowire.LingsMessage {
+			return &protowire.LingsMessage{Payload: &protowire.LingsMessage_NotifyVirtualSelectedParentBlueScoreChangedRequest{
+				NotifyVirtualSelectedParentBlueScoreChangedRequest: &protowire.NotifyVirtualSelectedParentBlueScoreChangedRequestMessage{}}}
+		},
+	},
+	{
+		name: "virtual-daa-score-changed",
+		request: func(_ *configFlags) *protowire.LingsMessage {
+			return &protowire.LingsMessage{Payload: &protowire.LingsMessage_NotifyVirtualDaaScoreChangedRequest{
+				NotifyVirtualDaaScoreChangedRequest: &protowire.NotifyVirtualDaaScoreChangedRequestMessage{}}}
+		},
+	},
+	{
+		name: "new-block-template",
+		request: func(_ *configFlags) *protowire.LingsMessage {
+			return &protowire.LingsMessage{Payload: &protowire.LingsMessage_NotifyNewBlockTemplateRequest{
+				NotifyNewBlockTemplateRequest: &protowire.NotifyNewBlockTemplateRequestMessage{}}}
+		},
+	},
+	{
+		name: "finality-conflicts",
+		request: func(_ *configFlags) *protowire.LingsMessage {
+			return &protowire.LingsMessage{Payload: &protowire.LingsMessage_NotifyFinalityConflictsRequest{
+				NotifyFinalityConflictsRequest: &protowire.NotifyFinalityConflictsRequestMessage{}}}
+		},
+	},
+	{
+		name: "pruning-point-utxo-set-override",
+		request: func(_ *configFlags) *protowire.LingsMessage {
+			return &protowire.LingsMessage{Payload: &protowire.LingsMessage_NotifyPruningPointUTXOSetOverrideRequest{
+				NotifyPruningPointUTXOSetOverrideRequest: &protowire.NotifyPruningPointUTXOSetOverrideRequestMessage{}}}
+		},
+	},
+}
+
+func subscriptionNames() []string {
+	names := make([]string, len(subscriptions))
+	for i, subscription := range subscriptions {
+		names[i] = subscription.name
+	}
+	return names
+}
+
+func findSubscription(name string) (*subscription, bool) {
+	for _, subscription := range subscriptions {
+		if subscription.name == name {
+			return subscription, true
+		}
+	}
+	return nil, false
+}
+
+// subscribe subscribes to the notifications in cfg.Subscribe and prints every
+// notification as a line of JSON. If the connection to the RPC server is lost,
+// it reconnects and subscribes again. It never returns.
+func subscribe(cfg *configFlags, rpcAddress string, client *grpcclient.GRPCClient) {
+	reconnectDelay := minReconnectDelay
+	for {
+		connectedAt := time.Now()
+		err := receiveNotifications(cfg, client)
+		client.Close()
+		if time.Since(connectedAt) > maxReconnectDelay {
+			reconnectDelay = minReconnectDelay
+		}
+
+		for {
+			fmt.Fprintf(os.Stderr, "Disconnected from the RPC server: %s. Reconnecting in %s\n", err, reconnectDelay)
+			time.Sleep(reconnectDelay)
+			reconnectDelay *= 2
+			if reconnectDelay > maxReconnectDelay {
+				reconnectDelay = maxReconnectDelay
+			}
+
+			client, err = connect(cfg, rpcAddress)
+			if err == nil {
+				fmt.Fprintf(os.Stderr, "Reconnected to the RPC server\n")
+				break
+			}
+		}
+	}
+}
+
+// receiveNotifications sends the subscription requests and prints the
+// notifications that arrive until the connection is lost. Errors returned by
+// the RPC server for the requests are fatal.
+func receiveNotifications(cfg *configFlags, client *grpcclient.GRPCClient) error {
+	for _, name := range cfg.Subscribe {
+		subscription, _ := findSubscription(name)
+		err := client.Send(subscription.request(cfg))
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		message, err := client.Receive()
+		if err != nil {
+			return err
+		}
+
+		isResponse, rpcError := responseError(message)
+		if isResponse {
+			if rpcError != nil {
+				printErrorAndExit(fmt.Sprintf("error subscribing: %s", rpcError.Message))
+			}
+			continue
+		}
+
+		messageBytes, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(message)
+		if err != nil {
+			printErrorAndExit(errors.Wrapf(err, "error parsing a notification from the RPC server").Error())
+		}
+		fmt.Println(string(messageBytes))
+	}
+}
+
+// responseError returns whether the given message is a response to a request,
+// rather than a notification, and the error it carries if there's one
+func responseError(message *protowire.LingsMessage) (isResponse bool, rpcError *protowire.RPCError) {
+	payloadType := reflect.TypeOf(message.Payload).Elem()
+	if !strings.HasSuffix(payloadType.Name(), "Response") {
+		return false, nil
+	}
+
+	response := reflect.ValueOf(message.Payload).Elem().Field(0).Interface()
+	responseWithError, ok := response.(interface{ GetError() *protowire.RPCError })
+	if !ok {
+		return true, nil
+	}
+	return true, responseWithError.GetError()
+}
@@ -18,6 +18,7 @@ const (
 	signSubCmd                      = "sign"
 	broadcastSubCmd                 = "broadcast"
 	parseSubCmd                     = "parse"
+	debugScriptSubCmd               = "debug-script"
 	showAddressesSubCmd             = "show-addresses"
 	newAddressSubCmd                = "new-address"
 	dumpUnencryptedDataSubCmd       = "dump-unencrypted-data"
@@ -105,6 +106,19 @@ type parseConfig struct {
 	config.NetworkFlags
 }
 
+type debugScriptConfig struct {
+	Transaction         string `long:"transaction" short:"t" description:"The transaction whose scripts to debug (encoded in hex)"`
+	TransactionFile     string `long:"transaction-file" short:"F" description:"The file containing the transaction whose scripts to debug (encoded in hex)"`
+	UTXOEntriesFile     string `long:"utxo-entries-file" short:"u" description:"A JSON file with the UTXO entries the transaction spends, in the order of its inputs (default: the previous outputs recorded in the transaction)"`
+	Input               int    `long:"input" short:"i" description:"The index of the input to debug, or -1 to debug all inputs" default:"-1"`
+	JSON                bool   `long:"json" description:"Print the execution trace as JSON"`
+	Interactive         bool   `long:"interactive" short:"I" description:"Step through the execution one opcode at a time"`
+	ECDSA               bool   `long:"ecdsa" description:"The transaction was signed by an ECDSA wallet"`
+	EnableIntrospection bool   `long:"enable-introspection" description:"Enable the transaction introspection opcodes"`
+	EnableSpliceOpcodes bool   `long:"enable-splice-opcodes" description:"Enable the splice and bitwise opcodes"`
+	config.NetworkFlags
+}
+
 type showAddressesConfig struct {
 	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
 	config.NetworkFlags
@@ -178,6 +192,11 @@ func parseCommandLine() (subCommand string, config interface{}) {
 	parser.AddCommand(parseSubCmd, "Parse the given transaction and print its contents",
 		"Parse the given transaction and print its contents", parseConf)
 
+	debugScriptConf := &debugScriptConfig{}
+	parser.AddCommand(debugScriptSubCmd, "Trace the script execution of the given transaction",
+		"Replay the script execution of the given transaction's inputs opcode by opcode, printing the stacks "+
+			"after every opcode, either interactively, as text or as JSON", debugScriptConf)
+
 	showAddressesConf := &showAddressesConfig{DaemonAddress: defaultListen}
 	parser.AddCommand(showAddressesSubCmd, "Shows all generated public addresses of the current wallet",
 		"Shows all generated public addresses of the current wallet", showAddressesConf)
@@ -276,6 +295,17 @@ func parseCommandLine() (subCommand string, config interface{}) {
 			printErrorAndExit(err)
 		}
 		config = parseConf
+	case debugScriptSubCmd:
+		combineNetworkFlags(&debugScriptConf.NetworkFlags, &cfg.NetworkFlags)
+		err := debugScriptConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		err = validateDebugScriptConfig(debugScriptConf)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = debugScriptConf
 	case showAddressesSubCmd:
 		combineNetworkFlags(&showAddressesConf.NetworkFlags, &cfg.NetworkFlags)
 		err := showAddressesConf.ResolveNetwork(parser)
@@ -338,3 +368,16 @@ func combineNetworkFlags(dst, src *config.NetworkFlags) {
 		dst.OverrideDAGParamsFile = src.OverrideDAGParamsFile
 	}
 }
+
+func validateDebugScriptConfig(conf *debugScriptConfig) error {
+	if conf.Transaction == "" && conf.TransactionFile == "" {
+		return errors.Errorf("Either --transaction or --transaction-file is required")
+	}
+	if conf.Transaction != "" && conf.TransactionFile != "" {
+		return errors.Errorf("Both --transaction and --transaction-file cannot be passed at the same time")
+	}
+	if conf.Interactive && conf.JSON {
+		return errors.Errorf("Both --interactive and --json cannot be passed at the same time")
+	}
+	return nil
+}
@@ -115,7 +115,7 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 		shutdown:                    make(chan struct{}),
 		forceSyncChan:               make(chan struct{}),
 		addressSet:                  make(walletAddressSet),
-		txMassCalculator:            txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
+		txMassCalculator:            txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp, params.MassPerSpliceOp),
 		usedOutpoints:               map[externalapi.DomainOutpoint]time.Time{},
 		isLogFinalProgressLineShown: false,
 		maxUsedAddressesForLog:      0,
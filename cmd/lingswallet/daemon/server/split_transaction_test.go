@@ -30,7 +30,7 @@ func TestEstimateMassAfterSignatures(t *testing.T) {
 			keysFile:         &keys.File{MinimumSignatures: 2},
 			shutdown:         make(chan struct{}),
 			addressSet:       make(walletAddressSet),
-			txMassCalculator: txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
+			txMassCalculator: txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp, params.MassPerSpliceOp),
 		}
 
 		unsignedTransaction, err := serialization.DeserializePartiallySignedTransaction(unsignedTransactionBytes)
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ammm56/lings/cmd/lingswallet/liblingswallet"
+	"github.com/ammm56/lings/cmd/lingswallet/liblingswallet/serialization"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/constants"
+	"github.com/ammm56/lings/domain/consensus/utils/txscript"
+	"github.com/ammm56/lings/domain/consensus/utils/utxo"
+	"github.com/pkg/errors"
+)
+
+// debugScriptUTXOEntry is the JSON representation of a UTXO entry in the file
+// passed with --utxo-entries-file
+type debugScriptUTXOEntry struct {
+	Amount                 uint64 `json:"amount"`
+	ScriptPublicKey        string `json:"scriptPublicKey"`
+	ScriptPublicKeyVersion uint16 `json:"scriptPublicKeyVersion"`
+	BlockDAAScore          uint64 `json:"blockDaaScore"`
+	IsCoinbase             bool   `json:"isCoinbase"`
+}
+
+type debugScriptInputTrace struct {
+	Input           int                     `json:"input"`
+	ScriptClass     string                  `json:"scriptClass"`
+	ScriptPublicKey string                  `json:"scriptPublicKey"`
+	Steps           []*debugScriptTraceStep `json:"steps"`
+	Success         bool                    `json:"success"`
+	Error           string                  `json:"error,omitempty"`
+}
+
+type debugScriptTraceStep struct {
+	Script         int      `json:"script"`
+	Offset         int      `json:"offset"`
+	Opcode         string   `json:"opcode"`
+	Executed       bool     `json:"executed"`
+	StackBefore    []string `json:"stackBefore"`
+	StackAfter     []string `json:"stackAfter"`
+	AltStackBefore []string `json:"altStackBefore"`
+	AltStackAfter  []string `json:"altStackAfter"`
+	CondStack      []string `json:"condStack"`
+	Error          string   `json:"error,omitempty"`
+}
+
+func debugScript(conf *debugScriptConfig) error {
+	transactionHex := conf.Transaction
+	if conf.TransactionFile != "" {
+		transactionHexBytes, err := ioutil.ReadFile(conf.TransactionFile)
+		if err != nil {
+			return errors.Wrapf(err, "Could not read hex from %s", conf.TransactionFile)
+		}
+		transactionHex = strings.TrimSpace(string(transactionHexBytes))
+	}
+
+	transactions, err := decodeTransactionsFromHex(transactionHex)
+	if err != nil {
+		return err
+	}
+	if len(transactions) != 1 {
+		return errors.Errorf("debug-script expects exactly one transaction, got %d", len(transactions))
+	}
+
+	transaction, err := debugScriptTransaction(transactions[0], conf.ECDSA)
+	if err != nil {
+		return err
+	}
+	if conf.UTXOEntriesFile != "" {
+		err = setUTXOEntriesFromFile(transaction, conf.UTXOEntriesFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	inputIndexes := make([]int, 0, len(transaction.Inputs))
+	if conf.Input >= 0 {
+		if conf.Input >= len(transaction.Inputs) {
+			return errors.Errorf("The transaction has %d inputs, input %d doesn't exist",
+				len(transaction.Inputs), conf.Input)
+		}
+		inputIndexes = append(inputIndexes, conf.Input)
+	} else {
+		for i := range transaction.Inputs {
+			inputIndexes = append(inputIndexes, i)
+		}
+	}
+
+	var flags txscript.ScriptFlags
+	if conf.EnableIntrospection {
+		flags |= txscript.ScriptEnableIntrospection
+	}
+	if conf.EnableSpliceOpcodes {
+		flags |= txscript.ScriptEnableSpliceOpcodes
+	}
+
+	traces := make([]*debugScriptInputTrace, 0, len(inputIndexes))
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+	for _, inputIndex := range inputIndexes {
+		var trace *debugScriptInputTrace
+		if conf.Interactive {
+			trace, err = debugScriptInteractively(transaction, inputIndex, flags, sighashReusedValues)
+			if err != nil {
+				return err
+			}
+		} else {
+			trace = traceScript(transaction, inputIndex, flags, sighashReusedValues)
+		}
+		traces = append(traces, trace)
+	}
+
+	if conf.JSON {
+		tracesJSON, err := json.MarshalIndent(traces, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(tracesJSON))
+		return nil
+	}
+	for _, trace := range traces {
+		if !conf.Interactive {
+			printInputTrace(trace)
+		}
+		printInputTraceResult(trace)
+	}
+	return nil
+}
+
+// debugScriptTransaction returns the transaction to debug out of the given
+// partially signed transaction. The signature scripts of a fully signed
+// transaction are built from its signatures. Otherwise, the signature scripts
+// that are already in the transaction are used as is, which allows debugging
+// transactions with custom signature scripts.
+func debugScriptTransaction(serializedTransaction []byte, ecdsa bool) (*externalapi.DomainTransaction, error) {
+	partiallySignedTransaction, err := serialization.DeserializePartiallySignedTransaction(serializedTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, partiallySignedInput := range partiallySignedTransaction.PartiallySignedInputs {
+		prevOutput := partiallySignedInput.PrevOutput
+		partiallySignedTransaction.Tx.Inputs[i].UTXOEntry = utxo.NewUTXOEntry(
+			prevOutput.Value, prevOutput.ScriptPublicKey, false, 0)
+	}
+
+	isFullySigned, err := liblingswallet.IsTransactionFullySigned(serializedTransaction)
+	if err != nil {
+		return nil, err
+	}
+	if !isFullySigned {
+		return partiallySignedTransaction.Tx, nil
+	}
+	return liblingswallet.ExtractTransactionDeserialized(partiallySignedTransaction, ecdsa)
+}
+
+func setUTXOEntriesFromFile(transaction *externalapi.DomainTransaction, utxoEntriesFile string) error {
+	utxoEntriesJSON, err := ioutil.ReadFile(utxoEntriesFile)
+	if err != nil {
+		return errors.Wrapf(err, "Could not read UTXO entries from %s", utxoEntriesFile)
+	}
+	var utxoEntries []*debugScriptUTXOEntry
+	err = json.Unmarshal(utxoEntriesJSON, &utxoEntries)
+	if err != nil {
+		return errors.Wrapf(err, "Could not parse UTXO entries from %s", utxoEntriesFile)
+	}
+	if len(utxoEntries) != len(transaction.Inputs) {
+		return errors.Errorf("The transaction has %d inputs but %d UTXO entries were given",
+			len(transaction.Inputs), len(utxoEntries))
+	}
+
+	for i, utxoEntry := range utxoEntries {
+		script, err := hex.DecodeString(utxoEntry.ScriptPublicKey)
+		if err != nil {
+			return errors.Wrapf(err, "Could not decode the script public key of UTXO entry %d", i)
+		}
+		transaction.Inputs[i].UTXOEntry = utxo.NewUTXOEntry(utxoEntry.Amount,
+			&externalapi.ScriptPublicKey{Script: script, Version: utxoEntry.ScriptPublicKeyVersion},
+			utxoEntry.IsCoinbase, utxoEntry.BlockDAAScore)
+	}
+	return nil
+}
+
+func newInputTrace(transaction *externalapi.DomainTransaction, inputIndex int) *debugScriptInputTrace {
+	scriptPublicKey := transaction.Inputs[inputIndex].UTXOEntry.ScriptPublicKey()
+	scriptClass := txscript.GetScriptClass(scriptPublicKey.Script)
+	return &debugScriptInputTrace{
+		Input:           inputIndex,
+		ScriptClass:     scriptClass.String(),
+		ScriptPublicKey: hex.EncodeToString(scriptPublicKey.Script),
+		Steps:           []*debugScriptTraceStep{},
+	}
+}
+
+func newEngineForInput(transaction *externalapi.DomainTransaction, inputIndex int, flags txscript.ScriptFlags,
+	sighashReusedValues *consensushashing.SighashReusedValues) (*txscript.Engine, error) {
+
+	scriptPublicKey := transaction.Inputs[inputIndex].UTXOEntry.ScriptPublicKey()
+	if scriptPublicKey.Version > constants.MaxScriptPublicKeyVersion {
+		return nil, errors.Errorf("script public key version %d is unknown, and is always valid",
+			scriptPublicKey.Version)
+	}
+	return txscript.NewEngine(scriptPublicKey, transaction, inputIndex, flags, nil, nil, sighashReusedValues)
+}
+
+// traceScript executes the scripts of the given input and returns their trace
+func traceScript(transaction *externalapi.DomainTransaction, inputIndex int, flags txscript.ScriptFlags,
+	sighashReusedValues *consensushashing.SighashReusedValues) *debugScriptInputTrace {
+
+	trace := newInputTrace(transaction, inputIndex)
+	vm, err := newEngineForInput(transaction, inputIndex, flags, sighashReusedValues)
+	if err != nil {
+		trace.Error = err.Error()
+		return trace
+	}
+
+	recorder := &txscript.TraceRecorder{}
+	vm.SetTracer(recorder)
+	err = vm.Execute()
+	for _, step := range recorder.Steps {
+		trace.Steps = append(trace.Steps, convertTraceStep(step))
+	}
+	if err != nil {
+		trace.Error = err.Error()
+		return trace
+	}
+	trace.Success = true
+	return trace
+}
+
+// debugScriptInteractively executes the scripts of the given input one opcode
+// at a time, waiting for a command before every opcode
+func debugScriptInteractively(transaction *externalapi.DomainTransaction, inputIndex int, flags txscript.ScriptFlags,
+	sighashReusedValues *consensushashing.SighashReusedValues) (*debugScriptInputTrace, error) {
+
+	trace := newInputTrace(transaction, inputIndex)
+	fmt.Printf("Input %d: \tScript class: %s \tScript public key: %s\n",
+		inputIndex, trace.ScriptClass, trace.ScriptPublicKey)
+	vm, err := newEngineForInput(transaction, inputIndex, flags, sighashReusedValues)
+	if err != nil {
+		trace.Error = err.Error()
+		return trace, nil
+	}
+	for scriptIndex := 0; scriptIndex < 2; scriptIndex++ {
+		disassembly, err := vm.DisasmScript(scriptIndex)
+		if err == nil {
+			fmt.Printf("Script %d:\n%s", scriptIndex, disassembly)
+		}
+	}
+	fmt.Println("Commands: [s]tep (default), [c]ontinue, stac[k], [q]uit")
+
+	recorder := &txscript.TraceRecorder{}
+	vm.SetTracer(recorder)
+	reader := bufio.NewReader(os.Stdin)
+	isContinuing := false
+	for done := false; !done; {
+		if !isContinuing {
+			nextOpcode, err := vm.DisasmPC()
+			if err != nil {
+				break
+			}
+			fmt.Printf("next %s > ", nextOpcode)
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return nil, err
+			}
+			switch strings.TrimSpace(line) {
+			case "", "s", "step":
+			case "c", "continue":
+				isContinuing = true
+			case "k", "stack":
+				printStacks(vm.GetStack(), vm.GetAltStack(), vm.GetCondStack())
+				continue
+			case "q", "quit":
+				trace.Error = "the execution was stopped"
+				return trace, nil
+			default:
+				fmt.Printf("Unknown command %s\n", strings.TrimSpace(line))
+				continue
+			}
+		}
+
+		numSteps := len(recorder.Steps)
+		done, err = vm.Step()
+		if len(recorder.Steps) > numSteps {
+			traceStep := convertTraceStep(recorder.Steps[numSteps])
+			trace.Steps = append(trace.Steps, traceStep)
+			printTraceStep(traceStep)
+		}
+		if err != nil {
+			trace.Error = err.Error()
+			return trace, nil
+		}
+	}
+
+	err = vm.CheckErrorCondition(true)
+	if err != nil {
+		trace.Error = err.Error()
+		return trace, nil
+	}
+	trace.Success = true
+	return trace, nil
+}
+
+func convertTraceStep(step *txscript.TraceStep) *debugScriptTraceStep {
+	traceStep := &debugScriptTraceStep{
+		Script:         step.ScriptIndex,
+		Offset:         step.ScriptOffset,
+		Opcode:         step.Disassembly,
+		Executed:       step.Executed,
+		StackBefore:    hexStack(step.StackBefore),
+		StackAfter:     hexStack(step.StackAfter),
+		AltStackBefore: hexStack(step.AltStackBefore),
+		AltStackAfter:  hexStack(step.AltStackAfter),
+		CondStack:      condStackNames(step.CondStackAfter),
+	}
+	if step.Err != nil {
+		traceStep.Error = step.Err.Error()
+	}
+	return traceStep
+}
+
+func hexStack(stack [][]byte) []string {
+	hexItems := make([]string, len(stack))
+	for i, item := range stack {
+		hexItems[i] = hex.EncodeToString(item)
+	}
+	return hexItems
+}
+
+// formatHexStack joins the items of a hex encoded stack for printing. Empty
+// items, such as the ones pushed by OP_FALSE, are printed as <empty>.
+func formatHexStack(stack []string) string {
+	items := make([]string, len(stack))
+	for i, item := range stack {
+		if item == "" {
+			item = "<empty>"
+		}
+		items[i] = item
+	}
+	return strings.Join(items, " ")
+}
+
+func condStackNames(condStack []int) []string {
+	names := make([]string, len(condStack))
+	for i, cond := range condStack {
+		switch cond {
+		case txscript.OpCondFalse:
+			names[i] = "false"
+		case txscript.OpCondTrue:
+			names[i] = "true"
+		case txscript.OpCondSkip:
+			names[i] = "skip"
+		}
+	}
+	return names
+}
+
+func printInputTrace(trace *debugScriptInputTrace) {
+	fmt.Printf("Input %d: \tScript class: %s \tScript public key: %s\n",
+		trace.Input, trace.ScriptClass, trace.ScriptPublicKey)
+	for _, step := range trace.Steps {
+		printTraceStep(step)
+	}
+}
+
+func printTraceStep(step *debugScriptTraceStep) {
+	skipped := ""
+	if !step.Executed {
+		skipped = " (skipped)"
+	}
+	fmt.Printf("%02x:%04x: %s%s\n", step.Script, step.Offset, step.Opcode, skipped)
+	if step.Error != "" {
+		fmt.Printf("  Error: %s\n", step.Error)
+		return
+	}
+	fmt.Printf("  Stack: [%s]\n", formatHexStack(step.StackAfter))
+	if len(step.AltStackAfter) > 0 {
+		fmt.Printf("  Alt stack: [%s]\n", formatHexStack(step.AltStackAfter))
+	}
+	if len(step.CondStack) > 0 {
+		fmt.Printf("  Condition stack: [%s]\n", strings.Join(step.CondStack, " "))
+	}
+}
+
+func printStacks(stack, altStack [][]byte, condStack []int) {
+	fmt.Printf("  Stack: [%s]\n", formatHexStack(hexStack(stack)))
+	fmt.Printf("  Alt stack: [%s]\n", formatHexStack(hexStack(altStack)))
+	fmt.Printf("  Condition stack: [%s]\n", strings.Join(condStackNames(condStack), " "))
+}
+
+func printInputTraceResult(trace *debugScriptInputTrace) {
+	if trace.Success {
+		fmt.Printf("Input %d: script execution succeeded\n\n", trace.Input)
+		return
+	}
+	fmt.Printf("Input %d: script execution failed: %s\n\n", trace.Input, trace.Error)
+}
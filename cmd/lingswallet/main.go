@@ -22,6 +22,8 @@ func main() {
 		err = broadcast(config.(*broadcastConfig))
 	case parseSubCmd:
 		err = parse(config.(*parseConfig))
+	case debugScriptSubCmd:
+		err = debugScript(config.(*debugScriptConfig))
 	case showAddressesSubCmd:
 		err = showAddresses(config.(*showAddressesConfig))
 	case newAddressSubCmd:
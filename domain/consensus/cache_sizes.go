@@ -0,0 +1,150 @@
+package consensus
+
+// minCacheSize is the smallest number of entries a cache is given when its
+// size is derived from the memory budget
+const minCacheSize = 100
+
+// The names of the store caches, as they're reported by CacheStats. The
+// caches of the stores of the block levels above 0 are reported together.
+const (
+	ghostdagDataCacheName                = "block-ghostdag-data"
+	blockHeadersCacheName                = "block-headers"
+	blockStatusesCacheName               = "block-statuses"
+	reachabilityDataCacheName            = "reachability-data"
+	utxoDiffsCacheName                   = "utxo-diffs"
+	daaWindowCacheName                   = "daa-window"
+	blockRelationsCacheName              = "block-relations"
+	daaBlocksCacheName                   = "daa-blocks"
+	headersSelectedChainCacheName        = "headers-selected-chain"
+	higherLevelBlockRelationsCacheName   = "higher-level-block-relations"
+	higherLevelReachabilityDataCacheName = "higher-level-reachability-data"
+	higherLevelGHOSTDAGDataCacheName     = "higher-level-block-ghostdag-data"
+)
+
+// cacheBudget describes how a store cache is sized by the memory budget
+type cacheBudget struct {
+	// share is the percentage of the memory budget that the cache gets. It
+	// reflects how often the cache is hit on the hot paths of block
+	// processing, and how expensive a miss is.
+	share uint64
+
+	// entrySize is the approximate memory taken by an entry of the cache,
+	// in bytes
+	entrySize uint64
+}
+
+// cacheBudgets are the budgets of the store caches by their names. Their
+// shares add up to 100, so that the whole memory budget is split between them.
+// The shares of the higher level caches are split between all the levels above
+// 0.
+var cacheBudgets = map[string]cacheBudget{
+	ghostdagDataCacheName:                {share: 25, entrySize: 700},
+	blockHeadersCacheName:                {share: 15, entrySize: 1500},
+	blockStatusesCacheName:               {share: 5, entrySize: 64},
+	reachabilityDataCacheName:            {share: 20, entrySize: 300},
+	utxoDiffsCacheName:                   {share: 10, entrySize: 20_000},
+	daaWindowCacheName:                   {share: 5, entrySize: 300},
+	blockRelationsCacheName:              {share: 8, entrySize: 500},
+	daaBlocksCacheName:                   {share: 5, entrySize: 450},
+	headersSelectedChainCacheName:        {share: 2, entrySize: 150},
+	higherLevelBlockRelationsCacheName:   {share: 1, entrySize: 500},
+	higherLevelReachabilityDataCacheName: {share: 2, entrySize: 300},
+	higherLevelGHOSTDAGDataCacheName:     {share: 2, entrySize: 700},
+}
+
+// cacheSizes holds the number of entries of every store cache that is sized by
+// the memory budget. The sizes of the higher level caches are per level.
+type cacheSizes struct {
+	ghostdagData                int
+	blockHeaders                int
+	blockStatuses               int
+	reachabilityData            int
+	utxoDiffs                   int
+	daaWindow                   int
+	blockRelations              int
+	daaScores                   int
+	daaAddedBlocks              int
+	headersSelectedChain        int
+	higherLevelBlockRelations   int
+	higherLevelReachabilityData int
+	higherLevelGHOSTDAGData     int
+}
+
+// newCacheSizes returns the sizes of the store caches for the given config.
+// Without a memory budget, the sizes are derived from the DAG parameters so
+// that the caches hold the blocks that are visited by pruning.
+func newCacheSizes(config *Config) *cacheSizes {
+	pruningWindowSize := int(config.PruningDepth())
+	// This is used for caches that are used as part of deletePastBlocks that need to traverse until
+	// the previous pruning point.
+	pruningWindowSizePlusFinalityDepth := int(config.PruningDepth() + config.FinalityDepth())
+
+	if config.CacheMemoryBudgetMiB == 0 {
+		ghostdagDataCacheSize := pruningWindowSize * 2
+		if ghostdagDataCacheSize < config.DifficultyAdjustmentWindowSize {
+			ghostdagDataCacheSize = config.DifficultyAdjustmentWindowSize
+		}
+		return &cacheSizes{
+			ghostdagData:                ghostdagDataCacheSize,
+			blockHeaders:                10_000,
+			blockStatuses:               pruningWindowSizePlusFinalityDepth,
+			reachabilityData:            pruningWindowSizePlusFinalityDepth * 2,
+			utxoDiffs:                   200,
+			daaWindow:                   10_000,
+			blockRelations:              pruningWindowSizePlusFinalityDepth,
+			daaScores:                   pruningWindowSize,
+			daaAddedBlocks:              int(config.FinalityDepth()),
+			headersSelectedChain:        pruningWindowSize,
+			higherLevelBlockRelations:   200,
+			higherLevelReachabilityData: pruningWindowSizePlusFinalityDepth,
+			higherLevelGHOSTDAGData:     200,
+		}
+	}
+
+	budget := config.CacheMemoryBudgetMiB << 20
+	entries := func(name string, levels int) int {
+		cacheBudget := cacheBudgets[name]
+		size := int(budget / 100 * cacheBudget.share / cacheBudget.entrySize)
+		if levels > 1 {
+			size /= levels
+		}
+		if size < minCacheSize {
+			return minCacheSize
+		}
+		return size
+	}
+
+	// The caches that deletePastBlocks traverses are never made smaller than
+	// the window it traverses, since pruning would otherwise miss the cache
+	// for most of the blocks it deletes. This may take more memory than the
+	// budget allows.
+	pruningWindowEntries := func(name string) int {
+		size := entries(name, 1)
+		if size < pruningWindowSizePlusFinalityDepth {
+			log.Infof("The memory budget allows %d entries in the %s cache, which is less than the %d blocks "+
+				"that pruning traverses. The cache is given %d entries instead.",
+				size, name, pruningWindowSizePlusFinalityDepth, pruningWindowSizePlusFinalityDepth)
+			return pruningWindowSizePlusFinalityDepth
+		}
+		return size
+	}
+
+	// Every block has both a DAA score and DAA added blocks, so the two
+	// caches of the DAA blocks store hold the same number of entries
+	daaBlocks := entries(daaBlocksCacheName, 1)
+	return &cacheSizes{
+		ghostdagData:                entries(ghostdagDataCacheName, 1),
+		blockHeaders:                entries(blockHeadersCacheName, 1),
+		blockStatuses:               pruningWindowEntries(blockStatusesCacheName),
+		reachabilityData:            pruningWindowEntries(reachabilityDataCacheName),
+		utxoDiffs:                   entries(utxoDiffsCacheName, 1),
+		daaWindow:                   entries(daaWindowCacheName, 1),
+		blockRelations:              pruningWindowEntries(blockRelationsCacheName),
+		daaScores:                   daaBlocks,
+		daaAddedBlocks:              daaBlocks,
+		headersSelectedChain:        entries(headersSelectedChainCacheName, 1),
+		higherLevelBlockRelations:   entries(higherLevelBlockRelationsCacheName, config.MaxBlockLevel),
+		higherLevelReachabilityData: entries(higherLevelReachabilityDataCacheName, config.MaxBlockLevel),
+		higherLevelGHOSTDAGData:     entries(higherLevelGHOSTDAGDataCacheName, config.MaxBlockLevel),
+	}
+}
@@ -0,0 +1,112 @@
+package consensus_test
+
+import (
+	"testing"
+
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/testutils"
+)
+
+func TestCacheMemoryBudget(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.CacheMemoryBudgetMiB = 64
+
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestCacheMemoryBudget")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		coinbaseData := &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{},
+			ExtraData:       []byte{},
+		}
+		for i := 0; i < 3; i++ {
+			block, err := tc.BuildBlock(coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			err = tc.ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+		}
+
+		// 64 MiB * 25% / 700 bytes per entry
+		const expectedGHOSTDAGDataCapacity = 23967
+		// 64 MiB * 10% / 20,000 bytes per entry, for each of the two caches
+		const expectedUTXODiffsCapacity = 2 * 335
+
+		// 64 MiB * 5% / 450 bytes per entry, for each of the two caches
+		const expectedDAABlocksCapacity = 2 * 7456
+
+		statsByName := tc.CacheStats()
+		for name, stats := range statsByName {
+			if stats.Name != name {
+				t.Fatalf("the stats of the %s cache are named %s", name, stats.Name)
+			}
+		}
+
+		ghostdagDataStats, ok := statsByName["block-ghostdag-data"]
+		if !ok {
+			t.Fatalf("no stats for the GHOSTDAG data cache")
+		}
+		if ghostdagDataStats.Capacity != expectedGHOSTDAGDataCapacity {
+			t.Fatalf("expected a GHOSTDAG data cache capacity of %d but got %d",
+				expectedGHOSTDAGDataCapacity, ghostdagDataStats.Capacity)
+		}
+		if ghostdagDataStats.Entries == 0 || ghostdagDataStats.Hits == 0 {
+			t.Fatalf("expected the GHOSTDAG data cache to be used, but got %+v", ghostdagDataStats)
+		}
+
+		utxoDiffsStats, ok := statsByName["utxo-diffs"]
+		if !ok {
+			t.Fatalf("no stats for the UTXO diffs cache")
+		}
+		if utxoDiffsStats.Capacity != expectedUTXODiffsCapacity {
+			t.Fatalf("expected a UTXO diffs cache capacity of %d but got %d",
+				expectedUTXODiffsCapacity, utxoDiffsStats.Capacity)
+		}
+
+		daaBlocksStats, ok := statsByName["daa-blocks"]
+		if !ok {
+			t.Fatalf("no stats for the DAA blocks cache")
+		}
+		if daaBlocksStats.Capacity != expectedDAABlocksCapacity {
+			t.Fatalf("expected a DAA blocks cache capacity of %d but got %d",
+				expectedDAABlocksCapacity, daaBlocksStats.Capacity)
+		}
+
+		// 64 MiB * 5% / 64 bytes per entry, unless it's less than the blocks
+		// that pruning traverses
+		expectedBlockStatusesCapacity := 52428
+		pruningWindowSizePlusFinalityDepth := int(consensusConfig.PruningDepth() + consensusConfig.FinalityDepth())
+		if expectedBlockStatusesCapacity < pruningWindowSizePlusFinalityDepth {
+			expectedBlockStatusesCapacity = pruningWindowSizePlusFinalityDepth
+		}
+		blockStatusesStats, ok := statsByName["block-statuses"]
+		if !ok {
+			t.Fatalf("no stats for the block statuses cache")
+		}
+		if blockStatusesStats.Capacity != expectedBlockStatusesCapacity {
+			t.Fatalf("expected a block statuses cache capacity of %d but got %d",
+				expectedBlockStatusesCapacity, blockStatusesStats.Capacity)
+		}
+
+		// 64 MiB * 2% / 700 bytes per entry, split between the levels above 0
+		expectedHigherLevelGHOSTDAGDataCapacity := 1917 / consensusConfig.MaxBlockLevel * consensusConfig.MaxBlockLevel
+		if expectedHigherLevelGHOSTDAGDataCapacity < 100*consensusConfig.MaxBlockLevel {
+			expectedHigherLevelGHOSTDAGDataCapacity = 100 * consensusConfig.MaxBlockLevel
+		}
+		higherLevelGHOSTDAGDataStats, ok := statsByName["higher-level-block-ghostdag-data"]
+		if !ok {
+			t.Fatalf("no stats for the higher level GHOSTDAG data caches")
+		}
+		if higherLevelGHOSTDAGDataStats.Capacity != expectedHigherLevelGHOSTDAGDataCapacity {
+			t.Fatalf("expected a higher level GHOSTDAG data cache capacity of %d but got %d",
+				expectedHigherLevelGHOSTDAGDataCapacity, higherLevelGHOSTDAGDataStats.Capacity)
+		}
+	})
+}
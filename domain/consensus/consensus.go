@@ -23,6 +23,7 @@ type consensus struct {
 	genesisHash  *externalapi.DomainHash
 
 	expectedDAAWindowDurationInMilliseconds int64
+	clock                                   mstime.Clock
 
 	blockProcessor        model.BlockProcessor
 	blockBuilder          model.BlockBuilder
@@ -54,6 +55,7 @@ type consensus struct {
 	headersSelectedTipStore             model.HeaderSelectedTipStore
 	multisetStore                       model.MultisetStore
 	reachabilityDataStore               model.ReachabilityDataStore
+	reachabilityDataStores              []model.ReachabilityDataStore
 	utxoDiffStore                       model.UTXODiffStore
 	finalityStore                       model.FinalityStore
 	headersSelectedChainStore           model.HeadersSelectedChainStore
@@ -198,6 +200,57 @@ func (s *consensus) BuildBlockTemplate(coinbaseData *externalapi.DomainCoinbaseD
 	}, nil
 }
 
+// BuildBlockTemplateOnParents builds a block template with the given parents
+// instead of the virtual's parents. The block contains no transactions other
+// than its coinbase.
+func (s *consensus) BuildBlockTemplateOnParents(parentHashes []*externalapi.DomainHash,
+	coinbaseData *externalapi.DomainCoinbaseData) (*externalapi.DomainBlockTemplate, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(parentHashes) == 0 {
+		return nil, errors.New("at least one parent is required")
+	}
+	stagingArea := model.NewStagingArea()
+	for _, parentHash := range parentHashes {
+		exists, err := s.blockStatusStore.Exists(s.databaseContext, stagingArea, parentHash)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.Errorf("block %s does not exist", parentHash)
+		}
+		blockStatus, err := s.blockStatusStore.Get(s.databaseContext, stagingArea, parentHash)
+		if err != nil {
+			return nil, err
+		}
+		if blockStatus == externalapi.StatusHeaderOnly {
+			return nil, errors.Errorf("block %s is header only", parentHash)
+		}
+		if blockStatus == externalapi.StatusInvalid {
+			return nil, errors.Errorf("block %s is invalid", parentHash)
+		}
+	}
+
+	block, hasRedReward, err := s.blockBuilder.BuildBlockOnParents(parentHashes, coinbaseData)
+	if err != nil {
+		return nil, err
+	}
+
+	isNearlySynced, err := s.isNearlySyncedNoLock()
+	if err != nil {
+		return nil, err
+	}
+
+	return &externalapi.DomainBlockTemplate{
+		Block:                block,
+		CoinbaseData:         coinbaseData,
+		CoinbaseHasRedReward: hasRedReward,
+		IsNearlySynced:       isNearlySynced,
+	}, nil
+}
+
 // ValidateAndInsertBlock validates the given block and, if valid, applies it
 // to the current state
 func (s *consensus) ValidateAndInsertBlock(block *externalapi.DomainBlock, updateVirtual bool) error {
@@ -1010,6 +1063,22 @@ func (s *consensus) ValidatePruningPointProof(pruningPointProof *externalapi.Pru
 	return nil
 }
 
+func (s *consensus) ValidatePruningPointProofWithReport(pruningPointProof *externalapi.PruningPointProof) (
+	*externalapi.PruningPointProofReport, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	log.Infof("Validating the pruning point proof")
+	report, err := s.pruningProofManager.ValidatePruningPointProofWithReport(pruningPointProof)
+	if err != nil {
+		return report, err
+	}
+
+	log.Infof("Done validating the pruning point proof")
+	return report, nil
+}
+
 func (s *consensus) ApplyPruningPointProof(pruningPointProof *externalapi.PruningPointProof) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -1133,7 +1202,7 @@ func (s *consensus) isNearlySyncedNoLock() (bool, error) {
 		return false, err
 	}
 
-	now := mstime.Now().UnixMilliseconds()
+	now := s.clock.Now().UnixMilliseconds()
 	// As a heuristic, we allow the node to mine if he is likely to be within the current DAA window of fully synced nodes.
 	// Such blocks contribute to security by maintaining the current difficulty despite possibly being slightly out of sync.
 	if now-virtualSelectedParentHeader.TimeInMilliseconds() < s.expectedDAAWindowDurationInMilliseconds {
@@ -1146,3 +1215,41 @@ func (s *consensus) isNearlySyncedNoLock() (bool, error) {
 		virtualSelectedParentHeader.TimeInMilliseconds())
 	return false, nil
 }
+
+// CacheStats returns the usage of the caches of the consensus stores that are
+// sized by the memory budget, by the names of the caches
+func (s *consensus) CacheStats() map[string]*externalapi.CacheStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	cacheStats := map[string]*externalapi.CacheStats{
+		ghostdagDataCacheName:         s.ghostdagDataStores[0].CacheStats(),
+		blockHeadersCacheName:         s.blockHeaderStore.CacheStats(),
+		blockStatusesCacheName:        s.blockStatusStore.CacheStats(),
+		reachabilityDataCacheName:     s.reachabilityDataStore.CacheStats(),
+		utxoDiffsCacheName:            s.utxoDiffStore.CacheStats(),
+		daaWindowCacheName:            s.blocksWithTrustedDataDAAWindowStore.CacheStats(),
+		blockRelationsCacheName:       s.blockRelationStores[0].CacheStats(),
+		daaBlocksCacheName:            s.daaBlocksStore.CacheStats(),
+		headersSelectedChainCacheName: s.headersSelectedChainStore.CacheStats(),
+	}
+
+	if len(s.ghostdagDataStores) > 1 {
+		higherLevelBlockRelations := &externalapi.CacheStats{}
+		higherLevelReachabilityData := &externalapi.CacheStats{}
+		higherLevelGHOSTDAGData := &externalapi.CacheStats{}
+		for level := 1; level < len(s.ghostdagDataStores); level++ {
+			higherLevelBlockRelations.Add(s.blockRelationStores[level].CacheStats())
+			higherLevelReachabilityData.Add(s.reachabilityDataStores[level].CacheStats())
+			higherLevelGHOSTDAGData.Add(s.ghostdagDataStores[level].CacheStats())
+		}
+		cacheStats[higherLevelBlockRelationsCacheName] = higherLevelBlockRelations
+		cacheStats[higherLevelReachabilityDataCacheName] = higherLevelReachabilityData
+		cacheStats[higherLevelGHOSTDAGDataCacheName] = higherLevelGHOSTDAGData
+	}
+
+	for name, stats := range cacheStats {
+		stats.Name = name
+	}
+	return cacheStats
+}
@@ -29,6 +29,9 @@ func DomainBlockHeaderToDbBlockHeader(domainBlockHeader externalapi.BlockHeader)
 
 // DbBlockHeaderToDomainBlockHeader converts DbBlockHeader to BlockHeader
 func DbBlockHeaderToDomainBlockHeader(dbBlockHeader *DbBlockHeader) (externalapi.BlockHeader, error) {
+	if dbBlockHeader == nil {
+		return nil, errors.New("DbBlockHeader is nil")
+	}
 	parents, err := DbParentsToDomainParents(dbBlockHeader.Parents)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,52 @@
+package serialization
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func FuzzDbBlockToDomainBlock(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		dbBlock := &DbBlock{}
+		err := proto.Unmarshal(data, dbBlock)
+		if err != nil {
+			return
+		}
+		domainBlock, err := DbBlockToDomainBlock(dbBlock)
+		if err != nil {
+			return
+		}
+
+		// A block that was deserialized successfully must survive a round trip
+		roundTripped, err := DbBlockToDomainBlock(DomainBlockToDbBlock(domainBlock))
+		if err != nil {
+			t.Fatalf("DbBlockToDomainBlock failed on a serialized domain block: %s", err)
+		}
+		if !roundTripped.Equal(domainBlock) {
+			t.Fatalf("The block changed after a serialization round trip")
+		}
+	})
+}
+
+func FuzzDbTransactionToDomainTransaction(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		dbTransaction := &DbTransaction{}
+		err := proto.Unmarshal(data, dbTransaction)
+		if err != nil {
+			return
+		}
+		domainTransaction, err := DbTransactionToDomainTransaction(dbTransaction)
+		if err != nil {
+			return
+		}
+
+		roundTripped, err := DbTransactionToDomainTransaction(DomainTransactionToDbTransaction(domainTransaction))
+		if err != nil {
+			t.Fatalf("DbTransactionToDomainTransaction failed on a serialized domain transaction: %s", err)
+		}
+		if !roundTripped.Equal(domainTransaction) {
+			t.Fatalf("The transaction changed after a serialization round trip")
+		}
+	})
+}
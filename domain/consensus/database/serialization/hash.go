@@ -2,10 +2,14 @@ package serialization
 
 import (
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
 )
 
 // DbHashToDomainHash converts a DbHash to a DomainHash
 func DbHashToDomainHash(dbHash *DbHash) (*externalapi.DomainHash, error) {
+	if dbHash == nil {
+		return nil, errors.New("DbHash is nil")
+	}
 	return externalapi.NewDomainHashFromByteSlice(dbHash.Hash)
 }
 
@@ -2,6 +2,7 @@ package serialization
 
 import (
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
 )
 
 // DomainOutpointToDbOutpoint converts DomainOutpoint to DbOutpoint
@@ -14,6 +15,9 @@ func DomainOutpointToDbOutpoint(domainOutpoint *externalapi.DomainOutpoint) *DbO
 
 // DbOutpointToDomainOutpoint converts DbOutpoint to DomainOutpoint
 func DbOutpointToDomainOutpoint(dbOutpoint *DbOutpoint) (*externalapi.DomainOutpoint, error) {
+	if dbOutpoint == nil {
+		return nil, errors.New("DbOutpoint is nil")
+	}
 	domainTransactionID, err := DbTransactionIDToDomainTransactionID(dbOutpoint.TransactionID)
 	if err != nil {
 		return nil, err
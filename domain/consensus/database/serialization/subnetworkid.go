@@ -3,10 +3,14 @@ package serialization
 import (
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
 	"github.com/ammm56/lings/domain/consensus/utils/subnetworks"
+	"github.com/pkg/errors"
 )
 
 // DbSubnetworkIDToDomainSubnetworkID converts DbSubnetworkId to DomainSubnetworkID
 func DbSubnetworkIDToDomainSubnetworkID(dbSubnetworkID *DbSubnetworkId) (*externalapi.DomainSubnetworkID, error) {
+	if dbSubnetworkID == nil {
+		return nil, errors.New("DbSubnetworkId is nil")
+	}
 	return subnetworks.FromBytes(dbSubnetworkID.SubnetworkId)
 }
 
@@ -3,10 +3,14 @@ package serialization
 import (
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
 	"github.com/ammm56/lings/domain/consensus/utils/transactionid"
+	"github.com/pkg/errors"
 )
 
 // DbTransactionIDToDomainTransactionID converts DbTransactionId to DomainTransactionID
 func DbTransactionIDToDomainTransactionID(dbTransactionID *DbTransactionId) (*externalapi.DomainTransactionID, error) {
+	if dbTransactionID == nil {
+		return nil, errors.New("DbTransactionId is nil")
+	}
 	return transactionid.FromBytes(dbTransactionID.TransactionId)
 }
 
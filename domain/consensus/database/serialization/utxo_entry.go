@@ -15,6 +15,9 @@ func ScriptPublicKeyToDBScriptPublicKey(scriptPublicKey *externalapi.ScriptPubli
 
 // DBScriptPublicKeyToScriptPublicKey convert DbScriptPublicKey ro ScriptPublicKey
 func DBScriptPublicKeyToScriptPublicKey(dbScriptPublicKey *DbScriptPublicKey) (*externalapi.ScriptPublicKey, error) {
+	if dbScriptPublicKey == nil {
+		return nil, errors.New("DbScriptPublicKey is nil")
+	}
 	if dbScriptPublicKey.Version > math.MaxUint16 {
 		return nil, errors.Errorf("The version on ScriptPublicKey is bigger then uint16.")
 	}
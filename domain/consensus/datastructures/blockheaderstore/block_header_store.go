@@ -64,6 +64,11 @@ func (bhs *blockHeaderStore) Stage(stagingArea *model.StagingArea, blockHash *ex
 	stagingShard.toAdd[*blockHash] = blockHeader
 }
 
+// CacheStats returns the usage of the store's cache
+func (bhs *blockHeaderStore) CacheStats() *externalapi.CacheStats {
+	return bhs.cache.Stats()
+}
+
 func (bhs *blockHeaderStore) IsStaged(stagingArea *model.StagingArea) bool {
 	return bhs.stagingShard(stagingArea).isStaged()
 }
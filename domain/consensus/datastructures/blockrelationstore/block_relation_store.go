@@ -33,6 +33,11 @@ func (brs *blockRelationStore) StageBlockRelation(stagingArea *model.StagingArea
 	stagingShard.toAdd[*blockHash] = blockRelations.Clone()
 }
 
+// CacheStats returns the usage of the store's cache
+func (brs *blockRelationStore) CacheStats() *externalapi.CacheStats {
+	return brs.cache.Stats()
+}
+
 func (brs *blockRelationStore) IsStaged(stagingArea *model.StagingArea) bool {
 	return brs.stagingShard(stagingArea).isStaged()
 }
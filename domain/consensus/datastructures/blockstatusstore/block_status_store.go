@@ -33,6 +33,11 @@ func (bss *blockStatusStore) Stage(stagingArea *model.StagingArea, blockHash *ex
 	stagingShard.toAdd[*blockHash] = blockStatus.Clone()
 }
 
+// CacheStats returns the usage of the store's cache
+func (bss *blockStatusStore) CacheStats() *externalapi.CacheStats {
+	return bss.cache.Stats()
+}
+
 func (bss *blockStatusStore) IsStaged(stagingArea *model.StagingArea) bool {
 	return bss.stagingShard(stagingArea).isStaged()
 }
@@ -43,6 +43,14 @@ func (daas *daaBlocksStore) StageBlockDAAAddedBlocks(stagingArea *model.StagingA
 	stagingShard.daaAddedBlocksToAdd[*blockHash] = externalapi.CloneHashes(addedBlocks)
 }
 
+// CacheStats returns the combined usage of the store's DAA score and DAA
+// added blocks caches
+func (daas *daaBlocksStore) CacheStats() *externalapi.CacheStats {
+	stats := daas.daaScoreLRUCache.Stats()
+	stats.Add(daas.daaAddedBlocksLRUCache.Stats())
+	return stats
+}
+
 func (daas *daaBlocksStore) IsStaged(stagingArea *model.StagingArea) bool {
 	return daas.stagingShard(stagingArea).isStaged()
 }
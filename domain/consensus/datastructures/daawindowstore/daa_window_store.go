@@ -85,6 +85,11 @@ func deserializePairBytes(pairBytes []byte) (*externalapi.BlockGHOSTDAGDataHashP
 	return serialization.DbBlockGHOSTDAGDataHashPairToBlockGHOSTDAGDataHashPair(dbPair)
 }
 
+// CacheStats returns the usage of the store's cache
+func (daaws *daaWindowStore) CacheStats() *externalapi.CacheStats {
+	return daaws.cache.Stats()
+}
+
 func (daaws *daaWindowStore) IsStaged(stagingArea *model.StagingArea) bool {
 	return daaws.stagingShard(stagingArea).isStaged()
 }
@@ -39,6 +39,11 @@ func (gds *ghostdagDataStore) Stage(stagingArea *model.StagingArea, blockHash *e
 	stagingShard.toAdd[newKey(blockHash, isTrustedData)] = blockGHOSTDAGData
 }
 
+// CacheStats returns the usage of the store's cache
+func (gds *ghostdagDataStore) CacheStats() *externalapi.CacheStats {
+	return gds.cache.Stats()
+}
+
 func (gds *ghostdagDataStore) IsStaged(stagingArea *model.StagingArea) bool {
 	return gds.stagingShard(stagingArea).isStaged()
 }
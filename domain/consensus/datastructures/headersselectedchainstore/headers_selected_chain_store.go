@@ -40,6 +40,14 @@ func New(prefixBucket model.DBBucket, cacheSize int, preallocate bool) model.Hea
 	}
 }
 
+// CacheStats returns the combined usage of the store's caches by index and by
+// hash
+func (hscs *headersSelectedChainStore) CacheStats() *externalapi.CacheStats {
+	stats := hscs.cacheByIndex.Stats()
+	stats.Add(hscs.cacheByHash.Stats())
+	return stats
+}
+
 // Stage stages the given chain changes
 func (hscs *headersSelectedChainStore) Stage(dbContext model.DBReader, stagingArea *model.StagingArea, chainChanges *externalapi.SelectedChainPath) error {
 	stagingShard := hscs.stagingShard(stagingArea)
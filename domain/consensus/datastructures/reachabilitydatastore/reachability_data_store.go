@@ -69,6 +69,11 @@ func (rds *reachabilityDataStore) StageReachabilityReindexRoot(stagingArea *mode
 	stagingShard.reachabilityReindexRoot = reachabilityReindexRoot
 }
 
+// CacheStats returns the usage of the store's cache
+func (rds *reachabilityDataStore) CacheStats() *externalapi.CacheStats {
+	return rds.reachabilityDataCache.Stats()
+}
+
 func (rds *reachabilityDataStore) IsStaged(stagingArea *model.StagingArea) bool {
 	return rds.stagingShard(stagingArea).isStaged()
 }
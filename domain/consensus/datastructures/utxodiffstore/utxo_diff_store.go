@@ -46,6 +46,14 @@ func (uds *utxoDiffStore) Stage(stagingArea *model.StagingArea, blockHash *exter
 	}
 }
 
+// CacheStats returns the combined usage of the store's UTXO diff and UTXO
+// diff child caches
+func (uds *utxoDiffStore) CacheStats() *externalapi.CacheStats {
+	stats := uds.utxoDiffCache.Stats()
+	stats.Add(uds.utxoDiffChildCache.Stats())
+	return stats
+}
+
 func (uds *utxoDiffStore) IsStaged(stagingArea *model.StagingArea) bool {
 	return uds.stagingShard(stagingArea).isStaged()
 }
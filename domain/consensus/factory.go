@@ -12,6 +12,7 @@ import (
 	"github.com/ammm56/lings/domain/consensus/processes/blockparentbuilder"
 	parentssanager "github.com/ammm56/lings/domain/consensus/processes/parentsmanager"
 	"github.com/ammm56/lings/domain/consensus/processes/pruningproofmanager"
+	"github.com/ammm56/lings/util/mstime"
 	"github.com/ammm56/lings/util/staging"
 	"github.com/pkg/errors"
 
@@ -71,6 +72,13 @@ type Config struct {
 	IsArchival bool
 	// EnableSanityCheckPruningUTXOSet checks the full pruning point utxo set against the commitment at every pruning movement
 	EnableSanityCheckPruningUTXOSet bool
+	// CacheMemoryBudgetMiB is the memory that is shared by the caches of the
+	// stores. If it's 0, the cache sizes are derived from the DAG parameters.
+	CacheMemoryBudgetMiB uint64
+	// Clock is the source of the current time, against which block timestamps
+	// are checked and new blocks are timestamped. If it's nil, the local time
+	// is used.
+	Clock mstime.Clock
 
 	SkipAddingGenesis bool
 }
@@ -117,8 +125,6 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 	dbManager := consensusdatabase.New(db)
 	prefixBucket := consensusdatabase.MakeBucket(dbPrefix.Serialize())
 
-	pruningWindowSizeForCaches := int(config.PruningDepth())
-
 	var preallocateCaches bool
 	if f.preallocateCaches != nil {
 		preallocateCaches = *f.preallocateCaches
@@ -126,37 +132,40 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		preallocateCaches = defaultPreallocateCaches
 	}
 
-	// This is used for caches that are used as part of deletePastBlocks that need to traverse until
-	// the previous pruning point.
-	pruningWindowSizePlusFinalityDepthForCache := int(config.PruningDepth() + config.FinalityDepth())
+	cacheSizes := newCacheSizes(config)
+
+	clock := config.Clock
+	if clock == nil {
+		clock = mstime.SystemClock
+	}
 
 	// Data Structures
 	mergeDepthRootStore := mergedepthrootstore.New(prefixBucket, 200, preallocateCaches)
-	daaWindowStore := daawindowstore.New(prefixBucket, 10_000, preallocateCaches)
+	daaWindowStore := daawindowstore.New(prefixBucket, cacheSizes.daaWindow, preallocateCaches)
 	acceptanceDataStore := acceptancedatastore.New(prefixBucket, 200, preallocateCaches)
 	blockStore, err := blockstore.New(dbManager, prefixBucket, 200, preallocateCaches)
 	if err != nil {
 		return nil, false, err
 	}
-	blockHeaderStore, err := blockheaderstore.New(dbManager, prefixBucket, 10_000, preallocateCaches)
+	blockHeaderStore, err := blockheaderstore.New(dbManager, prefixBucket, cacheSizes.blockHeaders, preallocateCaches)
 	if err != nil {
 		return nil, false, err
 	}
 
-	blockStatusStore := blockstatusstore.New(prefixBucket, pruningWindowSizePlusFinalityDepthForCache, preallocateCaches)
+	blockStatusStore := blockstatusstore.New(prefixBucket, cacheSizes.blockStatuses, preallocateCaches)
 	multisetStore := multisetstore.New(prefixBucket, 200, preallocateCaches)
 	pruningStore := pruningstore.New(prefixBucket, 2, preallocateCaches)
-	utxoDiffStore := utxodiffstore.New(prefixBucket, 200, preallocateCaches)
+	utxoDiffStore := utxodiffstore.New(prefixBucket, cacheSizes.utxoDiffs, preallocateCaches)
 	consensusStateStore := consensusstatestore.New(prefixBucket, 10_000, preallocateCaches)
 
 	headersSelectedTipStore := headersselectedtipstore.New(prefixBucket)
 	finalityStore := finalitystore.New(prefixBucket, 200, preallocateCaches)
-	headersSelectedChainStore := headersselectedchainstore.New(prefixBucket, pruningWindowSizeForCaches, preallocateCaches)
-	daaBlocksStore := daablocksstore.New(prefixBucket, pruningWindowSizeForCaches, int(config.FinalityDepth()), preallocateCaches)
+	headersSelectedChainStore := headersselectedchainstore.New(prefixBucket, cacheSizes.headersSelectedChain, preallocateCaches)
+	daaBlocksStore := daablocksstore.New(prefixBucket, cacheSizes.daaScores, cacheSizes.daaAddedBlocks, preallocateCaches)
 	windowHeapSliceStore := blockwindowheapslicestore.New(2000, preallocateCaches)
 
-	newReachabilityDataStore := reachabilitydatastore.New(prefixBucket, pruningWindowSizePlusFinalityDepthForCache*2, preallocateCaches)
-	blockRelationStores, reachabilityDataStores, ghostdagDataStores := dagStores(config, prefixBucket, pruningWindowSizePlusFinalityDepthForCache, pruningWindowSizeForCaches, preallocateCaches)
+	newReachabilityDataStore := reachabilitydatastore.New(prefixBucket, cacheSizes.reachabilityData, preallocateCaches)
+	blockRelationStores, reachabilityDataStores, ghostdagDataStores := dagStores(config, prefixBucket, cacheSizes, preallocateCaches)
 	oldReachabilityManager := reachabilitymanager.New(
 		dbManager,
 		ghostdagDataStores[0],
@@ -204,7 +213,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		config.MaxBlockLevel,
 	)
 
-	txMassCalculator := txmass.NewCalculator(config.MassPerTxByte, config.MassPerScriptPubKeyByte, config.MassPerSigOp)
+	txMassCalculator := txmass.NewCalculator(config.MassPerTxByte, config.MassPerScriptPubKeyByte, config.MassPerSigOp, config.MassPerSpliceOp)
 
 	pastMedianTimeManager := f.pastMedianTimeConsructor(
 		config.TimestampDeviationTolerance,
@@ -218,6 +227,8 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		config.MaxCoinbasePayloadLength,
 		config.K,
 		config.CoinbasePayloadScriptPublicKeyMaxLength,
+		config.IntrospectionActivationDAAScore,
+		config.SpliceOpcodesActivationDAAScore,
 		dbManager,
 		pastMedianTimeManager,
 		ghostdagDataStore,
@@ -246,6 +257,8 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		config.DeflationaryPhaseDaaScore,
 		config.DeflationaryPhaseBaseSubsidy,
 		config.DeflationaryPhaseCurveFactor,
+		config.DevFeeSchedule,
+		config.Prefix,
 
 		dagTraversalManager,
 		ghostdagDataStore,
@@ -281,6 +294,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		dbManager,
 		config.MaxBlockParents,
 		config.MergeSetSizeLimit,
+		config.MaxBlockMass,
 		genesisHash,
 
 		ghostdagManager,
@@ -351,6 +365,9 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		config.TargetTimePerBlock,
 		config.POWScores,
 		config.MaxBlockLevel,
+		config.DevFeeSchedule,
+		config.Prefix,
+		clock,
 
 		dbManager,
 		difficultyManager,
@@ -399,6 +416,8 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		dbManager,
 		genesisHash,
 		config.POWScores,
+		config.MaxBlockMass,
+		clock,
 
 		difficultyManager,
 		pastMedianTimeManager,
@@ -409,6 +428,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		finalityManager,
 		blockParentBuilder,
 		pruningManager,
+		reachabilityManager,
 
 		acceptanceDataStore,
 		blockRelationStore,
@@ -483,6 +503,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 
 		expectedDAAWindowDurationInMilliseconds: config.TargetTimePerBlock.Milliseconds() *
 			int64(config.DifficultyAdjustmentWindowSize),
+		clock: clock,
 
 		blockProcessor:        blockProcessor,
 		blockBuilder:          blockBuilder,
@@ -510,6 +531,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		ghostdagDataStores:                  ghostdagDataStores,
 		blockStatusStore:                    blockStatusStore,
 		blockRelationStores:                 blockRelationStores,
+		reachabilityDataStores:              reachabilityDataStores,
 		consensusStateStore:                 consensusStateStore,
 		headersSelectedTipStore:             headersSelectedTipStore,
 		multisetStore:                       multisetStore,
@@ -652,28 +674,23 @@ func (f *factory) SetTestPreAllocateCache(preallocateCaches bool) {
 
 func dagStores(config *Config,
 	prefixBucket model.DBBucket,
-	pruningWindowSizePlusFinalityDepthForCache, pruningWindowSizeForCaches int,
+	cacheSizes *cacheSizes,
 	preallocateCaches bool) ([]model.BlockRelationStore, []model.ReachabilityDataStore, []model.GHOSTDAGDataStore) {
 
 	blockRelationStores := make([]model.BlockRelationStore, config.MaxBlockLevel+1)
 	reachabilityDataStores := make([]model.ReachabilityDataStore, config.MaxBlockLevel+1)
 	ghostdagDataStores := make([]model.GHOSTDAGDataStore, config.MaxBlockLevel+1)
 
-	ghostdagDataCacheSize := pruningWindowSizeForCaches * 2
-	if ghostdagDataCacheSize < config.DifficultyAdjustmentWindowSize {
-		ghostdagDataCacheSize = config.DifficultyAdjustmentWindowSize
-	}
-
 	for i := 0; i <= config.MaxBlockLevel; i++ {
 		prefixBucket := prefixBucket.Bucket([]byte{byte(i)})
 		if i == 0 {
-			blockRelationStores[i] = blockrelationstore.New(prefixBucket, pruningWindowSizePlusFinalityDepthForCache, preallocateCaches)
-			reachabilityDataStores[i] = reachabilitydatastore.New(prefixBucket, pruningWindowSizePlusFinalityDepthForCache*2, preallocateCaches)
-			ghostdagDataStores[i] = ghostdagdatastore.New(prefixBucket, ghostdagDataCacheSize, preallocateCaches)
+			blockRelationStores[i] = blockrelationstore.New(prefixBucket, cacheSizes.blockRelations, preallocateCaches)
+			reachabilityDataStores[i] = reachabilitydatastore.New(prefixBucket, cacheSizes.reachabilityData, preallocateCaches)
+			ghostdagDataStores[i] = ghostdagdatastore.New(prefixBucket, cacheSizes.ghostdagData, preallocateCaches)
 		} else {
-			blockRelationStores[i] = blockrelationstore.New(prefixBucket, 200, false)
-			reachabilityDataStores[i] = reachabilitydatastore.New(prefixBucket, pruningWindowSizePlusFinalityDepthForCache, false)
-			ghostdagDataStores[i] = ghostdagdatastore.New(prefixBucket, 200, false)
+			blockRelationStores[i] = blockrelationstore.New(prefixBucket, cacheSizes.higherLevelBlockRelations, false)
+			reachabilityDataStores[i] = reachabilitydatastore.New(prefixBucket, cacheSizes.higherLevelReachabilityData, false)
+			ghostdagDataStores[i] = ghostdagdatastore.New(prefixBucket, cacheSizes.higherLevelGHOSTDAGData, false)
 		}
 	}
 
@@ -0,0 +1,19 @@
+package externalapi
+
+// CacheStats describes the usage of the cache of a consensus store
+type CacheStats struct {
+	Name     string
+	Entries  int
+	Capacity int
+	Hits     uint64
+	Misses   uint64
+}
+
+// Add adds the usage of another cache to the usage of this one, for stores
+// whose usage is reported over several caches
+func (s *CacheStats) Add(other *CacheStats) {
+	s.Entries += other.Entries
+	s.Capacity += other.Capacity
+	s.Hits += other.Hits
+	s.Misses += other.Misses
+}
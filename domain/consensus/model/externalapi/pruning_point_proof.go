@@ -1,6 +1,44 @@
 package externalapi
 
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
 // PruningPointProof is the data structure holding the pruning point proof
 type PruningPointProof struct {
 	Headers [][]BlockHeader
 }
+
+// PruningPointProofReport describes what was found while validating a pruning
+// point proof
+type PruningPointProofReport struct {
+	PruningPoint *DomainHash
+
+	// Levels holds the levels whose headers were all processed, from the
+	// highest level down. A proof that was rejected may miss the level it
+	// was rejected on, and the levels below it.
+	Levels []*PruningPointProofLevelReport
+}
+
+// PruningPointProofLevelReport describes a single level of a pruning point
+// proof. The blue score and blue work are those of the selected tip of the
+// level, as calculated by GHOSTDAG over the headers of the level.
+type PruningPointProofLevelReport struct {
+	Level       int
+	HeaderCount int
+	SelectedTip *DomainHash
+	BlueScore   uint64
+	BlueWork    *big.Int
+}
+
+func (r *PruningPointProofReport) String() string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("Pruning point: %s", r.PruningPoint))
+	for _, level := range r.Levels {
+		builder.WriteString(fmt.Sprintf("\nLevel %d: %d headers, selected tip %s, blue score %d, blue work %s",
+			level.Level, level.HeaderCount, level.SelectedTip, level.BlueScore, level.BlueWork.Text(16)))
+	}
+	return builder.String()
+}
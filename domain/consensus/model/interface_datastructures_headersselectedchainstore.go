@@ -9,4 +9,5 @@ type HeadersSelectedChainStore interface {
 	IsStaged(stagingArea *StagingArea) bool
 	GetIndexByHash(dbContext DBReader, stagingArea *StagingArea, blockHash *externalapi.DomainHash) (uint64, error)
 	GetHashByIndex(dbContext DBReader, stagingArea *StagingArea, index uint64) (*externalapi.DomainHash, error)
+	CacheStats() *externalapi.CacheStats
 }
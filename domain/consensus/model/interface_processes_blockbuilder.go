@@ -6,4 +6,6 @@ import "github.com/ammm56/lings/domain/consensus/model/externalapi"
 type BlockBuilder interface {
 	BuildBlock(coinbaseData *externalapi.DomainCoinbaseData,
 		transactions []*externalapi.DomainTransaction) (block *externalapi.DomainBlock, coinbaseHasRedReward bool, err error)
+	BuildBlockOnParents(parentHashes []*externalapi.DomainHash, coinbaseData *externalapi.DomainCoinbaseData) (
+		block *externalapi.DomainBlock, coinbaseHasRedReward bool, err error)
 }
@@ -6,5 +6,6 @@ import "github.com/ammm56/lings/domain/consensus/model/externalapi"
 type PruningProofManager interface {
 	BuildPruningPointProof(stagingArea *StagingArea) (*externalapi.PruningPointProof, error)
 	ValidatePruningPointProof(pruningPointProof *externalapi.PruningPointProof) error
+	ValidatePruningPointProofWithReport(pruningPointProof *externalapi.PruningPointProof) (*externalapi.PruningPointProofReport, error)
 	ApplyPruningPointProof(pruningPointProof *externalapi.PruningPointProof) error
 }
@@ -13,4 +13,5 @@ type TransactionValidator interface {
 	ValidateTransactionInContextAndPopulateFee(stagingArea *StagingArea,
 		tx *externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error
 	PopulateMass(transaction *externalapi.DomainTransaction)
+	SpliceOpMass(transaction *externalapi.DomainTransaction, povDAAScore uint64) uint64
 }
@@ -21,6 +21,8 @@ type blockBuilder struct {
 	databaseContext model.DBManager
 	genesisHash     *externalapi.DomainHash
 	POWScores       []uint64
+	maxBlockMass    uint64
+	clock           mstime.Clock
 
 	difficultyManager     model.DifficultyManager
 	pastMedianTimeManager model.PastMedianTimeManager
@@ -31,6 +33,7 @@ type blockBuilder struct {
 	finalityManager       model.FinalityManager
 	pruningManager        model.PruningManager
 	blockParentBuilder    model.BlockParentBuilder
+	reachabilityManager   model.ReachabilityManager
 
 	acceptanceDataStore model.AcceptanceDataStore
 	blockRelationStore  model.BlockRelationStore
@@ -44,6 +47,8 @@ func New(
 	databaseContext model.DBManager,
 	genesisHash *externalapi.DomainHash,
 	POWScores []uint64,
+	maxBlockMass uint64,
+	clock mstime.Clock,
 
 	difficultyManager model.DifficultyManager,
 	pastMedianTimeManager model.PastMedianTimeManager,
@@ -54,6 +59,7 @@ func New(
 	finalityManager model.FinalityManager,
 	blockParentBuilder model.BlockParentBuilder,
 	pruningManager model.PruningManager,
+	reachabilityManager model.ReachabilityManager,
 
 	acceptanceDataStore model.AcceptanceDataStore,
 	blockRelationStore model.BlockRelationStore,
@@ -66,6 +72,8 @@ func New(
 		databaseContext: databaseContext,
 		genesisHash:     genesisHash,
 		POWScores:       POWScores,
+		maxBlockMass:    maxBlockMass,
+		clock:           clock,
 
 		difficultyManager:     difficultyManager,
 		pastMedianTimeManager: pastMedianTimeManager,
@@ -76,6 +84,7 @@ func New(
 		finalityManager:       finalityManager,
 		blockParentBuilder:    blockParentBuilder,
 		pruningManager:        pruningManager,
+		reachabilityManager:   reachabilityManager,
 
 		acceptanceDataStore: acceptanceDataStore,
 		blockRelationStore:  blockRelationStore,
@@ -101,10 +110,11 @@ func (bb *blockBuilder) BuildBlock(coinbaseData *externalapi.DomainCoinbaseData,
 func (bb *blockBuilder) buildBlock(stagingArea *model.StagingArea, coinbaseData *externalapi.DomainCoinbaseData,
 	transactions []*externalapi.DomainTransaction) (block *externalapi.DomainBlock, coinbaseHasRedReward bool, err error) {
 
-	err = bb.validateTransactions(stagingArea, transactions)
+	spliceOpMasses, err := bb.validateTransactions(stagingArea, transactions)
 	if err != nil {
 		return nil, false, err
 	}
+	transactions = bb.transactionsWithinMassLimit(transactions, spliceOpMasses)
 
 	newBlockPruningPoint, err := bb.newBlockPruningPoint(stagingArea, model.VirtualBlockHash)
 	if err != nil {
@@ -128,15 +138,21 @@ func (bb *blockBuilder) buildBlock(stagingArea *model.StagingArea, coinbaseData
 }
 
 func (bb *blockBuilder) validateTransactions(stagingArea *model.StagingArea,
-	transactions []*externalapi.DomainTransaction) error {
+	transactions []*externalapi.DomainTransaction) (spliceOpMasses []uint64, err error) {
 
+	daaScore, err := bb.daaBlocksStore.DAAScore(bb.databaseContext, stagingArea, model.VirtualBlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	spliceOpMasses = make([]uint64, len(transactions))
 	invalidTransactions := make([]ruleerrors.InvalidTransaction, 0)
-	for _, transaction := range transactions {
-		err := bb.validateTransaction(stagingArea, transaction)
+	for i, transaction := range transactions {
+		spliceOpMasses[i], err = bb.validateTransaction(stagingArea, transaction, daaScore)
 		if err != nil {
 			ruleError := ruleerrors.RuleError{}
 			if !errors.As(err, &ruleError) {
-				return err
+				return nil, err
 			}
 			invalidTransactions = append(invalidTransactions,
 				ruleerrors.InvalidTransaction{Transaction: transaction, Error: &ruleError})
@@ -144,14 +160,16 @@ func (bb *blockBuilder) validateTransactions(stagingArea *model.StagingArea,
 	}
 
 	if len(invalidTransactions) > 0 {
-		return ruleerrors.NewErrInvalidTransactionsInNewBlock(invalidTransactions)
+		return nil, ruleerrors.NewErrInvalidTransactionsInNewBlock(invalidTransactions)
 	}
 
-	return nil
+	return spliceOpMasses, nil
 }
 
-func (bb *blockBuilder) validateTransaction(
-	stagingArea *model.StagingArea, transaction *externalapi.DomainTransaction) error {
+// validateTransaction validates the given transaction against the virtual and
+// returns the mass that its splice and bitwise operations add to the block
+func (bb *blockBuilder) validateTransaction(stagingArea *model.StagingArea,
+	transaction *externalapi.DomainTransaction, daaScore uint64) (spliceOpMass uint64, err error) {
 
 	originalEntries := make([]externalapi.UTXOEntry, len(transaction.Inputs))
 	for i, input := range transaction.Inputs {
@@ -165,22 +183,50 @@ func (bb *blockBuilder) validateTransaction(
 		}
 	}()
 
-	err := bb.consensusStateManager.PopulateTransactionWithUTXOEntries(stagingArea, transaction)
+	err = bb.consensusStateManager.PopulateTransactionWithUTXOEntries(stagingArea, transaction)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	virtualPastMedianTime, err := bb.pastMedianTimeManager.PastMedianTime(stagingArea, model.VirtualBlockHash)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	err = bb.transactionValidator.ValidateTransactionInContextIgnoringUTXO(stagingArea, transaction, model.VirtualBlockHash, virtualPastMedianTime)
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	err = bb.transactionValidator.ValidateTransactionInContextAndPopulateFee(stagingArea, transaction, model.VirtualBlockHash)
+	if err != nil {
+		return 0, err
 	}
 
-	return bb.transactionValidator.ValidateTransactionInContextAndPopulateFee(stagingArea, transaction, model.VirtualBlockHash)
+	return bb.transactionValidator.SpliceOpMass(transaction, daaScore), nil
+}
+
+// transactionsWithinMassLimit returns the given transactions without the ones
+// that would make the block exceed the mass limit once the mass of their
+// splice and bitwise operations is added. Block templates select transactions
+// by a mass that doesn't include it, since it depends on the UTXO set.
+func (bb *blockBuilder) transactionsWithinMassLimit(transactions []*externalapi.DomainTransaction,
+	spliceOpMasses []uint64) []*externalapi.DomainTransaction {
+
+	mass := uint64(0)
+	transactionsWithinMassLimit := make([]*externalapi.DomainTransaction, 0, len(transactions))
+	for i, transaction := range transactions {
+		bb.transactionValidator.PopulateMass(transaction)
+		transactionMass := transaction.Mass + spliceOpMasses[i]
+		if mass+transactionMass > bb.maxBlockMass || mass+transactionMass < mass {
+			log.Debugf("Leaving transaction %s out of the new block since it would exceed the mass limit",
+				consensushashing.TransactionID(transaction))
+			continue
+		}
+		mass += transactionMass
+		transactionsWithinMassLimit = append(transactionsWithinMassLimit, transaction)
+	}
+	return transactionsWithinMassLimit
 }
 
 func (bb *blockBuilder) newBlockCoinbaseTransaction(stagingArea *model.StagingArea,
@@ -228,13 +274,7 @@ func (bb *blockBuilder) buildHeader(stagingArea *model.StagingArea, transactions
 		return nil, err
 	}
 
-	// Raise BlockVersion until daaScore is more than powScore
-	var blockVersion uint16 = 1
-	for _, powScore := range bb.POWScores {
-		if daaScore >= powScore {
-			blockVersion += 1
-		}
-	}
+	blockVersion := bb.blockVersion(daaScore)
 	constants.BlockVersion = blockVersion
 
 	return blockheader.NewImmutableBlockHeader(
@@ -268,7 +308,7 @@ func (bb *blockBuilder) newBlockTime(stagingArea *model.StagingArea) (int64, err
 	// timestamp is truncated to a millisecond boundary before comparison since a
 	// block timestamp does not supported a precision greater than one
 	// millisecond.
-	newTimestamp := mstime.Now().UnixMilliseconds()
+	newTimestamp := bb.clock.Now().UnixMilliseconds()
 	minTimestamp, err := bb.minBlockTime(stagingArea, model.VirtualBlockHash)
 	if err != nil {
 		return 0, err
@@ -356,3 +396,15 @@ func (bb *blockBuilder) newBlockBlueScore(stagingArea *model.StagingArea) (uint6
 func (bb *blockBuilder) newBlockPruningPoint(stagingArea *model.StagingArea, blockHash *externalapi.DomainHash) (*externalapi.DomainHash, error) {
 	return bb.pruningManager.ExpectedHeaderPruningPoint(stagingArea, blockHash)
 }
+
+// blockVersion returns the version of blocks with the given DAA score: it's
+// raised by one for every POW score that the DAA score reached
+func (bb *blockBuilder) blockVersion(daaScore uint64) uint16 {
+	var blockVersion uint16 = 1
+	for _, powScore := range bb.POWScores {
+		if daaScore >= powScore {
+			blockVersion++
+		}
+	}
+	return blockVersion
+}
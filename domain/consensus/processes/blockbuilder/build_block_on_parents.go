@@ -0,0 +1,156 @@
+package blockbuilder
+
+import (
+	"sort"
+
+	"github.com/ammm56/lings/domain/consensus/model"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/blockheader"
+	"github.com/ammm56/lings/infrastructure/logger"
+	"github.com/pkg/errors"
+)
+
+// BuildBlockOnParents builds a block with the given parents and coinbaseData.
+// Unlike BuildBlock, the block isn't built over the virtual, so the only
+// transaction it contains is its coinbase.
+func (bb *blockBuilder) BuildBlockOnParents(parentHashes []*externalapi.DomainHash,
+	coinbaseData *externalapi.DomainCoinbaseData) (block *externalapi.DomainBlock, coinbaseHasRedReward bool, err error) {
+
+	onEnd := logger.LogAndMeasureExecutionTime(log, "BuildBlockOnParents")
+	defer onEnd()
+
+	stagingArea := model.NewStagingArea()
+	staged, err := bb.stageBlockOnParents(stagingArea, parentHashes)
+	if err != nil {
+		return nil, false, err
+	}
+	daaScore := staged.daaScore
+
+	coinbase, coinbaseHasRedReward, err := bb.coinbaseManager.ExpectedCoinbaseTransactionForBlockVersion(
+		stagingArea, tempBlockHash, coinbaseData, staged.blockVersion)
+	if err != nil {
+		return nil, false, err
+	}
+	transactions := []*externalapi.DomainTransaction{coinbase}
+
+	pruningPoint, err := bb.newBlockPruningPoint(stagingArea, tempBlockHash)
+	if err != nil {
+		return nil, false, err
+	}
+	parents, err := bb.blockParentBuilder.BuildParents(stagingArea, daaScore, parentHashes)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, blockLevelParents := range parents {
+		sort.Slice(blockLevelParents, func(i, j int) bool {
+			return blockLevelParents[i].Less(blockLevelParents[j])
+		})
+	}
+
+	timeInMilliseconds := bb.clock.Now().UnixMilliseconds()
+	minTimeInMilliseconds, err := bb.minBlockTime(stagingArea, tempBlockHash)
+	if err != nil {
+		return nil, false, err
+	}
+	if timeInMilliseconds < minTimeInMilliseconds {
+		timeInMilliseconds = minTimeInMilliseconds
+	}
+
+	acceptedIDMerkleRoot, err := bb.calculateAcceptedIDMerkleRoot(staged.acceptanceData)
+	if err != nil {
+		return nil, false, err
+	}
+
+	header := blockheader.NewImmutableBlockHeader(
+		staged.blockVersion,
+		parents,
+		bb.newBlockHashMerkleRoot(transactions),
+		acceptedIDMerkleRoot,
+		staged.multiset.Hash(),
+		timeInMilliseconds,
+		staged.bits,
+		0,
+		daaScore,
+		staged.ghostdagData.BlueScore(),
+		staged.ghostdagData.BlueWork(),
+		pruningPoint,
+	)
+
+	block = &externalapi.DomainBlock{
+		Header:       header,
+		Transactions: transactions,
+	}
+	cleanBlockPrefilledFields(block)
+	return block, coinbaseHasRedReward, nil
+}
+
+// stagedBlockOnParents is the data of a block that is built on given parents,
+// as staged under tempBlockHash by stageBlockOnParents
+type stagedBlockOnParents struct {
+	bits           uint32
+	daaScore       uint64
+	blockVersion   uint16
+	ghostdagData   *externalapi.BlockGHOSTDAGData
+	pastUTXO       externalapi.UTXODiff
+	acceptanceData externalapi.AcceptanceData
+	multiset       model.Multiset
+}
+
+// stageBlockOnParents stages a block with the given parents under
+// tempBlockHash, and calculates everything that is needed to build its
+// header and coinbase transaction. The selected parent of the block must
+// not be disqualified from the chain.
+func (bb *blockBuilder) stageBlockOnParents(stagingArea *model.StagingArea, parentHashes []*externalapi.DomainHash) (
+	*stagedBlockOnParents, error) {
+
+	bb.blockRelationStore.StageBlockRelation(stagingArea, tempBlockHash, &model.BlockRelations{Parents: parentHashes})
+
+	err := bb.ghostdagManager.GHOSTDAG(stagingArea, tempBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	bits, err := bb.difficultyManager.StageDAADataAndReturnRequiredDifficulty(stagingArea, tempBlockHash, false)
+	if err != nil {
+		return nil, err
+	}
+	daaScore, err := bb.daaBlocksStore.DAAScore(bb.databaseContext, stagingArea, tempBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	ghostdagData, err := bb.ghostdagDataStore.Get(bb.databaseContext, stagingArea, tempBlockHash, false)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedParentStatus, err := bb.consensusStateManager.ResolveBlockStatus(
+		stagingArea, ghostdagData.SelectedParent(), false)
+	if err != nil {
+		return nil, err
+	}
+	if selectedParentStatus == externalapi.StatusDisqualifiedFromChain {
+		return nil, errors.Errorf("cannot build a block with selected parent %s, which is "+
+			"disqualified from the chain", ghostdagData.SelectedParent())
+	}
+
+	pastUTXO, acceptanceData, multiset, err := bb.consensusStateManager.CalculatePastUTXOAndAcceptanceData(
+		stagingArea, tempBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	bb.acceptanceDataStore.Stage(stagingArea, tempBlockHash, acceptanceData)
+
+	err = bb.reachabilityManager.AddBlock(stagingArea, tempBlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stagedBlockOnParents{
+		bits:           bits,
+		daaScore:       daaScore,
+		blockVersion:   bb.blockVersion(daaScore),
+		ghostdagData:   ghostdagData,
+		pastUTXO:       pastUTXO,
+		acceptanceData: acceptanceData,
+		multiset:       multiset,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package blockbuilder_test
+
+import (
+	"testing"
+
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/testutils"
+)
+
+func TestBuildBlockTemplateOnParents(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		testConsensus, teardown, err := factory.NewTestConsensus(consensusConfig, "TestBuildBlockTemplateOnParents")
+		if err != nil {
+			t.Fatalf("Error initializing consensus for: %+v", err)
+		}
+		defer teardown(false)
+
+		coinbaseData := &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{Script: nil, Version: 0},
+			ExtraData:       nil,
+		}
+		insertBlockOnParents := func(parentHashes ...*externalapi.DomainHash) *externalapi.DomainHash {
+			blockTemplate, err := testConsensus.BuildBlockTemplateOnParents(parentHashes, coinbaseData)
+			if err != nil {
+				t.Fatalf("BuildBlockTemplateOnParents: %+v", err)
+			}
+			err = testConsensus.ValidateAndInsertBlock(blockTemplate.Block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+			return consensushashing.BlockHash(blockTemplate.Block)
+		}
+
+		// Build two chains in each other's anticone, and then merge them
+		genesisHash := consensusConfig.GenesisHash
+		chainA := insertBlockOnParents(genesisHash)
+		chainA = insertBlockOnParents(chainA)
+		chainB := insertBlockOnParents(genesisHash)
+		chainB = insertBlockOnParents(chainB)
+
+		tips, err := testConsensus.Tips()
+		if err != nil {
+			t.Fatalf("Tips: %+v", err)
+		}
+		if len(tips) != 2 {
+			t.Fatalf("expected 2 tips, got %d", len(tips))
+		}
+
+		mergingBlock := insertBlockOnParents(chainA, chainB)
+		parents, _, err := testConsensus.GetBlockRelations(mergingBlock)
+		if err != nil {
+			t.Fatalf("GetBlockRelations: %+v", err)
+		}
+		if len(parents) != 2 {
+			t.Fatalf("expected the merging block to have 2 parents, got %d", len(parents))
+		}
+		virtualSelectedParent, err := testConsensus.GetVirtualSelectedParent()
+		if err != nil {
+			t.Fatalf("GetVirtualSelectedParent: %+v", err)
+		}
+		if !virtualSelectedParent.Equal(mergingBlock) {
+			t.Fatalf("expected the merging block to be the virtual selected parent")
+		}
+
+		_, err = testConsensus.BuildBlockTemplateOnParents(
+			[]*externalapi.DomainHash{externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{0xff})},
+			coinbaseData)
+		if err == nil {
+			t.Fatalf("expected an error when building on a block that doesn't exist")
+		}
+	})
+}
@@ -95,16 +95,9 @@ func (bb *testBlockBuilder) buildUTXOInvalidHeader(stagingArea *model.StagingAre
 		})
 	}
 
-	// Raise BlockVersion until daaScore is more than powScore
-	var version uint16 = 1
-	for _, powScore := range bb.POWScores {
-		if daaScore >= powScore {
-			version = version + 1
-		}
-	}
 	bb.nonceCounter++
 	return blockheader.NewImmutableBlockHeader(
-		version,
+		bb.blockVersion(daaScore),
 		parents,
 		hashMerkleRoot,
 		&externalapi.DomainHash{},
@@ -167,60 +160,19 @@ func (bb *testBlockBuilder) buildBlockWithParents(stagingArea *model.StagingArea
 		}
 	}
 
-	bb.blockRelationStore.StageBlockRelation(stagingArea, tempBlockHash, &model.BlockRelations{Parents: parentHashes})
-
-	err := bb.ghostdagManager.GHOSTDAG(stagingArea, tempBlockHash)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	bits, err := bb.difficultyManager.StageDAADataAndReturnRequiredDifficulty(stagingArea, tempBlockHash, false)
-	if err != nil {
-		return nil, nil, err
-	}
-	daaScore, err := bb.daaBlocksStore.DAAScore(bb.databaseContext, stagingArea, tempBlockHash)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	ghostdagData, err := bb.ghostdagDataStore.Get(bb.databaseContext, stagingArea, tempBlockHash, false)
-	if err != nil {
-		return nil, nil, err
-	}
-	blueWork := ghostdagData.BlueWork()
-	blueScore := ghostdagData.BlueScore()
-
-	selectedParentStatus, err := bb.testConsensus.ConsensusStateManager().ResolveBlockStatus(
-		stagingArea, ghostdagData.SelectedParent(), false)
-	if err != nil {
-		return nil, nil, err
-	}
-	if selectedParentStatus == externalapi.StatusDisqualifiedFromChain {
-		return nil, nil, errors.Errorf("Error building block with selectedParent %s with status DisqualifiedFromChain",
-			ghostdagData.SelectedParent())
-	}
-
-	pastUTXO, acceptanceData, multiset, err :=
-		bb.consensusStateManager.CalculatePastUTXOAndAcceptanceData(stagingArea, tempBlockHash)
+	staged, err := bb.stageBlockOnParents(stagingArea, parentHashes)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	bb.acceptanceDataStore.Stage(stagingArea, tempBlockHash, acceptanceData)
-
 	coinbase, _, err := bb.coinbaseManager.ExpectedCoinbaseTransaction(stagingArea, tempBlockHash, coinbaseData)
 	if err != nil {
 		return nil, nil, err
 	}
 	transactionsWithCoinbase := append([]*externalapi.DomainTransaction{coinbase}, transactions...)
 
-	err = bb.testConsensus.ReachabilityManager().AddBlock(stagingArea, tempBlockHash)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	header, err := bb.buildHeaderWithParents(
-		stagingArea, parentHashes, bits, transactionsWithCoinbase, acceptanceData, multiset, daaScore, blueScore, blueWork)
+	header, err := bb.buildHeaderWithParents(stagingArea, parentHashes, staged.bits, transactionsWithCoinbase,
+		staged.acceptanceData, staged.multiset, staged.daaScore, staged.ghostdagData.BlueScore(), staged.ghostdagData.BlueWork())
 	if err != nil {
 		return nil, nil, err
 	}
@@ -228,7 +180,7 @@ func (bb *testBlockBuilder) buildBlockWithParents(stagingArea *model.StagingArea
 	return &externalapi.DomainBlock{
 		Header:       header,
 		Transactions: transactionsWithCoinbase,
-	}, pastUTXO, nil
+	}, staged.pastUTXO, nil
 }
 
 func (bb *testBlockBuilder) BuildUTXOInvalidHeader(parentHashes []*externalapi.DomainHash) (externalapi.BlockHeader,
@@ -6,11 +6,9 @@ import (
 	"github.com/ammm56/lings/domain/consensus/model"
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
 	"github.com/ammm56/lings/domain/consensus/ruleerrors"
-	"github.com/ammm56/lings/domain/consensus/utils/constants"
 	"github.com/ammm56/lings/domain/consensus/utils/transactionhelper"
 	"github.com/ammm56/lings/domain/consensus/utils/txscript"
 	"github.com/ammm56/lings/domain/consensus/utils/virtual"
-	"github.com/ammm56/lings/domain/dagconfig"
 	"github.com/ammm56/lings/infrastructure/logger"
 	"github.com/pkg/errors"
 )
@@ -210,16 +208,11 @@ func (v *blockValidator) checkCoinbaseSubsidy(
 	return nil
 }
 
-func IsDevFeeOutput(reward uint64, output *externalapi.DomainTransactionOutput) bool {
-	_, address, err := txscript.ExtractScriptPubKeyAddress(output.ScriptPublicKey, &dagconfig.MainnetParams)
-	if err != nil {
-		return false
-	}
-	devFeeAddressInBlock := address.EncodeAddress()
-	isDevFeeAddressEqual := devFeeAddressInBlock == constants.DevFeeAddress
-	devFeeMinQuantity := uint64(float64(constants.DevFeeMin) / 100 * float64(reward))
-	isValueEqual := output.Value >= devFeeMinQuantity
-	return isDevFeeAddressEqual && isValueEqual
+// IsDevFeeOutput returns whether the given output pays at least devFeeMinQuantity to devFeeScriptPublicKey
+func IsDevFeeOutput(output *externalapi.DomainTransactionOutput, devFeeScriptPublicKey *externalapi.ScriptPublicKey,
+	devFeeMinQuantity uint64) bool {
+
+	return output.ScriptPublicKey.Equal(devFeeScriptPublicKey) && output.Value >= devFeeMinQuantity
 }
 
 func (v *blockValidator) checkDevFee(stagingArea *model.StagingArea, blockHash *externalapi.DomainHash) error {
@@ -265,11 +258,32 @@ func (v *blockValidator) checkDevFee(stagingArea *model.StagingArea, blockHash *
 		return nil
 	}
 
-	reward, _ := v.coinbaseManager.CalcBlockSubsidy(stagingArea, blockHash)
+	blockDAAScore, err := v.daaBlocksStore.DAAScore(v.databaseContext, stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	devFeePeriod := v.devFeeSchedule.PeriodAt(blockDAAScore)
+	if devFeePeriod == nil {
+		return nil
+	}
+	devFeeAddress, err := devFeePeriod.DecodeAddress(v.addressPrefix)
+	if err != nil {
+		return err
+	}
+	devFeeScriptPublicKey, err := txscript.PayToAddrScript(devFeeAddress)
+	if err != nil {
+		return err
+	}
+
+	reward, err := v.coinbaseManager.CalcBlockSubsidy(stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	devFeeMinQuantity := uint64(float64(devFeePeriod.MinPercent) / 100 * float64(reward))
 	hasDevFee := false
 	for _, transaction := range block.Transactions {
 		for _, output := range transaction.Outputs {
-			if IsDevFeeOutput(reward, output) {
+			if IsDevFeeOutput(output, devFeeScriptPublicKey, devFeeMinQuantity) {
 				hasDevFee = true
 				break
 			}
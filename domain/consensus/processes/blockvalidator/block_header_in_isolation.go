@@ -7,7 +7,6 @@ import (
 	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
 	"github.com/ammm56/lings/domain/consensus/utils/constants"
 	"github.com/ammm56/lings/infrastructure/logger"
-	"github.com/ammm56/lings/util/mstime"
 	"github.com/pkg/errors"
 )
 
@@ -73,7 +72,7 @@ func (v *blockValidator) checkBlockVersion(header externalapi.BlockHeader) error
 
 func (v *blockValidator) checkBlockTimestampInIsolation(header externalapi.BlockHeader) error {
 	blockTimestamp := header.TimeInMilliseconds()
-	now := mstime.Now().UnixMilliseconds()
+	now := v.clock.Now().UnixMilliseconds()
 	maxCurrentTime := now + int64(v.timestampDeviationTolerance)*v.targetTimePerBlock.Milliseconds()
 	if blockTimestamp > maxCurrentTime {
 		return errors.Wrapf(
@@ -4,6 +4,9 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/util"
+	"github.com/ammm56/lings/util/mstime"
 	"github.com/ammm56/lings/util/txmass"
 
 	"github.com/ammm56/lings/domain/consensus/model"
@@ -26,6 +29,9 @@ type blockValidator struct {
 	targetTimePerBlock          time.Duration
 	POWScores                   []uint64
 	maxBlockLevel               int
+	devFeeSchedule              dagconfig.DevFeeSchedule
+	addressPrefix               util.Bech32Prefix
+	clock                       mstime.Clock
 
 	databaseContext       model.DBReader
 	difficultyManager     model.DifficultyManager
@@ -66,6 +72,9 @@ func New(powMax *big.Int,
 	targetTimePerBlock time.Duration,
 	POWScores []uint64,
 	maxBlockLevel int,
+	devFeeSchedule dagconfig.DevFeeSchedule,
+	addressPrefix util.Bech32Prefix,
+	clock mstime.Clock,
 
 	databaseContext model.DBReader,
 
@@ -106,6 +115,9 @@ func New(powMax *big.Int,
 		maxBlockParents:            maxBlockParents,
 		POWScores:                  POWScores,
 		maxBlockLevel:              maxBlockLevel,
+		devFeeSchedule:             devFeeSchedule,
+		addressPrefix:              addressPrefix,
+		clock:                      clock,
 
 		timestampDeviationTolerance: timestampDeviationTolerance,
 		targetTimePerBlock:          targetTimePerBlock,
@@ -10,6 +10,7 @@ import (
 	"github.com/ammm56/lings/domain/consensus/utils/subnetworks"
 	"github.com/ammm56/lings/domain/consensus/utils/transactionhelper"
 	"github.com/ammm56/lings/domain/consensus/utils/txscript"
+	"github.com/ammm56/lings/domain/dagconfig"
 	"github.com/ammm56/lings/infrastructure/db/database"
 	"github.com/ammm56/lings/util"
 	"github.com/pkg/errors"
@@ -23,6 +24,8 @@ type coinbaseManager struct {
 	deflationaryPhaseDaaScore               uint64
 	deflationaryPhaseBaseSubsidy            uint64
 	deflationaryPhaseCurveFactor            float64
+	devFeeSchedule                          dagconfig.DevFeeSchedule
+	addressPrefix                           util.Bech32Prefix
 
 	databaseContext     model.DBReader
 	dagTraversalManager model.DAGTraversalManager
@@ -37,6 +40,15 @@ type coinbaseManager struct {
 func (c *coinbaseManager) ExpectedCoinbaseTransaction(stagingArea *model.StagingArea, blockHash *externalapi.DomainHash,
 	coinbaseData *externalapi.DomainCoinbaseData) (expectedTransaction *externalapi.DomainTransaction, hasRedReward bool, err error) {
 
+	return c.ExpectedCoinbaseTransactionForBlockVersion(stagingArea, blockHash, coinbaseData, constants.BlockVersion)
+}
+
+// ExpectedCoinbaseTransactionForBlockVersion is like ExpectedCoinbaseTransaction, but builds the coinbase
+// transaction of a block of the given version rather than of the current constants.BlockVersion
+func (c *coinbaseManager) ExpectedCoinbaseTransactionForBlockVersion(stagingArea *model.StagingArea,
+	blockHash *externalapi.DomainHash, coinbaseData *externalapi.DomainCoinbaseData, blockVersion uint16) (
+	expectedTransaction *externalapi.DomainTransaction, hasRedReward bool, err error) {
+
 	ghostdagData, err := c.ghostdagDataStore.Get(c.databaseContext, stagingArea, blockHash, true)
 	if !database.IsNotFoundError(err) && err != nil {
 		return nil, false, err
@@ -62,7 +74,7 @@ func (c *coinbaseManager) ExpectedCoinbaseTransaction(stagingArea *model.Staging
 
 	txOuts := make([]*externalapi.DomainTransactionOutput, 0, len(ghostdagData.MergeSetBlues()))
 	acceptanceDataMap := acceptanceDataFromArrayToMap(acceptanceData)
-	if constants.BlockVersion == 1 {
+	if blockVersion == 1 {
 		for _, blue := range ghostdagData.MergeSetBlues() {
 			txOut, hasReward, err := c.coinbaseOutputForBlueBlockV1(stagingArea, blue, acceptanceDataMap[*blue], daaAddedBlocksSet)
 			if err != nil {
@@ -83,28 +95,38 @@ func (c *coinbaseManager) ExpectedCoinbaseTransaction(stagingArea *model.Staging
 		if hasRedReward {
 			txOuts = append(txOuts, txOut)
 		}
-	} else if constants.BlockVersion == 2 {
+	} else if blockVersion == 2 {
+		devFeeScriptPublicKey, devFeePercent, err := c.devFee(stagingArea, blockHash)
+		if err != nil {
+			return nil, false, err
+		}
+
 		for _, blue := range ghostdagData.MergeSetBlues() {
-			txOut, devTx, hasReward, err := c.coinbaseOutputForBlueBlockV2(stagingArea, blue, acceptanceDataMap[*blue], daaAddedBlocksSet)
+			txOut, devTx, hasReward, err := c.coinbaseOutputForBlueBlockV2(stagingArea, blue, acceptanceDataMap[*blue],
+				daaAddedBlocksSet, devFeeScriptPublicKey, devFeePercent)
 			if err != nil {
 				return nil, false, err
 			}
 
 			if hasReward {
 				txOuts = append(txOuts, txOut)
-				txOuts = append(txOuts, devTx)
+				if devTx != nil {
+					txOuts = append(txOuts, devTx)
+				}
 			}
 		}
 
 		txOut, devTx, hasRedReward, err := c.coinbaseOutputForRewardFromRedBlocksV2(
-			stagingArea, ghostdagData, acceptanceData, daaAddedBlocksSet, coinbaseData)
+			stagingArea, ghostdagData, acceptanceData, daaAddedBlocksSet, coinbaseData, devFeeScriptPublicKey, devFeePercent)
 		if err != nil {
 			return nil, false, err
 		}
 
 		if hasRedReward {
 			txOuts = append(txOuts, txOut)
-			txOuts = append(txOuts, devTx)
+			if devTx != nil {
+				txOuts = append(txOuts, devTx)
+			}
 		}
 	}
 
@@ -141,27 +163,62 @@ func (c *coinbaseManager) daaAddedBlocksSet(stagingArea *model.StagingArea, bloc
 	return hashset.NewFromSlice(daaAddedBlocks...), nil
 }
 
-// coinbaseOutputForBlueBlock calculates the output that should go into the coinbase transaction of blueBlock
-// If blueBlock gets no fee - returns nil for txOut
-func (c *coinbaseManager) coinbaseOutputForBlueBlockV2(stagingArea *model.StagingArea,
-	blueBlock *externalapi.DomainHash, blockAcceptanceData *externalapi.BlockAcceptanceData,
-	mergingBlockDAAAddedBlocksSet hashset.HashSet) (*externalapi.DomainTransactionOutput, *externalapi.DomainTransactionOutput, bool, error) {
+// devFee returns the script public key that the dev fee of the coinbase transaction of the given block is paid
+// to, and the percentage of the reward that it gets. If the block pays no dev fee, it returns a nil script public
+// key.
+func (c *coinbaseManager) devFee(stagingArea *model.StagingArea, blockHash *externalapi.DomainHash) (
+	*externalapi.ScriptPublicKey, uint64, error) {
 
-	blockReward, err := c.calcMergedBlockReward(stagingArea, blueBlock, blockAcceptanceData, mergingBlockDAAAddedBlocksSet)
+	blockDAAScore, err := c.daaBlocksStore.DAAScore(c.databaseContext, stagingArea, blockHash)
 	if err != nil {
-		return nil, nil, false, err
+		return nil, 0, err
+	}
+	devFeePeriod := c.devFeeSchedule.PeriodAt(blockDAAScore)
+	if devFeePeriod == nil {
+		return nil, 0, nil
 	}
 
-	devFeeDecodedAddress, err := util.DecodeAddress(constants.DevFeeAddress, util.Bech32PrefixLings)
+	devFeeAddress, err := devFeePeriod.DecodeAddress(c.addressPrefix)
 	if err != nil {
-		return nil, nil, false, err
+		return nil, 0, err
+	}
+	devFeeScriptPublicKey, err := txscript.PayToAddrScript(devFeeAddress)
+	if err != nil {
+		return nil, 0, err
+	}
+	return devFeeScriptPublicKey, devFeePeriod.Percent, nil
+}
+
+// devFeeOutput returns the dev fee output for the given reward and the part of the reward that remains. If no dev
+// fee is paid, it returns a nil output.
+func devFeeOutput(reward uint64, devFeeScriptPublicKey *externalapi.ScriptPublicKey, devFeePercent uint64) (
+	*externalapi.DomainTransactionOutput, uint64) {
+
+	if devFeeScriptPublicKey == nil {
+		return nil, reward
+	}
+	devFeeQuantity := uint64(float64(devFeePercent) / 100 * float64(reward))
+	devTx := &externalapi.DomainTransactionOutput{
+		Value:           devFeeQuantity,
+		ScriptPublicKey: devFeeScriptPublicKey,
 	}
-	devFeeScriptPublicKey, err := txscript.PayToAddrScript(devFeeDecodedAddress)
+	return devTx, reward - devFeeQuantity
+}
+
+// coinbaseOutputForBlueBlock calculates the output that should go into the coinbase transaction of blueBlock,
+// and its dev fee output, which is nil if no dev fee is paid.
+// If blueBlock gets no fee - returns nil for txOut
+func (c *coinbaseManager) coinbaseOutputForBlueBlockV2(stagingArea *model.StagingArea,
+	blueBlock *externalapi.DomainHash, blockAcceptanceData *externalapi.BlockAcceptanceData,
+	mergingBlockDAAAddedBlocksSet hashset.HashSet, devFeeScriptPublicKey *externalapi.ScriptPublicKey,
+	devFeePercent uint64) (*externalapi.DomainTransactionOutput, *externalapi.DomainTransactionOutput, bool, error) {
+
+	blockReward, err := c.calcMergedBlockReward(stagingArea, blueBlock, blockAcceptanceData, mergingBlockDAAAddedBlocksSet)
 	if err != nil {
 		return nil, nil, false, err
 	}
-	devFeeQuantity := uint64(float64(constants.DevFee) / 100 * float64(blockReward))
-	blockReward -= devFeeQuantity
+
+	devTx, blockReward := devFeeOutput(blockReward, devFeeScriptPublicKey, devFeePercent)
 	if blockReward <= 0 {
 		return nil, nil, false, nil
 	}
@@ -177,11 +234,6 @@ func (c *coinbaseManager) coinbaseOutputForBlueBlockV2(stagingArea *model.Stagin
 		ScriptPublicKey: coinbaseData.ScriptPublicKey,
 	}
 
-	devTx := &externalapi.DomainTransactionOutput{
-		Value:           devFeeQuantity,
-		ScriptPublicKey: devFeeScriptPublicKey,
-	}
-
 	return txOut, devTx, true, nil
 }
 
@@ -214,7 +266,8 @@ func (c *coinbaseManager) coinbaseOutputForBlueBlockV1(stagingArea *model.Stagin
 
 func (c *coinbaseManager) coinbaseOutputForRewardFromRedBlocksV2(stagingArea *model.StagingArea,
 	ghostdagData *externalapi.BlockGHOSTDAGData, acceptanceData externalapi.AcceptanceData, daaAddedBlocksSet hashset.HashSet,
-	coinbaseData *externalapi.DomainCoinbaseData) (*externalapi.DomainTransactionOutput, *externalapi.DomainTransactionOutput, bool, error) {
+	coinbaseData *externalapi.DomainCoinbaseData, devFeeScriptPublicKey *externalapi.ScriptPublicKey,
+	devFeePercent uint64) (*externalapi.DomainTransactionOutput, *externalapi.DomainTransactionOutput, bool, error) {
 
 	acceptanceDataMap := acceptanceDataFromArrayToMap(acceptanceData)
 	totalReward := uint64(0)
@@ -226,16 +279,7 @@ func (c *coinbaseManager) coinbaseOutputForRewardFromRedBlocksV2(stagingArea *mo
 		totalReward += reward
 	}
 
-	devFeeDecodedAddress, err := util.DecodeAddress(constants.DevFeeAddress, util.Bech32PrefixLings)
-	if err != nil {
-		return nil, nil, false, err
-	}
-	devFeeScriptPublicKey, err := txscript.PayToAddrScript(devFeeDecodedAddress)
-	if err != nil {
-		return nil, nil, false, err
-	}
-	devFeeQuantity := uint64(float64(constants.DevFee) / 100 * float64(totalReward))
-	totalReward -= devFeeQuantity
+	devTx, totalReward := devFeeOutput(totalReward, devFeeScriptPublicKey, devFeePercent)
 	if totalReward <= 0 {
 		return nil, nil, false, nil
 	}
@@ -245,11 +289,6 @@ func (c *coinbaseManager) coinbaseOutputForRewardFromRedBlocksV2(stagingArea *mo
 		ScriptPublicKey: coinbaseData.ScriptPublicKey,
 	}
 
-	devTx := &externalapi.DomainTransactionOutput{
-		Value:           devFeeQuantity,
-		ScriptPublicKey: devFeeScriptPublicKey,
-	}
-
 	return txOut, devTx, true, nil
 }
 
@@ -386,6 +425,8 @@ func New(
 	deflationaryPhaseDaaScore uint64,
 	deflationaryPhaseBaseSubsidy uint64,
 	defaultdeflationaryPhaseCurveFactor float64,
+	devFeeSchedule dagconfig.DevFeeSchedule,
+	addressPrefix util.Bech32Prefix,
 	dagTraversalManager model.DAGTraversalManager,
 	ghostdagDataStore model.GHOSTDAGDataStore,
 	acceptanceDataStore model.AcceptanceDataStore,
@@ -404,6 +445,8 @@ func New(
 		deflationaryPhaseDaaScore:               deflationaryPhaseDaaScore,
 		deflationaryPhaseBaseSubsidy:            deflationaryPhaseBaseSubsidy,
 		deflationaryPhaseCurveFactor:            defaultdeflationaryPhaseCurveFactor,
+		devFeeSchedule:                          devFeeSchedule,
+		addressPrefix:                           addressPrefix,
 
 		dagTraversalManager: dagTraversalManager,
 		ghostdagDataStore:   ghostdagDataStore,
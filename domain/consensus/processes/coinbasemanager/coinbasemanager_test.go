@@ -1,12 +1,16 @@
 package coinbasemanager
 
 import (
+	"bytes"
 	"strconv"
 	"testing"
 
+	"github.com/ammm56/lings/domain/consensus/model"
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
 	"github.com/ammm56/lings/domain/consensus/utils/constants"
+	"github.com/ammm56/lings/domain/consensus/utils/txscript"
 	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/util"
 )
 
 func TestCalcDeflationaryPeriodBlockSubsidy(t *testing.T) {
@@ -25,6 +29,8 @@ func TestCalcDeflationaryPeriodBlockSubsidy(t *testing.T) {
 		deflationaryPhaseBaseSubsidy,
 		deflationaryPhaseCurveFactor,
 		nil,
+		util.Bech32PrefixLings,
+		nil,
 		nil,
 		nil,
 		nil,
@@ -102,6 +108,8 @@ func TestBuildSubsidyTable(t *testing.T) {
 		deflationaryPhaseBaseSubsidy,
 		deflationaryPhaseCurveFactor,
 		nil,
+		util.Bech32PrefixLings,
+		nil,
 		nil,
 		nil,
 		nil,
@@ -131,3 +139,167 @@ func TestBuildSubsidyTable(t *testing.T) {
 	len := len(subsidyTable)
 	t.Logf("Length: %d", len)
 }
+
+// fakeDAABlocksStore returns the DAA scores in daaScores. Its other methods aren't implemented.
+type fakeDAABlocksStore struct {
+	model.DAABlocksStore
+	daaScores map[externalapi.DomainHash]uint64
+}
+
+func (f *fakeDAABlocksStore) DAAScore(_ model.DBReader, _ *model.StagingArea, blockHash *externalapi.DomainHash) (uint64, error) {
+	return f.daaScores[*blockHash], nil
+}
+
+func TestDevFeeSchedule(t *testing.T) {
+	const firstAddress = "lings:qp4ad2eh72xc8dtjjyz4llxzq9utn6k26uyl644xxw70wskdfl85zsksmwldz"
+	const secondAddress = "lings:qqqsqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq7gduuadd"
+	const blockReward = 100 * constants.SompiPerLings
+
+	scriptPublicKey := func(address string) *externalapi.ScriptPublicKey {
+		decodedAddress, err := util.DecodeAddress(address, util.Bech32PrefixLings)
+		if err != nil {
+			t.Fatalf("DecodeAddress: %s", err)
+		}
+		scriptPublicKey, err := txscript.PayToAddrScript(decodedAddress)
+		if err != nil {
+			t.Fatalf("PayToAddrScript: %s", err)
+		}
+		return scriptPublicKey
+	}
+
+	type expectedDevFee struct {
+		daaScore uint64
+		address  string // empty if no dev fee is expected
+		percent  uint64
+	}
+	tests := []struct {
+		name     string
+		schedule dagconfig.DevFeeSchedule
+		expected []expectedDevFee
+	}{
+		{
+			name:     "empty schedule",
+			schedule: nil,
+			expected: []expectedDevFee{{daaScore: 0}, {daaScore: 1000000}},
+		},
+		{
+			name: "single period that never expires",
+			schedule: dagconfig.DevFeeSchedule{
+				{Address: firstAddress, Percent: 5, MinPercent: 1},
+			},
+			expected: []expectedDevFee{
+				{daaScore: 0, address: firstAddress, percent: 5},
+				{daaScore: 1000000, address: firstAddress, percent: 5},
+			},
+		},
+		{
+			name: "period that activates and expires",
+			schedule: dagconfig.DevFeeSchedule{
+				{Address: firstAddress, Percent: 5, MinPercent: 1, ActivationDAAScore: 100, ExpiryDAAScore: 200},
+			},
+			expected: []expectedDevFee{
+				{daaScore: 99},
+				{daaScore: 100, address: firstAddress, percent: 5},
+				{daaScore: 199, address: firstAddress, percent: 5},
+				{daaScore: 200},
+			},
+		},
+		{
+			name: "consecutive periods with a gap",
+			schedule: dagconfig.DevFeeSchedule{
+				{Address: firstAddress, Percent: 5, MinPercent: 1, ExpiryDAAScore: 100},
+				{Address: secondAddress, Percent: 3, MinPercent: 2, ActivationDAAScore: 100, ExpiryDAAScore: 200},
+				{Address: firstAddress, Percent: 1, MinPercent: 1, ActivationDAAScore: 300},
+			},
+			expected: []expectedDevFee{
+				{daaScore: 99, address: firstAddress, percent: 5},
+				{daaScore: 100, address: secondAddress, percent: 3},
+				{daaScore: 250},
+				{daaScore: 300, address: firstAddress, percent: 1},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		err := test.schedule.Validate(util.Bech32PrefixLings)
+		if err != nil {
+			t.Fatalf("%s: Validate: %s", test.name, err)
+		}
+
+		daaBlocksStore := &fakeDAABlocksStore{daaScores: make(map[externalapi.DomainHash]uint64)}
+		coinbaseManagerInstance := New(nil, 0, 0, 0, &externalapi.DomainHash{}, 0, 0, 0,
+			test.schedule, util.Bech32PrefixLings,
+			nil, nil, nil, daaBlocksStore, nil, nil, nil).(*coinbaseManager)
+
+		for i, expected := range test.expected {
+			blockHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{byte(i)})
+			daaBlocksStore.daaScores[*blockHash] = expected.daaScore
+
+			devFeeScriptPublicKey, devFeePercent, err := coinbaseManagerInstance.devFee(nil, blockHash)
+			if err != nil {
+				t.Fatalf("%s: devFee: %s", test.name, err)
+			}
+			devTx, remainingReward := devFeeOutput(blockReward, devFeeScriptPublicKey, devFeePercent)
+
+			if expected.address == "" {
+				if devTx != nil {
+					t.Errorf("%s: unexpected dev fee output at DAA score %d", test.name, expected.daaScore)
+				}
+				if remainingReward != blockReward {
+					t.Errorf("%s: got remaining reward %d at DAA score %d, want %d",
+						test.name, remainingReward, expected.daaScore, uint64(blockReward))
+				}
+				continue
+			}
+
+			if devTx == nil {
+				t.Fatalf("%s: missing dev fee output at DAA score %d", test.name, expected.daaScore)
+			}
+			if !devTx.ScriptPublicKey.Equal(scriptPublicKey(expected.address)) {
+				t.Errorf("%s: dev fee at DAA score %d isn't paid to %s", test.name, expected.daaScore, expected.address)
+			}
+			expectedDevFeeValue := blockReward * expected.percent / 100
+			if devTx.Value != expectedDevFeeValue {
+				t.Errorf("%s: got dev fee %d at DAA score %d, want %d",
+					test.name, devTx.Value, expected.daaScore, expectedDevFeeValue)
+			}
+			if devTx.Value+remainingReward != blockReward {
+				t.Errorf("%s: the dev fee and the remaining reward at DAA score %d don't add up to the reward",
+					test.name, expected.daaScore)
+			}
+		}
+	}
+}
+
+// TestMainnetDevFeeScriptPublicKey pins the script public key that mainnet
+// blocks pay the dev fee to, so that any change of the mainnet dev fee
+// address is a deliberate one.
+func TestMainnetDevFeeScriptPublicKey(t *testing.T) {
+	expectedScript := []byte{
+		0x20, // OpData32
+		0x6b, 0xd6, 0xab, 0x37, 0xf2, 0x8d, 0x83, 0xb5,
+		0x72, 0x91, 0x05, 0x5f, 0xfc, 0xc2, 0x01, 0x78,
+		0xb9, 0xea, 0xca, 0xd7, 0x09, 0xfd, 0x56, 0xa6,
+		0x33, 0xbc, 0xf7, 0x42, 0xcd, 0x4f, 0xcf, 0x41,
+		0xac, // OpCheckSig
+	}
+
+	period := dagconfig.MainnetParams.DevFeeSchedule.PeriodAt(0)
+	if period == nil {
+		t.Fatalf("Mainnet doesn't pay a dev fee at DAA score 0")
+	}
+	address, err := period.DecodeAddress(dagconfig.MainnetParams.Prefix)
+	if err != nil {
+		t.Fatalf("DecodeAddress: %s", err)
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %s", err)
+	}
+	if scriptPublicKey.Version != 0 {
+		t.Errorf("Expected script public key version 0, but got %d", scriptPublicKey.Version)
+	}
+	if !bytes.Equal(scriptPublicKey.Script, expectedScript) {
+		t.Errorf("Expected the mainnet dev fee script %x, but got %x", expectedScript, scriptPublicKey.Script)
+	}
+}
@@ -20,10 +20,3 @@ func (csm *testConsensusStateManager) AddUTXOToMultiset(
 
 	return addUTXOToMultiset(multiset, entry, outpoint)
 }
-
-func (csm *testConsensusStateManager) ResolveBlockStatus(stagingArea *model.StagingArea, blockHash *externalapi.DomainHash,
-	useSeparateStagingAreaPerBlock bool) (externalapi.BlockStatus, error) {
-
-	status, _, err := csm.resolveBlockStatus(stagingArea, blockHash, useSeparateStagingAreaPerBlock)
-	return status, err
-}
@@ -68,6 +68,15 @@ func (csm *consensusStateManager) validateBlockTransactionsAgainstPastUTXO(stagi
 	}
 	log.Tracef("The past median time of %s is %d", blockHash, selectedParentMedianTime)
 
+	daaScore, err := csm.daaBlocksStore.DAAScore(csm.databaseContext, stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+
+	// The mass of the splice and bitwise operations depends on the script
+	// public keys the transactions spend, so unlike the rest of the block
+	// mass it can only be checked once their UTXO entries are populated
+	mass := uint64(0)
 	for i, transaction := range block.Transactions {
 		transactionID := consensushashing.TransactionID(transaction)
 		log.Tracef("Validating transaction %s in block %s against "+
@@ -91,6 +100,14 @@ func (csm *consensusStateManager) validateBlockTransactionsAgainstPastUTXO(stagi
 		}
 		log.Tracef("Validation against the block's past UTXO "+
 			"passed for transaction %s in block %s", transactionID, blockHash)
+
+		csm.transactionValidator.PopulateMass(transaction)
+		massBefore := mass
+		mass += transaction.Mass + csm.transactionValidator.SpliceOpMass(transaction, daaScore)
+		if mass > csm.maxBlockMass || mass < massBefore {
+			return errors.Wrapf(ruleerrors.ErrBlockMassTooHigh, "block %s exceeded the mass limit of %d "+
+				"including the mass of its splice and bitwise operations", blockHash, csm.maxBlockMass)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,482 @@
+package ghostdagmanager_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ammm56/lings/domain/consensus/model"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/processes/ghostdag2"
+	"github.com/ammm56/lings/domain/consensus/processes/ghostdagmanager"
+	"github.com/ammm56/lings/domain/consensus/utils/blockheader"
+	"github.com/ammm56/lings/domain/consensus/utils/constants"
+	"github.com/pkg/errors"
+)
+
+const (
+	differentialMaxK       = 10
+	differentialMaxWidth   = 16
+	differentialMaxParents = 5
+	differentialMaxBlocks  = 256
+)
+
+// differentialBits are the difficulties a block in a differentialDAG may have.
+// Mixing them makes blue work diverge from blue score, so that selected parent
+// selection by blue work gets exercised too.
+var differentialBits = []uint32{0x207fffff, 0x1f7fffff, 0x1e7fffff}
+
+// differentialDAG is a DAG on which the GHOSTDAG implementations are compared.
+// Block 0 is genesis, and the parents of every other block precede it.
+type differentialDAG struct {
+	k       externalapi.KType
+	parents [][]int
+	bits    []uint32
+	ids     []uint64
+	pasts   []*big.Int
+}
+
+func newDifferentialDAG(k externalapi.KType) *differentialDAG {
+	dag := &differentialDAG{k: k}
+	dag.addBlockWithID(nil, differentialBits[0], 0)
+	return dag
+}
+
+// differentialDAGFromBytes deterministically builds a DAG out of arbitrary
+// bytes. The first two bytes select K and how far back parents may be picked
+// from, and each block then takes a byte selecting its parent count and
+// difficulty, followed by a byte per parent.
+func differentialDAGFromBytes(data []byte) *differentialDAG {
+	next := func() byte {
+		if len(data) == 0 {
+			return 0
+		}
+		b := data[0]
+		data = data[1:]
+		return b
+	}
+
+	dag := newDifferentialDAG(externalapi.KType(1 + next()%differentialMaxK))
+	width := 1 + int(next()%differentialMaxWidth)
+	for len(data) > 0 && dag.blockCount() < differentialMaxBlocks {
+		b := next()
+		parentCount := 1 + int(b%differentialMaxParents)
+		bits := differentialBits[int(b/differentialMaxParents)%len(differentialBits)]
+
+		candidateCount := width
+		if candidateCount > dag.blockCount() {
+			candidateCount = dag.blockCount()
+		}
+		parents := make([]int, parentCount)
+		for i := range parents {
+			parents[i] = dag.blockCount() - 1 - int(next())%candidateCount
+		}
+		dag.addBlock(parents, bits)
+	}
+	return dag
+}
+
+func randomDifferentialDAGBytes(random *rand.Rand) []byte {
+	data := make([]byte, 2+random.Intn(differentialMaxBlocks*2))
+	random.Read(data)
+	return data
+}
+
+func (dag *differentialDAG) blockCount() int {
+	return len(dag.parents)
+}
+
+func (dag *differentialDAG) addBlock(parents []int, bits uint32) {
+	dag.addBlockWithID(parents, bits, splitMix64(uint64(dag.blockCount())))
+}
+
+// addBlockWithID adds a block with the given parents, dropping duplicate
+// parents and parents that are in the past of other parents, the same way
+// a valid block's parents are an antichain
+func (dag *differentialDAG) addBlockWithID(parents []int, bits uint32, id uint64) {
+	past := new(big.Int)
+	for _, parent := range parents {
+		past.SetBit(past, parent, 1)
+		past.Or(past, dag.pasts[parent])
+	}
+
+	antichain := make([]int, 0, len(parents))
+	for _, parent := range parents {
+		isDuplicate := false
+		for _, added := range antichain {
+			if added == parent {
+				isDuplicate = true
+			}
+		}
+		isInPastOfOtherParent := false
+		for _, other := range parents {
+			if dag.pasts[other].Bit(parent) == 1 {
+				isInPastOfOtherParent = true
+			}
+		}
+		if !isDuplicate && !isInPastOfOtherParent {
+			antichain = append(antichain, parent)
+		}
+	}
+	sort.Ints(antichain)
+
+	dag.parents = append(dag.parents, antichain)
+	dag.bits = append(dag.bits, bits)
+	dag.ids = append(dag.ids, id)
+	dag.pasts = append(dag.pasts, past)
+}
+
+// rebuild returns a copy of the DAG with K set to the given value and with
+// every block's parents replaced by mapParents. Blocks for which mapParents
+// returns nil are removed, and indices passed to mapParents and returned by it
+// refer to the original DAG.
+func (dag *differentialDAG) rebuild(k externalapi.KType, mapParents func(block int) []int) *differentialDAG {
+	rebuilt := newDifferentialDAG(k)
+	newIndices := map[int]int{0: 0}
+	for block := 1; block < dag.blockCount(); block++ {
+		parents := mapParents(block)
+		if parents == nil {
+			continue
+		}
+		newParents := make([]int, len(parents))
+		for i, parent := range parents {
+			newParents[i] = newIndices[parent]
+		}
+		newIndices[block] = rebuilt.blockCount()
+		rebuilt.addBlockWithID(newParents, dag.bits[block], dag.ids[block])
+	}
+	return rebuilt
+}
+
+// withoutBlock returns a copy of the DAG without the given block. Its children
+// inherit its parents so that the rest of the DAG keeps its shape.
+func (dag *differentialDAG) withoutBlock(removed int) *differentialDAG {
+	return dag.rebuild(dag.k, func(block int) []int {
+		if block == removed {
+			return nil
+		}
+		parents := make([]int, 0, len(dag.parents[block]))
+		for _, parent := range dag.parents[block] {
+			if parent == removed {
+				parents = append(parents, dag.parents[removed]...)
+			} else {
+				parents = append(parents, parent)
+			}
+		}
+		return parents
+	})
+}
+
+func (dag *differentialDAG) withoutParent(child int, removedParentIndex int) *differentialDAG {
+	return dag.rebuild(dag.k, func(block int) []int {
+		if block != child {
+			return dag.parents[block]
+		}
+		parents := make([]int, 0, len(dag.parents[block])-1)
+		parents = append(parents, dag.parents[block][:removedParentIndex]...)
+		return append(parents, dag.parents[block][removedParentIndex+1:]...)
+	})
+}
+
+func (dag *differentialDAG) withK(k externalapi.KType) *differentialDAG {
+	return dag.rebuild(k, func(block int) []int {
+		return dag.parents[block]
+	})
+}
+
+func (dag *differentialDAG) hash(block int) *externalapi.DomainHash {
+	var hashArray [externalapi.DomainHashSize]byte
+	binary.BigEndian.PutUint64(hashArray[:], dag.ids[block])
+	binary.BigEndian.PutUint64(hashArray[externalapi.DomainHashSize-8:], uint64(block))
+	return externalapi.NewDomainHashFromByteArray(&hashArray)
+}
+
+func (dag *differentialDAG) blockName(block int) string {
+	if block == 0 {
+		return "G"
+	}
+	return fmt.Sprintf("B%d", block)
+}
+
+// String returns the DAG in a form that is short enough to serve as a reproducer
+func (dag *differentialDAG) String() string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "K=%d\n", dag.k)
+	for block := 1; block < dag.blockCount(); block++ {
+		parentNames := make([]string, len(dag.parents[block]))
+		for i, parent := range dag.parents[block] {
+			parentNames[i] = dag.blockName(parent)
+		}
+		fmt.Fprintf(builder, "%s <- %s (bits %08x, id %016x)\n",
+			dag.blockName(block), strings.Join(parentNames, " "), dag.bits[block], dag.ids[block])
+	}
+	return builder.String()
+}
+
+// differentialDAGTopology answers topology queries for a differentialDAG.
+// It behaves like DAGTopologyManagerImpl, including not treating a block as
+// its own ancestor (which ghostdag2's k-cluster checks rely on), but uses
+// precomputed pasts to keep large random DAGs fast.
+type differentialDAGTopology struct {
+	*DAGTopologyManagerImpl
+	dag     *differentialDAG
+	indices map[externalapi.DomainHash]int
+}
+
+func (dt *differentialDAGTopology) index(blockHash *externalapi.DomainHash) (int, error) {
+	block, ok := dt.indices[*blockHash]
+	if !ok {
+		return 0, errors.Errorf("block %s is not in the DAG", blockHash)
+	}
+	return block, nil
+}
+
+func (dt *differentialDAGTopology) Parents(_ *model.StagingArea, blockHash *externalapi.DomainHash) ([]*externalapi.DomainHash, error) {
+	block, err := dt.index(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	parents := make([]*externalapi.DomainHash, len(dt.dag.parents[block]))
+	for i, parent := range dt.dag.parents[block] {
+		parents[i] = dt.dag.hash(parent)
+	}
+	return parents, nil
+}
+
+func (dt *differentialDAGTopology) IsAncestorOf(_ *model.StagingArea, blockHashA *externalapi.DomainHash,
+	blockHashB *externalapi.DomainHash) (bool, error) {
+
+	blockA, err := dt.index(blockHashA)
+	if err != nil {
+		return false, err
+	}
+	blockB, err := dt.index(blockHashB)
+	if err != nil {
+		return false, err
+	}
+	return dt.dag.pasts[blockB].Bit(blockA) == 1, nil
+}
+
+// runGHOSTDAG runs the given GHOSTDAG implementation on every block of the DAG
+// and returns their GHOSTDAG data by block index. Panics are returned as errors
+// so that they can be minimized like any other divergence.
+func (dag *differentialDAG) runGHOSTDAG(factory implManager) (ghostdagData []*externalapi.BlockGHOSTDAGData, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("%s panicked: %v", factory.implName, r)
+		}
+	}()
+
+	topology := &differentialDAGTopology{
+		DAGTopologyManagerImpl: &DAGTopologyManagerImpl{},
+		dag:                    dag,
+		indices:                make(map[externalapi.DomainHash]int, dag.blockCount()),
+	}
+	ghostdagDataStore := &GHOSTDAGDataStoreImpl{
+		dagMap: make(map[externalapi.DomainHash]*externalapi.BlockGHOSTDAGData, dag.blockCount()),
+	}
+	blockHeadersStore := &blockHeadersStore{
+		dagMap: make(map[externalapi.DomainHash]externalapi.BlockHeader, dag.blockCount()),
+	}
+
+	for block := 0; block < dag.blockCount(); block++ {
+		topology.indices[*dag.hash(block)] = block
+		blockHeadersStore.dagMap[*dag.hash(block)] = blockheader.NewImmutableBlockHeader(
+			constants.BlockVersion,
+			nil,
+			nil,
+			nil,
+			nil,
+			0,
+			dag.bits[block],
+			0,
+			0,
+			0,
+			big.NewInt(0),
+			nil,
+		)
+	}
+	genesisHash := dag.hash(0)
+	ghostdagDataStore.dagMap[*genesisHash] = externalapi.NewBlockGHOSTDAGData(0, new(big.Int), nil, nil, nil, nil)
+
+	manager := factory.function(nil, topology, ghostdagDataStore, blockHeadersStore, dag.k, genesisHash)
+	ghostdagData = make([]*externalapi.BlockGHOSTDAGData, dag.blockCount())
+	for block := 1; block < dag.blockCount(); block++ {
+		err := manager.GHOSTDAG(nil, dag.hash(block))
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s failed on block %s", factory.implName, dag.blockName(block))
+		}
+		ghostdagData[block], err = ghostdagDataStore.Get(nil, nil, dag.hash(block), false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ghostdagData, nil
+}
+
+// divergence runs both GHOSTDAG implementations on the DAG and describes
+// the first difference between their results, or returns an empty string if
+// they agree on every block.
+func (dag *differentialDAG) divergence() string {
+	original, originalErr := dag.runGHOSTDAG(implManager{ghostdagmanager.New, "Original"})
+	alternative, alternativeErr := dag.runGHOSTDAG(implManager{ghostdag2.New, "Tal's impl"})
+	if originalErr != nil || alternativeErr != nil {
+		if originalErr != nil && alternativeErr != nil {
+			return ""
+		}
+		return fmt.Sprintf("errors differ: %v vs %v", originalErr, alternativeErr)
+	}
+
+	names := func(blockHashes []*externalapi.DomainHash) []string {
+		blockNames := make([]string, len(blockHashes))
+		indices := make(map[externalapi.DomainHash]int, dag.blockCount())
+		for block := 0; block < dag.blockCount(); block++ {
+			indices[*dag.hash(block)] = block
+		}
+		for i, blockHash := range blockHashes {
+			blockNames[i] = dag.blockName(indices[*blockHash])
+		}
+		sort.Strings(blockNames)
+		return blockNames
+	}
+
+	for block := 1; block < dag.blockCount(); block++ {
+		a, b := original[block], alternative[block]
+		name := dag.blockName(block)
+		switch {
+		case !a.SelectedParent().Equal(b.SelectedParent()):
+			return fmt.Sprintf("%s: selected parent %v vs %v", name,
+				names([]*externalapi.DomainHash{a.SelectedParent()}), names([]*externalapi.DomainHash{b.SelectedParent()}))
+		case a.BlueScore() != b.BlueScore():
+			return fmt.Sprintf("%s: blue score %d vs %d", name, a.BlueScore(), b.BlueScore())
+		case a.BlueWork().Cmp(b.BlueWork()) != 0:
+			return fmt.Sprintf("%s: blue work %d vs %d", name, a.BlueWork(), b.BlueWork())
+		case !reflect.DeepEqual(names(a.MergeSetBlues()), names(b.MergeSetBlues())):
+			return fmt.Sprintf("%s: merge set blues %v vs %v", name, names(a.MergeSetBlues()), names(b.MergeSetBlues()))
+		case !reflect.DeepEqual(names(a.MergeSetReds()), names(b.MergeSetReds())):
+			return fmt.Sprintf("%s: merge set reds %v vs %v", name, names(a.MergeSetReds()), names(b.MergeSetReds()))
+		}
+	}
+	return ""
+}
+
+// minimizeDifferentialDAG greedily removes blocks and parents from the DAG and
+// lowers its K for as long as isFailing keeps holding, and returns the smallest
+// DAG it finds.
+func minimizeDifferentialDAG(dag *differentialDAG, isFailing func(*differentialDAG) bool) *differentialDAG {
+	for madeProgress := true; madeProgress; {
+		madeProgress = false
+
+		for block := dag.blockCount() - 1; block > 0; block-- {
+			if block >= dag.blockCount() {
+				continue
+			}
+			candidate := dag.withoutBlock(block)
+			if isFailing(candidate) {
+				dag = candidate
+				madeProgress = true
+			}
+		}
+
+		for block := dag.blockCount() - 1; block > 0; block-- {
+			for parentIndex := len(dag.parents[block]) - 1; parentIndex >= 0; parentIndex-- {
+				if len(dag.parents[block]) < 2 || parentIndex >= len(dag.parents[block]) {
+					continue
+				}
+				candidate := dag.withoutParent(block, parentIndex)
+				if isFailing(candidate) {
+					dag = candidate
+					madeProgress = true
+				}
+			}
+		}
+
+		for k := externalapi.KType(1); k < dag.k; k++ {
+			candidate := dag.withK(k)
+			if isFailing(candidate) {
+				dag = candidate
+				madeProgress = true
+				break
+			}
+		}
+	}
+	return dag
+}
+
+func checkGHOSTDAGDifferential(t *testing.T, data []byte) {
+	dag := differentialDAGFromBytes(data)
+	divergence := dag.divergence()
+	if divergence == "" {
+		return
+	}
+
+	minimized := minimizeDifferentialDAG(dag, func(candidate *differentialDAG) bool {
+		return candidate.divergence() != ""
+	})
+	t.Fatalf("GHOSTDAG implementations diverge on a DAG of %d blocks: %s\n"+
+		"Minimized to %d blocks: %s\n%s", dag.blockCount(), divergence,
+		minimized.blockCount(), minimized.divergence(), minimized)
+}
+
+// TestGHOSTDAGDifferential runs both GHOSTDAG implementations on random DAGs
+// of varying K, width and depth and checks that they agree on every block.
+func TestGHOSTDAGDifferential(t *testing.T) {
+	const iterations = 300
+	for seed := int64(0); seed < iterations; seed++ {
+		data := randomDifferentialDAGBytes(rand.New(rand.NewSource(seed)))
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			checkGHOSTDAGDifferential(t, data)
+		})
+	}
+}
+
+func TestMinimizeDifferentialDAG(t *testing.T) {
+	hasMergingBlock := func(dag *differentialDAG) bool {
+		for block := 1; block < dag.blockCount(); block++ {
+			if len(dag.parents[block]) >= 3 {
+				return true
+			}
+		}
+		return false
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		dag := differentialDAGFromBytes(randomDifferentialDAGBytes(rand.New(rand.NewSource(seed))))
+		if !hasMergingBlock(dag) {
+			continue
+		}
+		minimized := minimizeDifferentialDAG(dag, hasMergingBlock)
+		if !hasMergingBlock(minimized) {
+			t.Fatalf("seed %d: the minimized DAG no longer fails:\n%s", seed, minimized)
+		}
+		// Genesis, three parallel blocks and the block merging them
+		if minimized.blockCount() != 5 || minimized.k != 1 {
+			t.Fatalf("seed %d: expected a minimized DAG with 5 blocks and K=1, got:\n%s", seed, minimized)
+		}
+	}
+}
+
+func FuzzGHOSTDAGDifferential(f *testing.F) {
+	for seed := int64(0); seed < 10; seed++ {
+		f.Add(randomDifferentialDAGBytes(rand.New(rand.NewSource(seed))))
+	}
+	// A single chain, and a wide DAG whose blocks all merge several parents
+	f.Add([]byte{0, 0, 0, 0, 0, 0, 0, 0})
+	f.Add([]byte{17, 15, 4, 1, 2, 3, 4, 5, 4, 6, 7, 8, 9, 10, 4, 11, 12, 13, 14, 15, 4, 0, 2, 4, 6, 8})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkGHOSTDAGDifferential(t, data)
+	})
+}
+
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -336,6 +336,10 @@ func (ds *GHOSTDAGDataStoreImpl) UnstageAll(stagingArea *model.StagingArea) {
 	panic("implement me")
 }
 
+func (ds *GHOSTDAGDataStoreImpl) CacheStats() *externalapi.CacheStats {
+	panic("implement me")
+}
+
 type DAGTopologyManagerImpl struct {
 	parentsMap map[externalapi.DomainHash][]*externalapi.DomainHash
 }
@@ -458,3 +462,7 @@ func (b *blockHeadersStore) Delete(stagingArea *model.StagingArea, blockHash *ex
 func (b *blockHeadersStore) Count(*model.StagingArea) uint64 {
 	return uint64(len(b.dagMap))
 }
+
+func (b *blockHeadersStore) CacheStats() *externalapi.CacheStats {
+	panic("implement me")
+}
@@ -317,9 +317,27 @@ func (ppm *pruningProofManager) blockAtDepth(stagingArea *model.StagingArea, gho
 }
 
 func (ppm *pruningProofManager) ValidatePruningPointProof(pruningPointProof *externalapi.PruningPointProof) error {
+	_, err := ppm.ValidatePruningPointProofWithReport(pruningPointProof)
+	return err
+}
+
+// ValidatePruningPointProofWithReport validates the given proof, and returns a
+// report of its levels along with the validation error. The report covers the
+// levels that were processed before the proof was rejected, if it was.
+func (ppm *pruningProofManager) ValidatePruningPointProofWithReport(
+	pruningPointProof *externalapi.PruningPointProof) (*externalapi.PruningPointProofReport, error) {
+
 	onEnd := logger.LogAndMeasureExecutionTime(log, "ValidatePruningPointProof")
 	defer onEnd()
 
+	report := &externalapi.PruningPointProofReport{}
+	err := ppm.validatePruningPointProof(pruningPointProof, report)
+	return report, err
+}
+
+func (ppm *pruningProofManager) validatePruningPointProof(pruningPointProof *externalapi.PruningPointProof,
+	report *externalapi.PruningPointProofReport) error {
+
 	stagingArea := model.NewStagingArea()
 
 	if len(pruningPointProof.Headers) == 0 {
@@ -329,6 +347,7 @@ func (ppm *pruningProofManager) ValidatePruningPointProof(pruningPointProof *ext
 	level0Headers := pruningPointProof.Headers[0]
 	pruningPointHeader := level0Headers[len(level0Headers)-1]
 	pruningPoint := consensushashing.HeaderHash(pruningPointHeader)
+	report.PruningPoint = pruningPoint
 	pruningPointBlockLevel := pruningPointHeader.BlockLevel(ppm.maxBlockLevel)
 	maxLevel := len(ppm.parentsManager.Parents(pruningPointHeader)) - 1
 	if maxLevel >= len(pruningPointProof.Headers) {
@@ -396,7 +415,7 @@ func (ppm *pruningProofManager) ValidatePruningPointProof(pruningPointProof *ext
 			if len(parents) == 0 {
 				if i != 0 {
 					return errors.Wrapf(ruleerrors.ErrPruningProofHeaderWithNoKnownParents, "the proof header "+
-						"%s is missing known parents", blockHash)
+						"%s at level %d is missing known parents", blockHash, blockLevel)
 				}
 				parents = append(parents, model.VirtualGenesisBlockHash)
 			}
@@ -433,6 +452,25 @@ func (ppm *pruningProofManager) ValidatePruningPointProof(pruningPointProof *ext
 			}
 		}
 
+		levelReport := &externalapi.PruningPointProofLevelReport{
+			Level:       blockLevel,
+			HeaderCount: len(headers),
+			SelectedTip: selectedTip,
+			BlueWork:    big.NewInt(0),
+		}
+		if selectedTip != nil {
+			selectedTipGHOSTDAGData, err := ghostdagDataStores[blockLevel].Get(ppm.databaseContext, stagingArea, selectedTip, false)
+			if err != nil {
+				return err
+			}
+			levelReport.BlueScore = selectedTipGHOSTDAGData.BlueScore()
+			levelReport.BlueWork = selectedTipGHOSTDAGData.BlueWork()
+		}
+		report.Levels = append(report.Levels, levelReport)
+		log.Debugf("Level %d of the pruning point proof has %d headers, selected tip %s, blue score %d "+
+			"and blue work %s", blockLevel, levelReport.HeaderCount, selectedTip, levelReport.BlueScore,
+			levelReport.BlueWork.Text(16))
+
 		if blockLevel < maxLevel {
 			blockAtDepthMAtNextLevel, err := ppm.blockAtDepth(stagingArea, ghostdagDataStores[blockLevel+1], selectedTipByLevel[blockLevel+1], ppm.pruningProofM)
 			if err != nil {
@@ -521,7 +559,6 @@ func (ppm *pruningProofManager) ValidatePruningPointProof(pruningPointProof *ext
 			selectedTipBlueWorkDiff := big.NewInt(0).Sub(selectedTipGHOSTDAGData.BlueWork(), commonAncestorGHOSTDAGData.BlueWork())
 			currentDAGPruningPointParents := ppm.parentsManager.ParentsAtLevel(currentDAGPruningPointHeader, blockLevel)
 
-			foundBetterParent := false
 			for _, parent := range currentDAGPruningPointParents {
 				parentGHOSTDAGData, err := ppm.ghostdagDataStores[blockLevel].Get(ppm.databaseContext, model.NewStagingArea(), parent, false)
 				if err != nil {
@@ -530,15 +567,13 @@ func (ppm *pruningProofManager) ValidatePruningPointProof(pruningPointProof *ext
 
 				parentBlueWorkDiff := big.NewInt(0).Sub(parentGHOSTDAGData.BlueWork(), currentDAGCommonAncestorGHOSTDAGData.BlueWork())
 				if parentBlueWorkDiff.Cmp(selectedTipBlueWorkDiff) >= 0 {
-					foundBetterParent = true
-					break
+					return errors.Wrapf(ruleerrors.ErrPruningProofInsufficientBlueWork, "the proof doesn't "+
+						"have sufficient blue work in order to replace the current DAG: at level %d, the proof "+
+						"has blue work %s above the common ancestor %s, while the current pruning point parent "+
+						"%s has blue work %s above it", blockLevel, selectedTipBlueWorkDiff.Text(16),
+						commonAncestor, parent, parentBlueWorkDiff.Text(16))
 				}
 			}
-
-			if foundBetterParent {
-				return errors.Wrapf(ruleerrors.ErrPruningProofInsufficientBlueWork, "the proof doesn't "+
-					"have sufficient blue work in order to replace the current DAG")
-			}
 			return nil
 		}
 	}
@@ -11,3 +11,14 @@ func (v *transactionValidator) PopulateMass(transaction *externalapi.DomainTrans
 	}
 	transaction.Mass = v.txMassCalculator.CalculateTransactionMass(transaction)
 }
+
+// SpliceOpMass returns the mass that the splice and bitwise operations of the
+// given transaction add to a block with the given DAA score, which is zero
+// before the splice opcodes are activated. The UTXO entries of the transaction
+// must be populated.
+func (v *transactionValidator) SpliceOpMass(transaction *externalapi.DomainTransaction, povDAAScore uint64) uint64 {
+	if povDAAScore < v.spliceOpcodesActivationDAAScore {
+		return 0
+	}
+	return v.txMassCalculator.CalculateSpliceOpMass(transaction)
+}
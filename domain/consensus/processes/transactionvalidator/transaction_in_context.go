@@ -95,7 +95,11 @@ func (v *transactionValidator) ValidateTransactionInContextAndPopulateFee(stagin
 		return err
 	}
 
-	err = v.validateTransactionScripts(tx)
+	povBlockDAAScore, err := v.daaBlocksStore.DAAScore(v.databaseContext, stagingArea, povBlockHash)
+	if err != nil {
+		return err
+	}
+	err = v.validateTransactionScripts(tx, v.scriptFlags(povBlockDAAScore))
 	if err != nil {
 		return err
 	}
@@ -225,7 +229,21 @@ func (v *transactionValidator) checkTransactionSequenceLock(stagingArea *model.S
 	return nil
 }
 
-func (v *transactionValidator) validateTransactionScripts(tx *externalapi.DomainTransaction) error {
+// scriptFlags returns the flags that scripts are executed with in blocks with
+// the given DAA score, which enable the opcodes whose activation DAA score was
+// reached
+func (v *transactionValidator) scriptFlags(daaScore uint64) txscript.ScriptFlags {
+	flags := txscript.ScriptNoFlags
+	if daaScore >= v.introspectionActivationDAAScore {
+		flags |= txscript.ScriptEnableIntrospection
+	}
+	if daaScore >= v.spliceOpcodesActivationDAAScore {
+		flags |= txscript.ScriptEnableSpliceOpcodes
+	}
+	return flags
+}
+
+func (v *transactionValidator) validateTransactionScripts(tx *externalapi.DomainTransaction, flags txscript.ScriptFlags) error {
 	var missingOutpoints []*externalapi.DomainOutpoint
 	sighashReusedValues := &consensushashing.SighashReusedValues{}
 
@@ -239,7 +257,7 @@ func (v *transactionValidator) validateTransactionScripts(tx *externalapi.Domain
 		}
 
 		scriptPubKey := utxoEntry.ScriptPublicKey()
-		vm, err := txscript.NewEngine(scriptPubKey, tx, i, txscript.ScriptNoFlags, v.sigCache, v.sigCacheECDSA, sighashReusedValues)
+		vm, err := txscript.NewEngine(scriptPubKey, tx, i, flags, v.sigCache, v.sigCacheECDSA, sighashReusedValues)
 		if err != nil {
 			return errors.Wrapf(ruleerrors.ErrScriptMalformed, "failed to parse input "+
 				"%d which references output %s - "+
@@ -1,7 +1,10 @@
 package transactionvalidator
 
 import (
+	"math"
 	"testing"
+
+	"github.com/ammm56/lings/domain/consensus/utils/txscript"
 )
 
 // TestSequenceLocksActive tests the SequenceLockActive function to ensure it
@@ -31,3 +34,48 @@ func TestSequenceLocksActive(t *testing.T) {
 		}
 	}
 }
+
+// TestScriptFlags tests that the introspection and splice opcodes are enabled
+// from their activation DAA scores
+func TestScriptFlags(t *testing.T) {
+	tests := []struct {
+		introspectionActivationDAAScore uint64
+		spliceOpcodesActivationDAAScore uint64
+		daaScore                        uint64
+
+		want txscript.ScriptFlags
+	}{
+		// Nothing is scheduled.
+		{introspectionActivationDAAScore: math.MaxUint64, spliceOpcodesActivationDAAScore: math.MaxUint64,
+			daaScore: 1000, want: txscript.ScriptNoFlags},
+
+		// The opcodes are scheduled but not yet activated.
+		{introspectionActivationDAAScore: 30, spliceOpcodesActivationDAAScore: 40,
+			daaScore: 29, want: txscript.ScriptNoFlags},
+
+		// Only the introspection opcodes are activated.
+		{introspectionActivationDAAScore: 30, spliceOpcodesActivationDAAScore: 40,
+			daaScore: 30, want: txscript.ScriptEnableIntrospection},
+
+		// Only the splice opcodes are activated.
+		{introspectionActivationDAAScore: math.MaxUint64, spliceOpcodesActivationDAAScore: 40,
+			daaScore: 40, want: txscript.ScriptEnableSpliceOpcodes},
+
+		// Both are activated.
+		{introspectionActivationDAAScore: 30, spliceOpcodesActivationDAAScore: 40,
+			daaScore: 1000, want: txscript.ScriptEnableIntrospection | txscript.ScriptEnableSpliceOpcodes},
+		{introspectionActivationDAAScore: 0, spliceOpcodesActivationDAAScore: 0,
+			daaScore: 0, want: txscript.ScriptEnableIntrospection | txscript.ScriptEnableSpliceOpcodes},
+	}
+
+	for i, test := range tests {
+		validator := transactionValidator{
+			introspectionActivationDAAScore: test.introspectionActivationDAAScore,
+			spliceOpcodesActivationDAAScore: test.spliceOpcodesActivationDAAScore,
+		}
+		got := validator.scriptFlags(test.daaScore)
+		if got != test.want {
+			t.Fatalf("scriptFlags #%d got %v want %v", i, got, test.want)
+		}
+	}
+}
@@ -21,6 +21,8 @@ type transactionValidator struct {
 	maxCoinbasePayloadLength                uint64
 	ghostdagK                               externalapi.KType
 	coinbasePayloadScriptPublicKeyMaxLength uint8
+	introspectionActivationDAAScore         uint64
+	spliceOpcodesActivationDAAScore         uint64
 	sigCache                                *txscript.SigCache
 	sigCacheECDSA                           *txscript.SigCacheECDSA
 	txMassCalculator                        *txmass.Calculator
@@ -32,6 +34,8 @@ func New(blockCoinbaseMaturity uint64,
 	maxCoinbasePayloadLength uint64,
 	ghostdagK externalapi.KType,
 	coinbasePayloadScriptPublicKeyMaxLength uint8,
+	introspectionActivationDAAScore uint64,
+	spliceOpcodesActivationDAAScore uint64,
 	databaseContext model.DBReader,
 	pastMedianTimeManager model.PastMedianTimeManager,
 	ghostdagDataStore model.GHOSTDAGDataStore,
@@ -44,6 +48,8 @@ func New(blockCoinbaseMaturity uint64,
 		maxCoinbasePayloadLength:                maxCoinbasePayloadLength,
 		ghostdagK:                               ghostdagK,
 		coinbasePayloadScriptPublicKeyMaxLength: coinbasePayloadScriptPublicKeyMaxLength,
+		introspectionActivationDAAScore:         introspectionActivationDAAScore,
+		spliceOpcodesActivationDAAScore:         spliceOpcodesActivationDAAScore,
 		databaseContext:                         databaseContext,
 		pastMedianTimeManager:                   pastMedianTimeManager,
 		ghostdagDataStore:                       ghostdagDataStore,
@@ -0,0 +1,223 @@
+package consensus_test
+
+import (
+	"testing"
+
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/model"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/model/testapi"
+	"github.com/ammm56/lings/domain/consensus/utils/constants"
+	"github.com/ammm56/lings/domain/consensus/utils/testutils"
+	"github.com/ammm56/lings/domain/consensus/utils/transactionhelper"
+	"github.com/ammm56/lings/domain/consensus/utils/txscript"
+)
+
+// TestIntrospectionActivation verifies that an output whose redeem script
+// uses an introspection opcode can only be spent in blocks whose DAA score is
+// at least IntrospectionActivationDAAScore
+func TestIntrospectionActivation(t *testing.T) {
+	redeemScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OpTxInputCount).
+		AddOp(txscript.Op1).
+		AddOp(txscript.OpEqual).
+		Script()
+	if err != nil {
+		t.Fatalf("Script: %v", err)
+	}
+
+	testScriptActivation(t, "TestIntrospectionActivation", redeemScript,
+		func(consensusConfig *consensus.Config, activationDAAScore uint64) {
+			consensusConfig.IntrospectionActivationDAAScore = activationDAAScore
+		})
+}
+
+// TestSpliceOpcodesActivation verifies that an output whose redeem script
+// uses a splice opcode can only be spent in blocks whose DAA score is at least
+// SpliceOpcodesActivationDAAScore
+func TestSpliceOpcodesActivation(t *testing.T) {
+	redeemScript, err := txscript.NewScriptBuilder().
+		AddData([]byte("lin")).
+		AddData([]byte("gs")).
+		AddOp(txscript.OpCat).
+		AddData([]byte("lings")).
+		AddOp(txscript.OpEqual).
+		Script()
+	if err != nil {
+		t.Fatalf("Script: %v", err)
+	}
+
+	testScriptActivation(t, "TestSpliceOpcodesActivation", redeemScript,
+		func(consensusConfig *consensus.Config, activationDAAScore uint64) {
+			consensusConfig.SpliceOpcodesActivationDAAScore = activationDAAScore
+		})
+}
+
+// TestSpliceOpMass verifies that once the splice opcodes are activated, the
+// mass of the splice and bitwise operations counts towards the block mass
+// limit, and that blocks built by consensus leave out the transactions that
+// would make them exceed it
+func TestSpliceOpMass(t *testing.T) {
+	redeemScript, err := txscript.NewScriptBuilder().
+		AddData([]byte("lin")).
+		AddData([]byte("gs")).
+		AddOp(txscript.OpCat).
+		AddData([]byte("lings")).
+		AddOp(txscript.OpEqual).
+		Script()
+	if err != nil {
+		t.Fatalf("Script: %v", err)
+	}
+
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		// Keep all blocks at version 1, which doesn't pay a dev fee
+		consensusConfig.POWScores = nil
+		consensusConfig.SpliceOpcodesActivationDAAScore = 0
+		// A single splice operation exceeds the mass limit of a block
+		consensusConfig.MassPerSpliceOp = consensusConfig.MaxBlockMass
+
+		factory := consensus.NewFactory()
+		testConsensus, teardown, err := factory.NewTestConsensus(consensusConfig, "TestSpliceOpMass")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		tipHash, spendingTransaction := addBlockWithLockedOutput(t, testConsensus, consensusConfig, redeemScript)
+
+		blockHash, _, err := testConsensus.AddBlock([]*externalapi.DomainHash{tipHash}, nil,
+			[]*externalapi.DomainTransaction{spendingTransaction})
+		if err != nil {
+			t.Fatalf("Error adding the spending block: %v", err)
+		}
+		status, err := testConsensus.BlockStatusStore().Get(testConsensus.DatabaseContext(), model.NewStagingArea(), blockHash)
+		if err != nil {
+			t.Fatalf("Failed getting the block status: %v", err)
+		}
+		if status != externalapi.StatusDisqualifiedFromChain {
+			t.Fatalf("Expected the spending block to be disqualified from chain, but got %s", status)
+		}
+
+		coinbaseData := &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{},
+			ExtraData:       []byte{},
+		}
+		block, err := testConsensus.BuildBlock(coinbaseData, []*externalapi.DomainTransaction{spendingTransaction})
+		if err != nil {
+			t.Fatalf("BuildBlock: %+v", err)
+		}
+		if len(block.Transactions) != 1 {
+			t.Fatalf("Expected the built block to only have a coinbase transaction, but it has %d transactions",
+				len(block.Transactions))
+		}
+	})
+}
+
+// testScriptActivation verifies that an output locked to the given redeem
+// script is spendable from the activation DAA score that setActivationDAAScore
+// sets, and not before
+func testScriptActivation(t *testing.T, testName string, redeemScript []byte,
+	setActivationDAAScore func(consensusConfig *consensus.Config, activationDAAScore uint64)) {
+
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		// Keep all blocks at version 1, which doesn't pay a dev fee
+		consensusConfig.POWScores = nil
+		activationDAAScore := consensusConfig.GenesisBlock.Header.DAAScore() + 30
+		setActivationDAAScore(consensusConfig, activationDAAScore)
+
+		factory := consensus.NewFactory()
+		testConsensus, teardown, err := factory.NewTestConsensus(consensusConfig, testName)
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		tipHash, spendingTransaction := addBlockWithLockedOutput(t, testConsensus, consensusConfig, redeemScript)
+
+		// Build the chain up to two blocks below the activation DAA score
+		stagingArea := model.NewStagingArea()
+		tipDAAScore, err := testConsensus.DAABlocksStore().DAAScore(testConsensus.DatabaseContext(), stagingArea, tipHash)
+		if err != nil {
+			t.Fatalf("Failed getting DAA score: %v", err)
+		}
+		for ; tipDAAScore < activationDAAScore-2; tipDAAScore++ {
+			tipHash, _, err = testConsensus.AddBlock([]*externalapi.DomainHash{tipHash}, nil, nil)
+			if err != nil {
+				t.Fatalf("Error adding block: %v", err)
+			}
+		}
+
+		requireSpendingBlockStatus := func(parentHash *externalapi.DomainHash, expectedStatus externalapi.BlockStatus) *externalapi.DomainHash {
+			blockHash, _, err := testConsensus.AddBlock([]*externalapi.DomainHash{parentHash}, nil,
+				[]*externalapi.DomainTransaction{spendingTransaction})
+			if err != nil {
+				t.Fatalf("Error adding the spending block: %v", err)
+			}
+			daaScore, err := testConsensus.DAABlocksStore().DAAScore(testConsensus.DatabaseContext(), stagingArea, blockHash)
+			if err != nil {
+				t.Fatalf("Failed getting DAA score: %v", err)
+			}
+			status, err := testConsensus.BlockStatusStore().Get(testConsensus.DatabaseContext(), stagingArea, blockHash)
+			if err != nil {
+				t.Fatalf("Failed getting the block status: %v", err)
+			}
+			if !status.Equal(expectedStatus) {
+				t.Fatalf("Expected the spending block at DAA score %d to have status %s, but got %s "+
+					"(activation DAA score %d)", daaScore, expectedStatus, status, activationDAAScore)
+			}
+			return blockHash
+		}
+
+		// One block before the activation the opcodes are still disabled
+		requireSpendingBlockStatus(tipHash, externalapi.StatusDisqualifiedFromChain)
+
+		tipHash, _, err = testConsensus.AddBlock([]*externalapi.DomainHash{tipHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("Error adding block: %v", err)
+		}
+		requireSpendingBlockStatus(tipHash, externalapi.StatusUTXOValid)
+	})
+}
+
+// addBlockWithLockedOutput adds a chain of blocks on top of genesis, the last
+// of which locks an output to the pay-to-script-hash of the given redeem
+// script. It returns the tip and a transaction that spends the locked output.
+func addBlockWithLockedOutput(t *testing.T, testConsensus testapi.TestConsensus, consensusConfig *consensus.Config,
+	redeemScript []byte) (*externalapi.DomainHash, *externalapi.DomainTransaction) {
+
+	tipHash := consensusConfig.GenesisHash
+	var err error
+	for i := 0; i < 4; i++ {
+		tipHash, _, err = testConsensus.AddBlock([]*externalapi.DomainHash{tipHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("Error adding block: %v", err)
+		}
+	}
+	tip, _, err := testConsensus.GetBlock(tipHash)
+	if err != nil {
+		t.Fatalf("Failed getting the tip: %v", err)
+	}
+
+	p2shScript, err := txscript.PayToScriptHashScript(redeemScript)
+	if err != nil {
+		t.Fatalf("Failed to create a pay-to-script-hash script: %v", err)
+	}
+	fees := uint64(1)
+	lockingTransaction, err := createTransactionWithLockedOutput(tip.Transactions[transactionhelper.CoinbaseTransactionIndex],
+		fees, &externalapi.ScriptPublicKey{Version: constants.MaxScriptPublicKeyVersion, Script: p2shScript})
+	if err != nil {
+		t.Fatalf("Error in createTransactionWithLockedOutput: %v", err)
+	}
+	tipHash, _, err = testConsensus.AddBlock([]*externalapi.DomainHash{tipHash}, nil,
+		[]*externalapi.DomainTransaction{lockingTransaction})
+	if err != nil {
+		t.Fatalf("Error adding the block with the locked output: %v", err)
+	}
+	spendingTransaction, err := createTransactionThatSpentTheLockedOutput(lockingTransaction, fees, redeemScript, 0)
+	if err != nil {
+		t.Fatalf("Error creating the spending transaction: %v", err)
+	}
+	return tipHash, spendingTransaction
+}
@@ -11,10 +11,6 @@ var (
 )
 
 const (
-	DevFee        = 5
-	DevFeeMin     = 1
-	DevFeeAddress = "lings:qp4ad2eh72xc8dtjjyz4llxzq9utn6k26uyl644xxw70wskdfl85zsqj9k4vz"
-
 	// MaxTransactionVersion is the current latest supported transaction version.
 	MaxTransactionVersion uint16 = 0
 
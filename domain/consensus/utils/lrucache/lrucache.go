@@ -1,12 +1,19 @@
 package lrucache
 
 import (
+	"sync/atomic"
+
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
 )
 
 // LRUCache is a least-recently-used cache for any type
 // that's able to be indexed by DomainHash
 type LRUCache struct {
+	// hits and misses are updated atomically, since Get may be called
+	// concurrently by readers of the cache. They're first in the struct
+	// to keep them 64-bit aligned on 32-bit platforms.
+	hits     uint64
+	misses   uint64
 	cache    map[externalapi.DomainHash]interface{}
 	capacity int
 }
@@ -38,8 +45,10 @@ func (c *LRUCache) Add(key *externalapi.DomainHash, value interface{}) {
 func (c *LRUCache) Get(key *externalapi.DomainHash) (interface{}, bool) {
 	value, ok := c.cache[*key]
 	if !ok {
+		atomic.AddUint64(&c.misses, 1)
 		return nil, false
 	}
+	atomic.AddUint64(&c.hits, 1)
 	return value, true
 }
 
@@ -55,6 +64,17 @@ func (c *LRUCache) Remove(key *externalapi.DomainHash) {
 	delete(c.cache, *key)
 }
 
+// Stats returns the number of entries and the capacity of the LRUCache, and
+// how many lookups with Get found their entry. The name is left empty.
+func (c *LRUCache) Stats() *externalapi.CacheStats {
+	return &externalapi.CacheStats{
+		Entries:  len(c.cache),
+		Capacity: c.capacity,
+		Hits:     atomic.LoadUint64(&c.hits),
+		Misses:   atomic.LoadUint64(&c.misses),
+	}
+}
+
 func (c *LRUCache) evictRandom() {
 	var keyToEvict externalapi.DomainHash
 	for key := range c.cache {
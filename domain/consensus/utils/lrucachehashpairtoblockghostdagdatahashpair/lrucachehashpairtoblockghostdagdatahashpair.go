@@ -1,6 +1,10 @@
 package lrucachehashpairtoblockghostdagdatahashpair
 
-import "github.com/ammm56/lings/domain/consensus/model/externalapi"
+import (
+	"sync/atomic"
+
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+)
 
 type lruKey struct {
 	blockHash externalapi.DomainHash
@@ -17,6 +21,11 @@ func newKey(blockHash *externalapi.DomainHash, index uint64) lruKey {
 // LRUCache is a least-recently-used cache from
 // lruKey to *externalapi.BlockGHOSTDAGDataHashPair
 type LRUCache struct {
+	// hits and misses are updated atomically, since Get may be called
+	// concurrently by readers of the cache. They're first in the struct
+	// to keep them 64-bit aligned on 32-bit platforms.
+	hits     uint64
+	misses   uint64
 	cache    map[lruKey]*externalapi.BlockGHOSTDAGDataHashPair
 	capacity int
 }
@@ -50,8 +59,10 @@ func (c *LRUCache) Get(blockHash *externalapi.DomainHash, index uint64) (*extern
 	key := newKey(blockHash, index)
 	value, ok := c.cache[key]
 	if !ok {
+		atomic.AddUint64(&c.misses, 1)
 		return nil, false
 	}
+	atomic.AddUint64(&c.hits, 1)
 	return value, true
 }
 
@@ -69,6 +80,17 @@ func (c *LRUCache) Remove(blockHash *externalapi.DomainHash, index uint64) {
 	delete(c.cache, key)
 }
 
+// Stats returns the number of entries and the capacity of the LRUCache, and
+// how many lookups with Get found their entry. The name is left empty.
+func (c *LRUCache) Stats() *externalapi.CacheStats {
+	return &externalapi.CacheStats{
+		Entries:  len(c.cache),
+		Capacity: c.capacity,
+		Hits:     atomic.LoadUint64(&c.hits),
+		Misses:   atomic.LoadUint64(&c.misses),
+	}
+}
+
 func (c *LRUCache) evictRandom() {
 	var keyToEvict lruKey
 	for key := range c.cache {
@@ -0,0 +1,525 @@
+package testutils
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ammm56/lings/domain/consensus/model"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/model/testapi"
+	"github.com/ammm56/lings/domain/consensus/ruleerrors"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/pkg/errors"
+)
+
+const (
+	dagScenarioGenesisName        = "genesis"
+	dagScenarioVirtualName        = "virtual"
+	dagScenarioVirtualGenesisName = "virtual-genesis"
+)
+
+const (
+	dagScenarioPropertyParents        = "parents"
+	dagScenarioPropertySelectedParent = "selected-parent"
+	dagScenarioPropertyBlues          = "blues"
+	dagScenarioPropertyReds           = "reds"
+	dagScenarioPropertyBlueScore      = "blue-score"
+	dagScenarioPropertyDAAScore       = "daa-score"
+	dagScenarioPropertyStatus         = "status"
+	dagScenarioPropertyChain          = "chain"
+)
+
+var dagScenarioProperties = map[string]struct{}{
+	dagScenarioPropertyParents:        {},
+	dagScenarioPropertySelectedParent: {},
+	dagScenarioPropertyBlues:          {},
+	dagScenarioPropertyReds:           {},
+	dagScenarioPropertyBlueScore:      {},
+	dagScenarioPropertyDAAScore:       {},
+	dagScenarioPropertyStatus:         {},
+	dagScenarioPropertyChain:          {},
+}
+
+type dagScenarioStatementKind int
+
+const (
+	dagScenarioStatementBlock dagScenarioStatementKind = iota
+	dagScenarioStatementHeader
+	dagScenarioStatementExpect
+)
+
+type dagScenarioStatement struct {
+	line int
+	kind dagScenarioStatementKind
+
+	// Used by block and header statements
+	name        string
+	parents     []string
+	spends      []dagScenarioSpend
+	reject      bool
+	rejectError string
+
+	// Used by expect statements
+	property string
+	subject  string
+	values   []string
+}
+
+type dagScenarioSpend struct {
+	block            string
+	transactionIndex int
+}
+
+// DAGScenario is a DAG described in the scenario language accepted by
+// ParseDAGScenario, together with the outcomes expected from it.
+type DAGScenario struct {
+	statements []*dagScenarioStatement
+}
+
+// ParseDAGScenarioString parses a DAG scenario out of the given string.
+// See ParseDAGScenario for the scenario language.
+func ParseDAGScenarioString(scenario string) (*DAGScenario, error) {
+	return ParseDAGScenario(strings.NewReader(scenario))
+}
+
+// ParseDAGScenario parses a DAG scenario out of the given reader.
+//
+// A scenario is a list of statements, one per line. Everything following a
+// '#' is a comment. The following statements are supported:
+//
+//	block <name> <parent>... [spend=<block>[.<txIndex>]]... [reject[=<error>]]
+//	  Adds a block on top of the given parents. Each spend adds a transaction
+//	  that spends the first output of the given transaction of the given block
+//	  (its coinbase by default). If reject is set, the block is expected to be
+//	  rejected with a rule error, and if an error name such as ErrMissingParents
+//	  is given the rule error is expected to mention it.
+//
+//	header <name> <parent>...
+//	  Adds the header of a block on top of the given parents, without its body.
+//
+//	expect <property> <block> = <value>...
+//	  Checks a property of the given block, which may also be virtual. The
+//	  supported properties are parents, selected-parent, blues, reds,
+//	  blue-score, daa-score, status and chain. Blues and reds are the block's
+//	  merge set blues (including its selected parent) and reds, and are
+//	  compared regardless of order, as are parents. Status uses the names
+//	  returned by BlockStatus.String, e.g. Valid or DisqualifiedFromChain.
+//	  Chain is the selected chain leading to the block, excluding genesis and
+//	  including the block itself; for virtual it ends at its selected parent.
+//
+// The genesis block is always named genesis.
+func ParseDAGScenario(reader io.Reader) (*DAGScenario, error) {
+	scenario := &DAGScenario{}
+	definedNames := map[string]struct{}{dagScenarioGenesisName: {}}
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if commentIndex := strings.IndexByte(line, '#'); commentIndex != -1 {
+			line = line[:commentIndex]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		statement, err := parseDAGScenarioStatement(lineNumber, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		switch statement.kind {
+		case dagScenarioStatementBlock, dagScenarioStatementHeader:
+			if _, ok := definedNames[statement.name]; ok {
+				return nil, errors.Errorf("line %d: block %s is already defined", lineNumber, statement.name)
+			}
+			for _, parent := range statement.parents {
+				if _, ok := definedNames[parent]; !ok {
+					return nil, errors.Errorf("line %d: unknown parent %s", lineNumber, parent)
+				}
+			}
+			for _, spend := range statement.spends {
+				if _, ok := definedNames[spend.block]; !ok {
+					return nil, errors.Errorf("line %d: unknown block %s to spend from", lineNumber, spend.block)
+				}
+			}
+			if !statement.reject {
+				definedNames[statement.name] = struct{}{}
+			}
+		case dagScenarioStatementExpect:
+			if statement.subject != dagScenarioVirtualName {
+				if _, ok := definedNames[statement.subject]; !ok {
+					return nil, errors.Errorf("line %d: unknown block %s", lineNumber, statement.subject)
+				}
+			}
+		}
+
+		scenario.statements = append(scenario.statements, statement)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return scenario, nil
+}
+
+func parseDAGScenarioStatement(lineNumber int, fields []string) (*dagScenarioStatement, error) {
+	statement := &dagScenarioStatement{line: lineNumber}
+
+	switch fields[0] {
+	case "block", "header":
+		if fields[0] == "block" {
+			statement.kind = dagScenarioStatementBlock
+		} else {
+			statement.kind = dagScenarioStatementHeader
+		}
+		if len(fields) < 3 {
+			return nil, errors.Errorf("line %d: expected '%s <name> <parent>...'", lineNumber, fields[0])
+		}
+		statement.name = fields[1]
+		if !isValidDAGScenarioName(statement.name) {
+			return nil, errors.Errorf("line %d: invalid block name %s", lineNumber, statement.name)
+		}
+
+		for _, field := range fields[2:] {
+			switch {
+			case strings.HasPrefix(field, "spend="):
+				spend, err := parseDAGScenarioSpend(strings.TrimPrefix(field, "spend="))
+				if err != nil {
+					return nil, errors.Wrapf(err, "line %d", lineNumber)
+				}
+				statement.spends = append(statement.spends, spend)
+			case field == "reject":
+				statement.reject = true
+			case strings.HasPrefix(field, "reject="):
+				statement.reject = true
+				statement.rejectError = strings.TrimPrefix(field, "reject=")
+			default:
+				if len(statement.spends) > 0 || statement.reject {
+					return nil, errors.Errorf("line %d: parent %s must come before spend and reject", lineNumber, field)
+				}
+				statement.parents = append(statement.parents, field)
+			}
+		}
+		if len(statement.parents) == 0 {
+			return nil, errors.Errorf("line %d: block %s has no parents", lineNumber, statement.name)
+		}
+		if statement.kind == dagScenarioStatementHeader && (len(statement.spends) > 0 || statement.reject) {
+			return nil, errors.Errorf("line %d: header statements support neither spend nor reject", lineNumber)
+		}
+
+	case "expect":
+		statement.kind = dagScenarioStatementExpect
+		if len(fields) < 4 || fields[3] != "=" {
+			return nil, errors.Errorf("line %d: expected 'expect <property> <block> = <value>...'", lineNumber)
+		}
+		statement.property = fields[1]
+		if _, ok := dagScenarioProperties[statement.property]; !ok {
+			return nil, errors.Errorf("line %d: unknown property %s", lineNumber, statement.property)
+		}
+		statement.subject = fields[2]
+		statement.values = fields[4:]
+
+		switch statement.property {
+		case dagScenarioPropertySelectedParent, dagScenarioPropertyStatus:
+			if len(statement.values) != 1 {
+				return nil, errors.Errorf("line %d: %s expects exactly one value", lineNumber, statement.property)
+			}
+		case dagScenarioPropertyBlueScore, dagScenarioPropertyDAAScore:
+			if len(statement.values) != 1 {
+				return nil, errors.Errorf("line %d: %s expects exactly one value", lineNumber, statement.property)
+			}
+			if _, err := strconv.ParseUint(statement.values[0], 10, 64); err != nil {
+				return nil, errors.Errorf("line %d: invalid %s %s", lineNumber, statement.property, statement.values[0])
+			}
+		}
+		if statement.subject == dagScenarioVirtualName && statement.property == dagScenarioPropertyStatus {
+			return nil, errors.Errorf("line %d: virtual has no status", lineNumber)
+		}
+
+	default:
+		return nil, errors.Errorf("line %d: unknown statement %s", lineNumber, fields[0])
+	}
+
+	return statement, nil
+}
+
+func parseDAGScenarioSpend(value string) (dagScenarioSpend, error) {
+	spend := dagScenarioSpend{block: value}
+	if dotIndex := strings.LastIndexByte(value, '.'); dotIndex != -1 {
+		transactionIndex, err := strconv.Atoi(value[dotIndex+1:])
+		if err != nil || transactionIndex < 0 {
+			return dagScenarioSpend{}, errors.Errorf("invalid transaction index in spend=%s", value)
+		}
+		spend.block = value[:dotIndex]
+		spend.transactionIndex = transactionIndex
+	}
+	return spend, nil
+}
+
+func isValidDAGScenarioName(name string) bool {
+	if name == dagScenarioGenesisName || name == dagScenarioVirtualName || name == dagScenarioVirtualGenesisName {
+		return false
+	}
+	for _, r := range name {
+		isLetter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		isDigit := r >= '0' && r <= '9'
+		if !isLetter && !isDigit && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// dagScenarioRun holds the state of a single run of a DAGScenario
+type dagScenarioRun struct {
+	tc          testapi.TestConsensus
+	blocks      map[string]*externalapi.DomainHash
+	blockNames  map[externalapi.DomainHash]string
+	stagingArea *model.StagingArea
+}
+
+// Run adds the scenario's blocks to the given TestConsensus in order and
+// checks every expectation once all the statements preceding it have run.
+// It returns the hashes of the added blocks by name.
+func (s *DAGScenario) Run(tc testapi.TestConsensus) (map[string]*externalapi.DomainHash, error) {
+	genesisHash := tc.DAGParams().GenesisHash
+	run := &dagScenarioRun{
+		tc:     tc,
+		blocks: map[string]*externalapi.DomainHash{dagScenarioGenesisName: genesisHash},
+		blockNames: map[externalapi.DomainHash]string{
+			*genesisHash:                   dagScenarioGenesisName,
+			*model.VirtualGenesisBlockHash: dagScenarioVirtualGenesisName,
+		},
+	}
+
+	for _, statement := range s.statements {
+		var err error
+		switch statement.kind {
+		case dagScenarioStatementBlock:
+			err = run.addBlock(statement)
+		case dagScenarioStatementHeader:
+			err = run.addHeader(statement)
+		case dagScenarioStatementExpect:
+			// A fresh staging area makes sure the expectation sees exactly what was committed
+			run.stagingArea = model.NewStagingArea()
+			err = run.checkExpectation(statement)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", statement.line)
+		}
+	}
+
+	return run.blocks, nil
+}
+
+func (run *dagScenarioRun) parentHashes(statement *dagScenarioStatement) []*externalapi.DomainHash {
+	parentHashes := make([]*externalapi.DomainHash, len(statement.parents))
+	for i, parent := range statement.parents {
+		parentHashes[i] = run.blocks[parent]
+	}
+	return parentHashes
+}
+
+func (run *dagScenarioRun) addBlock(statement *dagScenarioStatement) error {
+	transactions := make([]*externalapi.DomainTransaction, 0, len(statement.spends))
+	for _, spend := range statement.spends {
+		block, found, err := run.tc.GetBlock(run.blocks[spend.block])
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.Errorf("block %s has no body to spend from", spend.block)
+		}
+		if spend.transactionIndex >= len(block.Transactions) {
+			return errors.Errorf("block %s has no transaction %d", spend.block, spend.transactionIndex)
+		}
+		transaction, err := CreateTransaction(block.Transactions[spend.transactionIndex], 1)
+		if err != nil {
+			return err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	blockHash, _, err := run.tc.AddBlock(run.parentHashes(statement), nil, transactions)
+	if statement.reject {
+		if err == nil {
+			return errors.Errorf("expected block %s to be rejected, but it was added as %s", statement.name, blockHash)
+		}
+		if !errors.As(err, &ruleerrors.RuleError{}) {
+			return errors.Wrapf(err, "expected block %s to be rejected with a rule error", statement.name)
+		}
+		if statement.rejectError != "" && !strings.Contains(err.Error(), statement.rejectError) {
+			return errors.Errorf("expected block %s to be rejected with %s, but got: %s",
+				statement.name, statement.rejectError, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed adding block %s", statement.name)
+	}
+
+	run.addName(statement.name, blockHash)
+	return nil
+}
+
+func (run *dagScenarioRun) addHeader(statement *dagScenarioStatement) error {
+	header, err := run.tc.BuildHeaderWithParents(run.parentHashes(statement))
+	if err != nil {
+		return errors.Wrapf(err, "failed building header %s", statement.name)
+	}
+
+	block := &externalapi.DomainBlock{Header: header}
+	err = run.tc.ValidateAndInsertBlock(block, true)
+	if err != nil {
+		return errors.Wrapf(err, "failed adding header %s", statement.name)
+	}
+
+	run.addName(statement.name, consensushashing.HeaderHash(header))
+	return nil
+}
+
+func (run *dagScenarioRun) addName(name string, blockHash *externalapi.DomainHash) {
+	run.blocks[name] = blockHash
+	run.blockNames[*blockHash] = name
+}
+
+func (run *dagScenarioRun) name(blockHash *externalapi.DomainHash) string {
+	if name, ok := run.blockNames[*blockHash]; ok {
+		return name
+	}
+	return blockHash.String()
+}
+
+func (run *dagScenarioRun) names(blockHashes []*externalapi.DomainHash) []string {
+	names := make([]string, len(blockHashes))
+	for i, blockHash := range blockHashes {
+		names[i] = run.name(blockHash)
+	}
+	return names
+}
+
+func (run *dagScenarioRun) checkExpectation(statement *dagScenarioStatement) error {
+	blockHash := model.VirtualBlockHash
+	if statement.subject != dagScenarioVirtualName {
+		blockHash = run.blocks[statement.subject]
+	}
+
+	actual, err := run.property(statement.property, blockHash)
+	if err != nil {
+		return err
+	}
+
+	expected := statement.values
+	if statement.property == dagScenarioPropertyParents || statement.property == dagScenarioPropertyBlues ||
+		statement.property == dagScenarioPropertyReds {
+
+		expected = sortedCopy(expected)
+		actual = sortedCopy(actual)
+	}
+
+	if strings.Join(expected, " ") != strings.Join(actual, " ") {
+		return errors.Errorf("expected %s of %s to be [%s], but got [%s]", statement.property, statement.subject,
+			strings.Join(statement.values, " "), strings.Join(actual, " "))
+	}
+	return nil
+}
+
+func (run *dagScenarioRun) property(property string, blockHash *externalapi.DomainHash) ([]string, error) {
+	dbContext := run.tc.DatabaseContext()
+	isVirtual := blockHash.Equal(model.VirtualBlockHash)
+
+	switch property {
+	case dagScenarioPropertyParents:
+		if isVirtual {
+			virtualInfo, err := run.tc.GetVirtualInfo()
+			if err != nil {
+				return nil, err
+			}
+			return run.names(virtualInfo.ParentHashes), nil
+		}
+		header, err := run.tc.BlockHeaderStore().BlockHeader(dbContext, run.stagingArea, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		return run.names(header.DirectParents()), nil
+
+	case dagScenarioPropertyStatus:
+		status, err := run.tc.BlockStatusStore().Get(dbContext, run.stagingArea, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		return []string{status.String()}, nil
+
+	case dagScenarioPropertyDAAScore:
+		if isVirtual {
+			daaScore, err := run.tc.GetVirtualDAAScore()
+			if err != nil {
+				return nil, err
+			}
+			return []string{strconv.FormatUint(daaScore, 10)}, nil
+		}
+		daaScore, err := run.tc.DAABlocksStore().DAAScore(dbContext, run.stagingArea, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		return []string{strconv.FormatUint(daaScore, 10)}, nil
+	}
+
+	ghostdagData, err := run.tc.GHOSTDAGDataStore().Get(dbContext, run.stagingArea, blockHash, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch property {
+	case dagScenarioPropertySelectedParent:
+		return []string{run.name(ghostdagData.SelectedParent())}, nil
+	case dagScenarioPropertyBlues:
+		return run.names(ghostdagData.MergeSetBlues()), nil
+	case dagScenarioPropertyReds:
+		return run.names(ghostdagData.MergeSetReds()), nil
+	case dagScenarioPropertyBlueScore:
+		return []string{strconv.FormatUint(ghostdagData.BlueScore(), 10)}, nil
+	case dagScenarioPropertyChain:
+		if isVirtual {
+			return run.chain(ghostdagData.SelectedParent())
+		}
+		return run.chain(blockHash)
+	}
+
+	return nil, errors.Errorf("unknown property %s", property)
+}
+
+// chain returns the selected chain from just above genesis up to and including
+// the given block
+func (run *dagScenarioRun) chain(blockHash *externalapi.DomainHash) ([]string, error) {
+	genesisHash := run.tc.DAGParams().GenesisHash
+	var chain []string
+	current := blockHash
+	for !current.Equal(genesisHash) && !current.Equal(model.VirtualGenesisBlockHash) {
+		chain = append(chain, run.name(current))
+		currentGHOSTDAGData, err := run.tc.GHOSTDAGDataStore().Get(
+			run.tc.DatabaseContext(), run.stagingArea, current, false)
+		if err != nil {
+			return nil, err
+		}
+		current = currentGHOSTDAGData.SelectedParent()
+	}
+
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+func sortedCopy(values []string) []string {
+	sorted := make([]string, len(values))
+	copy(sorted, values)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,117 @@
+package testutils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/utils/testutils"
+	"github.com/ammm56/lings/domain/dagconfig"
+)
+
+func TestDAGScenario(t *testing.T) {
+	consensusConfig := &consensus.Config{Params: dagconfig.MainnetParams}
+	consensusConfig.SkipProofOfWork = true
+	consensusConfig.K = 1
+	consensusConfig.BlockCoinbaseMaturity = 0
+
+	factory := consensus.NewFactory()
+	tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestDAGScenario")
+	if err != nil {
+		t.Fatalf("Error setting up consensus: %+v", err)
+	}
+	defer teardown(false)
+
+	scenario, err := testutils.ParseDAGScenarioString(`
+		# A two block chain merging two parallel blocks, which are red since K=1
+		block A1 genesis
+		block A2 A1
+		block B1 genesis
+		block C1 genesis
+		block D  A2 B1 C1
+		expect selected-parent D = A2
+		expect blues D = A2
+		expect reds D = B1 C1
+		expect blue-score D = 3
+		expect daa-score A1 = 0
+		expect daa-score D = 4
+		expect parents D = A2 B1 C1
+		expect chain virtual = A1 A2 D
+
+		block Bad A1 A2 reject=ErrInvalidParentsRelation
+
+		# A longer chain on top of B1 takes over the selected chain
+		block E1 B1
+		block E2 E1 spend=A2
+		block E3 E2
+		block E4 E3
+		expect chain virtual = B1 E1 E2 E3 E4
+		expect selected-parent virtual = E4
+		expect status E2 = Valid
+		expect chain D = A1 A2 D
+
+		# Spending the same output again on the selected chain disqualifies the block
+		block E5 E4 spend=A2
+		expect status E5 = DisqualifiedFromChain
+		expect selected-parent virtual = E4
+
+		header H1 E4
+		expect status H1 = HeaderOnly
+	`)
+	if err != nil {
+		t.Fatalf("ParseDAGScenarioString: %+v", err)
+	}
+
+	blocks, err := scenario.Run(tc)
+	if err != nil {
+		t.Fatalf("Run: %+v", err)
+	}
+	if _, ok := blocks["Bad"]; ok {
+		t.Fatalf("Expected the rejected block to not be returned")
+	}
+	if !blocks["genesis"].Equal(consensusConfig.GenesisHash) {
+		t.Fatalf("Expected genesis to be %s, got %s", consensusConfig.GenesisHash, blocks["genesis"])
+	}
+
+	failingScenario, err := testutils.ParseDAGScenarioString(`
+		block A genesis
+		expect blue-score A = 2
+	`)
+	if err != nil {
+		t.Fatalf("ParseDAGScenarioString: %+v", err)
+	}
+	_, err = failingScenario.Run(tc)
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("Expected a failed expectation on line 3, got: %v", err)
+	}
+}
+
+func TestParseDAGScenarioErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario string
+	}{
+		{name: "unknown statement", scenario: "blocks A genesis"},
+		{name: "no parents", scenario: "block A"},
+		{name: "unknown parent", scenario: "block A B"},
+		{name: "duplicate block", scenario: "block A genesis\nblock A genesis"},
+		{name: "reserved name", scenario: "block virtual genesis"},
+		{name: "parent after spend", scenario: "block A genesis\nblock B genesis spend=A A"},
+		{name: "unknown spend", scenario: "block A genesis spend=B"},
+		{name: "invalid transaction index", scenario: "block A genesis spend=genesis.x"},
+		{name: "spending header", scenario: "header A genesis spend=genesis"},
+		{name: "missing equals sign", scenario: "block A genesis\nexpect blues A A"},
+		{name: "unknown property", scenario: "block A genesis\nexpect color A = blue"},
+		{name: "unknown subject", scenario: "expect blues A = genesis"},
+		{name: "invalid score", scenario: "block A genesis\nexpect blue-score A = one"},
+		{name: "rejected block as parent", scenario: "block A genesis reject\nblock B A"},
+		{name: "virtual status", scenario: "expect status virtual = Valid"},
+	}
+
+	for _, test := range tests {
+		_, err := testutils.ParseDAGScenarioString(test.scenario)
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
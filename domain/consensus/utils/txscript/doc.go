@@ -22,6 +22,35 @@ is used to prove the the spender is authorized to perform the transaction.
 One benefit of using a scripting language is added flexibility in specifying
 what conditions must be met in order to spend lings.
 
+# Transaction Introspection
+
+When the ScriptEnableIntrospection flag is set, scripts can inspect the
+transaction that spends them, which makes it possible to restrict where the
+funds go, as vaults and payment channels do. OP_TXINPUTCOUNT,
+OP_TXOUTPUTCOUNT and OP_TXINPUTINDEX push the number of inputs, the number of
+outputs and the index of the input being validated. OP_TXINPUTAMOUNT and
+OP_TXINPUTSPK replace an input index with the amount and the script public key
+of the UTXO entry the input spends, and OP_TXOUTPUTAMOUNT and OP_TXOUTPUTSPK
+replace an output index with the value and the script public key of the output.
+Script public keys are pushed as their version in 2 big-endian bytes followed
+by the script. Since amounts don't fit in 4 bytes, numbers of up to 8 bytes are
+allowed when the flag is set. Consensus sets the flag in blocks whose DAA score
+is at least the IntrospectionActivationDAAScore of the network.
+
+# Splice and Bitwise Opcodes
+
+When the ScriptEnableSpliceOpcodes flag is set, OP_CAT, OP_SUBSTR, OP_LEFT,
+OP_RIGHT, OP_INVERT, OP_AND, OP_OR and OP_XOR are enabled. Without the flag
+they're disabled, and fail the script even in a branch that isn't executed.
+OP_CAT fails if the concatenated element is larger than MaxSpliceElementSize,
+OP_SUBSTR, OP_LEFT and OP_RIGHT fail if they refer to bytes outside of the
+element, and OP_AND, OP_OR and OP_XOR fail if their operands are not of the
+same size. Since these opcodes make scripts more expensive to run, every one of
+them in the script public key an input spends, or in its pay-to-script-hash
+redeem script, adds to the mass of the block that includes the transaction,
+see GetPreciseSpliceOpCount. Consensus sets the flag in blocks whose DAA score is
+at least the SpliceOpcodesActivationDAAScore of the network.
+
 # Errors
 
 Errors returned by this package are of type txscript.Error. This allows the
@@ -20,6 +20,17 @@ type ScriptFlags uint32
 const (
 	// ScriptNoFlags is used when you want to use ScriptFlags without raising any flags
 	ScriptNoFlags ScriptFlags = 0
+
+	// ScriptEnableIntrospection enables the opcodes that push data about the
+	// spending transaction and the UTXO entries it spends, and allows numbers
+	// of up to 8 bytes so that amounts can be compared.
+	ScriptEnableIntrospection ScriptFlags = 1 << 0
+
+	// ScriptEnableSpliceOpcodes enables the splice opcodes OP_CAT,
+	// OP_SUBSTR, OP_LEFT and OP_RIGHT, and the bitwise opcodes OP_INVERT,
+	// OP_AND, OP_OR and OP_XOR. The elements they produce are limited to
+	// MaxSpliceElementSize bytes.
+	ScriptEnableSpliceOpcodes ScriptFlags = 1 << 1
 )
 
 const (
@@ -49,6 +60,7 @@ type Engine struct {
 	sigHashReusedValues *consensushashing.SighashReusedValues
 	isP2SH              bool     // treat execution as pay-to-script-hash
 	savedFirstStack     [][]byte // stack from first script for ps2h scripts
+	tracer              Tracer
 }
 
 // hasFlag returns whether the script engine instance has the passed flag set.
@@ -72,7 +84,7 @@ func (vm *Engine) isBranchExecuting() bool {
 // tested in this case.
 func (vm *Engine) executeOpcode(pop *parsedOpcode) error {
 	// Disabled opcodes are fail on program counter.
-	if pop.isDisabled() {
+	if pop.isDisabled(vm.flags) {
 		str := fmt.Sprintf("attempt to execute disabled opcode %s",
 			pop.opcode.name)
 		return scriptError(ErrDisabledOpcode, str)
@@ -239,6 +251,7 @@ func (vm *Engine) Step() (done bool, err error) {
 		return true, err
 	}
 	opcode := &vm.scripts[vm.scriptIdx][vm.scriptOff]
+	traceStep := vm.beginTraceStep(opcode)
 	vm.scriptOff++
 
 	// Execute the opcode while taking into account several things such as
@@ -246,6 +259,7 @@ func (vm *Engine) Step() (done bool, err error) {
 	// script, maximum script element sizes, and conditionals.
 	err = vm.executeOpcode(opcode)
 	if err != nil {
+		vm.endTraceStep(traceStep, err)
 		return true, err
 	}
 
@@ -255,8 +269,11 @@ func (vm *Engine) Step() (done bool, err error) {
 	if combinedStackSize > MaxStackSize {
 		str := fmt.Sprintf("combined stack size %d > max allowed %d",
 			combinedStackSize, MaxStackSize)
-		return false, scriptError(ErrStackOverflow, str)
+		err = scriptError(ErrStackOverflow, str)
+		vm.endTraceStep(traceStep, err)
+		return false, err
 	}
+	vm.endTraceStep(traceStep, nil)
 
 	// Prepare for next instruction.
 	if vm.scriptOff >= len(vm.scripts[vm.scriptIdx]) {
@@ -456,6 +473,10 @@ func NewEngine(scriptPubKey *externalapi.ScriptPublicKey, tx *externalapi.Domain
 			"false stack entry at end of script execution")
 	}
 	vm := Engine{scriptVersion: scriptPubKey.Version, flags: flags, sigCache: sigCache, sigCacheECDSA: sigCacheECDSA}
+	if vm.hasFlag(ScriptEnableIntrospection) {
+		vm.dstack.scriptNumLen = introspectionScriptNumLen
+		vm.astack.scriptNumLen = introspectionScriptNumLen
+	}
 
 	if vm.scriptVersion > constants.MaxScriptPublicKeyVersion {
 		return &vm, nil
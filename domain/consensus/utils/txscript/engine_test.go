@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/constants"
+	"github.com/ammm56/lings/domain/consensus/utils/utxo"
 
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
 )
@@ -342,3 +344,226 @@ func TestDisasmScript(t *testing.T) {
 		}
 	}
 }
+
+// TestIntrospectionOpcodes ensures the introspection opcodes push the right
+// data about the spending transaction when ScriptEnableIntrospection is set,
+// and are invalid otherwise.
+func TestIntrospectionOpcodes(t *testing.T) {
+	t.Parallel()
+
+	vaultScriptPublicKey := &externalapi.ScriptPublicKey{Script: mustParseShortForm("OP_TRUE", 0), Version: 0}
+	otherScriptPublicKey := &externalapi.ScriptPublicKey{Script: mustParseShortForm("OP_2 OP_DROP OP_TRUE", 0), Version: 1}
+	inputs := []*externalapi.DomainTransactionInput{
+		{
+			PreviousOutpoint: externalapi.DomainOutpoint{Index: 0},
+			SignatureScript:  nil,
+			Sequence:         constants.MaxTxInSequenceNum,
+			UTXOEntry:        utxo.NewUTXOEntry(5_000_000_000, vaultScriptPublicKey, false, 100),
+		},
+		{
+			PreviousOutpoint: externalapi.DomainOutpoint{Index: 1},
+			SignatureScript:  nil,
+			Sequence:         constants.MaxTxInSequenceNum,
+			UTXOEntry:        utxo.NewUTXOEntry(7, otherScriptPublicKey, false, 100),
+		},
+		{
+			PreviousOutpoint: externalapi.DomainOutpoint{Index: 2},
+			SignatureScript:  nil,
+			Sequence:         constants.MaxTxInSequenceNum,
+		},
+	}
+	outputs := []*externalapi.DomainTransactionOutput{
+		{Value: 4_999_990_000, ScriptPublicKey: vaultScriptPublicKey},
+		{Value: 7, ScriptPublicKey: otherScriptPublicKey},
+	}
+	tx := &externalapi.DomainTransaction{
+		Version: 0,
+		Inputs:  inputs,
+		Outputs: outputs,
+	}
+
+	tests := []struct {
+		name        string
+		script      string
+		inputIndex  int
+		flags       ScriptFlags
+		expectedErr error
+	}{
+		{
+			name:   "input count",
+			script: "OP_TXINPUTCOUNT 3 OP_EQUAL",
+			flags:  ScriptEnableIntrospection,
+		},
+		{
+			name:   "output count",
+			script: "OP_TXOUTPUTCOUNT 2 OP_EQUAL",
+			flags:  ScriptEnableIntrospection,
+		},
+		{
+			name:   "input index of the first input",
+			script: "OP_TXINPUTINDEX 0 OP_EQUAL",
+			flags:  ScriptEnableIntrospection,
+		},
+		{
+			name:       "input index of the second input",
+			script:     "OP_TXINPUTINDEX 1 OP_EQUAL",
+			inputIndex: 1,
+			flags:      ScriptEnableIntrospection,
+		},
+		{
+			name:   "input amount above 4 bytes",
+			script: "0 OP_TXINPUTAMOUNT 5000000000 OP_EQUAL",
+			flags:  ScriptEnableIntrospection,
+		},
+		{
+			name:   "input amount of another input",
+			script: "1 OP_TXINPUTAMOUNT 7 OP_EQUAL",
+			flags:  ScriptEnableIntrospection,
+		},
+		{
+			name:        "input amount of an input with a missing UTXO entry",
+			script:      "2 OP_TXINPUTAMOUNT",
+			flags:       ScriptEnableIntrospection,
+			expectedErr: scriptError(ErrMissingUTXOEntry, ""),
+		},
+		{
+			name:        "input amount of an input that doesn't exist",
+			script:      "3 OP_TXINPUTAMOUNT",
+			flags:       ScriptEnableIntrospection,
+			expectedErr: scriptError(ErrInvalidInputIndex, ""),
+		},
+		{
+			name:        "input amount of a negative index",
+			script:      "-1 OP_TXINPUTAMOUNT",
+			flags:       ScriptEnableIntrospection,
+			expectedErr: scriptError(ErrInvalidInputIndex, ""),
+		},
+		{
+			name:        "input amount with an empty stack",
+			script:      "OP_TXINPUTAMOUNT",
+			flags:       ScriptEnableIntrospection,
+			expectedErr: scriptError(ErrInvalidStackOperation, ""),
+		},
+		{
+			name:   "input script public key",
+			script: "0 OP_TXINPUTSPK 0x03 0x000051 OP_EQUAL",
+			flags:  ScriptEnableIntrospection,
+		},
+		{
+			name:   "input script public key with a non-zero version",
+			script: "1 OP_TXINPUTSPK 0x05 0x0001527551 OP_EQUAL",
+			flags:  ScriptEnableIntrospection,
+		},
+		{
+			name:        "input script public key of an input that doesn't exist",
+			script:      "3 OP_TXINPUTSPK",
+			flags:       ScriptEnableIntrospection,
+			expectedErr: scriptError(ErrInvalidInputIndex, ""),
+		},
+		{
+			name:   "output amount",
+			script: "0 OP_TXOUTPUTAMOUNT 4999990000 OP_EQUAL",
+			flags:  ScriptEnableIntrospection,
+		},
+		{
+			name:        "output amount of an output that doesn't exist",
+			script:      "2 OP_TXOUTPUTAMOUNT",
+			flags:       ScriptEnableIntrospection,
+			expectedErr: scriptError(ErrInvalidOutputIndex, ""),
+		},
+		{
+			name:   "output script public key",
+			script: "1 OP_TXOUTPUTSPK 0x05 0x0001527551 OP_EQUAL",
+			flags:  ScriptEnableIntrospection,
+		},
+		{
+			name:        "output script public key of an output that doesn't exist",
+			script:      "2 OP_TXOUTPUTSPK",
+			flags:       ScriptEnableIntrospection,
+			expectedErr: scriptError(ErrInvalidOutputIndex, ""),
+		},
+		{
+			name: "vault that must be spent back to itself with a limited fee",
+			script: "0 OP_TXOUTPUTSPK OP_TXINPUTINDEX OP_TXINPUTSPK OP_EQUALVERIFY " +
+				"0 OP_TXOUTPUTAMOUNT OP_TXINPUTINDEX OP_TXINPUTAMOUNT 10000 OP_SUB OP_GREATERTHANOREQUAL",
+			flags: ScriptEnableIntrospection,
+		},
+		{
+			name: "vault spent with a fee that is too high",
+			script: "0 OP_TXOUTPUTSPK OP_TXINPUTINDEX OP_TXINPUTSPK OP_EQUALVERIFY " +
+				"0 OP_TXOUTPUTAMOUNT OP_TXINPUTINDEX OP_TXINPUTAMOUNT 9999 OP_SUB OP_GREATERTHANOREQUAL",
+			flags:       ScriptEnableIntrospection,
+			expectedErr: scriptError(ErrEvalFalse, ""),
+		},
+		{
+			name:        "vault spent to another script public key",
+			script:      "1 OP_TXOUTPUTSPK OP_TXINPUTINDEX OP_TXINPUTSPK OP_EQUALVERIFY OP_TRUE",
+			inputIndex:  0,
+			flags:       ScriptEnableIntrospection,
+			expectedErr: scriptError(ErrEqualVerify, ""),
+		},
+		{
+			name:        "amount arithmetic overflow",
+			script:      "9223372036854775807 0 OP_TXINPUTAMOUNT OP_ADD",
+			flags:       ScriptEnableIntrospection,
+			expectedErr: scriptError(ErrNumberTooBig, ""),
+		},
+		{
+			name:        "input count without the flag",
+			script:      "OP_TXINPUTCOUNT 3 OP_EQUAL",
+			expectedErr: scriptError(ErrReservedOpcode, ""),
+		},
+		{
+			name:        "output count without the flag",
+			script:      "OP_TXOUTPUTCOUNT 2 OP_EQUAL",
+			expectedErr: scriptError(ErrReservedOpcode, ""),
+		},
+		{
+			name:        "input index without the flag",
+			script:      "OP_TXINPUTINDEX 0 OP_EQUAL",
+			expectedErr: scriptError(ErrReservedOpcode, ""),
+		},
+		{
+			name:        "input amount without the flag",
+			script:      "0 OP_TXINPUTAMOUNT 5000000000 OP_EQUAL",
+			expectedErr: scriptError(ErrReservedOpcode, ""),
+		},
+		{
+			name:        "input script public key without the flag",
+			script:      "0 OP_TXINPUTSPK 0x03 0x000051 OP_EQUAL",
+			expectedErr: scriptError(ErrReservedOpcode, ""),
+		},
+		{
+			name:        "output amount without the flag",
+			script:      "0 OP_TXOUTPUTAMOUNT 4999990000 OP_EQUAL",
+			expectedErr: scriptError(ErrReservedOpcode, ""),
+		},
+		{
+			name:        "output script public key without the flag",
+			script:      "1 OP_TXOUTPUTSPK 0x05 0x0001527551 OP_EQUAL",
+			expectedErr: scriptError(ErrReservedOpcode, ""),
+		},
+		{
+			name:        "numbers above 4 bytes without the flag",
+			script:      "5000000000 1 OP_ADD OP_DROP OP_TRUE",
+			expectedErr: scriptError(ErrNumberTooBig, ""),
+		},
+		{
+			name:   "non-executed branch without the flag",
+			script: "OP_FALSE OP_IF OP_TXINPUTCOUNT OP_ENDIF OP_TRUE",
+		},
+	}
+
+	for _, test := range tests {
+		scriptPubKey := &externalapi.ScriptPublicKey{Script: mustParseShortForm(test.script, 0), Version: 0}
+		vm, err := NewEngine(scriptPubKey, tx, test.inputIndex, test.flags, nil, nil, &consensushashing.SighashReusedValues{})
+		if err != nil {
+			t.Fatalf("TestIntrospectionOpcodes: %s: failed to create the engine: %v", test.name, err)
+		}
+
+		err = vm.Execute()
+		if e := checkScriptError(err, test.expectedErr); e != nil {
+			t.Errorf("TestIntrospectionOpcodes: %s: %s", test.name, e)
+		}
+	}
+}
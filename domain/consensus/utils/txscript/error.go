@@ -214,6 +214,34 @@ const (
 	// is not either an empty vector or [0x01].
 	ErrMinimalIf
 
+	// ---------------------------------------------------
+	// Failures related to transaction introspection.
+	// ---------------------------------------------------
+
+	// ErrInvalidInputIndex is returned when an introspection opcode refers
+	// to an input that the transaction doesn't have.
+	ErrInvalidInputIndex
+
+	// ErrInvalidOutputIndex is returned when an introspection opcode refers
+	// to an output that the transaction doesn't have.
+	ErrInvalidOutputIndex
+
+	// ErrMissingUTXOEntry is returned when an introspection opcode refers to
+	// an input whose UTXO entry isn't populated.
+	ErrMissingUTXOEntry
+
+	// ---------------------------------------------------
+	// Failures related to the splice and bitwise opcodes.
+	// ---------------------------------------------------
+
+	// ErrInvalidSpliceRange is returned when OP_SUBSTR, OP_LEFT or OP_RIGHT
+	// refer to bytes outside of the element they split.
+	ErrInvalidSpliceRange
+
+	// ErrOperandSizeMismatch is returned when the operands of OP_AND, OP_OR
+	// or OP_XOR are not of the same size.
+	ErrOperandSizeMismatch
+
 	// numErrorCodes is the maximum error code number used in tests. This
 	// entry MUST be the last entry in the enum.
 	numErrorCodes
@@ -260,6 +288,11 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrNegativeLockTime:      "ErrNegativeLockTime",
 	ErrUnsatisfiedLockTime:   "ErrUnsatisfiedLockTime",
 	ErrMinimalIf:             "ErrMinimalIf",
+	ErrInvalidInputIndex:     "ErrInvalidInputIndex",
+	ErrInvalidOutputIndex:    "ErrInvalidOutputIndex",
+	ErrMissingUTXOEntry:      "ErrMissingUTXOEntry",
+	ErrInvalidSpliceRange:    "ErrInvalidSpliceRange",
+	ErrOperandSizeMismatch:   "ErrOperandSizeMismatch",
 }
 
 // String returns the ErrorCode as a human-readable name.
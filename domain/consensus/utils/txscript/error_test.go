@@ -55,6 +55,11 @@ func TestErrorCodeStringer(t *testing.T) {
 		{ErrNegativeLockTime, "ErrNegativeLockTime"},
 		{ErrUnsatisfiedLockTime, "ErrUnsatisfiedLockTime"},
 		{ErrMinimalIf, "ErrMinimalIf"},
+		{ErrInvalidInputIndex, "ErrInvalidInputIndex"},
+		{ErrInvalidOutputIndex, "ErrInvalidOutputIndex"},
+		{ErrMissingUTXOEntry, "ErrMissingUTXOEntry"},
+		{ErrInvalidSpliceRange, "ErrInvalidSpliceRange"},
+		{ErrOperandSizeMismatch, "ErrOperandSizeMismatch"},
 		{0xffff, "Unknown ErrorCode (65535)"},
 	}
 
@@ -0,0 +1,63 @@
+package txscript
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/utxo"
+)
+
+func FuzzParseScript(f *testing.F) {
+	f.Fuzz(func(t *testing.T, script []byte) {
+		// None of these may panic, whether the script parses or not
+		_, _ = DisasmString(0, script)
+		GetSigOpCount(script)
+		GetScriptClass(script)
+		IsUnspendable(script)
+
+		parsedScript, err := parseScript(script)
+		if err != nil {
+			return
+		}
+		unparsedScript, err := unparseScript(parsedScript)
+		if err != nil {
+			t.Fatalf("unparseScript failed on parsed script %x: %s", script, err)
+		}
+		if !bytes.Equal(unparsedScript, script) {
+			t.Fatalf("Unparsing script %x resulted in %x", script, unparsedScript)
+		}
+	})
+}
+
+func FuzzExecuteScript(f *testing.F) {
+	f.Fuzz(func(t *testing.T, signatureScript []byte, scriptPublicKey []byte, flags uint8) {
+		scriptPubKey := &externalapi.ScriptPublicKey{Script: scriptPublicKey, Version: 0}
+		tx := &externalapi.DomainTransaction{
+			Version: 0,
+			Inputs: []*externalapi.DomainTransactionInput{{
+				PreviousOutpoint: externalapi.DomainOutpoint{
+					TransactionID: externalapi.DomainTransactionID{},
+					Index:         0,
+				},
+				SignatureScript: signatureScript,
+				Sequence:        0,
+				SigOpCount:      1,
+				UTXOEntry:       utxo.NewUTXOEntry(1000, scriptPubKey, false, 1),
+			}},
+			Outputs: []*externalapi.DomainTransactionOutput{{
+				Value:           900,
+				ScriptPublicKey: scriptPubKey,
+			}},
+			Payload: []byte{},
+		}
+
+		scriptFlags := ScriptFlags(flags) & (ScriptEnableIntrospection | ScriptEnableSpliceOpcodes)
+		vm, err := NewEngine(scriptPubKey, tx, 0, scriptFlags, nil, nil, &consensushashing.SighashReusedValues{})
+		if err != nil {
+			return
+		}
+		_ = vm.Execute()
+	})
+}
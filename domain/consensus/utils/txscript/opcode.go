@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"hash"
 
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
 	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
 
 	"golang.org/x/crypto/blake2b"
@@ -213,13 +214,13 @@ const (
 	OpCheckMultiSigVerify = 0xaf // 175
 	OpCheckLockTimeVerify = 0xb0 // 176
 	OpCheckSequenceVerify = 0xb1 // 177
-	OpUnknown178          = 0xb2 // 178
-	OpUnknown179          = 0xb3 // 179
-	OpUnknown180          = 0xb4 // 180
-	OpUnknown181          = 0xb5 // 181
-	OpUnknown182          = 0xb6 // 182
-	OpUnknown183          = 0xb7 // 183
-	OpUnknown184          = 0xb8 // 184
+	OpTxInputCount        = 0xb2 // 178
+	OpTxOutputCount       = 0xb3 // 179
+	OpTxInputIndex        = 0xb4 // 180
+	OpTxInputAmount       = 0xb5 // 181
+	OpTxInputSPK          = 0xb6 // 182
+	OpTxOutputAmount      = 0xb7 // 183
+	OpTxOutputSPK         = 0xb8 // 184
 	OpUnknown185          = 0xb9 // 185
 	OpUnknown186          = 0xba // 186
 	OpUnknown187          = 0xbb // 187
@@ -439,17 +440,17 @@ var opcodeArray = [256]opcode{
 	OpTuck:         {OpTuck, "OP_TUCK", 1, opcodeTuck},
 
 	// Splice opcodes.
-	OpCat:    {OpCat, "OP_CAT", 1, opcodeDisabled},
-	OpSubStr: {OpSubStr, "OP_SUBSTR", 1, opcodeDisabled},
-	OpLeft:   {OpLeft, "OP_LEFT", 1, opcodeDisabled},
-	OpRight:  {OpRight, "OP_RIGHT", 1, opcodeDisabled},
+	OpCat:    {OpCat, "OP_CAT", 1, opcodeCat},
+	OpSubStr: {OpSubStr, "OP_SUBSTR", 1, opcodeSubStr},
+	OpLeft:   {OpLeft, "OP_LEFT", 1, opcodeLeft},
+	OpRight:  {OpRight, "OP_RIGHT", 1, opcodeRight},
 	OpSize:   {OpSize, "OP_SIZE", 1, opcodeSize},
 
 	// Bitwise logic opcodes.
-	OpInvert:      {OpInvert, "OP_INVERT", 1, opcodeDisabled},
-	OpAnd:         {OpAnd, "OP_AND", 1, opcodeDisabled},
-	OpOr:          {OpOr, "OP_OR", 1, opcodeDisabled},
-	OpXor:         {OpXor, "OP_XOR", 1, opcodeDisabled},
+	OpInvert:      {OpInvert, "OP_INVERT", 1, opcodeInvert},
+	OpAnd:         {OpAnd, "OP_AND", 1, opcodeAnd},
+	OpOr:          {OpOr, "OP_OR", 1, opcodeOr},
+	OpXor:         {OpXor, "OP_XOR", 1, opcodeXor},
 	OpEqual:       {OpEqual, "OP_EQUAL", 1, opcodeEqual},
 	OpEqualVerify: {OpEqualVerify, "OP_EQUALVERIFY", 1, opcodeEqualVerify},
 	OpReserved1:   {OpReserved1, "OP_RESERVED1", 1, opcodeReserved},
@@ -494,19 +495,21 @@ var opcodeArray = [256]opcode{
 	OpCheckMultiSig:       {OpCheckMultiSig, "OP_CHECKMULTISIG", 1, opcodeCheckMultiSig},
 	OpCheckMultiSigVerify: {OpCheckMultiSigVerify, "OP_CHECKMULTISIGVERIFY", 1, opcodeCheckMultiSigVerify},
 
+	// Transaction introspection opcodes.
+	OpTxInputCount:   {OpTxInputCount, "OP_TXINPUTCOUNT", 1, opcodeTxInputCount},
+	OpTxOutputCount:  {OpTxOutputCount, "OP_TXOUTPUTCOUNT", 1, opcodeTxOutputCount},
+	OpTxInputIndex:   {OpTxInputIndex, "OP_TXINPUTINDEX", 1, opcodeTxInputIndex},
+	OpTxInputAmount:  {OpTxInputAmount, "OP_TXINPUTAMOUNT", 1, opcodeTxInputAmount},
+	OpTxInputSPK:     {OpTxInputSPK, "OP_TXINPUTSPK", 1, opcodeTxInputSPK},
+	OpTxOutputAmount: {OpTxOutputAmount, "OP_TXOUTPUTAMOUNT", 1, opcodeTxOutputAmount},
+	OpTxOutputSPK:    {OpTxOutputSPK, "OP_TXOUTPUTSPK", 1, opcodeTxOutputSPK},
+
 	// Undefined opcodes.
 	OpUnknown166: {OpUnknown166, "OP_UNKNOWN166", 1, opcodeInvalid},
 	OpUnknown167: {OpUnknown167, "OP_UNKNOWN167", 1, opcodeInvalid},
-	OpUnknown178: {OpUnknown188, "OP_UNKNOWN178", 1, opcodeInvalid},
-	OpUnknown179: {OpUnknown189, "OP_UNKNOWN179", 1, opcodeInvalid},
-	OpUnknown180: {OpUnknown190, "OP_UNKNOWN180", 1, opcodeInvalid},
-	OpUnknown181: {OpUnknown191, "OP_UNKNOWN181", 1, opcodeInvalid},
-	OpUnknown182: {OpUnknown192, "OP_UNKNOWN182", 1, opcodeInvalid},
-	OpUnknown183: {OpUnknown193, "OP_UNKNOWN183", 1, opcodeInvalid},
-	OpUnknown184: {OpUnknown194, "OP_UNKNOWN184", 1, opcodeInvalid},
-	OpUnknown185: {OpUnknown195, "OP_UNKNOWN185", 1, opcodeInvalid},
-	OpUnknown186: {OpUnknown196, "OP_UNKNOWN186", 1, opcodeInvalid},
-	OpUnknown187: {OpUnknown197, "OP_UNKNOWN187", 1, opcodeInvalid},
+	OpUnknown185: {OpUnknown185, "OP_UNKNOWN185", 1, opcodeInvalid},
+	OpUnknown186: {OpUnknown186, "OP_UNKNOWN186", 1, opcodeInvalid},
+	OpUnknown187: {OpUnknown187, "OP_UNKNOWN187", 1, opcodeInvalid},
 	OpUnknown188: {OpUnknown188, "OP_UNKNOWN188", 1, opcodeInvalid},
 	OpUnknown189: {OpUnknown189, "OP_UNKNOWN189", 1, opcodeInvalid},
 	OpUnknown190: {OpUnknown190, "OP_UNKNOWN190", 1, opcodeInvalid},
@@ -613,24 +616,14 @@ type parsedOpcode struct {
 
 // isDisabled returns whether or not the opcode is disabled and thus is always
 // bad to see in the instruction stream (even if turned off by a conditional).
-func (pop *parsedOpcode) isDisabled() bool {
+// The splice and bitwise opcodes are disabled unless ScriptEnableSpliceOpcodes
+// is set in the given flags.
+func (pop *parsedOpcode) isDisabled(flags ScriptFlags) bool {
+	if pop.isSpliceOrBitwise() {
+		return flags&ScriptEnableSpliceOpcodes != ScriptEnableSpliceOpcodes
+	}
+
 	switch pop.opcode.value {
-	case OpCat:
-		return true
-	case OpSubStr:
-		return true
-	case OpLeft:
-		return true
-	case OpRight:
-		return true
-	case OpInvert:
-		return true
-	case OpAnd:
-		return true
-	case OpOr:
-		return true
-	case OpXor:
-		return true
 	case Op2Mul:
 		return true
 	case Op2Div:
@@ -650,6 +643,17 @@ func (pop *parsedOpcode) isDisabled() bool {
 	}
 }
 
+// isSpliceOrBitwise returns whether or not the opcode is one of the splice or
+// bitwise opcodes that are enabled by ScriptEnableSpliceOpcodes.
+func (pop *parsedOpcode) isSpliceOrBitwise() bool {
+	switch pop.opcode.value {
+	case OpCat, OpSubStr, OpLeft, OpRight, OpInvert, OpAnd, OpOr, OpXor:
+		return true
+	default:
+		return false
+	}
+}
+
 // alwaysIllegal returns whether or not the opcode is always illegal when passed
 // over by the program counter even if in a non-executed branch (it isn't a
 // coincidence that they are conditionals).
@@ -1399,6 +1403,171 @@ func opcodeSize(op *parsedOpcode, vm *Engine) error {
 	return nil
 }
 
+// opcodeCat removes the top 2 items of the data stack and pushes their
+// concatenation. The result may not be larger than MaxSpliceElementSize.
+//
+// Stack transformation: [... x1 x2] -> [... x1||x2]
+func opcodeCat(op *parsedOpcode, vm *Engine) error {
+	x2, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+	x1, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+
+	if len(x1)+len(x2) > MaxSpliceElementSize {
+		str := fmt.Sprintf("concatenated size %d exceeds max allowed size %d",
+			len(x1)+len(x2), MaxSpliceElementSize)
+		return scriptError(ErrElementTooBig, str)
+	}
+	result := make([]byte, 0, len(x1)+len(x2))
+	result = append(result, x1...)
+	vm.dstack.PushByteArray(append(result, x2...))
+	return nil
+}
+
+// opcodeSubStr replaces the top 3 items of the data stack with the size bytes
+// of x that start at begin. The range must be within x.
+//
+// Stack transformation: [... x begin size] -> [... x[begin:begin+size]]
+func opcodeSubStr(op *parsedOpcode, vm *Engine) error {
+	size, err := vm.dstack.PopInt()
+	if err != nil {
+		return err
+	}
+	begin, err := vm.dstack.PopInt()
+	if err != nil {
+		return err
+	}
+	x, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+
+	if begin < 0 || size < 0 || int64(begin)+int64(size) > int64(len(x)) {
+		str := fmt.Sprintf("range of %d bytes from %d is out of bounds for an element of size %d",
+			size, begin, len(x))
+		return scriptError(ErrInvalidSpliceRange, str)
+	}
+	vm.dstack.PushByteArray(x[begin : begin+size])
+	return nil
+}
+
+// opcodeLeft replaces the top 2 items of the data stack with the first size
+// bytes of x. size may not be larger than the size of x.
+//
+// Stack transformation: [... x size] -> [... x[:size]]
+func opcodeLeft(op *parsedOpcode, vm *Engine) error {
+	x, size, err := popSpliceSize(vm)
+	if err != nil {
+		return err
+	}
+
+	vm.dstack.PushByteArray(x[:size])
+	return nil
+}
+
+// opcodeRight replaces the top 2 items of the data stack with the last size
+// bytes of x. size may not be larger than the size of x.
+//
+// Stack transformation: [... x size] -> [... x[len(x)-size:]]
+func opcodeRight(op *parsedOpcode, vm *Engine) error {
+	x, size, err := popSpliceSize(vm)
+	if err != nil {
+		return err
+	}
+
+	vm.dstack.PushByteArray(x[len(x)-size:])
+	return nil
+}
+
+// popSpliceSize pops a size and the element it applies to off the data stack,
+// and makes sure the size is within the element.
+func popSpliceSize(vm *Engine) ([]byte, int, error) {
+	size, err := vm.dstack.PopInt()
+	if err != nil {
+		return nil, 0, err
+	}
+	x, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if size < 0 || int64(size) > int64(len(x)) {
+		str := fmt.Sprintf("size %d is out of bounds for an element of size %d", size, len(x))
+		return nil, 0, scriptError(ErrInvalidSpliceRange, str)
+	}
+	return x, int(size), nil
+}
+
+// opcodeInvert replaces the top item of the data stack with its bitwise
+// inversion.
+//
+// Stack transformation: [... x] -> [... ^x]
+func opcodeInvert(op *parsedOpcode, vm *Engine) error {
+	x, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+
+	result := make([]byte, len(x))
+	for i := range x {
+		result[i] = ^x[i]
+	}
+	vm.dstack.PushByteArray(result)
+	return nil
+}
+
+// opcodeAnd replaces the top 2 items of the data stack, which must be of the
+// same size, with their bitwise AND.
+//
+// Stack transformation: [... x1 x2] -> [... x1&x2]
+func opcodeAnd(op *parsedOpcode, vm *Engine) error {
+	return abstractBitwise(vm, func(a, b byte) byte { return a & b })
+}
+
+// opcodeOr replaces the top 2 items of the data stack, which must be of the
+// same size, with their bitwise OR.
+//
+// Stack transformation: [... x1 x2] -> [... x1|x2]
+func opcodeOr(op *parsedOpcode, vm *Engine) error {
+	return abstractBitwise(vm, func(a, b byte) byte { return a | b })
+}
+
+// opcodeXor replaces the top 2 items of the data stack, which must be of the
+// same size, with their bitwise XOR.
+//
+// Stack transformation: [... x1 x2] -> [... x1^x2]
+func opcodeXor(op *parsedOpcode, vm *Engine) error {
+	return abstractBitwise(vm, func(a, b byte) byte { return a ^ b })
+}
+
+// abstractBitwise is a common handler for the bitwise opcodes that combine the
+// top 2 items of the data stack byte by byte.
+func abstractBitwise(vm *Engine, operation func(a, b byte) byte) error {
+	x2, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+	x1, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+
+	if len(x1) != len(x2) {
+		str := fmt.Sprintf("operands of sizes %d and %d are not of the same size", len(x1), len(x2))
+		return scriptError(ErrOperandSizeMismatch, str)
+	}
+	result := make([]byte, len(x1))
+	for i := range x1 {
+		result[i] = operation(x1[i], x2[i])
+	}
+	vm.dstack.PushByteArray(result)
+	return nil
+}
+
 // opcodeEqual removes the top 2 items of the data stack, compares them as raw
 // bytes, and pushes the result, encoded as a boolean, back to the stack.
 //
@@ -1442,7 +1611,11 @@ func opcode1Add(op *parsedOpcode, vm *Engine) error {
 		return err
 	}
 
-	vm.dstack.PushInt(m + 1)
+	result, err := addScriptNums(m, 1)
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(result)
 	return nil
 }
 
@@ -1455,8 +1628,11 @@ func opcode1Sub(op *parsedOpcode, vm *Engine) error {
 	if err != nil {
 		return err
 	}
-	vm.dstack.PushInt(m - 1)
-
+	result, err := addScriptNums(m, -1)
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(result)
 	return nil
 }
 
@@ -1551,7 +1727,11 @@ func opcodeAdd(op *parsedOpcode, vm *Engine) error {
 		return err
 	}
 
-	vm.dstack.PushInt(v0 + v1)
+	result, err := addScriptNums(v0, v1)
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(result)
 	return nil
 }
 
@@ -1571,7 +1751,11 @@ func opcodeSub(op *parsedOpcode, vm *Engine) error {
 		return err
 	}
 
-	vm.dstack.PushInt(v1 - v0)
+	result, err := addScriptNums(v1, -v0)
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(result)
 	return nil
 }
 
@@ -2479,6 +2663,169 @@ func opcodeCheckMultiSigVerify(op *parsedOpcode, vm *Engine) error {
 	return err
 }
 
+// opcodeTxInputCount pushes the number of inputs of the spending transaction
+// to the data stack. It's an invalid opcode unless ScriptEnableIntrospection
+// is set.
+//
+// Stack transformation: [...] -> [... inputcount]
+func opcodeTxInputCount(op *parsedOpcode, vm *Engine) error {
+	if !vm.hasFlag(ScriptEnableIntrospection) {
+		return opcodeInvalid(op, vm)
+	}
+
+	vm.dstack.PushInt(scriptNum(len(vm.tx.Inputs)))
+	return nil
+}
+
+// opcodeTxOutputCount pushes the number of outputs of the spending transaction
+// to the data stack. It's an invalid opcode unless ScriptEnableIntrospection
+// is set.
+//
+// Stack transformation: [...] -> [... outputcount]
+func opcodeTxOutputCount(op *parsedOpcode, vm *Engine) error {
+	if !vm.hasFlag(ScriptEnableIntrospection) {
+		return opcodeInvalid(op, vm)
+	}
+
+	vm.dstack.PushInt(scriptNum(len(vm.tx.Outputs)))
+	return nil
+}
+
+// opcodeTxInputIndex pushes the index of the input whose script is being
+// executed to the data stack. It's an invalid opcode unless
+// ScriptEnableIntrospection is set.
+//
+// Stack transformation: [...] -> [... inputindex]
+func opcodeTxInputIndex(op *parsedOpcode, vm *Engine) error {
+	if !vm.hasFlag(ScriptEnableIntrospection) {
+		return opcodeInvalid(op, vm)
+	}
+
+	vm.dstack.PushInt(scriptNum(vm.txIdx))
+	return nil
+}
+
+// opcodeTxInputAmount replaces the input index on top of the data stack with
+// the amount of the UTXO entry that the input spends. It's an invalid opcode
+// unless ScriptEnableIntrospection is set.
+//
+// Stack transformation: [... inputindex] -> [... amount]
+func opcodeTxInputAmount(op *parsedOpcode, vm *Engine) error {
+	if !vm.hasFlag(ScriptEnableIntrospection) {
+		return opcodeInvalid(op, vm)
+	}
+
+	utxoEntry, err := popInputUTXOEntry(vm)
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(scriptNum(utxoEntry.Amount()))
+	return nil
+}
+
+// opcodeTxInputSPK replaces the input index on top of the data stack with the
+// script public key of the UTXO entry that the input spends, serialized as its
+// version in big-endian followed by the script. It's an invalid opcode unless
+// ScriptEnableIntrospection is set.
+//
+// Stack transformation: [... inputindex] -> [... scriptpubkey]
+func opcodeTxInputSPK(op *parsedOpcode, vm *Engine) error {
+	if !vm.hasFlag(ScriptEnableIntrospection) {
+		return opcodeInvalid(op, vm)
+	}
+
+	utxoEntry, err := popInputUTXOEntry(vm)
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushByteArray(serializeScriptPublicKey(utxoEntry.ScriptPublicKey()))
+	return nil
+}
+
+// opcodeTxOutputAmount replaces the output index on top of the data stack with
+// the value of the output. It's an invalid opcode unless
+// ScriptEnableIntrospection is set.
+//
+// Stack transformation: [... outputindex] -> [... value]
+func opcodeTxOutputAmount(op *parsedOpcode, vm *Engine) error {
+	if !vm.hasFlag(ScriptEnableIntrospection) {
+		return opcodeInvalid(op, vm)
+	}
+
+	output, err := popOutput(vm)
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(scriptNum(output.Value))
+	return nil
+}
+
+// opcodeTxOutputSPK replaces the output index on top of the data stack with the
+// script public key of the output, serialized as its version in big-endian
+// followed by the script. It's an invalid opcode unless
+// ScriptEnableIntrospection is set.
+//
+// Stack transformation: [... outputindex] -> [... scriptpubkey]
+func opcodeTxOutputSPK(op *parsedOpcode, vm *Engine) error {
+	if !vm.hasFlag(ScriptEnableIntrospection) {
+		return opcodeInvalid(op, vm)
+	}
+
+	output, err := popOutput(vm)
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushByteArray(serializeScriptPublicKey(output.ScriptPublicKey))
+	return nil
+}
+
+// popInputUTXOEntry pops an input index off the data stack and returns the
+// UTXO entry that the input spends.
+func popInputUTXOEntry(vm *Engine) (externalapi.UTXOEntry, error) {
+	index, err := vm.dstack.PopInt()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || int64(index) >= int64(len(vm.tx.Inputs)) {
+		str := fmt.Sprintf("input index %d is out of range for a transaction with %d inputs",
+			index, len(vm.tx.Inputs))
+		return nil, scriptError(ErrInvalidInputIndex, str)
+	}
+
+	utxoEntry := vm.tx.Inputs[index].UTXOEntry
+	if utxoEntry == nil {
+		str := fmt.Sprintf("the UTXO entry of input %d is missing", index)
+		return nil, scriptError(ErrMissingUTXOEntry, str)
+	}
+	return utxoEntry, nil
+}
+
+// popOutput pops an output index off the data stack and returns the output.
+func popOutput(vm *Engine) (*externalapi.DomainTransactionOutput, error) {
+	index, err := vm.dstack.PopInt()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || int64(index) >= int64(len(vm.tx.Outputs)) {
+		str := fmt.Sprintf("output index %d is out of range for a transaction with %d outputs",
+			index, len(vm.tx.Outputs))
+		return nil, scriptError(ErrInvalidOutputIndex, str)
+	}
+	return vm.tx.Outputs[index], nil
+}
+
+// serializeScriptPublicKey returns the script public key as it's pushed by the
+// introspection opcodes: its version as 2 big-endian bytes followed by the
+// script.
+func serializeScriptPublicKey(scriptPublicKey *externalapi.ScriptPublicKey) []byte {
+	if scriptPublicKey == nil {
+		return []byte{0, 0}
+	}
+	serialized := make([]byte, 2, 2+len(scriptPublicKey.Script))
+	binary.BigEndian.PutUint16(serialized, scriptPublicKey.Version)
+	return append(serialized, scriptPublicKey.Script...)
+}
+
 // OpcodeByName is a map that can be used to lookup an opcode by its
 // human-readable name (OP_CHECKMULTISIG, OP_CHECKSIG, etc).
 var OpcodeByName = make(map[string]byte)
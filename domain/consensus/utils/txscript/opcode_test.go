@@ -33,6 +33,18 @@ func TestOpcodeDisabled(t *testing.T) {
 	}
 }
 
+// TestOpcodeValues ensures that every opcode in the opcode array carries its own
+// value, since the value is what gets written back when a script is serialized.
+func TestOpcodeValues(t *testing.T) {
+	t.Parallel()
+
+	for i, opcode := range opcodeArray {
+		if int(opcode.value) != i {
+			t.Errorf("opcodeArray[%d] (%s) has value %d", i, opcode.name, opcode.value)
+		}
+	}
+}
+
 // TestOpcodeDisasm tests the print function for all opcodes in both the oneline
 // and full modes to ensure it provides the expected disassembly.
 func TestOpcodeDisasm(t *testing.T) {
@@ -76,6 +88,10 @@ func TestOpcodeDisasm(t *testing.T) {
 		0xab: "OP_CHECKSIGECDSA", 0xac: "OP_CHECKSIG", 0xad: "OP_CHECKSIGVERIFY",
 		0xae: "OP_CHECKMULTISIG", 0xaf: "OP_CHECKMULTISIGVERIFY",
 		0xb0: "OP_CHECKLOCKTIMEVERIFY", 0xb1: "OP_CHECKSEQUENCEVERIFY",
+		0xb2: "OP_TXINPUTCOUNT", 0xb3: "OP_TXOUTPUTCOUNT",
+		0xb4: "OP_TXINPUTINDEX", 0xb5: "OP_TXINPUTAMOUNT",
+		0xb6: "OP_TXINPUTSPK", 0xb7: "OP_TXOUTPUTAMOUNT",
+		0xb8: "OP_TXOUTPUTSPK",
 		0xfa: "OP_SMALLINTEGER", 0xfb: "OP_PUBKEYS",
 		0xfd: "OP_PUBKEYHASH", 0xfe: "OP_PUBKEY",
 		0xff: "OP_INVALIDOPCODE",
@@ -178,6 +194,6 @@ func TestOpcodeDisasm(t *testing.T) {
 }
 
 func isOpUnknown(opcodeVal int) bool {
-	return opcodeVal >= 0xb2 && opcodeVal <= 0xf9 || opcodeVal == 0xfc ||
+	return opcodeVal >= 0xb9 && opcodeVal <= 0xf9 || opcodeVal == 0xfc ||
 		opcodeVal == 0xa6 || opcodeVal == 0xa7
 }
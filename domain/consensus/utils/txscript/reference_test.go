@@ -18,6 +18,7 @@ import (
 	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
 
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/utxo"
 	"github.com/ammm56/lings/infrastructure/logger"
 	"github.com/pkg/errors"
 )
@@ -139,6 +140,10 @@ func parseScriptFlags(flagStr string) (ScriptFlags, error) {
 		switch flag {
 		case "":
 			// Nothing.
+		case "INTROSPECTION":
+			flags |= ScriptEnableIntrospection
+		case "SPLICE":
+			flags |= ScriptEnableSpliceOpcodes
 		default:
 			return flags, errors.Errorf("invalid flag: %s", flag)
 		}
@@ -206,6 +211,14 @@ func parseExpectedResult(expected string) ([]ErrorCode, error) {
 		return []ErrorCode{ErrUnsatisfiedLockTime}, nil
 	case "MINIMALIF":
 		return []ErrorCode{ErrMinimalIf}, nil
+	case "INVALID_INPUT_INDEX":
+		return []ErrorCode{ErrInvalidInputIndex}, nil
+	case "INVALID_OUTPUT_INDEX":
+		return []ErrorCode{ErrInvalidOutputIndex}, nil
+	case "INVALID_SPLICE_RANGE":
+		return []ErrorCode{ErrInvalidSpliceRange}, nil
+	case "OPERAND_SIZE_MISMATCH":
+		return []ErrorCode{ErrOperandSizeMismatch}, nil
 	}
 
 	return nil, errors.Errorf("unrecognized expected result in test data: %v",
@@ -239,6 +252,7 @@ func createSpendingTx(sigScript []byte, scriptPubKey *externalapi.ScriptPublicKe
 		PreviousOutpoint: outpoint,
 		SignatureScript:  sigScript,
 		Sequence:         constants.MaxTxInSequenceNum,
+		UTXOEntry:        utxo.NewUTXOEntry(output.Value, output.ScriptPublicKey, true, 0),
 	}
 	output = &externalapi.DomainTransactionOutput{Value: 0, ScriptPublicKey: nil}
 	spendingTx := &externalapi.DomainTransaction{
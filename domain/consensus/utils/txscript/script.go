@@ -18,6 +18,7 @@ const (
 	MaxOpsPerScript       = 201 // Max number of non-push operations.
 	MaxPubKeysPerMultiSig = 20  // Multisig can't have more sigs than this.
 	MaxScriptElementSize  = 520 // Max bytes pushable to the stack.
+	MaxSpliceElementSize  = 520 // Max bytes of an element produced by the splice opcodes.
 )
 
 // isSmallInt returns whether or not the opcode is considered a small integer,
@@ -320,6 +321,39 @@ func GetPreciseSigOpCount(scriptSig []byte, scriptPubKey *externalapi.ScriptPubl
 	return getSigOpCount(shPops, true)
 }
 
+// GetPreciseSpliceOpCount returns the number of splice and bitwise operations
+// in scriptPubKey, and if it's a pay-to-script-hash, in the script it commits
+// to, which is the last item scriptSig pushes to the stack. If a script fails
+// to parse, then the count up to the point of failure is returned.
+func GetPreciseSpliceOpCount(scriptSig []byte, scriptPubKey *externalapi.ScriptPublicKey) int {
+	// Don't check error since parseScript returns the parsed-up-to-error
+	// list of pops.
+	pops, _ := parseScript(scriptPubKey.Script)
+	if !isScriptHash(pops) {
+		return getSpliceOpCount(pops)
+	}
+
+	// Signature scripts that don't fully parse or aren't push only can't
+	// be valid for a pay-to-script-hash, so they count as 0 operations.
+	sigPops, err := parseScript(scriptSig)
+	if err != nil || !isPushOnly(sigPops) || len(sigPops) == 0 {
+		return 0
+	}
+	shPops, _ := parseScript(sigPops[len(sigPops)-1].data)
+	return getSpliceOpCount(shPops)
+}
+
+// getSpliceOpCount returns the number of splice and bitwise opcodes in pops
+func getSpliceOpCount(pops []parsedOpcode) int {
+	count := 0
+	for _, pop := range pops {
+		if pop.isSpliceOrBitwise() {
+			count++
+		}
+	}
+	return count
+}
+
 // IsUnspendable returns whether the passed public key script is unspendable, or
 // guaranteed to fail at execution. This allows inputs to be pruned instantly
 // when entering the UTXO set.
@@ -3677,6 +3677,75 @@ func TestGetPreciseSigOps(t *testing.T) {
 	}
 }
 
+// TestGetPreciseSpliceOps ensures that the splice and bitwise operations are
+// counted in script public keys and in pay-to-script-hash redeem scripts.
+func TestGetPreciseSpliceOps(t *testing.T) {
+	t.Parallel()
+
+	redeemScript := mustParseShortForm("CAT 0 SUBSTR DUP XOR SHA256 EQUAL", 0)
+	p2shScript, err := PayToScriptHashScript(redeemScript)
+	if err != nil {
+		t.Fatalf("PayToScriptHashScript: %s", err)
+	}
+	p2shScriptPubKey := &externalapi.ScriptPublicKey{Script: p2shScript, Version: 0}
+	sigScript, err := NewScriptBuilder().AddData([]byte("salt")).AddData(redeemScript).Script()
+	if err != nil {
+		t.Fatalf("Script: %s", err)
+	}
+
+	tests := []struct {
+		name            string
+		scriptSig       []byte
+		scriptPublicKey *externalapi.ScriptPublicKey
+		nSpliceOps      int
+	}{
+		{
+			name:      "splice operations in the script public key",
+			scriptSig: nil,
+			scriptPublicKey: &externalapi.ScriptPublicKey{
+				Script:  mustParseShortForm("CAT 2 LEFT INVERT 1 RIGHT AND OR", 0),
+				Version: 0,
+			},
+			nSpliceOps: 6,
+		},
+		{
+			name:      "a partially parseable script public key",
+			scriptSig: nil,
+			scriptPublicKey: &externalapi.ScriptPublicKey{
+				Script:  mustParseShortForm("CAT CAT DATA_1", 0),
+				Version: 0,
+			},
+			nSpliceOps: 2,
+		},
+		{
+			name:            "p2sh redeem script",
+			scriptSig:       sigScript,
+			scriptPublicKey: p2shScriptPubKey,
+			nSpliceOps:      3,
+		},
+		{
+			name:            "p2sh scriptSig isn't push only",
+			scriptSig:       mustParseShortForm("1 CAT", 0),
+			scriptPublicKey: p2shScriptPubKey,
+			nSpliceOps:      0,
+		},
+		{
+			name:            "p2sh scriptSig length 0",
+			scriptSig:       nil,
+			scriptPublicKey: p2shScriptPubKey,
+			nSpliceOps:      0,
+		},
+	}
+
+	for _, test := range tests {
+		count := GetPreciseSpliceOpCount(test.scriptSig, test.scriptPublicKey)
+		if count != test.nSpliceOps {
+			t.Errorf("%s: expected count of %d, got %d", test.name,
+				test.nSpliceOps, count)
+		}
+	}
+}
+
 // TestIsPayToScriptHash ensures the IsPayToScriptHash function returns the
 // expected results for all the scripts in scriptClassTests.
 func TestIsPayToScriptHash(t *testing.T) {
@@ -6,12 +6,18 @@ package txscript
 
 import (
 	"fmt"
+	"math"
 )
 
 const (
 	maxInt32 = 1<<31 - 1
 	minInt32 = -1 << 31
 
+	// introspectionScriptNumLen is the number of bytes data interpreted as
+	// integers may be when ScriptEnableIntrospection is set. It's large
+	// enough for the amounts pushed by the introspection opcodes.
+	introspectionScriptNumLen = 8
+
 	// defaultScriptNumLen is the default number of bytes
 	// data being interpreted as an integer may be.
 	defaultScriptNumLen = 4
@@ -162,6 +168,18 @@ func (n scriptNum) Int32() int32 {
 	return int32(n)
 }
 
+// addScriptNums returns the sum of a and b, or an error if it overflows the
+// range of numbers that can be encoded in 8 bytes. Both numbers must have been
+// created with makeScriptNum.
+func addScriptNums(a, b scriptNum) (scriptNum, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) || sum == math.MinInt64 {
+		str := fmt.Sprintf("the sum of %d and %d is out of range", a, b)
+		return 0, scriptError(ErrNumberTooBig, str)
+	}
+	return sum, nil
+}
+
 // makeScriptNum interprets the passed serialized bytes as an encoded integer
 // and returns the result as a script number.
 //
@@ -37,6 +37,10 @@ func fromBool(v bool) []byte {
 // stack.
 type stack struct {
 	stk [][]byte
+
+	// scriptNumLen is the maximum number of bytes of data interpreted as an
+	// integer. Zero means defaultScriptNumLen.
+	scriptNumLen int
 }
 
 // Depth returns the number of items on the stack.
@@ -85,7 +89,16 @@ func (s *stack) PopInt() (scriptNum, error) {
 		return 0, err
 	}
 
-	return makeScriptNum(so, defaultScriptNumLen)
+	return makeScriptNum(so, s.maxScriptNumLen())
+}
+
+// maxScriptNumLen returns the maximum number of bytes of data interpreted as an
+// integer
+func (s *stack) maxScriptNumLen() int {
+	if s.scriptNumLen == 0 {
+		return defaultScriptNumLen
+	}
+	return s.scriptNumLen
 }
 
 // PopBool pops the value off the top of the stack, converts it into a bool, and
@@ -122,7 +135,7 @@ func (s *stack) PeekInt(idx int32) (scriptNum, error) {
 		return 0, err
 	}
 
-	return makeScriptNum(so, defaultScriptNumLen)
+	return makeScriptNum(so, s.maxScriptNumLen())
 }
 
 // PeekBool returns the Nth item on the stack as a bool without removing it.
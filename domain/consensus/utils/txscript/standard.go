@@ -20,10 +20,15 @@ type ScriptClass byte
 
 // Classes of script payment known about in the blockDAG.
 const (
-	NonStandardTy ScriptClass = iota // None of the recognized forms.
-	PubKeyTy                         // Pay to pubkey.
-	PubKeyECDSATy                    // Pay to pubkey ECDSA.
-	ScriptHashTy                     // Pay to script hash.
+	NonStandardTy  ScriptClass = iota // None of the recognized forms.
+	PubKeyTy                          // Pay to pubkey.
+	PubKeyECDSATy                     // Pay to pubkey ECDSA.
+	ScriptHashTy                      // Pay to script hash.
+	MultiSigTy                        // Multisig.
+	AtomicSwapTy                      // Atomic swap contract.
+	TimeLockTy                        // Pay to pubkey after an absolute lock time.
+	SequenceLockTy                    // Pay to pubkey after a relative lock time.
+	RefundTy                          // Pay to pubkey, refundable after a relative lock time.
 )
 
 // Script public key versions for address types.
@@ -36,10 +41,15 @@ const (
 // scriptClassToName houses the human-readable strings which describe each
 // script class.
 var scriptClassToName = []string{
-	NonStandardTy: "nonstandard",
-	PubKeyTy:      "pubkey",
-	PubKeyECDSATy: "pubkeyecdsa",
-	ScriptHashTy:  "scripthash",
+	NonStandardTy:  "nonstandard",
+	PubKeyTy:       "pubkey",
+	PubKeyECDSATy:  "pubkeyecdsa",
+	ScriptHashTy:   "scripthash",
+	MultiSigTy:     "multisig",
+	AtomicSwapTy:   "atomicswap",
+	TimeLockTy:     "timelock",
+	SequenceLockTy: "sequencelock",
+	RefundTy:       "refund",
 }
 
 // String implements the Stringer interface by returning the name of
@@ -79,6 +89,16 @@ func typeOfScript(pops []parsedOpcode) ScriptClass {
 		return PubKeyECDSATy
 	case isScriptHash(pops):
 		return ScriptHashTy
+	case isMultiSig(pops):
+		return MultiSigTy
+	case isAtomicSwap(pops):
+		return AtomicSwapTy
+	case isTimeLock(pops, OpCheckLockTimeVerify):
+		return TimeLockTy
+	case isTimeLock(pops, OpCheckSequenceVerify):
+		return SequenceLockTy
+	case isRefund(pops):
+		return RefundTy
 	}
 	return NonStandardTy
 }
@@ -109,6 +129,25 @@ func expectedInputs(pops []parsedOpcode, class ScriptClass) int {
 		// Not including script. That is handled by the caller.
 		return 1
 
+	case MultiSigTy:
+		// Standard multisig has a push of the number of required
+		// signatures as its first opcode, and that many signatures are
+		// expected.
+		return asSmallInt(pops[0].opcode)
+
+	case TimeLockTy, SequenceLockTy:
+		return 1
+
+	case RefundTy:
+		// A signature and the selector of the branch.
+		return 2
+
+	case AtomicSwapTy:
+		// The redeem branch expects a signature, a public key, the
+		// secret and the branch selector, while the refund branch
+		// doesn't expect the secret, so the number is unknown.
+		return -1
+
 	default:
 		return -1
 	}
@@ -303,7 +342,8 @@ func PushedData(script []byte) ([][]byte, error) {
 
 // ExtractScriptPubKeyAddress returns the type of script and its addresses.
 // Note that it only works for 'standard' transaction script types. Any data such
-// as public keys which are invalid will return a nil address.
+// as public keys which are invalid will return a nil address, and so do the
+// standard types that can be spent by more than one key.
 func ExtractScriptPubKeyAddress(scriptPubKey *externalapi.ScriptPublicKey, dagParams *dagconfig.Params) (ScriptClass, util.Address, error) {
 	if scriptPubKey.Version > constants.MaxScriptPublicKeyVersion {
 		return NonStandardTy, nil, nil
@@ -352,6 +392,25 @@ func ExtractScriptPubKeyAddress(scriptPubKey *externalapi.ScriptPublicKey, dagPa
 		}
 		return scriptClass, addr, nil
 
+	case TimeLockTy, SequenceLockTy:
+		// A time-locked script is of the form:
+		// <lock time or sequence> OP_CHECKLOCKTIMEVERIFY/OP_CHECKSEQUENCEVERIFY
+		// <pubkey> OP_CHECKSIG
+		// Therefore the pubkey, which is the only key that can spend the
+		// output, is the third item on the stack.
+		addr, err := util.NewAddressPublicKey(pops[2].data,
+			dagParams.Prefix)
+		if err != nil {
+			return scriptClass, nil, nil
+		}
+		return scriptClass, addr, nil
+
+	case MultiSigTy, AtomicSwapTy, RefundTy:
+		// These scripts can be spent by more than one key, so they don't
+		// have a single address. Their parameters can be extracted with
+		// the Extract*DataPushes functions.
+		return scriptClass, nil, nil
+
 	case NonStandardTy:
 		// Don't attempt to extract addresses or required signatures for
 		// nonstandard transactions.
@@ -375,41 +434,14 @@ type AtomicSwapDataPushes struct {
 // ExtractAtomicSwapDataPushes returns (nil, nil). Non-nil errors are returned
 // for unparsable scripts.
 //
-// NOTE: Atomic swaps are not considered standard script types by the dcrd
-// mempool policy and should be used with P2SH. The atomic swap format is also
-// expected to change to use a more secure hash function in the future.
-//
-// This function is only defined in the txscript package due to API limitations
-// which prevent callers using txscript to parse nonstandard scripts.
+// Atomic swap contracts can be built with AtomicSwapContract.
 func ExtractAtomicSwapDataPushes(version uint16, scriptPubKey []byte) (*AtomicSwapDataPushes, error) {
 	pops, err := parseScript(scriptPubKey)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(pops) != 19 {
-		return nil, nil
-	}
-	isAtomicSwap := pops[0].opcode.value == OpIf &&
-		pops[1].opcode.value == OpSize &&
-		canonicalPush(pops[2]) &&
-		pops[3].opcode.value == OpEqualVerify &&
-		pops[4].opcode.value == OpSHA256 &&
-		pops[5].opcode.value == OpData32 &&
-		pops[6].opcode.value == OpEqualVerify &&
-		pops[7].opcode.value == OpDup &&
-		pops[8].opcode.value == OpBlake2b &&
-		pops[9].opcode.value == OpData32 &&
-		pops[10].opcode.value == OpElse &&
-		canonicalPush(pops[11]) &&
-		pops[12].opcode.value == OpCheckLockTimeVerify &&
-		pops[13].opcode.value == OpDup &&
-		pops[14].opcode.value == OpBlake2b &&
-		pops[15].opcode.value == OpData32 &&
-		pops[16].opcode.value == OpEndIf &&
-		pops[17].opcode.value == OpEqualVerify &&
-		pops[18].opcode.value == OpCheckSig
-	if !isAtomicSwap {
+	if !isAtomicSwap(pops) {
 		return nil, nil
 	}
 
@@ -428,16 +460,6 @@ func ExtractAtomicSwapDataPushes(version uint16, scriptPubKey []byte) (*AtomicSw
 	} else {
 		return nil, nil
 	}
-	if pops[11].data != nil {
-		locktime, err := makeScriptNum(pops[11].data, 8)
-		if err != nil {
-			return nil, nil
-		}
-		pushes.LockTime = uint64(locktime)
-	} else if op := pops[11].opcode; isSmallInt(op) {
-		pushes.LockTime = uint64(asSmallInt(op))
-	} else {
-		return nil, nil
-	}
+	pushes.LockTime = lockTimeOrSequence(pops[11])
 	return pushes, nil
 }
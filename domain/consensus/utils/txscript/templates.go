@@ -0,0 +1,385 @@
+package txscript
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// This file holds the templates of the standard scripts that go beyond paying
+// to a single public key: multisig, atomic swaps, and payments that are locked
+// until an absolute or a relative time. Every template has a function that
+// builds it, a check that recognizes it, which typeOfScript uses to classify
+// it, and a function that extracts its parameters. The templates can be used
+// as script public keys, or as the redeem scripts of pay-to-script-hash
+// outputs.
+//
+// All public keys in the templates are 32-byte Schnorr public keys, except in
+// MultiSigScriptECDSA, where they're 33-byte ECDSA public keys.
+
+const (
+	schnorrPubKeyLength = 32
+	ecdsaPubKeyLength   = 33
+)
+
+// MultiSigScript returns a script for a multisig redemption with
+// requiredSigs signatures out of the given Schnorr public keys:
+//
+//	<requiredSigs> <pubkey>... <number of pubkeys> OP_CHECKMULTISIG
+func MultiSigScript(pubKeys [][]byte, requiredSigs int) ([]byte, error) {
+	return multiSigScript(pubKeys, requiredSigs, schnorrPubKeyLength, OpCheckMultiSig)
+}
+
+// MultiSigScriptECDSA returns a script for a multisig redemption with
+// requiredSigs signatures out of the given ECDSA public keys:
+//
+//	<requiredSigs> <pubkey>... <number of pubkeys> OP_CHECKMULTISIGECDSA
+func MultiSigScriptECDSA(pubKeys [][]byte, requiredSigs int) ([]byte, error) {
+	return multiSigScript(pubKeys, requiredSigs, ecdsaPubKeyLength, OpCheckMultiSigECDSA)
+}
+
+func multiSigScript(pubKeys [][]byte, requiredSigs int, pubKeyLength int, checkMultiSigOpcode byte) ([]byte, error) {
+	if len(pubKeys) == 0 || len(pubKeys) > MaxPubKeysPerMultiSig {
+		str := fmt.Sprintf("a multisig script must have between 1 and %d public keys, got %d",
+			MaxPubKeysPerMultiSig, len(pubKeys))
+		return nil, scriptError(ErrTooManyRequiredSigs, str)
+	}
+	if requiredSigs < 1 || requiredSigs > len(pubKeys) {
+		str := fmt.Sprintf("unable to generate multisig script with %d required signatures "+
+			"when there are only %d public keys available", requiredSigs, len(pubKeys))
+		return nil, scriptError(ErrTooManyRequiredSigs, str)
+	}
+
+	builder := NewScriptBuilder().AddInt64(int64(requiredSigs))
+	for i, pubKey := range pubKeys {
+		if len(pubKey) != pubKeyLength {
+			str := fmt.Sprintf("public key %d is %d bytes long instead of %d", i, len(pubKey), pubKeyLength)
+			return nil, scriptError(ErrPubKeyFormat, str)
+		}
+		builder.AddData(pubKey)
+	}
+	return builder.AddInt64(int64(len(pubKeys))).AddOp(checkMultiSigOpcode).Script()
+}
+
+// MultiSigSignatureScript returns a signature script that spends a multisig
+// script with the given signatures, which must be in the same order as their
+// public keys are in the multisig script.
+func MultiSigSignatureScript(signatures [][]byte) ([]byte, error) {
+	builder := NewScriptBuilder()
+	for _, signature := range signatures {
+		builder.AddData(signature)
+	}
+	return builder.Script()
+}
+
+// MultiSigDataPushes houses the parameters of a multisig script
+type MultiSigDataPushes struct {
+	RequiredSigs int
+	PubKeys      [][]byte
+	IsECDSA      bool
+}
+
+// isMultiSig returns whether the passed script is a multisig script in the
+// form of MultiSigScript or MultiSigScriptECDSA.
+func isMultiSig(pops []parsedOpcode) bool {
+	// The absolute minimum is 1 pubkey:
+	// OP_1 <pubkey> OP_1 OP_CHECKMULTISIG
+	numPops := len(pops)
+	if numPops < 4 {
+		return false
+	}
+
+	var pubKeyLength int
+	switch pops[numPops-1].opcode.value {
+	case OpCheckMultiSig:
+		pubKeyLength = schnorrPubKeyLength
+	case OpCheckMultiSigECDSA:
+		pubKeyLength = ecdsaPubKeyLength
+	default:
+		return false
+	}
+
+	if !isSmallInt(pops[0].opcode) || !isSmallInt(pops[numPops-2].opcode) {
+		return false
+	}
+	requiredSigs := asSmallInt(pops[0].opcode)
+	numPubKeys := asSmallInt(pops[numPops-2].opcode)
+	if requiredSigs < 1 || requiredSigs > numPubKeys || numPubKeys != numPops-3 {
+		return false
+	}
+
+	for _, pop := range pops[1 : numPops-2] {
+		if len(pop.data) != pubKeyLength || !canonicalPush(pop) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExtractMultiSigDataPushes returns the parameters of a multisig script. If
+// the script is not a multisig script, ExtractMultiSigDataPushes returns
+// (nil, nil). Non-nil errors are returned for unparsable scripts.
+func ExtractMultiSigDataPushes(script []byte) (*MultiSigDataPushes, error) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return nil, err
+	}
+	if !isMultiSig(pops) {
+		return nil, nil
+	}
+
+	numPops := len(pops)
+	pushes := &MultiSigDataPushes{
+		RequiredSigs: asSmallInt(pops[0].opcode),
+		PubKeys:      make([][]byte, 0, numPops-3),
+		IsECDSA:      pops[numPops-1].opcode.value == OpCheckMultiSigECDSA,
+	}
+	for _, pop := range pops[1 : numPops-2] {
+		pushes.PubKeys = append(pushes.PubKeys, pop.data)
+	}
+	return pushes, nil
+}
+
+// AtomicSwapContract returns a hashed time-locked contract for an atomic swap.
+// The recipient can redeem it by revealing a secret of secretSize bytes whose
+// SHA256 hash is secretHash. Once the lock time has passed, the refunder can
+// redeem it without the secret. recipientBlake2b and refundBlake2b are the
+// BLAKE2B hashes of the public keys of the recipient and the refunder:
+//
+//	OP_IF
+//	    OP_SIZE <secretSize> OP_EQUALVERIFY OP_SHA256 <secretHash> OP_EQUALVERIFY
+//	    OP_DUP OP_BLAKE2B <recipientBlake2b>
+//	OP_ELSE
+//	    <lockTime> OP_CHECKLOCKTIMEVERIFY
+//	    OP_DUP OP_BLAKE2B <refundBlake2b>
+//	OP_ENDIF
+//	OP_EQUALVERIFY OP_CHECKSIG
+func AtomicSwapContract(recipientBlake2b, refundBlake2b, secretHash [32]byte, secretSize int64,
+	lockTime uint64) ([]byte, error) {
+
+	return NewScriptBuilder().
+		AddOp(OpIf).
+		AddOp(OpSize).AddInt64(secretSize).AddOp(OpEqualVerify).
+		AddOp(OpSHA256).AddData(secretHash[:]).AddOp(OpEqualVerify).
+		AddOp(OpDup).AddOp(OpBlake2b).AddData(recipientBlake2b[:]).
+		AddOp(OpElse).
+		AddLockTimeNumber(lockTime).AddOp(OpCheckLockTimeVerify).
+		AddOp(OpDup).AddOp(OpBlake2b).AddData(refundBlake2b[:]).
+		AddOp(OpEndIf).
+		AddOp(OpEqualVerify).AddOp(OpCheckSig).
+		Script()
+}
+
+// AtomicSwapRedeemSignatureScript returns a signature script that redeems an
+// atomic swap contract with the secret, the recipient's signature and the
+// recipient's public key
+func AtomicSwapRedeemSignatureScript(signature, pubKey, secret []byte) ([]byte, error) {
+	return NewScriptBuilder().AddData(signature).AddData(pubKey).AddData(secret).AddOp(OpTrue).Script()
+}
+
+// AtomicSwapRefundSignatureScript returns a signature script that refunds an
+// atomic swap contract after its lock time with the refunder's signature and
+// the refunder's public key
+func AtomicSwapRefundSignatureScript(signature, pubKey []byte) ([]byte, error) {
+	return NewScriptBuilder().AddData(signature).AddData(pubKey).AddOp(OpFalse).Script()
+}
+
+// isAtomicSwap returns whether the passed script is an atomic swap contract in
+// the form of AtomicSwapContract.
+func isAtomicSwap(pops []parsedOpcode) bool {
+	return len(pops) == 19 &&
+		pops[0].opcode.value == OpIf &&
+		pops[1].opcode.value == OpSize &&
+		(isSmallInt(pops[2].opcode) || pops[2].data != nil) && canonicalPush(pops[2]) &&
+		pops[3].opcode.value == OpEqualVerify &&
+		pops[4].opcode.value == OpSHA256 &&
+		pops[5].opcode.value == OpData32 &&
+		pops[6].opcode.value == OpEqualVerify &&
+		pops[7].opcode.value == OpDup &&
+		pops[8].opcode.value == OpBlake2b &&
+		pops[9].opcode.value == OpData32 &&
+		pops[10].opcode.value == OpElse &&
+		isLockTimeOrSequencePush(pops[11]) &&
+		pops[12].opcode.value == OpCheckLockTimeVerify &&
+		pops[13].opcode.value == OpDup &&
+		pops[14].opcode.value == OpBlake2b &&
+		pops[15].opcode.value == OpData32 &&
+		pops[16].opcode.value == OpEndIf &&
+		pops[17].opcode.value == OpEqualVerify &&
+		pops[18].opcode.value == OpCheckSig
+}
+
+// TimeLockScript returns a script that pays to the given public key once the
+// lock time of the spending transaction reaches lockTime, which is a DAA score
+// or a timestamp depending on whether it's below constants.LockTimeThreshold:
+//
+//	<lockTime> OP_CHECKLOCKTIMEVERIFY <pubkey> OP_CHECKSIG
+func TimeLockScript(pubKey []byte, lockTime uint64) ([]byte, error) {
+	if len(pubKey) != schnorrPubKeyLength {
+		str := fmt.Sprintf("public key is %d bytes long instead of %d", len(pubKey), schnorrPubKeyLength)
+		return nil, scriptError(ErrPubKeyFormat, str)
+	}
+	return NewScriptBuilder().
+		AddLockTimeNumber(lockTime).AddOp(OpCheckLockTimeVerify).
+		AddData(pubKey).AddOp(OpCheckSig).
+		Script()
+}
+
+// SequenceLockScript returns a script that pays to the given public key once
+// the output it's in is as old as the relative lock time in sequence. The
+// sequence of the spending input must be at least sequence:
+//
+//	<sequence> OP_CHECKSEQUENCEVERIFY <pubkey> OP_CHECKSIG
+func SequenceLockScript(pubKey []byte, sequence uint64) ([]byte, error) {
+	if len(pubKey) != schnorrPubKeyLength {
+		str := fmt.Sprintf("public key is %d bytes long instead of %d", len(pubKey), schnorrPubKeyLength)
+		return nil, scriptError(ErrPubKeyFormat, str)
+	}
+	return NewScriptBuilder().
+		AddSequenceNumber(sequence).AddOp(OpCheckSequenceVerify).
+		AddData(pubKey).AddOp(OpCheckSig).
+		Script()
+}
+
+// TimeLockDataPushes houses the parameters of a time-locked script. LockTime
+// is the absolute lock time of a TimeLockScript, or the sequence of a
+// SequenceLockScript.
+type TimeLockDataPushes struct {
+	PubKey   []byte
+	LockTime uint64
+}
+
+// isTimeLock returns whether the passed script is a time-locked payment to a
+// public key that is locked by the given opcode, either
+// OP_CHECKLOCKTIMEVERIFY or OP_CHECKSEQUENCEVERIFY.
+func isTimeLock(pops []parsedOpcode, lockOpcode byte) bool {
+	return len(pops) == 4 &&
+		isLockTimeOrSequencePush(pops[0]) &&
+		pops[1].opcode.value == lockOpcode &&
+		pops[2].opcode.value == OpData32 &&
+		pops[3].opcode.value == OpCheckSig
+}
+
+// ExtractTimeLockDataPushes returns the parameters of a script in the form of
+// TimeLockScript or SequenceLockScript. If the script is neither,
+// ExtractTimeLockDataPushes returns (nil, nil). Non-nil errors are returned
+// for unparsable scripts.
+func ExtractTimeLockDataPushes(script []byte) (*TimeLockDataPushes, error) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return nil, err
+	}
+	if !isTimeLock(pops, OpCheckLockTimeVerify) && !isTimeLock(pops, OpCheckSequenceVerify) {
+		return nil, nil
+	}
+	return &TimeLockDataPushes{
+		PubKey:   pops[2].data,
+		LockTime: lockTimeOrSequence(pops[0]),
+	}, nil
+}
+
+// RefundScript returns a script that pays to the recipient's public key, and
+// that the refunder can take back once the output it's in is as old as the
+// relative lock time in sequence:
+//
+//	OP_IF
+//	    <recipient pubkey> OP_CHECKSIG
+//	OP_ELSE
+//	    <sequence> OP_CHECKSEQUENCEVERIFY <refund pubkey> OP_CHECKSIG
+//	OP_ENDIF
+func RefundScript(recipientPubKey, refundPubKey []byte, sequence uint64) ([]byte, error) {
+	for _, pubKey := range [][]byte{recipientPubKey, refundPubKey} {
+		if len(pubKey) != schnorrPubKeyLength {
+			str := fmt.Sprintf("public key is %d bytes long instead of %d", len(pubKey), schnorrPubKeyLength)
+			return nil, scriptError(ErrPubKeyFormat, str)
+		}
+	}
+	return NewScriptBuilder().
+		AddOp(OpIf).
+		AddData(recipientPubKey).AddOp(OpCheckSig).
+		AddOp(OpElse).
+		AddSequenceNumber(sequence).AddOp(OpCheckSequenceVerify).
+		AddData(refundPubKey).AddOp(OpCheckSig).
+		AddOp(OpEndIf).
+		Script()
+}
+
+// RefundRecipientSignatureScript returns a signature script that spends a
+// RefundScript with the recipient's signature
+func RefundRecipientSignatureScript(signature []byte) ([]byte, error) {
+	return NewScriptBuilder().AddData(signature).AddOp(OpTrue).Script()
+}
+
+// RefundRefunderSignatureScript returns a signature script that spends a
+// RefundScript after its relative lock time with the refunder's signature
+func RefundRefunderSignatureScript(signature []byte) ([]byte, error) {
+	return NewScriptBuilder().AddData(signature).AddOp(OpFalse).Script()
+}
+
+// RefundDataPushes houses the parameters of a RefundScript
+type RefundDataPushes struct {
+	RecipientPubKey []byte
+	RefundPubKey    []byte
+	Sequence        uint64
+}
+
+// isRefund returns whether the passed script is in the form of RefundScript
+func isRefund(pops []parsedOpcode) bool {
+	return len(pops) == 9 &&
+		pops[0].opcode.value == OpIf &&
+		pops[1].opcode.value == OpData32 &&
+		pops[2].opcode.value == OpCheckSig &&
+		pops[3].opcode.value == OpElse &&
+		isLockTimeOrSequencePush(pops[4]) &&
+		pops[5].opcode.value == OpCheckSequenceVerify &&
+		pops[6].opcode.value == OpData32 &&
+		pops[7].opcode.value == OpCheckSig &&
+		pops[8].opcode.value == OpEndIf
+}
+
+// ExtractRefundDataPushes returns the parameters of a RefundScript. If the
+// script is not a RefundScript, ExtractRefundDataPushes returns (nil, nil).
+// Non-nil errors are returned for unparsable scripts.
+func ExtractRefundDataPushes(script []byte) (*RefundDataPushes, error) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return nil, err
+	}
+	if !isRefund(pops) {
+		return nil, nil
+	}
+	return &RefundDataPushes{
+		RecipientPubKey: pops[1].data,
+		RefundPubKey:    pops[6].data,
+		Sequence:        lockTimeOrSequence(pops[4]),
+	}, nil
+}
+
+// isLockTimeOrSequencePush returns whether the passed opcode pushes a lock
+// time or a sequence the way ScriptBuilder.AddLockTimeNumber and
+// ScriptBuilder.AddSequenceNumber do: canonically, in up to 8 bytes.
+func isLockTimeOrSequencePush(pop parsedOpcode) bool {
+	if pop.opcode.value == Op0 || pop.opcode.value == Op1Negate || isSmallInt(pop.opcode) {
+		return true
+	}
+	return pop.data != nil && len(pop.data) <= 8 && canonicalPush(pop)
+}
+
+// lockTimeOrSequence returns the lock time or the sequence the passed opcode
+// pushes, as OP_CHECKLOCKTIMEVERIFY and OP_CHECKSEQUENCEVERIFY read it
+func lockTimeOrSequence(pop parsedOpcode) uint64 {
+	var data []byte
+	switch {
+	case pop.opcode.value == Op0:
+		return 0
+	case pop.opcode.value == Op1Negate:
+		data = []byte{0x81}
+	case isSmallInt(pop.opcode):
+		return uint64(asSmallInt(pop.opcode))
+	default:
+		data = pop.data
+	}
+
+	padded := make([]byte, 8)
+	copy(padded, data)
+	return binary.LittleEndian.Uint64(padded)
+}
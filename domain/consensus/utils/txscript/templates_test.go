@@ -0,0 +1,479 @@
+package txscript
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/constants"
+	"github.com/ammm56/lings/domain/consensus/utils/utxo"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/util"
+	"github.com/kaspanet/go-secp256k1"
+)
+
+// templateKey is a key pair used to sign the spending transactions of the
+// templates, with its serialized public key
+type templateKey struct {
+	keyPair *secp256k1.SchnorrKeyPair
+	pubKey  []byte
+}
+
+func newTemplateKeys(t *testing.T, count int) []*templateKey {
+	keys := make([]*templateKey, count)
+	for i := range keys {
+		keyPair, err := secp256k1.GenerateSchnorrKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateSchnorrKeyPair: %s", err)
+		}
+		pubKey, err := keyPair.SchnorrPublicKey()
+		if err != nil {
+			t.Fatalf("SchnorrPublicKey: %s", err)
+		}
+		serializedPubKey, err := pubKey.Serialize()
+		if err != nil {
+			t.Fatalf("Serialize: %s", err)
+		}
+		keys[i] = &templateKey{keyPair: keyPair, pubKey: serializedPubKey[:]}
+	}
+	return keys
+}
+
+// templateSpendingTx returns a transaction that spends an output with the
+// given script public key, with the given lock time and input sequence
+func templateSpendingTx(scriptPubKey *externalapi.ScriptPublicKey, lockTime uint64, sequence uint64) *externalapi.DomainTransaction {
+	return &externalapi.DomainTransaction{
+		Version: 0,
+		Inputs: []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: externalapi.DomainOutpoint{Index: 0},
+			Sequence:         sequence,
+			UTXOEntry:        utxo.NewUTXOEntry(100_000_000, scriptPubKey, false, 0),
+		}},
+		Outputs: []*externalapi.DomainTransactionOutput{{
+			Value:           99_000_000,
+			ScriptPublicKey: &externalapi.ScriptPublicKey{Script: []byte{OpTrue}, Version: 0},
+		}},
+		LockTime: lockTime,
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	t.Parallel()
+
+	keys := newTemplateKeys(t, 3)
+	secret := bytes.Repeat([]byte{0xab}, 32)
+	secretHash := sha256.Sum256(secret)
+	var recipientBlake2b, refundBlake2b [32]byte
+	copy(recipientBlake2b[:], util.HashBlake2b(keys[0].pubKey))
+	copy(refundBlake2b[:], util.HashBlake2b(keys[1].pubKey))
+
+	mustBuild := func(script []byte, err error) []byte {
+		if err != nil {
+			t.Fatalf("failed to build a template: %s", err)
+		}
+		return script
+	}
+	multiSigScript := mustBuild(MultiSigScript([][]byte{keys[0].pubKey, keys[1].pubKey, keys[2].pubKey}, 2))
+	atomicSwapScript := mustBuild(AtomicSwapContract(recipientBlake2b, refundBlake2b, secretHash, 32, 1000))
+	timeLockScript := mustBuild(TimeLockScript(keys[0].pubKey, 1000))
+	sequenceLockScript := mustBuild(SequenceLockScript(keys[0].pubKey, 10))
+	refundScript := mustBuild(RefundScript(keys[0].pubKey, keys[1].pubKey, 10))
+
+	type signatureScriptFunc func(sign func(key *templateKey) []byte) ([]byte, error)
+	tests := []struct {
+		name     string
+		script   []byte
+		class    ScriptClass
+		lockTime uint64
+		sequence uint64
+
+		// signatureScript returns the signature script that spends the
+		// template, given a function that signs the spending
+		// transaction
+		signatureScript signatureScriptFunc
+		isValid         bool
+	}{
+		{
+			name:   "multisig",
+			script: multiSigScript,
+			class:  MultiSigTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return MultiSigSignatureScript([][]byte{sign(keys[0]), sign(keys[2])})
+			},
+			sequence: constants.MaxTxInSequenceNum,
+			isValid:  true,
+		},
+		{
+			name:   "multisig with signatures out of order",
+			script: multiSigScript,
+			class:  MultiSigTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return MultiSigSignatureScript([][]byte{sign(keys[2]), sign(keys[0])})
+			},
+			sequence: constants.MaxTxInSequenceNum,
+			isValid:  false,
+		},
+		{
+			name:   "atomic swap redeem",
+			script: atomicSwapScript,
+			class:  AtomicSwapTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return AtomicSwapRedeemSignatureScript(sign(keys[0]), keys[0].pubKey, secret)
+			},
+			sequence: constants.MaxTxInSequenceNum,
+			isValid:  true,
+		},
+		{
+			name:   "atomic swap redeem with a wrong secret",
+			script: atomicSwapScript,
+			class:  AtomicSwapTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return AtomicSwapRedeemSignatureScript(sign(keys[0]), keys[0].pubKey, bytes.Repeat([]byte{0xcd}, 32))
+			},
+			sequence: constants.MaxTxInSequenceNum,
+			isValid:  false,
+		},
+		{
+			name:   "atomic swap refund",
+			script: atomicSwapScript,
+			class:  AtomicSwapTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return AtomicSwapRefundSignatureScript(sign(keys[1]), keys[1].pubKey)
+			},
+			lockTime: 1000,
+			sequence: 0,
+			isValid:  true,
+		},
+		{
+			name:   "atomic swap refund before the lock time",
+			script: atomicSwapScript,
+			class:  AtomicSwapTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return AtomicSwapRefundSignatureScript(sign(keys[1]), keys[1].pubKey)
+			},
+			lockTime: 999,
+			sequence: 0,
+			isValid:  false,
+		},
+		{
+			name:   "time lock",
+			script: timeLockScript,
+			class:  TimeLockTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return NewScriptBuilder().AddData(sign(keys[0])).Script()
+			},
+			lockTime: 1000,
+			sequence: 0,
+			isValid:  true,
+		},
+		{
+			name:   "time lock before the lock time",
+			script: timeLockScript,
+			class:  TimeLockTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return NewScriptBuilder().AddData(sign(keys[0])).Script()
+			},
+			lockTime: 999,
+			sequence: 0,
+			isValid:  false,
+		},
+		{
+			name:   "sequence lock",
+			script: sequenceLockScript,
+			class:  SequenceLockTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return NewScriptBuilder().AddData(sign(keys[0])).Script()
+			},
+			sequence: 10,
+			isValid:  true,
+		},
+		{
+			name:   "sequence lock before the relative lock time",
+			script: sequenceLockScript,
+			class:  SequenceLockTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return NewScriptBuilder().AddData(sign(keys[0])).Script()
+			},
+			sequence: 9,
+			isValid:  false,
+		},
+		{
+			name:   "refund script spent by the recipient",
+			script: refundScript,
+			class:  RefundTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return RefundRecipientSignatureScript(sign(keys[0]))
+			},
+			sequence: constants.MaxTxInSequenceNum,
+			isValid:  true,
+		},
+		{
+			name:   "refund script spent by the refunder",
+			script: refundScript,
+			class:  RefundTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return RefundRefunderSignatureScript(sign(keys[1]))
+			},
+			sequence: 10,
+			isValid:  true,
+		},
+		{
+			name:   "refund script spent by the refunder before the relative lock time",
+			script: refundScript,
+			class:  RefundTy,
+			signatureScript: func(sign func(key *templateKey) []byte) ([]byte, error) {
+				return RefundRefunderSignatureScript(sign(keys[1]))
+			},
+			sequence: 9,
+			isValid:  false,
+		},
+	}
+
+	for _, test := range tests {
+		class := GetScriptClass(test.script)
+		if class != test.class {
+			t.Fatalf("%s: expected class %s, got %s", test.name, test.class, class)
+		}
+
+		p2shScript, err := PayToScriptHashScript(test.script)
+		if err != nil {
+			t.Fatalf("%s: PayToScriptHashScript: %s", test.name, err)
+		}
+		scriptPubKeys := map[string]*externalapi.ScriptPublicKey{
+			"bare": {Script: test.script, Version: 0},
+			"p2sh": {Script: p2shScript, Version: 0},
+		}
+		for kind, scriptPubKey := range scriptPubKeys {
+			tx := templateSpendingTx(scriptPubKey, test.lockTime, test.sequence)
+			sign := func(key *templateKey) []byte {
+				signature, err := RawTxInSignature(tx, 0, consensushashing.SigHashAll, key.keyPair,
+					&consensushashing.SighashReusedValues{})
+				if err != nil {
+					t.Fatalf("%s: RawTxInSignature: %s", test.name, err)
+				}
+				return signature
+			}
+			sigScript, err := test.signatureScript(sign)
+			if err != nil {
+				t.Fatalf("%s: failed to build the signature script: %s", test.name, err)
+			}
+			if kind == "p2sh" {
+				sigScript, err = PayToScriptHashSignatureScript(test.script, sigScript)
+				if err != nil {
+					t.Fatalf("%s: PayToScriptHashSignatureScript: %s", test.name, err)
+				}
+			}
+
+			err = checkScripts(test.name, tx, 0, sigScript, scriptPubKey)
+			if test.isValid && err != nil {
+				t.Errorf("%s (%s): %s", test.name, kind, err)
+			}
+			if !test.isValid && err == nil {
+				t.Errorf("%s (%s): the script is valid when it shouldn't be", test.name, kind)
+			}
+		}
+	}
+}
+
+func TestExtractTemplateDataPushes(t *testing.T) {
+	t.Parallel()
+
+	keys := newTemplateKeys(t, 3)
+	pubKeys := [][]byte{keys[0].pubKey, keys[1].pubKey, keys[2].pubKey}
+
+	multiSigScript, err := MultiSigScript(pubKeys, 2)
+	if err != nil {
+		t.Fatalf("MultiSigScript: %s", err)
+	}
+	multiSig, err := ExtractMultiSigDataPushes(multiSigScript)
+	if err != nil {
+		t.Fatalf("ExtractMultiSigDataPushes: %s", err)
+	}
+	if multiSig.RequiredSigs != 2 || multiSig.IsECDSA || len(multiSig.PubKeys) != 3 ||
+		!bytes.Equal(multiSig.PubKeys[1], keys[1].pubKey) {
+		t.Errorf("ExtractMultiSigDataPushes: unexpected data pushes %+v", multiSig)
+	}
+
+	ecdsaPubKeys := [][]byte{bytes.Repeat([]byte{0x02}, 33), bytes.Repeat([]byte{0x03}, 33)}
+	multiSigECDSAScript, err := MultiSigScriptECDSA(ecdsaPubKeys, 1)
+	if err != nil {
+		t.Fatalf("MultiSigScriptECDSA: %s", err)
+	}
+	multiSigECDSA, err := ExtractMultiSigDataPushes(multiSigECDSAScript)
+	if err != nil {
+		t.Fatalf("ExtractMultiSigDataPushes: %s", err)
+	}
+	if multiSigECDSA.RequiredSigs != 1 || !multiSigECDSA.IsECDSA || len(multiSigECDSA.PubKeys) != 2 {
+		t.Errorf("ExtractMultiSigDataPushes: unexpected data pushes %+v", multiSigECDSA)
+	}
+	if class := GetScriptClass(multiSigECDSAScript); class != MultiSigTy {
+		t.Errorf("expected class %s for an ECDSA multisig, got %s", MultiSigTy, class)
+	}
+
+	var secretHash, recipientBlake2b, refundBlake2b [32]byte
+	secretHash[0], recipientBlake2b[0], refundBlake2b[0] = 1, 2, 3
+	for _, lockTime := range []uint64{0, 5, 0x80, 1000, 1<<63 + 1} {
+		atomicSwapScript, err := AtomicSwapContract(recipientBlake2b, refundBlake2b, secretHash, 32, lockTime)
+		if err != nil {
+			t.Fatalf("AtomicSwapContract: %s", err)
+		}
+		atomicSwap, err := ExtractAtomicSwapDataPushes(0, atomicSwapScript)
+		if err != nil {
+			t.Fatalf("ExtractAtomicSwapDataPushes: %s", err)
+		}
+		expected := AtomicSwapDataPushes{
+			RecipientBlake2b: recipientBlake2b,
+			RefundBlake2b:    refundBlake2b,
+			SecretHash:       secretHash,
+			SecretSize:       32,
+			LockTime:         lockTime,
+		}
+		if atomicSwap == nil || *atomicSwap != expected {
+			t.Errorf("ExtractAtomicSwapDataPushes: expected %+v, got %+v", expected, atomicSwap)
+		}
+
+		timeLockScript, err := TimeLockScript(keys[0].pubKey, lockTime)
+		if err != nil {
+			t.Fatalf("TimeLockScript: %s", err)
+		}
+		timeLock, err := ExtractTimeLockDataPushes(timeLockScript)
+		if err != nil {
+			t.Fatalf("ExtractTimeLockDataPushes: %s", err)
+		}
+		if timeLock == nil || timeLock.LockTime != lockTime || !bytes.Equal(timeLock.PubKey, keys[0].pubKey) {
+			t.Errorf("ExtractTimeLockDataPushes: unexpected data pushes %+v for lock time %d", timeLock, lockTime)
+		}
+
+		refundScript, err := RefundScript(keys[0].pubKey, keys[1].pubKey, lockTime)
+		if err != nil {
+			t.Fatalf("RefundScript: %s", err)
+		}
+		refund, err := ExtractRefundDataPushes(refundScript)
+		if err != nil {
+			t.Fatalf("ExtractRefundDataPushes: %s", err)
+		}
+		if refund == nil || refund.Sequence != lockTime || !bytes.Equal(refund.RecipientPubKey, keys[0].pubKey) ||
+			!bytes.Equal(refund.RefundPubKey, keys[1].pubKey) {
+			t.Errorf("ExtractRefundDataPushes: unexpected data pushes %+v for sequence %d", refund, lockTime)
+		}
+	}
+
+	// A script of another class has no data pushes of these templates
+	scriptPubKey, err := payToPubKeyScript(keys[0].pubKey)
+	if err != nil {
+		t.Fatalf("payToPubKeyScript: %s", err)
+	}
+	multiSig, _ = ExtractMultiSigDataPushes(scriptPubKey)
+	timeLock, _ := ExtractTimeLockDataPushes(scriptPubKey)
+	refund, _ := ExtractRefundDataPushes(scriptPubKey)
+	if multiSig != nil || timeLock != nil || refund != nil {
+		t.Errorf("extracted template data pushes from a pay-to-pubkey script")
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	t.Parallel()
+
+	pubKey := bytes.Repeat([]byte{0x01}, 32)
+	tooManyPubKeys := make([][]byte, MaxPubKeysPerMultiSig+1)
+	for i := range tooManyPubKeys {
+		tooManyPubKeys[i] = pubKey
+	}
+
+	tests := []struct {
+		name         string
+		build        func() ([]byte, error)
+		expectedCode ErrorCode
+	}{
+		{
+			name:         "multisig without public keys",
+			build:        func() ([]byte, error) { return MultiSigScript(nil, 1) },
+			expectedCode: ErrTooManyRequiredSigs,
+		},
+		{
+			name:         "multisig with too many public keys",
+			build:        func() ([]byte, error) { return MultiSigScript(tooManyPubKeys, 1) },
+			expectedCode: ErrTooManyRequiredSigs,
+		},
+		{
+			name:         "multisig with more required signatures than public keys",
+			build:        func() ([]byte, error) { return MultiSigScript([][]byte{pubKey}, 2) },
+			expectedCode: ErrTooManyRequiredSigs,
+		},
+		{
+			name:         "multisig without required signatures",
+			build:        func() ([]byte, error) { return MultiSigScript([][]byte{pubKey}, 0) },
+			expectedCode: ErrTooManyRequiredSigs,
+		},
+		{
+			name:         "multisig with an ECDSA public key",
+			build:        func() ([]byte, error) { return MultiSigScript([][]byte{append(pubKey, 0x02)}, 1) },
+			expectedCode: ErrPubKeyFormat,
+		},
+		{
+			name:         "ECDSA multisig with a Schnorr public key",
+			build:        func() ([]byte, error) { return MultiSigScriptECDSA([][]byte{pubKey}, 1) },
+			expectedCode: ErrPubKeyFormat,
+		},
+		{
+			name:         "time lock with an invalid public key",
+			build:        func() ([]byte, error) { return TimeLockScript(pubKey[:31], 1000) },
+			expectedCode: ErrPubKeyFormat,
+		},
+		{
+			name:         "sequence lock with an invalid public key",
+			build:        func() ([]byte, error) { return SequenceLockScript(nil, 10) },
+			expectedCode: ErrPubKeyFormat,
+		},
+		{
+			name:         "refund script with an invalid public key",
+			build:        func() ([]byte, error) { return RefundScript(pubKey, pubKey[:1], 10) },
+			expectedCode: ErrPubKeyFormat,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := test.build()
+		if !IsErrorCode(err, test.expectedCode) {
+			t.Errorf("%s: expected error code %s, got %v", test.name, test.expectedCode, err)
+		}
+	}
+}
+
+func TestTemplateAddresses(t *testing.T) {
+	t.Parallel()
+
+	keys := newTemplateKeys(t, 2)
+	timeLockScript, err := TimeLockScript(keys[0].pubKey, 1000)
+	if err != nil {
+		t.Fatalf("TimeLockScript: %s", err)
+	}
+	refundScript, err := RefundScript(keys[0].pubKey, keys[1].pubKey, 10)
+	if err != nil {
+		t.Fatalf("RefundScript: %s", err)
+	}
+	expectedAddress, err := util.NewAddressPublicKey(keys[0].pubKey, dagconfig.MainnetParams.Prefix)
+	if err != nil {
+		t.Fatalf("NewAddressPublicKey: %s", err)
+	}
+
+	class, address, err := ExtractScriptPubKeyAddress(
+		&externalapi.ScriptPublicKey{Script: timeLockScript, Version: 0}, &dagconfig.MainnetParams)
+	if err != nil {
+		t.Fatalf("ExtractScriptPubKeyAddress: %s", err)
+	}
+	if class != TimeLockTy || address == nil || address.EncodeAddress() != expectedAddress.EncodeAddress() {
+		t.Errorf("expected class %s and address %s, got %s and %v", TimeLockTy, expectedAddress, class, address)
+	}
+
+	class, address, err = ExtractScriptPubKeyAddress(
+		&externalapi.ScriptPublicKey{Script: refundScript, Version: 0}, &dagconfig.MainnetParams)
+	if err != nil {
+		t.Fatalf("ExtractScriptPubKeyAddress: %s", err)
+	}
+	if class != RefundTy || address != nil {
+		t.Errorf("expected class %s without an address, got %s and %v", RefundTy, class, address)
+	}
+}
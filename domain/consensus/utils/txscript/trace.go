@@ -0,0 +1,101 @@
+package txscript
+
+// TraceStep describes the execution of a single opcode by an Engine. The stacks
+// are listed bottom up, so the last item of every stack is its top.
+type TraceStep struct {
+	// ScriptIndex is the index of the script the opcode is in: 0 for the
+	// signature script, 1 for the script public key and 2 for the redeem
+	// script of a pay-to-script-hash script public key.
+	ScriptIndex  int
+	ScriptOffset int
+	Disassembly  string
+
+	// Executed is false when the opcode was skipped because it's in a
+	// non-executing conditional branch.
+	Executed bool
+
+	StackBefore     [][]byte
+	StackAfter      [][]byte
+	AltStackBefore  [][]byte
+	AltStackAfter   [][]byte
+	CondStackBefore []int
+	CondStackAfter  []int
+
+	// Err is the error that the execution of the opcode failed with, if any.
+	// The stacks after the failed opcode are undefined.
+	Err error
+}
+
+// Tracer is notified by an Engine of every opcode it executes.
+type Tracer interface {
+	TraceStep(step *TraceStep)
+}
+
+// TraceRecorder is a Tracer that keeps all the steps it's notified of
+type TraceRecorder struct {
+	Steps []*TraceStep
+}
+
+// TraceStep implements the Tracer interface
+func (tr *TraceRecorder) TraceStep(step *TraceStep) {
+	tr.Steps = append(tr.Steps, step)
+}
+
+// SetTracer attaches a Tracer to the engine. Every call to Step, including the
+// calls made by Execute, notifies the tracer of the executed opcode along with
+// the stacks before and after its execution. Pass nil to detach the tracer.
+func (vm *Engine) SetTracer(tracer Tracer) {
+	vm.tracer = tracer
+}
+
+// GetCondStack returns the contents of the condition stack, where the last item
+// is the innermost conditional. Its items are OpCondFalse, OpCondTrue and
+// OpCondSkip.
+func (vm *Engine) GetCondStack() []int {
+	return copyCondStack(vm.condStack)
+}
+
+// beginTraceStep returns the TraceStep of the opcode that is about to be
+// executed, or nil if there's no tracer attached.
+func (vm *Engine) beginTraceStep(pop *parsedOpcode) *TraceStep {
+	if vm.tracer == nil {
+		return nil
+	}
+	return &TraceStep{
+		ScriptIndex:     vm.scriptIdx,
+		ScriptOffset:    vm.scriptOff,
+		Disassembly:     pop.print(false),
+		Executed:        vm.isBranchExecuting() || pop.isConditional(),
+		StackBefore:     copyStack(vm.GetStack()),
+		AltStackBefore:  copyStack(vm.GetAltStack()),
+		CondStackBefore: copyCondStack(vm.condStack),
+	}
+}
+
+// endTraceStep completes the passed TraceStep with the state of the engine
+// after the execution of its opcode and notifies the tracer.
+func (vm *Engine) endTraceStep(step *TraceStep, err error) {
+	if step == nil {
+		return
+	}
+	step.Err = err
+	step.StackAfter = copyStack(vm.GetStack())
+	step.AltStackAfter = copyStack(vm.GetAltStack())
+	step.CondStackAfter = copyCondStack(vm.condStack)
+	vm.tracer.TraceStep(step)
+}
+
+func copyStack(stack [][]byte) [][]byte {
+	stackCopy := make([][]byte, len(stack))
+	for i, item := range stack {
+		stackCopy[i] = make([]byte, len(item))
+		copy(stackCopy[i], item)
+	}
+	return stackCopy
+}
+
+func copyCondStack(condStack []int) []int {
+	condStackCopy := make([]int, len(condStack))
+	copy(condStackCopy, condStack)
+	return condStackCopy
+}
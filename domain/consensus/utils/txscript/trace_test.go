@@ -0,0 +1,121 @@
+package txscript
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+)
+
+func TestTraceRecorder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		sigScript       string
+		scriptPubKey    string
+		expectedOpcodes []string
+		expectedSkipped map[int]bool
+		expectedFinal   [][]byte
+		expectErr       bool
+	}{
+		{
+			name:         "conditional branches",
+			sigScript:    "1",
+			scriptPubKey: "IF 2 ELSE 3 ENDIF 2 EQUAL",
+			expectedOpcodes: []string{"OP_1", "OP_IF", "OP_2", "OP_ELSE", "OP_3", "OP_ENDIF",
+				"OP_2", "OP_EQUAL"},
+			expectedSkipped: map[int]bool{4: true},
+			expectedFinal:   [][]byte{{1}},
+		},
+		{
+			name:            "failing opcode",
+			sigScript:       "0",
+			scriptPubKey:    "VERIFY 1",
+			expectedOpcodes: []string{"OP_0", "OP_VERIFY"},
+			expectedFinal:   nil,
+			expectErr:       true,
+		},
+	}
+
+	for _, test := range tests {
+		tx := &externalapi.DomainTransaction{
+			Version: 0,
+			Inputs: []*externalapi.DomainTransactionInput{{
+				SignatureScript: mustParseShortForm(test.sigScript, 0),
+			}},
+		}
+		scriptPubKey := &externalapi.ScriptPublicKey{Script: mustParseShortForm(test.scriptPubKey, 0), Version: 0}
+		vm, err := NewEngine(scriptPubKey, tx, 0, ScriptNoFlags, nil, nil, &consensushashing.SighashReusedValues{})
+		if err != nil {
+			t.Fatalf("%s: NewEngine: %v", test.name, err)
+		}
+		recorder := &TraceRecorder{}
+		vm.SetTracer(recorder)
+
+		err = vm.Execute()
+		if (err != nil) != test.expectErr {
+			t.Fatalf("%s: Execute: unexpected error %v", test.name, err)
+		}
+
+		if len(recorder.Steps) != len(test.expectedOpcodes) {
+			t.Fatalf("%s: got %d steps, want %d", test.name, len(recorder.Steps), len(test.expectedOpcodes))
+		}
+		for i, step := range recorder.Steps {
+			if step.Disassembly != test.expectedOpcodes[i] {
+				t.Errorf("%s: step %d: got opcode %s, want %s", test.name, i, step.Disassembly, test.expectedOpcodes[i])
+			}
+			if step.Executed == test.expectedSkipped[i] {
+				t.Errorf("%s: step %d: got executed %t", test.name, i, step.Executed)
+			}
+			if i > 0 && step.ScriptIndex == recorder.Steps[i-1].ScriptIndex &&
+				!reflect.DeepEqual(step.StackBefore, recorder.Steps[i-1].StackAfter) {
+				t.Errorf("%s: step %d: the stack before isn't the stack after the previous step", test.name, i)
+			}
+		}
+
+		lastStep := recorder.Steps[len(recorder.Steps)-1]
+		if test.expectErr {
+			if lastStep.Err == nil {
+				t.Errorf("%s: the last step doesn't have an error", test.name)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(lastStep.StackAfter, test.expectedFinal) {
+			t.Errorf("%s: got final stack %v, want %v", test.name, lastStep.StackAfter, test.expectedFinal)
+		}
+	}
+}
+
+func TestTraceCondStack(t *testing.T) {
+	t.Parallel()
+
+	tx := &externalapi.DomainTransaction{
+		Version: 0,
+		Inputs: []*externalapi.DomainTransactionInput{{
+			SignatureScript: mustParseShortForm("0", 0),
+		}},
+	}
+	scriptPubKey := &externalapi.ScriptPublicKey{Script: mustParseShortForm("NOTIF 1 ENDIF", 0), Version: 0}
+	vm, err := NewEngine(scriptPubKey, tx, 0, ScriptNoFlags, nil, nil, &consensushashing.SighashReusedValues{})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	recorder := &TraceRecorder{}
+	vm.SetTracer(recorder)
+	err = vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	expectedCondStacks := [][]int{{}, {OpCondTrue}, {OpCondTrue}, {}}
+	for i, step := range recorder.Steps {
+		if !reflect.DeepEqual(step.CondStackAfter, expectedCondStacks[i]) {
+			t.Errorf("step %d: got condition stack %v, want %v", i, step.CondStackAfter, expectedCondStacks[i])
+		}
+	}
+	if len(vm.GetCondStack()) != 0 {
+		t.Errorf("the condition stack isn't empty after execution")
+	}
+}
@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+// ErrInconsistentConsensus is returned by CheckConsistency when it finds
+// inconsistencies and isn't allowed to repair them
+var ErrInconsistentConsensus = errors.New("the consensus is inconsistent")
+
+// CheckConsistency verifies that the consensus stores agree with each other
+// around the virtual, which they might not after a crash of the host when the
+// database doesn't sync its writes. It checks that the virtual parents and the
+// tips exist, and that every block in the selected chain of the virtual, up to
+// depth chain blocks or the pruning point, and in the merge sets of these
+// blocks, has the header and the body its status says it has.
+//
+// Every inconsistency that is found is logged. Unless repair is set,
+// CheckConsistency then returns ErrInconsistentConsensus. If it's set, the
+// consensus is rebuilt from the data that is still intact, leaving out the
+// blocks that can't be read. If it can't be rebuilt, it's reset to genesis,
+// and the DAG is downloaded again from peers.
+//
+// NOTE: While this is called no new blocks can be added to the consensus.
+func (d *domain) CheckConsistency(depth uint64, repair bool) error {
+	problems, err := findInconsistencies(d.Consensus(), depth)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		log.Infof("The consensus is consistent")
+		return nil
+	}
+
+	for _, problem := range problems {
+		log.Warnf("Consensus inconsistency: %s", problem)
+	}
+	if !repair {
+		return errors.Wrapf(ErrInconsistentConsensus, "found %d inconsistencies", len(problems))
+	}
+	log.Warnf("Found %d inconsistencies in the consensus. Rebuilding it", len(problems))
+
+	err = d.repairConsensus()
+	if err == nil {
+		log.Infof("Rebuilt the consensus")
+		return nil
+	}
+	log.Errorf("Failed to rebuild the consensus: %s", err)
+
+	log.Warnf("Resetting the consensus to genesis")
+	err = d.DeleteStagingConsensus()
+	if err != nil {
+		return err
+	}
+	err = d.initStagingConsensus(d.consensusConfig)
+	if err != nil {
+		return err
+	}
+	return d.CommitStagingConsensus()
+}
+
+// findInconsistencies returns a description of every broken invariant that
+// CheckConsistency looks for
+func findInconsistencies(consensus externalapi.Consensus, depth uint64) ([]string, error) {
+	var problems []string
+	checked := make(map[externalapi.DomainHash]struct{})
+	checkBlock := func(blockHash *externalapi.DomainHash, name string, mustBeUTXOValid bool) *externalapi.BlockInfo {
+		checked[*blockHash] = struct{}{}
+		blockInfo, problem := checkBlockConsistency(consensus, blockHash, mustBeUTXOValid)
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("%s %s %s", name, blockHash, problem))
+			return nil
+		}
+		return blockInfo
+	}
+
+	virtualInfo, err := consensus.GetVirtualInfo()
+	if err != nil {
+		return nil, err
+	}
+	for _, parent := range virtualInfo.ParentHashes {
+		checkBlock(parent, "virtual parent", true)
+	}
+
+	tips, err := consensus.Tips()
+	if err != nil {
+		return nil, err
+	}
+	for _, tip := range tips {
+		checkBlock(tip, "tip", false)
+	}
+
+	pruningPoint, err := consensus.PruningPoint()
+	if err != nil {
+		return nil, err
+	}
+	chainBlock, err := consensus.GetVirtualSelectedParent()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < depth; i++ {
+		blockInfo := checkBlock(chainBlock, "chain block", false)
+		if blockInfo == nil || chainBlock.Equal(pruningPoint) {
+			break
+		}
+
+		mergeSet := make([]*externalapi.DomainHash, 0, len(blockInfo.MergeSetBlues)+len(blockInfo.MergeSetReds))
+		mergeSet = append(mergeSet, blockInfo.MergeSetBlues...)
+		mergeSet = append(mergeSet, blockInfo.MergeSetReds...)
+		for _, mergeSetBlock := range mergeSet {
+			if _, ok := checked[*mergeSetBlock]; ok || mergeSetBlock.Equal(blockInfo.SelectedParent) {
+				continue
+			}
+			checkBlock(mergeSetBlock, "merged block", false)
+		}
+		chainBlock = blockInfo.SelectedParent
+	}
+
+	return problems, nil
+}
+
+// checkBlockConsistency checks that the given block has a valid status, and
+// has the header and the body that its status says it has. It returns the
+// block info, or a description of the first problem it finds.
+func checkBlockConsistency(consensus externalapi.Consensus, blockHash *externalapi.DomainHash,
+	mustBeUTXOValid bool) (*externalapi.BlockInfo, string) {
+
+	blockInfo, err := consensus.GetBlockInfo(blockHash)
+	if err != nil {
+		return nil, fmt.Sprintf("has no block info: %s", err)
+	}
+	if !blockInfo.HasHeader() || (mustBeUTXOValid && blockInfo.BlockStatus != externalapi.StatusUTXOValid) {
+		return nil, fmt.Sprintf("has status %s", blockInfo.BlockStatus)
+	}
+
+	_, err = consensus.GetBlockHeader(blockHash)
+	if err != nil {
+		return nil, fmt.Sprintf("has no header: %s", err)
+	}
+	if blockInfo.HasBody() {
+		_, found, err := consensus.GetBlock(blockHash)
+		if err != nil {
+			return nil, fmt.Sprintf("has status %s but its body can't be read: %s", blockInfo.BlockStatus, err)
+		}
+		if !found {
+			return nil, fmt.Sprintf("has status %s but its body is missing", blockInfo.BlockStatus)
+		}
+	}
+	return blockInfo, ""
+}
+
+// repairConsensus rebuilds the consensus in a staging consensus from the
+// pruning point data and the blocks above the pruning point that can still
+// be read, and commits it. The staging consensus is left in place if it
+// fails.
+func (d *domain) repairConsensus() error {
+	pruningPoint, err := d.Consensus().PruningPoint()
+	if err != nil {
+		return err
+	}
+
+	if d.consensusConfig.Params.GenesisHash.Equal(pruningPoint) {
+		err = d.initStagingConsensus(d.consensusConfig)
+		if err != nil {
+			return err
+		}
+		err = syncBlocks(d.Consensus(), d.StagingConsensus(), pruningPoint, true)
+		if err == nil {
+			err = resolveVirtual(d.Consensus(), d.StagingConsensus())
+		}
+	} else {
+		err = d.InitStagingConsensusWithoutGenesis()
+		if err != nil {
+			return err
+		}
+		err = syncConsensuses(d.Consensus(), d.StagingConsensus(), true)
+	}
+	if err != nil {
+		return err
+	}
+
+	problems, err := findInconsistencies(d.StagingConsensus(), 0)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return errors.Errorf("the rebuilt consensus is inconsistent: %s", problems[0])
+	}
+
+	return d.CommitStagingConsensus()
+}
@@ -1,6 +1,7 @@
 package dagconfig
 
 import (
+	"math"
 	"time"
 
 	"github.com/ammm56/lings/domain/consensus/utils/constants"
@@ -33,6 +34,9 @@ const (
 	defaultMassPerTxByte           = 1
 	defaultMassPerScriptPubKeyByte = 10
 	defaultMassPerSigOp            = 1000
+	// defaultMassPerSpliceOp is the number of grams per splice or bitwise operation in
+	// the scripts a transaction input runs.
+	defaultMassPerSpliceOp = 100
 	// defaultMaxBlockParents is the number of blocks any block can point to.
 	// Should be about d/defaultTargetTimePerBlock where d is a bound on the round trip time of a block.
 	defaultMaxBlockParents = 10
@@ -83,4 +87,14 @@ const (
 	defaultDeflationaryPhaseDaaScore = 360
 
 	defaultMergeDepth = 3600
+
+	// notScheduledDAAScore is the activation DAA score of script rule changes
+	// that aren't scheduled on a network yet
+	notScheduledDAAScore = math.MaxUint64
+
+	// defaultDevFeePercent is the percentage of the reward of every merged block that is paid as dev fee.
+	defaultDevFeePercent = 5
+	// defaultDevFeeMinPercent is the minimum percentage of the block subsidy that a block's dev fee output
+	// must have.
+	defaultDevFeeMinPercent = 1
 )
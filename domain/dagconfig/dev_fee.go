@@ -0,0 +1,87 @@
+package dagconfig
+
+import (
+	"github.com/ammm56/lings/util"
+	"github.com/pkg/errors"
+)
+
+// DevFeePeriod defines the dev fee that the coinbase transactions of blocks pay
+// while their DAA score is in [ActivationDAAScore, ExpiryDAAScore).
+type DevFeePeriod struct {
+	// Address is the address the dev fee is paid to. It must be encoded
+	// with the prefix of the network.
+	Address string `json:"address" toml:"address"`
+
+	// Percent is the percentage of the reward of every merged block that
+	// is paid as dev fee.
+	Percent uint64 `json:"percent" toml:"percent"`
+
+	// MinPercent is the minimum percentage of the block subsidy that the
+	// dev fee output of a block must have for the block to be valid.
+	MinPercent uint64 `json:"minPercent" toml:"minPercent"`
+
+	// ActivationDAAScore is the DAA score from which the period applies.
+	ActivationDAAScore uint64 `json:"activationDaaScore" toml:"activationDaaScore"`
+
+	// ExpiryDAAScore is the DAA score from which the period no longer
+	// applies. 0 means that the period never expires.
+	ExpiryDAAScore uint64 `json:"expiryDaaScore" toml:"expiryDaaScore"`
+}
+
+// isActiveAt returns whether the period applies to blocks with the given DAA score
+func (period *DevFeePeriod) isActiveAt(daaScore uint64) bool {
+	return daaScore >= period.ActivationDAAScore &&
+		(period.ExpiryDAAScore == 0 || daaScore < period.ExpiryDAAScore)
+}
+
+// DecodeAddress decodes the address of the period with the given network prefix
+func (period *DevFeePeriod) DecodeAddress(prefix util.Bech32Prefix) (util.Address, error) {
+	return util.DecodeAddress(period.Address, prefix)
+}
+
+// DevFeeSchedule is the list of the dev fee periods of a network, ordered by
+// their activation DAA score. A network with an empty schedule doesn't pay a
+// dev fee.
+type DevFeeSchedule []*DevFeePeriod
+
+// PeriodAt returns the dev fee period that applies to blocks with the given
+// DAA score, or nil if no dev fee is paid at that DAA score.
+func (schedule DevFeeSchedule) PeriodAt(daaScore uint64) *DevFeePeriod {
+	for _, period := range schedule {
+		if period.isActiveAt(daaScore) {
+			return period
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if any of the periods of the schedule is invalid,
+// or if the periods are not ordered or overlap.
+func (schedule DevFeeSchedule) Validate(prefix util.Bech32Prefix) error {
+	for i, period := range schedule {
+		_, err := period.DecodeAddress(prefix)
+		if err != nil {
+			return errors.Wrapf(err, "dev fee period %d has an invalid address %s", i, period.Address)
+		}
+		if period.Percent == 0 || period.Percent > 100 {
+			return errors.Errorf("dev fee period %d: percent must be between 1 and 100, got %d",
+				i, period.Percent)
+		}
+		if period.MinPercent > period.Percent {
+			return errors.Errorf("dev fee period %d: min percent %d is greater than percent %d",
+				i, period.MinPercent, period.Percent)
+		}
+		if period.ExpiryDAAScore != 0 && period.ExpiryDAAScore <= period.ActivationDAAScore {
+			return errors.Errorf("dev fee period %d: expiry DAA score %d is not after activation DAA score %d",
+				i, period.ExpiryDAAScore, period.ActivationDAAScore)
+		}
+		if i == 0 {
+			continue
+		}
+		previousPeriod := schedule[i-1]
+		if previousPeriod.ExpiryDAAScore == 0 || previousPeriod.ExpiryDAAScore > period.ActivationDAAScore {
+			return errors.Errorf("dev fee period %d overlaps dev fee period %d", i, i-1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+package dagconfig
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/blockheader"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/merkle"
+	"github.com/ammm56/lings/domain/consensus/utils/subnetworks"
+	"github.com/ammm56/lings/domain/consensus/utils/transactionhelper"
+	"github.com/ammm56/lings/util"
+	"github.com/ammm56/lings/util/difficulty"
+	"github.com/kaspanet/go-muhash"
+	"github.com/pkg/errors"
+)
+
+// NetworkDefinition defines a custom Lings network. It's loaded from a JSON or
+// TOML file, and is turned into Params by taking the parameters of a standard
+// network and replacing the ones that the definition sets.
+type NetworkDefinition struct {
+	// Name is the name of the network. It must differ from the names of the
+	// standard networks.
+	Name string `json:"name" toml:"name"`
+
+	// Net is the magic number that identifies the messages of the network.
+	// It must differ from the magic numbers of the other networks.
+	Net uint32 `json:"net" toml:"net"`
+
+	// Base is the standard network the parameters that the definition doesn't
+	// set are taken from: mainnet, testnet, simnet or devnet. The default is
+	// devnet.
+	Base string `json:"base" toml:"base"`
+
+	RPCPort     string   `json:"rpcPort" toml:"rpcPort"`
+	DefaultPort string   `json:"defaultPort" toml:"defaultPort"`
+	DNSSeeds    []string `json:"dnsSeeds" toml:"dnsSeeds"`
+	GRPCSeeds   []string `json:"grpcSeeds" toml:"grpcSeeds"`
+
+	// AddressPrefix is the Bech32 prefix of the addresses of the network. It
+	// may be one of the prefixes of the standard networks, or a new one.
+	AddressPrefix string `json:"addressPrefix" toml:"addressPrefix"`
+
+	K                                *externalapi.KType `json:"k" toml:"k"`
+	MaxBlockParents                  *externalapi.KType `json:"maxBlockParents" toml:"maxBlockParents"`
+	MergeSetSizeLimit                *uint64            `json:"mergeSetSizeLimit" toml:"mergeSetSizeLimit"`
+	MergeDepth                       *uint64            `json:"mergeDepth" toml:"mergeDepth"`
+	TargetTimePerBlockInMilliSeconds *int64             `json:"targetTimePerBlockInMilliSeconds" toml:"targetTimePerBlockInMilliSeconds"`
+	FinalityDurationInMilliSeconds   *int64             `json:"finalityDurationInMilliSeconds" toml:"finalityDurationInMilliSeconds"`
+	TimestampDeviationTolerance      *int               `json:"timestampDeviationTolerance" toml:"timestampDeviationTolerance"`
+	DifficultyAdjustmentWindowSize   *int               `json:"difficultyAdjustmentWindowSize" toml:"difficultyAdjustmentWindowSize"`
+	DisableDifficultyAdjustment      *bool              `json:"disableDifficultyAdjustment" toml:"disableDifficultyAdjustment"`
+	PowMax                           *string            `json:"powMax" toml:"powMax"`
+	SkipProofOfWork                  *bool              `json:"skipProofOfWork" toml:"skipProofOfWork"`
+	POWScores                        []uint64           `json:"powScores" toml:"powScores"`
+	IntrospectionActivationDAAScore  *uint64            `json:"introspectionActivationDaaScore" toml:"introspectionActivationDaaScore"`
+	SpliceOpcodesActivationDAAScore  *uint64            `json:"spliceOpcodesActivationDaaScore" toml:"spliceOpcodesActivationDaaScore"`
+	MaxBlockLevel                    *int               `json:"maxBlockLevel" toml:"maxBlockLevel"`
+	PruningProofM                    *uint64            `json:"pruningProofM" toml:"pruningProofM"`
+
+	BlockCoinbaseMaturity           *uint64         `json:"blockCoinbaseMaturity" toml:"blockCoinbaseMaturity"`
+	SubsidyGenesisReward            *uint64         `json:"subsidyGenesisReward" toml:"subsidyGenesisReward"`
+	PreDeflationaryPhaseBaseSubsidy *uint64         `json:"preDeflationaryPhaseBaseSubsidy" toml:"preDeflationaryPhaseBaseSubsidy"`
+	DeflationaryPhaseBaseSubsidy    *uint64         `json:"deflationaryPhaseBaseSubsidy" toml:"deflationaryPhaseBaseSubsidy"`
+	DeflationaryPhaseCurveFactor    *float64        `json:"deflationaryPhaseCurveFactor" toml:"deflationaryPhaseCurveFactor"`
+	DeflationaryPhaseDaaScore       *uint64         `json:"deflationaryPhaseDaaScore" toml:"deflationaryPhaseDaaScore"`
+	DevFeeSchedule                  *DevFeeSchedule `json:"devFeeSchedule" toml:"devFeeSchedule"`
+
+	MaxBlockMass                            *uint64 `json:"maxBlockMass" toml:"maxBlockMass"`
+	MaxCoinbasePayloadLength                *uint64 `json:"maxCoinbasePayloadLength" toml:"maxCoinbasePayloadLength"`
+	CoinbasePayloadScriptPublicKeyMaxLength *uint8  `json:"coinbasePayloadScriptPublicKeyMaxLength" toml:"coinbasePayloadScriptPublicKeyMaxLength"`
+	MassPerTxByte                           *uint64 `json:"massPerTxByte" toml:"massPerTxByte"`
+	MassPerScriptPubKeyByte                 *uint64 `json:"massPerScriptPubKeyByte" toml:"massPerScriptPubKeyByte"`
+	MassPerSigOp                            *uint64 `json:"massPerSigOp" toml:"massPerSigOp"`
+	MassPerSpliceOp                         *uint64 `json:"massPerSpliceOp" toml:"massPerSpliceOp"`
+	RelayNonStdTxs                          *bool   `json:"relayNonStdTxs" toml:"relayNonStdTxs"`
+	AcceptUnroutable                        *bool   `json:"acceptUnroutable" toml:"acceptUnroutable"`
+	EnableNonNativeSubnetworks              *bool   `json:"enableNonNativeSubnetworks" toml:"enableNonNativeSubnetworks"`
+
+	Genesis GenesisDefinition `json:"genesis" toml:"genesis"`
+}
+
+// GenesisDefinition defines the genesis block of a custom network
+type GenesisDefinition struct {
+	Version            uint16 `json:"version" toml:"version"`
+	TimeInMilliseconds int64  `json:"timeInMilliseconds" toml:"timeInMilliseconds"`
+
+	// Bits is the difficulty of the genesis block in compact form. 0 means
+	// the lowest difficulty that PowMax allows.
+	Bits  uint32 `json:"bits" toml:"bits"`
+	Nonce uint64 `json:"nonce" toml:"nonce"`
+
+	// Message is embedded in the payload of the genesis coinbase transaction
+	Message string `json:"message" toml:"message"`
+
+	// Hash is the expected hash of the genesis block. It's checked when it's
+	// set, and is printed, along with the nonce, by `lings gen-genesis`.
+	Hash string `json:"hash" toml:"hash"`
+}
+
+var standardNetworks = map[string]*Params{
+	"mainnet": &MainnetParams,
+	"testnet": &TestnetParams,
+	"simnet":  &SimnetParams,
+	"devnet":  &DevnetParams,
+}
+
+// LoadNetworkDefinition loads a network definition from the given file. Files
+// with a .toml extension are decoded as TOML, and all other files as JSON.
+func LoadNetworkDefinition(path string) (*NetworkDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	definition := &NetworkDefinition{}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, definition)
+	} else {
+		err = json.Unmarshal(data, definition)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't parse the network definition %s", path)
+	}
+	return definition, nil
+}
+
+// LoadNetworkParams loads the network definition in the given file, and returns
+// the Params of the network after registering them.
+func LoadNetworkParams(path string) (*Params, error) {
+	definition, err := LoadNetworkDefinition(path)
+	if err != nil {
+		return nil, err
+	}
+	params, err := definition.Params()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid network definition %s", path)
+	}
+	err = Register(params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't register the network %s", params.Name)
+	}
+	return params, nil
+}
+
+// Params returns the parameters of the network that the definition defines
+func (definition *NetworkDefinition) Params() (*Params, error) {
+	baseName := definition.Base
+	if baseName == "" {
+		baseName = "devnet"
+	}
+	base, ok := standardNetworks[baseName]
+	if !ok {
+		return nil, errors.Errorf("unknown base network %s", baseName)
+	}
+	if definition.Name == "" {
+		return nil, errors.New("the network must have a name")
+	}
+	for _, standardNetwork := range standardNetworks {
+		if definition.Name == standardNetwork.Name {
+			return nil, errors.Errorf("the network name %s is the name of a standard network", definition.Name)
+		}
+	}
+	if definition.Net == 0 {
+		return nil, errors.New("the network must have a non-zero net magic")
+	}
+
+	params := *base
+	params.Name = definition.Name
+	params.Net = appmessage.LingsNet(definition.Net)
+	params.DNSSeeds = definition.DNSSeeds
+	params.GRPCSeeds = definition.GRPCSeeds
+	if definition.RPCPort != "" {
+		params.RPCPort = definition.RPCPort
+	}
+	if definition.DefaultPort != "" {
+		params.DefaultPort = definition.DefaultPort
+	}
+	if definition.AddressPrefix != "" {
+		prefix, err := util.RegisterBech32Prefix(definition.AddressPrefix)
+		if err != nil {
+			return nil, err
+		}
+		params.Prefix = prefix
+	}
+
+	if definition.PowMax != nil {
+		powMax, ok := big.NewInt(0).SetString(*definition.PowMax, 16)
+		if !ok {
+			return nil, errors.Errorf("couldn't convert %s to big int", *definition.PowMax)
+		}
+		params.PowMax = powMax
+	}
+	if definition.TargetTimePerBlockInMilliSeconds != nil {
+		params.TargetTimePerBlock = time.Duration(*definition.TargetTimePerBlockInMilliSeconds) * time.Millisecond
+	}
+	if definition.FinalityDurationInMilliSeconds != nil {
+		params.FinalityDuration = time.Duration(*definition.FinalityDurationInMilliSeconds) * time.Millisecond
+	}
+	if definition.POWScores != nil {
+		params.POWScores = definition.POWScores
+	}
+	if definition.IntrospectionActivationDAAScore != nil {
+		params.IntrospectionActivationDAAScore = *definition.IntrospectionActivationDAAScore
+	}
+	if definition.SpliceOpcodesActivationDAAScore != nil {
+		params.SpliceOpcodesActivationDAAScore = *definition.SpliceOpcodesActivationDAAScore
+	}
+	if definition.DevFeeSchedule != nil {
+		params.DevFeeSchedule = *definition.DevFeeSchedule
+	}
+	if definition.K != nil {
+		params.K = *definition.K
+	}
+	if definition.MaxBlockParents != nil {
+		params.MaxBlockParents = *definition.MaxBlockParents
+	}
+	if definition.MergeSetSizeLimit != nil {
+		params.MergeSetSizeLimit = *definition.MergeSetSizeLimit
+	}
+	if definition.MergeDepth != nil {
+		params.MergeDepth = *definition.MergeDepth
+	}
+	if definition.TimestampDeviationTolerance != nil {
+		params.TimestampDeviationTolerance = *definition.TimestampDeviationTolerance
+	}
+	if definition.DifficultyAdjustmentWindowSize != nil {
+		params.DifficultyAdjustmentWindowSize = *definition.DifficultyAdjustmentWindowSize
+	}
+	if definition.DisableDifficultyAdjustment != nil {
+		params.DisableDifficultyAdjustment = *definition.DisableDifficultyAdjustment
+	}
+	if definition.SkipProofOfWork != nil {
+		params.SkipProofOfWork = *definition.SkipProofOfWork
+	}
+	if definition.MaxBlockLevel != nil {
+		params.MaxBlockLevel = *definition.MaxBlockLevel
+	}
+	if definition.PruningProofM != nil {
+		params.PruningProofM = *definition.PruningProofM
+	}
+	if definition.BlockCoinbaseMaturity != nil {
+		params.BlockCoinbaseMaturity = *definition.BlockCoinbaseMaturity
+	}
+	if definition.SubsidyGenesisReward != nil {
+		params.SubsidyGenesisReward = *definition.SubsidyGenesisReward
+	}
+	if definition.PreDeflationaryPhaseBaseSubsidy != nil {
+		params.PreDeflationaryPhaseBaseSubsidy = *definition.PreDeflationaryPhaseBaseSubsidy
+	}
+	if definition.DeflationaryPhaseBaseSubsidy != nil {
+		params.DeflationaryPhaseBaseSubsidy = *definition.DeflationaryPhaseBaseSubsidy
+	}
+	if definition.DeflationaryPhaseCurveFactor != nil {
+		params.DeflationaryPhaseCurveFactor = *definition.DeflationaryPhaseCurveFactor
+	}
+	if definition.DeflationaryPhaseDaaScore != nil {
+		params.DeflationaryPhaseDaaScore = *definition.DeflationaryPhaseDaaScore
+	}
+	if definition.MaxBlockMass != nil {
+		params.MaxBlockMass = *definition.MaxBlockMass
+	}
+	if definition.MaxCoinbasePayloadLength != nil {
+		params.MaxCoinbasePayloadLength = *definition.MaxCoinbasePayloadLength
+	}
+	if definition.CoinbasePayloadScriptPublicKeyMaxLength != nil {
+		params.CoinbasePayloadScriptPublicKeyMaxLength = *definition.CoinbasePayloadScriptPublicKeyMaxLength
+	}
+	if definition.MassPerTxByte != nil {
+		params.MassPerTxByte = *definition.MassPerTxByte
+	}
+	if definition.MassPerScriptPubKeyByte != nil {
+		params.MassPerScriptPubKeyByte = *definition.MassPerScriptPubKeyByte
+	}
+	if definition.MassPerSigOp != nil {
+		params.MassPerSigOp = *definition.MassPerSigOp
+	}
+	if definition.MassPerSpliceOp != nil {
+		params.MassPerSpliceOp = *definition.MassPerSpliceOp
+	}
+	if definition.RelayNonStdTxs != nil {
+		params.RelayNonStdTxs = *definition.RelayNonStdTxs
+	}
+	if definition.AcceptUnroutable != nil {
+		params.AcceptUnroutable = *definition.AcceptUnroutable
+	}
+	if definition.EnableNonNativeSubnetworks != nil {
+		params.EnableNonNativeSubnetworks = *definition.EnableNonNativeSubnetworks
+	}
+
+	if params.K == 0 || params.TargetTimePerBlock <= 0 || params.DifficultyAdjustmentWindowSize <= 0 {
+		return nil, errors.New("k, targetTimePerBlockInMilliSeconds and difficultyAdjustmentWindowSize must be positive")
+	}
+
+	params.GenesisBlock = definition.GenesisBlock(&params)
+	params.GenesisHash = consensushashing.BlockHash(params.GenesisBlock)
+	if definition.Genesis.Hash != "" && definition.Genesis.Hash != params.GenesisHash.String() {
+		return nil, errors.Errorf("the genesis block hash is %s but the definition expects %s. "+
+			"Run `lings gen-genesis` to mine the genesis block", params.GenesisHash, definition.Genesis.Hash)
+	}
+
+	err := params.ValidateDevFeeSchedule()
+	if err != nil {
+		return nil, err
+	}
+
+	return &params, nil
+}
+
+// GenesisBlock returns the genesis block that the definition defines for a
+// network with the given params
+func (definition *NetworkDefinition) GenesisBlock(params *Params) *externalapi.DomainBlock {
+	// The payload is laid out like the payloads of the standard genesis blocks:
+	// blue score, subsidy, script version, script length and an OP-FALSE
+	// script, followed by the message
+	coinbasePayload := make([]byte, 20, 20+len(definition.Genesis.Message))
+	binary.LittleEndian.PutUint64(coinbasePayload[8:16], params.SubsidyGenesisReward)
+	coinbasePayload[18] = 0x01
+	coinbasePayload = append(coinbasePayload, definition.Genesis.Message...)
+
+	coinbaseTx := transactionhelper.NewSubnetworkTransaction(0, []*externalapi.DomainTransactionInput{},
+		[]*externalapi.DomainTransactionOutput{}, &subnetworks.SubnetworkIDCoinbase, 0, coinbasePayload)
+	transactions := []*externalapi.DomainTransaction{coinbaseTx}
+
+	bits := definition.Genesis.Bits
+	if bits == 0 {
+		bits = difficulty.BigToCompact(params.PowMax)
+	}
+
+	return &externalapi.DomainBlock{
+		Header: blockheader.NewImmutableBlockHeader(
+			definition.Genesis.Version,
+			[]externalapi.BlockLevelParents{},
+			merkle.CalculateHashMerkleRoot(transactions),
+			&externalapi.DomainHash{},
+			externalapi.NewDomainHashFromByteArray(muhash.EmptyMuHashHash.AsArray()),
+			definition.Genesis.TimeInMilliseconds,
+			bits,
+			definition.Genesis.Nonce,
+			0,
+			0,
+			big.NewInt(0),
+			&externalapi.DomainHash{},
+		),
+		Transactions: transactions,
+	}
+}
@@ -0,0 +1,158 @@
+package dagconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/util"
+)
+
+const jsonNetworkDefinition = `{
+  "name": "lings-private",
+  "net": 3405643777,
+  "base": "simnet",
+  "rpcPort": "52110",
+  "defaultPort": "52111",
+  "grpcSeeds": ["10.0.0.1:52111"],
+  "addressPrefix": "lingspriv",
+  "k": 40,
+  "targetTimePerBlockInMilliSeconds": 250,
+  "difficultyAdjustmentWindowSize": 600,
+  "powScores": [100, 200],
+  "deflationaryPhaseBaseSubsidy": 5000000000,
+  "deflationaryPhaseCurveFactor": 1.5,
+  "genesis": {
+    "timeInMilliseconds": 1700000000000,
+    "message": "lings-private"
+  }
+}`
+
+const tomlNetworkDefinition = `
+name = "lings-private"
+net = 0xcafe0001
+base = "simnet"
+rpcPort = "52110"
+defaultPort = "52111"
+grpcSeeds = ["10.0.0.1:52111"]
+addressPrefix = "lingspriv"
+k = 40
+targetTimePerBlockInMilliSeconds = 250
+difficultyAdjustmentWindowSize = 600
+powScores = [100, 200]
+deflationaryPhaseBaseSubsidy = 5_000_000_000
+deflationaryPhaseCurveFactor = 1.5
+
+[genesis]
+timeInMilliseconds = 1700000000000
+message = "lings-private"
+`
+
+func writeNetworkDefinition(t *testing.T, fileName string, content string) string {
+	path := filepath.Join(t.TempDir(), fileName)
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	return path
+}
+
+func TestNetworkDefinitionParams(t *testing.T) {
+	var allParams []*Params
+	for _, file := range []struct{ name, content string }{
+		{"network.json", jsonNetworkDefinition},
+		{"network.toml", tomlNetworkDefinition},
+	} {
+		definition, err := LoadNetworkDefinition(writeNetworkDefinition(t, file.name, file.content))
+		if err != nil {
+			t.Fatalf("%s: LoadNetworkDefinition: %s", file.name, err)
+		}
+		params, err := definition.Params()
+		if err != nil {
+			t.Fatalf("%s: Params: %s", file.name, err)
+		}
+		allParams = append(allParams, params)
+
+		if params.Name != "lings-private" || params.Net != 0xcafe0001 {
+			t.Errorf("%s: got network %s with net %x", file.name, params.Name, params.Net)
+		}
+		if params.RPCPort != "52110" || params.DefaultPort != "52111" {
+			t.Errorf("%s: got ports %s and %s", file.name, params.RPCPort, params.DefaultPort)
+		}
+		if params.K != 40 || params.TargetTimePerBlock != 250*time.Millisecond ||
+			params.DifficultyAdjustmentWindowSize != 600 {
+			t.Errorf("%s: got K %d, target time per block %s and difficulty window %d",
+				file.name, params.K, params.TargetTimePerBlock, params.DifficultyAdjustmentWindowSize)
+		}
+		if !reflect.DeepEqual(params.POWScores, []uint64{100, 200}) {
+			t.Errorf("%s: got POW scores %v", file.name, params.POWScores)
+		}
+		if params.DeflationaryPhaseBaseSubsidy != 5000000000 || params.DeflationaryPhaseCurveFactor != 1.5 {
+			t.Errorf("%s: the subsidy curve wasn't set", file.name)
+		}
+		if params.Prefix.String() != "lingspriv" {
+			t.Errorf("%s: got address prefix %s", file.name, params.Prefix)
+		}
+
+		// Parameters that aren't defined are taken from the base network
+		if params.BlockCoinbaseMaturity != SimnetParams.BlockCoinbaseMaturity ||
+			params.DisableDifficultyAdjustment != SimnetParams.DisableDifficultyAdjustment {
+			t.Errorf("%s: the parameters of the base network weren't kept", file.name)
+		}
+		if SimnetParams.K == 40 || SimnetParams.Name != "lings-simnet" {
+			t.Errorf("%s: the base network was modified", file.name)
+		}
+
+		if !consensushashing.BlockHash(params.GenesisBlock).Equal(params.GenesisHash) {
+			t.Errorf("%s: the genesis hash doesn't match the genesis block", file.name)
+		}
+	}
+
+	if !allParams[0].GenesisHash.Equal(allParams[1].GenesisHash) {
+		t.Errorf("the JSON and TOML definitions have different genesis blocks")
+	}
+
+	prefix, err := util.ParsePrefix("lingspriv")
+	if err != nil || prefix != allParams[0].Prefix {
+		t.Errorf("the custom address prefix wasn't registered")
+	}
+}
+
+func TestNetworkDefinitionErrors(t *testing.T) {
+	validDefinition := func() *NetworkDefinition {
+		return &NetworkDefinition{Name: "lings-invalid", Net: 0xcafe0002}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(definition *NetworkDefinition)
+	}{
+		{name: "missing name", modify: func(definition *NetworkDefinition) { definition.Name = "" }},
+		{name: "standard network name", modify: func(definition *NetworkDefinition) { definition.Name = "lings-mainnet" }},
+		{name: "missing net", modify: func(definition *NetworkDefinition) { definition.Net = 0 }},
+		{name: "unknown base", modify: func(definition *NetworkDefinition) { definition.Base = "regtest" }},
+		{name: "invalid address prefix", modify: func(definition *NetworkDefinition) { definition.AddressPrefix = "Lings-X" }},
+		{name: "wrong genesis hash", modify: func(definition *NetworkDefinition) {
+			definition.Genesis.Hash = "0000000000000000000000000000000000000000000000000000000000000000"
+		}},
+		{name: "invalid dev fee schedule", modify: func(definition *NetworkDefinition) {
+			definition.DevFeeSchedule = &DevFeeSchedule{{Address: "lings:invalid", Percent: 5}}
+		}},
+	}
+
+	_, err := validDefinition().Params()
+	if err != nil {
+		t.Fatalf("Params: %s", err)
+	}
+	for _, test := range tests {
+		definition := validDefinition()
+		test.modify(definition)
+		_, err := definition.Params()
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
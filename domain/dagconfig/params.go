@@ -170,6 +170,10 @@ type Params struct {
 	// signature operation adds to a transaction.
 	MassPerSigOp uint64
 
+	// MassPerSpliceOp is the number of grams that any splice
+	// or bitwise operation adds to a transaction.
+	MassPerSpliceOp uint64
+
 	// MergeSetSizeLimit is the maximum number of blocks in a block's merge set
 	MergeSetSizeLimit uint64
 
@@ -191,6 +195,18 @@ type Params struct {
 	MergeDepth uint64
 
 	POWScores []uint64
+
+	// IntrospectionActivationDAAScore is the DAA score from which scripts may
+	// use the transaction introspection opcodes
+	IntrospectionActivationDAAScore uint64
+
+	// SpliceOpcodesActivationDAAScore is the DAA score from which scripts may
+	// use the splice and bitwise opcodes
+	SpliceOpcodesActivationDAAScore uint64
+
+	// DevFeeSchedule defines the dev fee that coinbase transactions pay
+	// over time. See ValidateDevFeeSchedule.
+	DevFeeSchedule DevFeeSchedule
 }
 
 // NormalizeRPCServerAddress returns addr with the current network default
@@ -204,11 +220,41 @@ func (p *Params) FinalityDepth() uint64 {
 	return uint64(p.FinalityDuration / p.TargetTimePerBlock)
 }
 
+// ValidateDevFeeSchedule returns an error if the dev fee schedule of the
+// network is invalid, e.g. if one of its addresses isn't encoded with the
+// prefix of the network
+func (p *Params) ValidateDevFeeSchedule() error {
+	return p.DevFeeSchedule.Validate(p.Prefix)
+}
+
 // PruningDepth returns the pruning duration represented in blocks
 func (p *Params) PruningDepth() uint64 {
 	return 2*p.FinalityDepth() + 4*p.MergeSetSizeLimit*uint64(p.K) + 2*uint64(p.K) + 2
 }
 
+// blockGenerationMinPowMax is the lowest PowMax at which blocks are solved
+// instantly. At most 2^16 hashes are expected to be needed to solve a block.
+var blockGenerationMinPowMax = new(big.Int).Lsh(bigOne, 240)
+
+// AllowsBlockGeneration returns whether the node may generate blocks by itself
+// (see the GenerateBlocks RPC). This is only allowed on networks that skip
+// proof of work, and on networks whose difficulty is fixed at a PowMax low
+// enough for blocks to be solved instantly, such as simnet.
+func (p *Params) AllowsBlockGeneration() bool {
+	if p.SkipProofOfWork {
+		return true
+	}
+	return p.DisableDifficultyAdjustment && p.PowMax.Cmp(blockGenerationMinPowMax) >= 0
+}
+
+// AllowsTimeControl returns whether the node's notion of the current time may
+// be changed at runtime (see the SetNodeTime RPC). This is only allowed on
+// simnet, where it is used to test timelocks, mempool expiry and difficulty
+// adjustment without waiting in real time.
+func (p *Params) AllowsTimeControl() bool {
+	return p.Net == appmessage.Simnet
+}
+
 // MainnetParams defines the network parameters for the main Lings network.
 var MainnetParams = Params{
 	K:           defaultGHOSTDAGK,
@@ -266,6 +312,7 @@ var MainnetParams = Params{
 	MassPerTxByte:                           defaultMassPerTxByte,
 	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
 	MassPerSigOp:                            defaultMassPerSigOp,
+	MassPerSpliceOp:                         defaultMassPerSpliceOp,
 	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
 	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
 	PruningProofM:                           defaultPruningProofM,
@@ -277,6 +324,15 @@ var MainnetParams = Params{
 	MaxBlockLevel: 225,
 	MergeDepth:    defaultMergeDepth,
 	POWScores:     []uint64{17500000},
+
+	IntrospectionActivationDAAScore: notScheduledDAAScore,
+	SpliceOpcodesActivationDAAScore: notScheduledDAAScore,
+
+	DevFeeSchedule: DevFeeSchedule{{
+		Address:    "lings:qp4ad2eh72xc8dtjjyz4llxzq9utn6k26uyl644xxw70wskdfl85zsksmwldz",
+		Percent:    defaultDevFeePercent,
+		MinPercent: defaultDevFeeMinPercent,
+	}},
 }
 
 // TestnetParams defines the network parameters for the test Lings network.
@@ -334,6 +390,7 @@ var TestnetParams = Params{
 	MassPerTxByte:                           defaultMassPerTxByte,
 	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
 	MassPerSigOp:                            defaultMassPerSigOp,
+	MassPerSpliceOp:                         defaultMassPerSpliceOp,
 	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
 	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
 	PruningProofM:                           defaultPruningProofM,
@@ -342,6 +399,16 @@ var TestnetParams = Params{
 	MaxBlockLevel: 250,
 	MergeDepth:    defaultMergeDepth,
 	POWScores:     []uint64{5},
+
+	IntrospectionActivationDAAScore: notScheduledDAAScore,
+	SpliceOpcodesActivationDAAScore: notScheduledDAAScore,
+
+	// The testnet pays its dev fee to the same key as the mainnet
+	DevFeeSchedule: DevFeeSchedule{{
+		Address:    "lingstest:qp4ad2eh72xc8dtjjyz4llxzq9utn6k26uyl644xxw70wskdfl85za30u7k3v",
+		Percent:    defaultDevFeePercent,
+		MinPercent: defaultDevFeeMinPercent,
+	}},
 }
 
 // SimnetParams defines the network parameters for the simulation test Lings
@@ -401,6 +468,7 @@ var SimnetParams = Params{
 	MassPerTxByte:                           defaultMassPerTxByte,
 	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
 	MassPerSigOp:                            defaultMassPerSigOp,
+	MassPerSpliceOp:                         defaultMassPerSpliceOp,
 	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
 	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
 	PruningProofM:                           defaultPruningProofM,
@@ -409,6 +477,9 @@ var SimnetParams = Params{
 	MaxBlockLevel: 250,
 	MergeDepth:    defaultMergeDepth,
 	POWScores:     []uint64{5},
+
+	IntrospectionActivationDAAScore: 0,
+	SpliceOpcodesActivationDAAScore: 0,
 }
 
 // DevnetParams defines the network parameters for the development Lings network.
@@ -464,6 +535,7 @@ var DevnetParams = Params{
 	MassPerTxByte:                           defaultMassPerTxByte,
 	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
 	MassPerSigOp:                            defaultMassPerSigOp,
+	MassPerSpliceOp:                         defaultMassPerSpliceOp,
 	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
 	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
 	PruningProofM:                           defaultPruningProofM,
@@ -472,6 +544,9 @@ var DevnetParams = Params{
 	MaxBlockLevel: 250,
 	MergeDepth:    defaultMergeDepth,
 	POWScores:     []uint64{5},
+
+	IntrospectionActivationDAAScore: 0,
+	SpliceOpcodesActivationDAAScore: 0,
 }
 
 // ErrDuplicateNet describes an error where the parameters for a Lings
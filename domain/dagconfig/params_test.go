@@ -5,6 +5,7 @@
 package dagconfig
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
@@ -87,3 +88,101 @@ func TestSkipProofOfWork(t *testing.T) {
 		}
 	}
 }
+
+// TestAllowsBlockGeneration ensures that block generation is only allowed on
+// networks where blocks can be solved instantly.
+func TestAllowsBlockGeneration(t *testing.T) {
+	skipProofOfWork := MainnetParams
+	skipProofOfWork.SkipProofOfWork = true
+
+	highPowMax := SimnetParams
+	highPowMax.PowMax = new(big.Int).Sub(blockGenerationMinPowMax, bigOne)
+
+	tests := []struct {
+		params                        Params
+		expectedAllowsBlockGeneration bool
+	}{
+		{MainnetParams, false},
+		{TestnetParams, false},
+		{SimnetParams, true},
+		{DevnetParams, false},
+		{skipProofOfWork, true},
+		{highPowMax, false},
+	}
+
+	for i, test := range tests {
+		allowsBlockGeneration := test.params.AllowsBlockGeneration()
+		if allowsBlockGeneration != test.expectedAllowsBlockGeneration {
+			t.Errorf("test %d (%s): expected AllowsBlockGeneration to be %t, but got %t",
+				i, test.params.Name, test.expectedAllowsBlockGeneration, allowsBlockGeneration)
+		}
+	}
+}
+
+// TestValidateDevFeeSchedule ensures the hard coded dev fee schedules are valid,
+// and that invalid schedules are rejected.
+func TestValidateDevFeeSchedule(t *testing.T) {
+	allParams := []Params{
+		MainnetParams,
+		TestnetParams,
+		SimnetParams,
+		DevnetParams,
+	}
+
+	for _, params := range allParams {
+		err := params.ValidateDevFeeSchedule()
+		if err != nil {
+			t.Errorf("The dev fee schedule of %s is invalid: %s", params.Name, err)
+		}
+	}
+
+	mainnetAddress := MainnetParams.DevFeeSchedule[0].Address
+	tests := []struct {
+		name     string
+		schedule DevFeeSchedule
+	}{
+		{
+			name:     "address of another network",
+			schedule: DevFeeSchedule{{Address: TestnetParams.DevFeeSchedule[0].Address, Percent: 5}},
+		},
+		{
+			name:     "zero percent",
+			schedule: DevFeeSchedule{{Address: mainnetAddress, Percent: 0}},
+		},
+		{
+			name:     "more than 100 percent",
+			schedule: DevFeeSchedule{{Address: mainnetAddress, Percent: 101}},
+		},
+		{
+			name:     "min percent greater than percent",
+			schedule: DevFeeSchedule{{Address: mainnetAddress, Percent: 5, MinPercent: 6}},
+		},
+		{
+			name: "expiry before activation",
+			schedule: DevFeeSchedule{
+				{Address: mainnetAddress, Percent: 5, ActivationDAAScore: 100, ExpiryDAAScore: 100},
+			},
+		},
+		{
+			name: "overlapping periods",
+			schedule: DevFeeSchedule{
+				{Address: mainnetAddress, Percent: 5, ExpiryDAAScore: 200},
+				{Address: mainnetAddress, Percent: 3, ActivationDAAScore: 100},
+			},
+		},
+		{
+			name: "period after a period that never expires",
+			schedule: DevFeeSchedule{
+				{Address: mainnetAddress, Percent: 5},
+				{Address: mainnetAddress, Percent: 3, ActivationDAAScore: 100},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		err := test.schedule.Validate(MainnetParams.Prefix)
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
@@ -26,6 +26,7 @@ type Domain interface {
 	CommitStagingConsensus() error
 	DeleteStagingConsensus() error
 	ConsensusEventsChannel() chan externalapi.ConsensusEvent
+	CheckConsistency(depth uint64, repair bool) error
 }
 
 type domain struct {
@@ -16,7 +16,10 @@ import (
 	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
 	"github.com/ammm56/lings/domain/consensus/utils/testutils"
 	"github.com/ammm56/lings/domain/miningmanager/mempool"
+	"github.com/ammm56/lings/domain/prefixmanager"
+	"github.com/ammm56/lings/infrastructure/db/database"
 	"github.com/ammm56/lings/infrastructure/db/database/ldb"
+	"github.com/pkg/errors"
 )
 
 func TestCreateStagingConsensus(t *testing.T) {
@@ -155,3 +158,103 @@ func TestCreateStagingConsensus(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckConsistency(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		dataDir, err := ioutil.TempDir("", fmt.Sprintf("TestCheckConsistency-%s", consensusConfig.Name))
+		if err != nil {
+			t.Fatalf("ioutil.TempDir: %+v", err)
+		}
+		defer os.RemoveAll(dataDir)
+
+		db, err := ldb.NewLevelDB(dataDir, 8)
+		if err != nil {
+			t.Fatalf("NewLevelDB: %+v", err)
+		}
+		defer db.Close()
+
+		domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), db)
+		if err != nil {
+			t.Fatalf("New: %+v", err)
+		}
+
+		coinbaseData := &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{},
+			ExtraData:       []byte{},
+		}
+		blockHashes := make([]*externalapi.DomainHash, 0, 3)
+		for i := 0; i < 3; i++ {
+			block, err := domainInstance.Consensus().BuildBlock(coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			err = domainInstance.Consensus().ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+			blockHashes = append(blockHashes, consensushashing.BlockHash(block))
+		}
+
+		err = domainInstance.CheckConsistency(100, false)
+		if err != nil {
+			t.Fatalf("CheckConsistency: %+v", err)
+		}
+		virtualSelectedParent, err := domainInstance.Consensus().GetVirtualSelectedParent()
+		if err != nil {
+			t.Fatalf("GetVirtualSelectedParent: %+v", err)
+		}
+		if !virtualSelectedParent.Equal(blockHashes[2]) {
+			t.Fatalf("a consistent consensus was changed by CheckConsistency")
+		}
+
+		// Delete the body of the virtual selected parent behind the back of the
+		// consensus, as if the write was lost in a crash
+		activePrefix, _, err := prefixmanager.ActivePrefix(db)
+		if err != nil {
+			t.Fatalf("ActivePrefix: %+v", err)
+		}
+		blockKey := database.MakeBucket(activePrefix.Serialize()).Bucket([]byte("blocks")).Key(blockHashes[2].ByteSlice())
+		err = db.Delete(blockKey)
+		if err != nil {
+			t.Fatalf("Delete: %+v", err)
+		}
+
+		domainInstance, err = domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), db)
+		if err != nil {
+			t.Fatalf("New: %+v", err)
+		}
+		// Without repair, the inconsistency is only reported
+		err = domainInstance.CheckConsistency(100, false)
+		if !errors.Is(err, domain.ErrInconsistentConsensus) {
+			t.Fatalf("expected CheckConsistency to return ErrInconsistentConsensus, but got: %+v", err)
+		}
+		virtualSelectedParent, err = domainInstance.Consensus().GetVirtualSelectedParent()
+		if err != nil {
+			t.Fatalf("GetVirtualSelectedParent: %+v", err)
+		}
+		if !virtualSelectedParent.Equal(blockHashes[2]) {
+			t.Fatalf("CheckConsistency changed the consensus although it wasn't allowed to repair it")
+		}
+
+		err = domainInstance.CheckConsistency(100, true)
+		if err != nil {
+			t.Fatalf("CheckConsistency: %+v", err)
+		}
+
+		virtualSelectedParent, err = domainInstance.Consensus().GetVirtualSelectedParent()
+		if err != nil {
+			t.Fatalf("GetVirtualSelectedParent: %+v", err)
+		}
+		if !virtualSelectedParent.Equal(blockHashes[1]) {
+			t.Fatalf("expected the virtual selected parent to be %s after the repair, but got %s",
+				blockHashes[1], virtualSelectedParent)
+		}
+		blockInfo, err := domainInstance.Consensus().GetBlockInfo(blockHashes[2])
+		if err != nil {
+			t.Fatalf("GetBlockInfo: %+v", err)
+		}
+		if blockInfo.Exists {
+			t.Fatalf("the block with the missing body was not removed by the repair")
+		}
+	})
+}
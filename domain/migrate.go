@@ -26,7 +26,7 @@ func (d *domain) migrate() error {
 			return err
 		}
 
-		err = syncConsensuses(d.Consensus(), d.StagingConsensus())
+		err = syncConsensuses(d.Consensus(), d.StagingConsensus(), false)
 		if err != nil {
 			return err
 		}
@@ -41,7 +41,12 @@ func (d *domain) migrate() error {
 	return nil
 }
 
-func syncConsensuses(syncer, syncee externalapi.Consensus) error {
+// syncConsensuses copies the pruning point, its anticone, the blocks above it
+// and the pruning point UTXO set from syncer into syncee, which must be empty.
+// If skipUnavailableBlocks is set, blocks above the pruning point that can't
+// be read from syncer or inserted into syncee are skipped rather than failing
+// the sync.
+func syncConsensuses(syncer, syncee externalapi.Consensus, skipUnavailableBlocks bool) error {
 	pruningPointProof, err := syncer.BuildPruningPointProof()
 	if err != nil {
 		return err
@@ -118,12 +123,62 @@ func syncConsensuses(syncer, syncee externalapi.Consensus) error {
 		}
 	}
 
-	syncerVirtualSelectedParent, err := syncer.GetVirtualSelectedParent()
+	pruningPoint, err := syncer.PruningPoint()
 	if err != nil {
 		return err
 	}
 
-	pruningPoint, err := syncer.PruningPoint()
+	err = syncBlocks(syncer, syncee, pruningPoint, skipUnavailableBlocks)
+	if err != nil {
+		return err
+	}
+
+	var fromOutpoint *externalapi.DomainOutpoint
+	const step = 100_000
+	for {
+		outpointAndUTXOEntryPairs, err := syncer.GetPruningPointUTXOs(pruningPoint, fromOutpoint, step)
+		if err != nil {
+			return err
+		}
+		fromOutpoint = outpointAndUTXOEntryPairs[len(outpointAndUTXOEntryPairs)-1].Outpoint
+		err = syncee.AppendImportedPruningPointUTXOs(outpointAndUTXOEntryPairs)
+		if err != nil {
+			return err
+		}
+		if len(outpointAndUTXOEntryPairs) < step {
+			break
+		}
+	}
+
+	// Check that ValidateAndInsertImportedPruningPoint works given the right arguments.
+	err = syncee.ValidateAndInsertImportedPruningPoint(pruningPoint)
+	if err != nil {
+		return err
+	}
+
+	emptyCoinbase := &externalapi.DomainCoinbaseData{
+		ScriptPublicKey: &externalapi.ScriptPublicKey{
+			Script:  nil,
+			Version: 0,
+		},
+	}
+
+	// Check that we can build a block just after importing the pruning point.
+	_, err = syncee.BuildBlock(emptyCoinbase, nil)
+	if err != nil {
+		return err
+	}
+
+	return resolveVirtual(syncer, syncee)
+}
+
+// syncBlocks copies the blocks between the pruning point and the virtual
+// selected parent of syncer, as well as the anticones of its tips, into syncee
+// without updating the virtual of syncee
+func syncBlocks(syncer, syncee externalapi.Consensus, pruningPoint *externalapi.DomainHash,
+	skipUnavailableBlocks bool) error {
+
+	syncerVirtualSelectedParent, err := syncer.GetVirtualSelectedParent()
 	if err != nil {
 		return err
 	}
@@ -152,6 +207,7 @@ func syncConsensuses(syncer, syncee externalapi.Consensus) error {
 	}
 
 	percents := 0
+	skippedBlocks := 0
 	for i, blocksHash := range missingBlocks {
 		blockInfo, err := syncee.GetBlockInfo(blocksHash)
 		if err != nil {
@@ -162,17 +218,14 @@ func syncConsensuses(syncer, syncee externalapi.Consensus) error {
 			continue
 		}
 
-		block, found, err := syncer.GetBlock(blocksHash)
+		err = syncBlock(syncer, syncee, blocksHash)
 		if err != nil {
-			return err
-		}
-
-		if !found {
-			return errors.Errorf("block %s is missing", blocksHash)
-		}
-		err = syncee.ValidateAndInsertBlock(block, false)
-		if err != nil {
-			return err
+			if !skipUnavailableBlocks {
+				return err
+			}
+			log.Debugf("Skipping block %s: %s", blocksHash, err)
+			skippedBlocks++
+			continue
 		}
 
 		newPercents := 100 * i / len(missingBlocks)
@@ -181,49 +234,33 @@ func syncConsensuses(syncer, syncee externalapi.Consensus) error {
 			log.Infof("Processed %d%% of the blocks", 100*i/len(missingBlocks))
 		}
 	}
-
-	var fromOutpoint *externalapi.DomainOutpoint
-	const step = 100_000
-	for {
-		outpointAndUTXOEntryPairs, err := syncer.GetPruningPointUTXOs(pruningPoint, fromOutpoint, step)
-		if err != nil {
-			return err
-		}
-		fromOutpoint = outpointAndUTXOEntryPairs[len(outpointAndUTXOEntryPairs)-1].Outpoint
-		err = syncee.AppendImportedPruningPointUTXOs(outpointAndUTXOEntryPairs)
-		if err != nil {
-			return err
-		}
-		if len(outpointAndUTXOEntryPairs) < step {
-			break
-		}
+	if skippedBlocks > 0 {
+		log.Warnf("Skipped %d blocks that are missing or invalid. They will be downloaded again from peers",
+			skippedBlocks)
 	}
+	return nil
+}
 
-	// Check that ValidateAndInsertImportedPruningPoint works given the right arguments.
-	err = syncee.ValidateAndInsertImportedPruningPoint(pruningPoint)
+func syncBlock(syncer, syncee externalapi.Consensus, blockHash *externalapi.DomainHash) error {
+	block, found, err := syncer.GetBlock(blockHash)
 	if err != nil {
 		return err
 	}
 
-	emptyCoinbase := &externalapi.DomainCoinbaseData{
-		ScriptPublicKey: &externalapi.ScriptPublicKey{
-			Script:  nil,
-			Version: 0,
-		},
-	}
-
-	// Check that we can build a block just after importing the pruning point.
-	_, err = syncee.BuildBlock(emptyCoinbase, nil)
-	if err != nil {
-		return err
+	if !found {
+		return errors.Errorf("block %s is missing", blockHash)
 	}
+	return syncee.ValidateAndInsertBlock(block, false)
+}
 
+func resolveVirtual(syncer, syncee externalapi.Consensus) error {
 	estimatedVirtualDAAScoreTarget, err := syncer.GetVirtualDAAScore()
 	if err != nil {
 		return err
 	}
 
-	err = syncer.ResolveVirtual(func(virtualDAAScoreStart uint64, virtualDAAScore uint64) {
+	err = syncee.ResolveVirtual(func(virtualDAAScoreStart uint64, virtualDAAScore uint64) {
+		var percents int
 		if estimatedVirtualDAAScoreTarget-virtualDAAScoreStart <= 0 {
 			percents = 100
 		} else {
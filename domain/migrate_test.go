@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/testutils"
+	"github.com/ammm56/lings/domain/miningmanager/mempool"
+	"github.com/ammm56/lings/infrastructure/db/database/ldb"
+)
+
+// TestResolveVirtual checks that resolveVirtual resolves the virtual of the
+// consensus that the blocks were synced into, since syncBlocks inserts them
+// without updating its virtual
+func TestResolveVirtual(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		db, err := ldb.NewLevelDB(t.TempDir(), 8)
+		if err != nil {
+			t.Fatalf("NewLevelDB: %+v", err)
+		}
+		defer db.Close()
+
+		domainInstance, err := New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), db)
+		if err != nil {
+			t.Fatalf("New: %+v", err)
+		}
+		d := domainInstance.(*domain)
+
+		coinbaseData := &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{},
+			ExtraData:       []byte{},
+		}
+		for i := 0; i < 3; i++ {
+			block, err := d.Consensus().BuildBlock(coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			err = d.Consensus().ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+		}
+		syncerVirtualSelectedParent, err := d.Consensus().GetVirtualSelectedParent()
+		if err != nil {
+			t.Fatalf("GetVirtualSelectedParent: %+v", err)
+		}
+
+		err = d.initStagingConsensus(d.consensusConfig)
+		if err != nil {
+			t.Fatalf("initStagingConsensus: %+v", err)
+		}
+		err = syncBlocks(d.Consensus(), d.StagingConsensus(), consensusConfig.GenesisHash, false)
+		if err != nil {
+			t.Fatalf("syncBlocks: %+v", err)
+		}
+		err = resolveVirtual(d.Consensus(), d.StagingConsensus())
+		if err != nil {
+			t.Fatalf("resolveVirtual: %+v", err)
+		}
+
+		synceeVirtualSelectedParent, err := d.StagingConsensus().GetVirtualSelectedParent()
+		if err != nil {
+			t.Fatalf("GetVirtualSelectedParent: %+v", err)
+		}
+		if !synceeVirtualSelectedParent.Equal(syncerVirtualSelectedParent) {
+			t.Fatalf("expected the virtual selected parent of the synced consensus to be %s, but got %s",
+				syncerVirtualSelectedParent, synceeVirtualSelectedParent)
+		}
+	})
+}
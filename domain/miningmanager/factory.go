@@ -2,12 +2,12 @@ package miningmanager
 
 import (
 	"sync"
-	"time"
 
 	"github.com/ammm56/lings/domain/consensusreference"
 	"github.com/ammm56/lings/domain/dagconfig"
 	"github.com/ammm56/lings/domain/miningmanager/blocktemplatebuilder"
 	mempoolpkg "github.com/ammm56/lings/domain/miningmanager/mempool"
+	"github.com/ammm56/lings/util/mstime"
 )
 
 // Factory instantiates new mining managers
@@ -22,14 +22,16 @@ func (f *factory) NewMiningManager(consensusReference consensusreference.Consens
 	mempoolConfig *mempoolpkg.Config) MiningManager {
 
 	mempool := mempoolpkg.New(mempoolConfig, consensusReference)
-	blockTemplateBuilder := blocktemplatebuilder.New(consensusReference, mempool, params.MaxBlockMass, params.CoinbasePayloadScriptPublicKeyMaxLength)
+	blockTemplateBuilder := blocktemplatebuilder.New(consensusReference, mempool, params.MaxBlockMass,
+		params.CoinbasePayloadScriptPublicKeyMaxLength, mempoolConfig.Clock)
 
 	return &miningManager{
 		consensusReference:   consensusReference,
 		mempool:              mempool,
 		blockTemplateBuilder: blockTemplateBuilder,
-		cachingTime:          time.Time{},
+		cachingTime:          mstime.Time{},
 		cacheLock:            &sync.Mutex{},
+		clock:                mempoolConfig.Clock,
 	}
 }
 
@@ -17,6 +17,11 @@ const (
 	// that are considered standard in a pay-to-script-hash script.
 	maxStandardP2SHSigOps = 15
 
+	// maxStandardMultiSigKeys is the maximum number of public keys allowed
+	// in a multisig script public key for it to be considered standard.
+	// Larger multisig scripts can be used with pay-to-script-hash.
+	maxStandardMultiSigKeys = 3
+
 	// maximumStandardSignatureScriptSize is the maximum size allowed for a
 	// transaction input signature script to be considered standard. This
 	// value allows for a 15-of-15 CHECKMULTISIG pay-to-script-hash with
@@ -86,6 +91,17 @@ func (mp *mempool) checkTransactionStandardInIsolation(transaction *externalapi.
 			str := fmt.Sprintf("transaction output %d: non-standard script form", i)
 			return transactionRuleError(RejectNonstandard, str)
 		}
+		if scriptClass == txscript.MultiSigTy {
+			multiSig, err := txscript.ExtractMultiSigDataPushes(output.ScriptPublicKey.Script)
+			if err != nil {
+				return err
+			}
+			if len(multiSig.PubKeys) > maxStandardMultiSigKeys {
+				str := fmt.Sprintf("transaction output %d: multisig script with %d public keys is "+
+					"larger than the max allowed %d public keys", i, len(multiSig.PubKeys), maxStandardMultiSigKeys)
+				return transactionRuleError(RejectNonstandard, str)
+			}
+		}
 
 		if mp.IsTransactionOutputDust(output) {
 			str := fmt.Sprintf("transaction output %d: payment "+
@@ -147,7 +163,8 @@ func (mp *mempool) IsTransactionOutputDust(output *externalapi.DomainTransaction
 // checkTransactionStandardInContext performs a series of checks on a transaction's
 // inputs to ensure they are "standard". A standard transaction input within the
 // context of this function is one whose referenced public key script is of a
-// standard form and, for pay-to-script-hash, does not have more than
+// standard form, which includes the multisig, atomic swap, time-locked and
+// refund templates, and, for pay-to-script-hash, does not have more than
 // maxStandardP2SHSigOps signature operations.
 // In addition, makes sure that the transaction's fee is above the minimum for acceptance
 // into the mempool and relay
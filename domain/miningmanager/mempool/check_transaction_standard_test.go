@@ -218,6 +218,17 @@ func TestCheckTransactionStandardInIsolation(t *testing.T) {
 		Value:           100000000, // 1 LSN
 		ScriptPublicKey: dummyScriptPublicKey,
 	}
+	multiSigScript := func(numPubKeys int) *externalapi.ScriptPublicKey {
+		pubKeys := make([][]byte, numPubKeys)
+		for i := range pubKeys {
+			pubKeys[i] = bytes.Repeat([]byte{byte(i + 1)}, 32)
+		}
+		script, err := txscript.MultiSigScript(pubKeys, 2)
+		if err != nil {
+			t.Fatalf("MultiSigScript: unexpected error: %v", err)
+		}
+		return &externalapi.ScriptPublicKey{Script: script, Version: 0}
+	}
 
 	tests := []struct {
 		name       string
@@ -271,6 +282,25 @@ func TestCheckTransactionStandardInIsolation(t *testing.T) {
 			isStandard: false,
 			code:       RejectNonstandard,
 		},
+		{
+			name: "Bare multisig with the max standard number of public keys",
+			tx: &externalapi.DomainTransaction{Version: 0, Inputs: []*externalapi.DomainTransactionInput{&dummyTxIn}, Outputs: []*externalapi.DomainTransactionOutput{{
+				Value:           100000000,
+				ScriptPublicKey: multiSigScript(maxStandardMultiSigKeys),
+			}}},
+			height:     300000,
+			isStandard: true,
+		},
+		{
+			name: "Bare multisig with too many public keys",
+			tx: &externalapi.DomainTransaction{Version: 0, Inputs: []*externalapi.DomainTransactionInput{&dummyTxIn}, Outputs: []*externalapi.DomainTransactionOutput{{
+				Value:           100000000,
+				ScriptPublicKey: multiSigScript(maxStandardMultiSigKeys + 1),
+			}}},
+			height:     300000,
+			isStandard: false,
+			code:       RejectNonstandard,
+		},
 		{ //Todo : check on ScriptPublicKey type.
 			name: "Dust output",
 			tx: &externalapi.DomainTransaction{Version: 0, Inputs: []*externalapi.DomainTransactionInput{&dummyTxIn}, Outputs: []*externalapi.DomainTransactionOutput{{
@@ -6,6 +6,7 @@ import (
 	"github.com/ammm56/lings/domain/consensus/utils/constants"
 
 	"github.com/ammm56/lings/util"
+	"github.com/ammm56/lings/util/mstime"
 
 	"github.com/ammm56/lings/domain/dagconfig"
 )
@@ -50,6 +51,9 @@ type Config struct {
 	MinimumRelayTransactionFee            util.Amount
 	MinimumStandardTransactionVersion     uint16
 	MaximumStandardTransactionVersion     uint16
+	// Clock is the source of the current time for the mempool and for the
+	// block templates built from it
+	Clock mstime.Clock
 }
 
 // DefaultConfig returns the default mempool configuration
@@ -70,5 +74,6 @@ func DefaultConfig(dagParams *dagconfig.Params) *Config {
 		MinimumRelayTransactionFee:            defaultMinimumRelayTransactionFee,
 		MinimumStandardTransactionVersion:     defaultMinimumStandardTransactionVersion,
 		MaximumStandardTransactionVersion:     defaultMaximumStandardTransactionVersion,
+		Clock:                                 mstime.SystemClock,
 	}
 }
@@ -48,6 +48,13 @@ func (mp *mempool) ValidateAndInsertTransaction(transaction *externalapi.DomainT
 	return mp.validateAndInsertTransaction(transaction, isHighPriority, allowOrphan)
 }
 
+func (mp *mempool) ValidateTransaction(transaction *externalapi.DomainTransaction) error {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	return mp.validateTransaction(transaction)
+}
+
 func (mp *mempool) GetTransaction(transactionID *externalapi.DomainTransactionID,
 	includeTransactionPool bool,
 	includeOrphanPool bool) (
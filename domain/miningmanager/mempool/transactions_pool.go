@@ -1,13 +1,12 @@
 package mempool
 
 import (
-	"time"
-
 	"github.com/pkg/errors"
 
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
 	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
 	"github.com/ammm56/lings/domain/miningmanager/mempool/model"
+	"github.com/ammm56/lings/util/mstime"
 )
 
 type transactionsPool struct {
@@ -17,7 +16,7 @@ type transactionsPool struct {
 	chainedTransactionsByParentID model.IDToTransactionsSliceMap
 	transactionsOrderedByFeeRate  model.TransactionsOrderedByFeeRate
 	lastExpireScanDAAScore        uint64
-	lastExpireScanTime            time.Time
+	lastExpireScanTime            mstime.Time
 }
 
 func newTransactionsPool(mp *mempool) *transactionsPool {
@@ -28,7 +27,7 @@ func newTransactionsPool(mp *mempool) *transactionsPool {
 		chainedTransactionsByParentID: model.IDToTransactionsSliceMap{},
 		transactionsOrderedByFeeRate:  model.TransactionsOrderedByFeeRate{},
 		lastExpireScanDAAScore:        0,
-		lastExpireScanTime:            time.Now(),
+		lastExpireScanTime:            mp.config.Clock.Now(),
 	}
 }
 
@@ -104,7 +103,7 @@ func (tp *transactionsPool) expireOldTransactions() error {
 	}
 
 	if virtualDAAScore-tp.lastExpireScanDAAScore < tp.mempool.config.TransactionExpireScanIntervalDAAScore ||
-		time.Since(tp.lastExpireScanTime).Seconds() < float64(tp.mempool.config.TransactionExpireScanIntervalSeconds) {
+		tp.mempool.config.Clock.Now().Sub(tp.lastExpireScanTime).Seconds() < float64(tp.mempool.config.TransactionExpireScanIntervalSeconds) {
 		return nil
 	}
 
@@ -127,7 +126,7 @@ func (tp *transactionsPool) expireOldTransactions() error {
 	}
 
 	tp.lastExpireScanDAAScore = virtualDAAScore
-	tp.lastExpireScanTime = time.Now()
+	tp.lastExpireScanTime = tp.mempool.config.Clock.Now()
 	return nil
 }
 
@@ -9,6 +9,29 @@ import (
 	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
 )
 
+// validateTransaction validates the given transaction the same way
+// validateAndInsertTransaction does, without inserting it into the mempool.
+// Orphans are rejected.
+func (mp *mempool) validateTransaction(transaction *externalapi.DomainTransaction) error {
+	mp.consensusReference.Consensus().PopulateMass(transaction)
+
+	err := mp.validateTransactionPreUTXOEntry(transaction)
+	if err != nil {
+		return err
+	}
+
+	_, missingOutpoints, err := mp.fillInputsAndGetMissingParents(transaction)
+	if err != nil {
+		return err
+	}
+	if len(missingOutpoints) > 0 {
+		str := fmt.Sprintf("Transaction %s is an orphan", consensushashing.TransactionID(transaction))
+		return transactionRuleError(RejectBadOrphan, str)
+	}
+
+	return mp.validateTransactionInContext(transaction)
+}
+
 func (mp *mempool) validateTransactionPreUTXOEntry(transaction *externalapi.DomainTransaction) error {
 	err := mp.validateTransactionInIsolation(transaction)
 	if err != nil {
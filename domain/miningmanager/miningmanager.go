@@ -7,12 +7,15 @@ import (
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
 	"github.com/ammm56/lings/domain/consensusreference"
 	miningmanagermodel "github.com/ammm56/lings/domain/miningmanager/model"
+	"github.com/ammm56/lings/util/mstime"
 )
 
 // MiningManager creates block templates for mining as well as maintaining
 // known transactions that have no yet been added to any block
 type MiningManager interface {
 	GetBlockTemplate(coinbaseData *externalapi.DomainCoinbaseData) (block *externalapi.DomainBlock, isNearlySynced bool, err error)
+	GetBlockTemplateOnParents(parentHashes []*externalapi.DomainHash, coinbaseData *externalapi.DomainCoinbaseData) (
+		block *externalapi.DomainBlock, isNearlySynced bool, err error)
 	ClearBlockTemplate()
 	GetBlockTemplateBuilder() miningmanagermodel.BlockTemplateBuilder
 	GetTransaction(transactionID *externalapi.DomainTransactionID, includeTransactionPool bool, includeOrphanPool bool) (
@@ -32,6 +35,7 @@ type MiningManager interface {
 	HandleNewBlockTransactions(txs []*externalapi.DomainTransaction) ([]*externalapi.DomainTransaction, error)
 	ValidateAndInsertTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool, allowOrphan bool) (
 		acceptedTransactions []*externalapi.DomainTransaction, err error)
+	ValidateTransaction(transaction *externalapi.DomainTransaction) error
 	RevalidateHighPriorityTransactions() (validTransactions []*externalapi.DomainTransaction, err error)
 }
 
@@ -40,8 +44,9 @@ type miningManager struct {
 	mempool              miningmanagermodel.Mempool
 	blockTemplateBuilder miningmanagermodel.BlockTemplateBuilder
 	cachedBlockTemplate  *externalapi.DomainBlockTemplate
-	cachingTime          time.Time
+	cachingTime          mstime.Time
 	cacheLock            *sync.Mutex
+	clock                mstime.Clock
 }
 
 // GetBlockTemplate obtains a block template for a miner to consume
@@ -76,15 +81,28 @@ func (mm *miningManager) GetBlockTemplate(coinbaseData *externalapi.DomainCoinba
 	return blockTemplate.Block, blockTemplate.IsNearlySynced, nil
 }
 
+// GetBlockTemplateOnParents builds a block template with the given parents
+// instead of the virtual's parents. Such templates don't include any mempool
+// transactions, and aren't cached.
+func (mm *miningManager) GetBlockTemplateOnParents(parentHashes []*externalapi.DomainHash,
+	coinbaseData *externalapi.DomainCoinbaseData) (block *externalapi.DomainBlock, isNearlySynced bool, err error) {
+
+	blockTemplate, err := mm.consensusReference.Consensus().BuildBlockTemplateOnParents(parentHashes, coinbaseData)
+	if err != nil {
+		return nil, false, err
+	}
+	return blockTemplate.Block, blockTemplate.IsNearlySynced, nil
+}
+
 func (mm *miningManager) ClearBlockTemplate() {
 	mm.cacheLock.Lock()
-	mm.cachingTime = time.Time{}
+	mm.cachingTime = mstime.Time{}
 	mm.cachedBlockTemplate = nil
 	mm.cacheLock.Unlock()
 }
 
 func (mm *miningManager) getImmutableCachedTemplate() *externalapi.DomainBlockTemplate {
-	if time.Since(mm.cachingTime) > time.Second {
+	if mm.clock.Now().Sub(mm.cachingTime) > time.Second {
 		// No point in cache optimizations if queries are more than a second apart -- we prefer rechecking the mempool.
 		// Full explanation: On the one hand this is a sub-millisecond optimization, so there is no harm in doing the full block building
 		// every ~1 second. Additionally, we would like to refresh the mempool access even if virtual info was
@@ -95,7 +113,7 @@ func (mm *miningManager) getImmutableCachedTemplate() *externalapi.DomainBlockTe
 }
 
 func (mm *miningManager) setImmutableCachedTemplate(blockTemplate *externalapi.DomainBlockTemplate) {
-	mm.cachingTime = time.Now()
+	mm.cachingTime = mm.clock.Now()
 	mm.cachedBlockTemplate = blockTemplate
 }
 
@@ -117,6 +135,14 @@ func (mm *miningManager) ValidateAndInsertTransaction(transaction *externalapi.D
 	return mm.mempool.ValidateAndInsertTransaction(transaction, isHighPriority, allowOrphan)
 }
 
+// ValidateTransaction validates the given transaction the same way
+// ValidateAndInsertTransaction does, without adding it to the set of
+// known transactions. Transactions that spend outputs that are neither
+// in the UTXO set nor in the mempool are rejected.
+func (mm *miningManager) ValidateTransaction(transaction *externalapi.DomainTransaction) error {
+	return mm.mempool.ValidateTransaction(transaction)
+}
+
 func (mm *miningManager) GetTransaction(
 	transactionID *externalapi.DomainTransactionID,
 	includeTransactionPool bool,
@@ -38,6 +38,9 @@ func New(domain domain.Domain, database database.Database) (*UTXOIndex, error) {
 	}
 
 	if !isSynced || !hasCirculatingSupplyKey {
+		if !isSynced {
+			log.Infof("The virtual parents of the UTXO index don't match the consensus")
+		}
 
 		err := utxoIndex.Reset()
 		if err != nil {
@@ -53,9 +53,28 @@ const (
 	defaultSigCacheMaxSize  = 100_000
 	sampleConfigFilename    = "sample-lings.conf"
 	defaultMaxUTXOCacheSize = 5_000_000_000
-	defaultProtocolVersion  = 5
+	defaultProtocolVersion  = 6
+	defaultDbType           = DbTypeLevelDB
+
+	defaultLevelDBCompression  = "none"
+	defaultLevelDBTableSizeMiB = 2
+	maxLevelDBBloomFilterBits  = 32
+)
+
+// Supported values of the dbtype option
+const (
+	// DbTypeLevelDB stores the node's data in LevelDB
+	DbTypeLevelDB = "leveldb"
+
+	// DbTypeLogDB stores the node's data in an append-only data log with
+	// an in-memory index
+	DbTypeLogDB = "logdb"
 )
 
+var knownDbTypes = []string{DbTypeLevelDB, DbTypeLogDB}
+
+var knownLevelDBCompressions = []string{"none", "snappy"}
+
 var (
 	// DefaultAppDir is the default home directory for lings.
 	DefaultAppDir = util.AppDir("lings", false)
@@ -106,7 +125,15 @@ type Flags struct {
 	Proxy                           string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	ProxyUser                       string        `long:"proxyuser" description:"Username for proxy server"`
 	ProxyPass                       string        `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
-	DbType                          string        `long:"dbtype" description:"Database backend to use for the Block DAG"`
+	DbType                          string        `long:"dbtype" description:"Database backend to use for the Block DAG {leveldb, logdb}"`
+	LevelDBCompression              string        `long:"ldb-compression" description:"Compression of new LevelDB table files {none, snappy}"`
+	LevelDBWriteBufferMiB           int           `long:"ldb-writebuffer" description:"Size of the LevelDB write buffer in MiB -- Defaults to half of the LevelDB cache size"`
+	LevelDBTableSizeMiB             int           `long:"ldb-tablesize" description:"Size of the LevelDB table files written by compactions in MiB"`
+	LevelDBBloomFilterBits          int           `long:"ldb-bloombits" description:"Bits per key of the LevelDB bloom filter -- 0 disables the filter"`
+	LevelDBSync                     bool          `long:"ldb-sync" description:"Allow LevelDB to fsync its files, which is also required to sync writes with the SetDatabaseOptions RPC"`
+	DurableWrites                   bool          `long:"durable-writes" description:"Sync every database write to disk before it completes, so that a crash of the host can't leave the database inconsistent -- Implies ldb-sync"`
+	ConsistencyCheckDepth           uint64        `long:"consistency-check-depth" description:"Number of selected chain blocks below the virtual whose data is verified on startup -- Disabled by default"`
+	RepairConsensus                 bool          `long:"repair-consensus" description:"Rebuild the consensus from its intact data if the consistency check finds it inconsistent, or reset it to genesis if it can't be rebuilt -- Requires consistency-check-depth"`
 	Profile                         string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	LogLevel                        string        `short:"d" long:"loglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
 	Upnp                            bool          `long:"upnp" description:"Use UPnP to map our listening port outside of NAT"`
@@ -121,11 +148,15 @@ type Flags struct {
 	RejectNonStd                    bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
 	ResetDatabase                   bool          `long:"reset-db" description:"Reset database before starting node. It's needed when switching between subnetworks."`
 	MaxUTXOCacheSize                uint64        `long:"maxutxocachesize" description:"Max size of loaded UTXO into ram from the disk in bytes"`
+	MemoryBudgetMiB                 uint64        `long:"memory-budget" description:"Memory in MiB that is shared by the caches of the consensus stores, in proportion to how much their hits save -- 0 sizes the caches by the network parameters"`
 	UTXOIndex                       bool          `long:"utxoindex" description:"Enable the UTXO index"`
 	IsArchivalNode                  bool          `long:"archival" description:"Run as an archival node: don't delete old block data when moving the pruning point (Warning: heavy disk usage)'"`
 	AllowSubmitBlockWhenNotSynced   bool          `long:"allow-submit-block-when-not-synced" hidden:"true" description:"Allow the node to accept blocks from RPC while not synced (this flag is mainly used for testing)"`
 	EnableSanityCheckPruningUTXOSet bool          `long:"enable-sanity-check-pruning-utxo" hidden:"true" description:"When moving the pruning point - check that the utxo set matches the utxo commitment"`
 	ProtocolVersion                 uint32        `long:"protocol-version" description:"Use non default p2p protocol version"`
+	StemRelay                       bool          `long:"stemrelay" description:"Relay transactions through a single peer for a random number of hops before broadcasting them, in order to hide their origin"`
+	ImportSnapshot                  string        `long:"import-snapshot" description:"Bootstrap an empty node from a snapshot file created by the export-snapshot command before syncing with the network"`
+	RestoreBackup                   string        `long:"restore-backup" description:"Verify the integrity of a database backup created by the BackupDatabase RPC and replace the database with it before starting. Ignored once the database was restored from the backup"`
 	NetworkFlags
 	ServiceOptions *ServiceOptions
 }
@@ -141,6 +172,11 @@ type Config struct {
 	MinRelayTxFee util.Amount
 	Whitelists    []*net.IPNet
 	SubnetworkID  *externalapi.DomainSubnetworkID // nil in full nodes
+
+	// Command holds the positional command line arguments, if any. These
+	// select a one-shot command, such as export-snapshot, that runs instead
+	// of the node.
+	Command []string
 }
 
 // ServiceOptions defines the configuration options for the daemon as a service on
@@ -163,6 +199,16 @@ func cleanAndExpandPath(path string) string {
 	return filepath.Clean(os.ExpandEnv(path))
 }
 
+// isOneOf returns whether or not value is one of the given known values.
+func isOneOf(value string, knownValues []string) bool {
+	for _, knownValue := range knownValues {
+		if value == knownValue {
+			return true
+		}
+	}
+	return false
+}
+
 // newConfigParser returns a new command line flags parser.
 func newConfigParser(cfgFlags *Flags, options flags.Options) *flags.Parser {
 	parser := flags.NewParser(cfgFlags, options)
@@ -190,6 +236,9 @@ func defaultFlags() *Flags {
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
 		MinRelayTxFee:        defaultMinRelayTxFee,
+		DbType:               defaultDbType,
+		LevelDBCompression:   defaultLevelDBCompression,
+		LevelDBTableSizeMiB:  defaultLevelDBTableSizeMiB,
 		MaxUTXOCacheSize:     defaultMaxUTXOCacheSize,
 		ServiceOptions:       &ServiceOptions{},
 		ProtocolVersion:      defaultProtocolVersion,
@@ -266,7 +315,7 @@ func LoadConfig() (*Config, error) {
 	}
 
 	// Parse command line options again to ensure they take precedence.
-	_, err = parser.Parse()
+	cfg.Command, err = parser.Parse()
 	if err != nil {
 		var flagsErr *flags.Error
 		if ok := errors.As(err, &flagsErr); !ok || flagsErr.Type != flags.ErrHelp {
@@ -318,8 +367,59 @@ func LoadConfig() (*Config, error) {
 	case cfg.RelayNonStd:
 		relayNonStd = true
 	}
+
+	if cfg.StemRelay && cfg.BlocksOnly {
+		str := "%s: stemrelay and blocksonly cannot be used together"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
 	cfg.RelayNonStd = relayNonStd
 
+	if cfg.RepairConsensus && cfg.ConsistencyCheckDepth == 0 {
+		str := "%s: repair-consensus requires consistency-check-depth"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// Validate the database type.
+	if !isOneOf(cfg.DbType, knownDbTypes) {
+		str := "%s: The specified database type [%s] is invalid -- " +
+			"supported types %s"
+		err := errors.Errorf(str, funcName, cfg.DbType, knownDbTypes)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// Validate the LevelDB options.
+	if !isOneOf(cfg.LevelDBCompression, knownLevelDBCompressions) {
+		str := "%s: The specified LevelDB compression [%s] is invalid -- " +
+			"supported compressions %s"
+		err := errors.Errorf(str, funcName, cfg.LevelDBCompression, knownLevelDBCompressions)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	if cfg.LevelDBWriteBufferMiB < 0 || cfg.LevelDBTableSizeMiB < 1 {
+		str := "%s: The LevelDB write buffer size must not be negative, and the " +
+			"table size must be at least 1 MiB"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	if cfg.LevelDBBloomFilterBits < 0 || cfg.LevelDBBloomFilterBits > maxLevelDBBloomFilterBits {
+		str := "%s: The LevelDB bloom filter bits per key must be between 0 and %d"
+		err := errors.Errorf(str, funcName, maxLevelDBBloomFilterBits)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
 	cfg.AppDir = cleanAndExpandPath(cfg.AppDir)
 	// Append the network type to the app directory so it is "namespaced"
 	// per network.
@@ -334,6 +434,13 @@ func LoadConfig() (*Config, error) {
 	}
 	cfg.LogDir = cleanAndExpandPath(cfg.LogDir)
 
+	if cfg.ImportSnapshot != "" {
+		cfg.ImportSnapshot = cleanAndExpandPath(cfg.ImportSnapshot)
+	}
+	if cfg.RestoreBackup != "" {
+		cfg.RestoreBackup = cleanAndExpandPath(cfg.RestoreBackup)
+	}
+
 	// Special show command to list supported subsystems and exit.
 	if cfg.LogLevel == "show" {
 		fmt.Println("Supported subsystems", logger.SupportedSubsystems())
@@ -21,35 +21,40 @@ type NetworkFlags struct {
 	Simnet                bool   `long:"simnet" description:"Use the simulation test network"`
 	Devnet                bool   `long:"devnet" description:"Use the development test network"`
 	OverrideDAGParamsFile string `long:"override-dag-params-file" description:"Overrides DAG params (allowed only on devnet)"`
+	NetParamsFile         string `long:"netparams" description:"Use the custom network defined in the given JSON or TOML network definition file"`
 
 	ActiveNetParams *dagconfig.Params
 }
 
 type overrideDAGParamsConfig struct {
-	K                                       *externalapi.KType `json:"k"`
-	MaxBlockParents                         *externalapi.KType `json:"maxBlockParents"`
-	MergeSetSizeLimit                       *uint64            `json:"mergeSetSizeLimit"`
-	MaxBlockMass                            *uint64            `json:"maxBlockMass"`
-	MaxCoinbasePayloadLength                *uint64            `json:"maxCoinbasePayloadLength"`
-	MassPerTxByte                           *uint64            `json:"massPerTxByte"`
-	MassPerScriptPubKeyByte                 *uint64            `json:"massPerScriptPubKeyByte"`
-	MassPerSigOp                            *uint64            `json:"massPerSigOp"`
-	CoinbasePayloadScriptPublicKeyMaxLength *uint8             `json:"coinbasePayloadScriptPublicKeyMaxLength"`
-	PowMax                                  *string            `json:"powMax"`
-	BlockCoinbaseMaturity                   *uint64            `json:"blockCoinbaseMaturity"`
-	SubsidyGenesisReward                    *uint64            `json:"subsidyGenesisReward"`
-	SubsidyPastRewardMultiplier             *float64           `json:"subsidyPastRewardMultiplier"`
-	SubsidyMergeSetRewardMultiplier         *float64           `json:"subsidyMergeSetRewardMultiplier"`
-	TargetTimePerBlockInMilliSeconds        *int64             `json:"targetTimePerBlockInMilliSeconds"`
-	FinalityDuration                        *int64             `json:"finalityDuration"`
-	TimestampDeviationTolerance             *int               `json:"timestampDeviationTolerance"`
-	DifficultyAdjustmentWindowSize          *int               `json:"difficultyAdjustmentWindowSize"`
-	RelayNonStdTxs                          *bool              `json:"relayNonStdTxs"`
-	AcceptUnroutable                        *bool              `json:"acceptUnroutable"`
-	EnableNonNativeSubnetworks              *bool              `json:"enableNonNativeSubnetworks"`
-	DisableDifficultyAdjustment             *bool              `json:"disableDifficultyAdjustment"`
-	SkipProofOfWork                         *bool              `json:"skipProofOfWork"`
-	HardForkOmitGenesisFromParentsDAAScore  *uint64            `json:"hardForkOmitGenesisFromParentsDaaScore"`
+	K                                       *externalapi.KType        `json:"k"`
+	MaxBlockParents                         *externalapi.KType        `json:"maxBlockParents"`
+	MergeSetSizeLimit                       *uint64                   `json:"mergeSetSizeLimit"`
+	MaxBlockMass                            *uint64                   `json:"maxBlockMass"`
+	MaxCoinbasePayloadLength                *uint64                   `json:"maxCoinbasePayloadLength"`
+	MassPerTxByte                           *uint64                   `json:"massPerTxByte"`
+	MassPerScriptPubKeyByte                 *uint64                   `json:"massPerScriptPubKeyByte"`
+	MassPerSigOp                            *uint64                   `json:"massPerSigOp"`
+	MassPerSpliceOp                         *uint64                   `json:"massPerSpliceOp"`
+	CoinbasePayloadScriptPublicKeyMaxLength *uint8                    `json:"coinbasePayloadScriptPublicKeyMaxLength"`
+	PowMax                                  *string                   `json:"powMax"`
+	BlockCoinbaseMaturity                   *uint64                   `json:"blockCoinbaseMaturity"`
+	SubsidyGenesisReward                    *uint64                   `json:"subsidyGenesisReward"`
+	SubsidyPastRewardMultiplier             *float64                  `json:"subsidyPastRewardMultiplier"`
+	SubsidyMergeSetRewardMultiplier         *float64                  `json:"subsidyMergeSetRewardMultiplier"`
+	TargetTimePerBlockInMilliSeconds        *int64                    `json:"targetTimePerBlockInMilliSeconds"`
+	FinalityDuration                        *int64                    `json:"finalityDuration"`
+	TimestampDeviationTolerance             *int                      `json:"timestampDeviationTolerance"`
+	DifficultyAdjustmentWindowSize          *int                      `json:"difficultyAdjustmentWindowSize"`
+	RelayNonStdTxs                          *bool                     `json:"relayNonStdTxs"`
+	AcceptUnroutable                        *bool                     `json:"acceptUnroutable"`
+	EnableNonNativeSubnetworks              *bool                     `json:"enableNonNativeSubnetworks"`
+	DisableDifficultyAdjustment             *bool                     `json:"disableDifficultyAdjustment"`
+	SkipProofOfWork                         *bool                     `json:"skipProofOfWork"`
+	HardForkOmitGenesisFromParentsDAAScore  *uint64                   `json:"hardForkOmitGenesisFromParentsDaaScore"`
+	IntrospectionActivationDAAScore         *uint64                   `json:"introspectionActivationDaaScore"`
+	SpliceOpcodesActivationDAAScore         *uint64                   `json:"spliceOpcodesActivationDaaScore"`
+	DevFeeSchedule                          *dagconfig.DevFeeSchedule `json:"devFeeSchedule"`
 }
 
 // ResolveNetwork parses the network command line argument and sets NetParams accordingly.
@@ -74,8 +79,16 @@ func (networkFlags *NetworkFlags) ResolveNetwork(parser *flags.Parser) error {
 		numNets++
 		networkFlags.ActiveNetParams = &dagconfig.DevnetParams
 	}
+	if networkFlags.NetParamsFile != "" {
+		numNets++
+		params, err := dagconfig.LoadNetworkParams(networkFlags.NetParamsFile)
+		if err != nil {
+			return err
+		}
+		networkFlags.ActiveNetParams = params
+	}
 	if numNets > 1 {
-		message := "Multiple networks parameters (testnet, simnet, devnet, etc.) cannot be used" +
+		message := "Multiple networks parameters (testnet, simnet, devnet, netparams, etc.) cannot be used" +
 			"together. Please choose only one network"
 		err := errors.Errorf(message)
 		fmt.Fprintln(os.Stderr, err)
@@ -88,6 +101,11 @@ func (networkFlags *NetworkFlags) ResolveNetwork(parser *flags.Parser) error {
 		return err
 	}
 
+	err = networkFlags.ActiveNetParams.ValidateDevFeeSchedule()
+	if err != nil {
+		return errors.Wrapf(err, "invalid dev fee schedule for %s", networkFlags.ActiveNetParams.Name)
+	}
+
 	return nil
 }
 
@@ -151,6 +169,10 @@ func (networkFlags *NetworkFlags) overrideDAGParams() error {
 		networkFlags.ActiveNetParams.MassPerSigOp = *config.MassPerSigOp
 	}
 
+	if config.MassPerSpliceOp != nil {
+		networkFlags.ActiveNetParams.MassPerSpliceOp = *config.MassPerSpliceOp
+	}
+
 	if config.CoinbasePayloadScriptPublicKeyMaxLength != nil {
 		networkFlags.ActiveNetParams.CoinbasePayloadScriptPublicKeyMaxLength = *config.CoinbasePayloadScriptPublicKeyMaxLength
 	}
@@ -214,5 +236,17 @@ func (networkFlags *NetworkFlags) overrideDAGParams() error {
 		networkFlags.ActiveNetParams.SkipProofOfWork = *config.SkipProofOfWork
 	}
 
+	if config.IntrospectionActivationDAAScore != nil {
+		networkFlags.ActiveNetParams.IntrospectionActivationDAAScore = *config.IntrospectionActivationDAAScore
+	}
+
+	if config.SpliceOpcodesActivationDAAScore != nil {
+		networkFlags.ActiveNetParams.SpliceOpcodesActivationDAAScore = *config.SpliceOpcodesActivationDAAScore
+	}
+
+	if config.DevFeeSchedule != nil {
+		networkFlags.ActiveNetParams.DevFeeSchedule = *config.DevFeeSchedule
+	}
+
 	return nil
 }
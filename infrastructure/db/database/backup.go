@@ -0,0 +1,24 @@
+package database
+
+import (
+	"time"
+)
+
+// BackupManifest describes a point-in-time copy of a database. It's stored
+// alongside the copy so that the copy's integrity can be verified before it's
+// restored.
+type BackupManifest struct {
+	EntryCount uint64    `json:"entryCount"`
+	Size       uint64    `json:"size"`
+	Checksum   string    `json:"checksum"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Backupable is implemented by databases that can create a consistent
+// point-in-time copy of themselves while they're in use.
+type Backupable interface {
+	// Backup copies the database into a new database at the given path.
+	// onProgress, if not nil, is called periodically with the number of
+	// entries copied so far.
+	Backup(path string, onProgress func(copiedEntries uint64)) (*BackupManifest, error)
+}
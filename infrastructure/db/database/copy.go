@@ -0,0 +1,74 @@
+package database
+
+// copyBatchSize is the number of entries written to the target database in
+// a single transaction by Copy
+const copyBatchSize = 10_000
+
+// Copy copies all the entries of the source database into the target
+// database, and returns the number of copied entries. onProgress, if not nil,
+// is called after every committed batch with the number of entries copied
+// so far.
+//
+// The source database should not be written to while it's being copied.
+func Copy(source Database, target Database, onProgress func(copiedEntries uint64)) (uint64, error) {
+	cursor, err := source.Cursor(MakeBucket(nil))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+
+	copiedEntries := uint64(0)
+	transaction, err := target.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		// transaction is replaced after every batch, so it has to be
+		// evaluated when the function returns
+		if transaction != nil {
+			_ = transaction.RollbackUnlessClosed()
+		}
+	}()
+
+	batchSize := 0
+	for cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return 0, err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return 0, err
+		}
+		err = transaction.Put(key, value)
+		if err != nil {
+			return 0, err
+		}
+		copiedEntries++
+		batchSize++
+
+		if batchSize == copyBatchSize {
+			err := transaction.Commit()
+			if err != nil {
+				return 0, err
+			}
+			if onProgress != nil {
+				onProgress(copiedEntries)
+			}
+			transaction, err = target.Begin()
+			if err != nil {
+				return 0, err
+			}
+			batchSize = 0
+		}
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return 0, err
+	}
+	if onProgress != nil {
+		onProgress(copiedEntries)
+	}
+	return copiedEntries, nil
+}
@@ -0,0 +1,58 @@
+package database_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/ammm56/lings/infrastructure/db/database"
+)
+
+func TestCopy(t *testing.T) {
+	for _, prepareSource := range databasePrepareFuncs {
+		for _, prepareTarget := range databasePrepareFuncs {
+			func() {
+				source, sourceType, teardownSource := prepareSource(t, "TestCopySource")
+				defer teardownSource()
+				target, targetType, teardownTarget := prepareTarget(t, "TestCopyTarget")
+				defer teardownTarget()
+
+				testName := fmt.Sprintf("TestCopy: %s to %s", sourceType, targetType)
+				testCopy(t, source, target, testName)
+			}()
+		}
+	}
+}
+
+func testCopy(t *testing.T, source database.Database, target database.Database, testName string) {
+	const entryCount = 25_000
+	bucket := database.MakeBucket([]byte("bucket"))
+	for i := 0; i < entryCount; i++ {
+		err := source.Put(bucket.Key([]byte(fmt.Sprintf("key%d", i))), []byte(fmt.Sprintf("value%d", i)))
+		if err != nil {
+			t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+		}
+	}
+
+	var lastProgress uint64
+	copiedEntries, err := database.Copy(source, target, func(copiedEntries uint64) {
+		lastProgress = copiedEntries
+	})
+	if err != nil {
+		t.Fatalf("%s: Copy unexpectedly failed: %s", testName, err)
+	}
+	if copiedEntries != entryCount || lastProgress != entryCount {
+		t.Fatalf("%s: expected %d copied entries but got %d (last progress: %d)",
+			testName, entryCount, copiedEntries, lastProgress)
+	}
+
+	for i := 0; i < entryCount; i++ {
+		value, err := target.Get(bucket.Key([]byte(fmt.Sprintf("key%d", i))))
+		if err != nil {
+			t.Fatalf("%s: Get unexpectedly failed: %s", testName, err)
+		}
+		if !bytes.Equal(value, []byte(fmt.Sprintf("value%d", i))) {
+			t.Fatalf("%s: unexpected value for key%d: %s", testName, i, value)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package ldb
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ammm56/lings/infrastructure/db/database"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+const (
+	// BackupManifestFileName is the name of the file inside a backup
+	// directory that holds the backup manifest
+	BackupManifestFileName = "backup-manifest.json"
+
+	// backupBatchSize is the number of entries written to the backup in
+	// a single batch. Progress is reported after every batch.
+	backupBatchSize = 10_000
+)
+
+// Backup copies a consistent snapshot of the database into a new LevelDB
+// instance at the given path, without blocking writes to the database. A
+// manifest with the number of entries and their checksum is written alongside
+// the copy, so that it can later be verified with VerifyBackup.
+func (db *LevelDB) Backup(path string, onProgress func(copiedEntries uint64)) (*database.BackupManifest, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return nil, errors.Errorf("backup path %s already exists", path)
+	}
+	if !os.IsNotExist(err) {
+		return nil, errors.WithStack(err)
+	}
+
+	snapshot, err := db.ldb.GetSnapshot()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer snapshot.Release()
+
+	manifest, err := copySnapshot(snapshot, path, onProgress)
+	if err != nil {
+		removeErr := os.RemoveAll(path)
+		if removeErr != nil {
+			log.Errorf("Failed to remove the partial backup at %s: %s", path, removeErr)
+		}
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func copySnapshot(snapshot *leveldb.Snapshot, path string,
+	onProgress func(copiedEntries uint64)) (*database.BackupManifest, error) {
+
+	options := Options()
+	options.ErrorIfExist = true
+	target, err := leveldb.OpenFile(path, &options)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer target.Close()
+
+	iterator := snapshot.NewIterator(nil, nil)
+	defer iterator.Release()
+
+	checksum := newBackupChecksum()
+	batch := new(leveldb.Batch)
+	for iterator.Next() {
+		batch.Put(iterator.Key(), iterator.Value())
+		checksum.add(iterator.Key(), iterator.Value())
+
+		if batch.Len() == backupBatchSize {
+			err := target.Write(batch, nil)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			batch.Reset()
+			if onProgress != nil {
+				onProgress(checksum.entryCount)
+			}
+		}
+	}
+	err = iterator.Error()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The last batch is written synchronously, which flushes all the
+	// previous writes to disk as well
+	err = target.Write(batch, &opt.WriteOptions{Sync: true})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if onProgress != nil {
+		onProgress(checksum.entryCount)
+	}
+
+	manifest := checksum.manifest()
+	manifest.CreatedAt = time.Now()
+	serializedManifest, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = os.WriteFile(filepath.Join(path, BackupManifestFileName), serializedManifest, 0600)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return manifest, nil
+}
+
+// ReadBackupManifest reads the manifest of the backup at the given path
+// without verifying the backup against it
+func ReadBackupManifest(path string) (*database.BackupManifest, error) {
+	serializedManifest, err := os.ReadFile(filepath.Join(path, BackupManifestFileName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the manifest of backup %s", path)
+	}
+	manifest := &database.BackupManifest{}
+	err = json.Unmarshal(serializedManifest, manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse the manifest of backup %s", path)
+	}
+	return manifest, nil
+}
+
+// VerifyBackup verifies that the backup at the given path matches its manifest
+func VerifyBackup(path string) (*database.BackupManifest, error) {
+	manifest, err := ReadBackupManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	options := Options()
+	options.ReadOnly = true
+	options.ErrorIfMissing = true
+	backup, err := leveldb.OpenFile(path, &options)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer backup.Close()
+
+	iterator := backup.NewIterator(nil, nil)
+	defer iterator.Release()
+
+	actual, err := checksumEntries(iterator)
+	if err != nil {
+		return nil, err
+	}
+	if actual.EntryCount != manifest.EntryCount || actual.Checksum != manifest.Checksum {
+		return nil, errors.Errorf("backup %s is corrupt: expected %d entries with checksum %s "+
+			"but found %d entries with checksum %s", path, manifest.EntryCount, manifest.Checksum,
+			actual.EntryCount, actual.Checksum)
+	}
+	return manifest, nil
+}
+
+func checksumEntries(iterator iterator.Iterator) (*database.BackupManifest, error) {
+	checksum := newBackupChecksum()
+	for iterator.Next() {
+		checksum.add(iterator.Key(), iterator.Value())
+	}
+	err := iterator.Error()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return checksum.manifest(), nil
+}
+
+// backupChecksum hashes database entries in iteration order. Keys and values
+// are length-prefixed so that different entry sets never hash alike.
+type backupChecksum struct {
+	hasher     hash.Hash
+	entryCount uint64
+	size       uint64
+}
+
+func newBackupChecksum() *backupChecksum {
+	return &backupChecksum{hasher: sha256.New()}
+}
+
+func (c *backupChecksum) add(key, value []byte) {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(key)))
+	c.hasher.Write(length[:])
+	c.hasher.Write(key)
+	binary.LittleEndian.PutUint32(length[:], uint32(len(value)))
+	c.hasher.Write(length[:])
+	c.hasher.Write(value)
+
+	c.entryCount++
+	c.size += uint64(len(key) + len(value))
+}
+
+func (c *backupChecksum) manifest() *database.BackupManifest {
+	return &database.BackupManifest{
+		EntryCount: c.entryCount,
+		Size:       c.size,
+		Checksum:   hex.EncodeToString(c.hasher.Sum(nil)),
+	}
+}
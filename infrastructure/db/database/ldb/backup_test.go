@@ -0,0 +1,70 @@
+package ldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ammm56/lings/infrastructure/db/database"
+)
+
+func TestBackup(t *testing.T) {
+	ldb, teardownFunc := prepareDatabaseForTest(t, "TestBackup")
+	defer teardownFunc()
+
+	bucket := database.MakeBucket([]byte("bucket"))
+	const entryCount = backupBatchSize + 5
+	for i := 0; i < entryCount; i++ {
+		err := ldb.Put(bucket.Key([]byte(fmt.Sprintf("key%d", i))), []byte(fmt.Sprintf("value%d", i)))
+		if err != nil {
+			t.Fatalf("Put: %s", err)
+		}
+	}
+
+	backupPath := filepath.Join(t.TempDir(), "backup")
+	var lastProgress uint64
+	manifest, err := ldb.Backup(backupPath, func(copiedEntries uint64) {
+		lastProgress = copiedEntries
+	})
+	if err != nil {
+		t.Fatalf("Backup: %s", err)
+	}
+	if manifest.EntryCount != entryCount || lastProgress != entryCount {
+		t.Fatalf("expected %d entries but got %d (last progress: %d)", entryCount, manifest.EntryCount, lastProgress)
+	}
+
+	// Writes after the backup must not affect it
+	err = ldb.Put(bucket.Key([]byte("new key")), []byte("new value"))
+	if err != nil {
+		t.Fatalf("Put: %s", err)
+	}
+
+	verifiedManifest, err := VerifyBackup(backupPath)
+	if err != nil {
+		t.Fatalf("VerifyBackup: %s", err)
+	}
+	if verifiedManifest.Checksum != manifest.Checksum {
+		t.Fatalf("expected checksum %s but got %s", manifest.Checksum, verifiedManifest.Checksum)
+	}
+
+	_, err = ldb.Backup(backupPath, nil)
+	if err == nil {
+		t.Fatalf("Backup unexpectedly succeeded on an existing path")
+	}
+
+	manifest.EntryCount++
+	serializedManifest, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("Marshal: %s", err)
+	}
+	err = os.WriteFile(filepath.Join(backupPath, BackupManifestFileName), serializedManifest, 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	_, err = VerifyBackup(backupPath)
+	if err == nil {
+		t.Fatalf("VerifyBackup unexpectedly succeeded on a backup that doesn't match its manifest")
+	}
+}
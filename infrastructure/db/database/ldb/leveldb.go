@@ -1,6 +1,8 @@
 package ldb
 
 import (
+	"sync/atomic"
+
 	"github.com/ammm56/lings/infrastructure/db/database"
 	"github.com/pkg/errors"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -11,16 +13,28 @@ import (
 
 // LevelDB defines a thin wrapper around leveldb.
 type LevelDB struct {
-	ldb *leveldb.DB
+	ldb    *leveldb.DB
+	config Config
+
+	// syncWrites is accessed atomically, since it may be changed while
+	// the database is in use
+	syncWrites uint32
 }
 
 // NewLevelDB opens a leveldb instance defined by the given path.
 func NewLevelDB(path string, cacheSizeMiB int) (*LevelDB, error) {
+	return NewLevelDBWithConfig(path, &Config{CacheSizeMiB: cacheSizeMiB})
+}
+
+// NewLevelDBWithConfig opens a leveldb instance defined by the given path,
+// with the options given in config.
+func NewLevelDBWithConfig(path string, config *Config) (*LevelDB, error) {
 	// Open leveldb. If it doesn't exist, create it.
-	options := Options()
-	options.BlockCacheCapacity = cacheSizeMiB * opt.MiB
-	options.WriteBuffer = (cacheSizeMiB * opt.MiB) / 2
-	ldb, err := leveldb.OpenFile(path, &options)
+	options, err := config.options()
+	if err != nil {
+		return nil, err
+	}
+	ldb, err := leveldb.OpenFile(path, options)
 
 	// If the database is corrupted, attempt to recover.
 	if _, corrupted := err.(*ldbErrors.ErrCorrupted); corrupted {
@@ -44,11 +58,41 @@ func NewLevelDB(path string, cacheSizeMiB int) (*LevelDB, error) {
 	}
 
 	db := &LevelDB{
-		ldb: ldb,
+		ldb:    ldb,
+		config: *config,
 	}
 	return db, nil
 }
 
+// SetSyncWrites sets whether every write is synced to disk before it
+// returns. It's safe to call while the database is in use, but has no
+// effect unless the database was opened with Config.Sync.
+func (db *LevelDB) SetSyncWrites(syncWrites bool) error {
+	if syncWrites && !db.config.Sync {
+		return errors.New("writes can't be synced since the database was opened without sync")
+	}
+	value := uint32(0)
+	if syncWrites {
+		value = 1
+	}
+	atomic.StoreUint32(&db.syncWrites, value)
+	return nil
+}
+
+// Config returns the options the database was opened with.
+func (db *LevelDB) Config() Config {
+	return db.config
+}
+
+// SyncWrites returns whether every write is synced to disk before it returns.
+func (db *LevelDB) SyncWrites() bool {
+	return atomic.LoadUint32(&db.syncWrites) == 1
+}
+
+func (db *LevelDB) writeOptions() *opt.WriteOptions {
+	return &opt.WriteOptions{Sync: db.SyncWrites()}
+}
+
 // Compact compacts the leveldb instance.
 func (db *LevelDB) Compact() error {
 	err := db.ldb.CompactRange(util.Range{Start: nil, Limit: nil})
@@ -64,7 +108,7 @@ func (db *LevelDB) Close() error {
 // Put sets the value for the given key. It overwrites
 // any previous value for that key.
 func (db *LevelDB) Put(key *database.Key, value []byte) error {
-	err := db.ldb.Put(key.Bytes(), value, nil)
+	err := db.ldb.Put(key.Bytes(), value, db.writeOptions())
 	return errors.WithStack(err)
 }
 
@@ -95,6 +139,6 @@ func (db *LevelDB) Has(key *database.Key) (bool, error) {
 // Delete deletes the value for the given key. Will not
 // return an error if the key doesn't exist.
 func (db *LevelDB) Delete(key *database.Key) error {
-	err := db.ldb.Delete(key.Bytes(), nil)
+	err := db.ldb.Delete(key.Bytes(), db.writeOptions())
 	return errors.WithStack(err)
 }
@@ -1,6 +1,7 @@
 package ldb
 
 import (
+	"fmt"
 	"io/ioutil"
 	"reflect"
 	"testing"
@@ -151,3 +152,31 @@ func TestLevelDBTransactionSanity(t *testing.T) {
 			"returned unexpected error: %s", err)
 	}
 }
+
+func BenchmarkCommit(b *testing.B) {
+	ldb, err := NewLevelDB(b.TempDir(), 8)
+	if err != nil {
+		b.Fatalf("NewLevelDB: %s", err)
+	}
+	defer ldb.Close()
+
+	bucket := database.MakeBucket([]byte("bucket"))
+	value := make([]byte, 200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx, err := ldb.Begin()
+		if err != nil {
+			b.Fatalf("Begin: %s", err)
+		}
+		for j := 0; j < 100; j++ {
+			err := tx.Put(bucket.Key([]byte(fmt.Sprintf("key%05d", i*100+j))), value)
+			if err != nil {
+				b.Fatalf("Put: %s", err)
+			}
+		}
+		err = tx.Commit()
+		if err != nil {
+			b.Fatalf("Commit: %s", err)
+		}
+	}
+}
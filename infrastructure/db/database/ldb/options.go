@@ -1,6 +1,10 @@
 package ldb
 
-import "github.com/syndtr/goleveldb/leveldb/opt"
+import (
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb/filter"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
 
 // Options is a function that returns a leveldb
 // opt.Options struct for opening a database.
@@ -11,3 +15,60 @@ func Options() opt.Options {
 		NoSync:                 true,
 	}
 }
+
+// Supported values of Config.Compression
+const (
+	CompressionNone   = "none"
+	CompressionSnappy = "snappy"
+)
+
+// Config holds the tunable options of a LevelDB instance. Zero values mean
+// the defaults of NewLevelDB.
+type Config struct {
+	// CacheSizeMiB is the size of the block cache
+	CacheSizeMiB int
+
+	// WriteBufferMiB is the size of the in-memory buffer that is flushed
+	// into a table once it's full. It defaults to half of CacheSizeMiB.
+	WriteBufferMiB int
+
+	// TableSizeMiB is the size of the table files written by compactions
+	TableSizeMiB int
+
+	// BloomFilterBits is the number of bits per key of the bloom filter
+	// that is used to skip tables on reads. 0 disables the filter.
+	BloomFilterBits int
+
+	// Compression is either CompressionNone or CompressionSnappy
+	Compression string
+
+	// Sync allows LevelDB to fsync its files. When it's disabled, no
+	// write is ever synced, even if SetSyncWrites is enabled.
+	Sync bool
+}
+
+// options returns the leveldb options that match the config
+func (config *Config) options() (*opt.Options, error) {
+	options := Options()
+	options.BlockCacheCapacity = config.CacheSizeMiB * opt.MiB
+	options.WriteBuffer = (config.CacheSizeMiB * opt.MiB) / 2
+	if config.WriteBufferMiB > 0 {
+		options.WriteBuffer = config.WriteBufferMiB * opt.MiB
+	}
+	if config.TableSizeMiB > 0 {
+		options.CompactionTableSize = config.TableSizeMiB * opt.MiB
+	}
+	if config.BloomFilterBits > 0 {
+		options.Filter = filter.NewBloomFilter(config.BloomFilterBits)
+	}
+	switch config.Compression {
+	case "", CompressionNone:
+		options.Compression = opt.NoCompression
+	case CompressionSnappy:
+		options.Compression = opt.SnappyCompression
+	default:
+		return nil, errors.Errorf("unknown compression %s", config.Compression)
+	}
+	options.NoSync = !config.Sync
+	return &options, nil
+}
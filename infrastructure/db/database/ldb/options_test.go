@@ -0,0 +1,70 @@
+package ldb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ammm56/lings/infrastructure/db/database"
+)
+
+func TestNewLevelDBWithConfig(t *testing.T) {
+	_, err := NewLevelDBWithConfig(t.TempDir(), &Config{CacheSizeMiB: 8, Compression: "lz4"})
+	if err == nil {
+		t.Fatalf("NewLevelDBWithConfig unexpectedly accepted an unknown compression")
+	}
+
+	ldb, err := NewLevelDBWithConfig(t.TempDir(), &Config{
+		CacheSizeMiB:    8,
+		WriteBufferMiB:  1,
+		TableSizeMiB:    1,
+		BloomFilterBits: 10,
+		Compression:     CompressionSnappy,
+		Sync:            true,
+	})
+	if err != nil {
+		t.Fatalf("NewLevelDBWithConfig: %s", err)
+	}
+	defer ldb.Close()
+
+	err = ldb.SetSyncWrites(true)
+	if err != nil {
+		t.Fatalf("SetSyncWrites: %s", err)
+	}
+	if !ldb.SyncWrites() {
+		t.Fatalf("expected writes to be synced")
+	}
+
+	bucket := database.MakeBucket([]byte("bucket"))
+	for i := 0; i < 1000; i++ {
+		err := ldb.Put(bucket.Key([]byte(fmt.Sprintf("key%d", i))), make([]byte, 1000))
+		if err != nil {
+			t.Fatalf("Put: %s", err)
+		}
+	}
+	err = ldb.Compact()
+	if err != nil {
+		t.Fatalf("Compact: %s", err)
+	}
+
+	sizes, err := ldb.BucketSizes([]*database.Bucket{bucket, database.MakeBucket([]byte("empty"))})
+	if err != nil {
+		t.Fatalf("BucketSizes: %s", err)
+	}
+	if sizes[0] == 0 || sizes[1] != 0 {
+		t.Fatalf("unexpected bucket sizes %v", sizes)
+	}
+}
+
+func TestSetSyncWritesWithoutSync(t *testing.T) {
+	ldb, teardownFunc := prepareDatabaseForTest(t, "TestSetSyncWritesWithoutSync")
+	defer teardownFunc()
+
+	err := ldb.SetSyncWrites(true)
+	if err == nil {
+		t.Fatalf("SetSyncWrites unexpectedly succeeded on a database that was opened without sync")
+	}
+	err = ldb.SetSyncWrites(false)
+	if err != nil {
+		t.Fatalf("SetSyncWrites: %s", err)
+	}
+}
@@ -0,0 +1,27 @@
+package ldb
+
+import (
+	"github.com/ammm56/lings/infrastructure/db/database"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// BucketSizes returns the approximate disk space, in bytes, that is taken by
+// the entries of each of the given buckets. Entries that weren't flushed from
+// the write buffer into tables yet aren't counted.
+func (db *LevelDB) BucketSizes(buckets []*database.Bucket) ([]uint64, error) {
+	ranges := make([]util.Range, len(buckets))
+	for i, bucket := range buckets {
+		ranges[i] = *util.BytesPrefix(bucket.Path())
+	}
+	sizes, err := db.ldb.SizeOf(ranges)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	bucketSizes := make([]uint64, len(sizes))
+	for i, size := range sizes {
+		bucketSizes[i] = uint64(size)
+	}
+	return bucketSizes, nil
+}
@@ -0,0 +1,140 @@
+package logdb
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// compactionRecordSize is the approximate size of the records written to the
+// compacted data log
+const compactionRecordSize = 4 << 20
+
+// compact writes the live entries of the index into a new data log, and
+// replaces the current data log with it. The caller must hold the database
+// lock for writing.
+func (db *LogDB) compact() error {
+	dataLogPath := filepath.Join(db.path, dataLogFileName)
+	compactedPath := dataLogPath + compactedDataLogSuffix
+
+	compacted, err := os.OpenFile(compactedPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	newLocations, size, err := db.writeLiveEntries(compacted)
+	if err != nil {
+		compacted.Close()
+		removeErr := os.Remove(compactedPath)
+		if removeErr != nil {
+			log.Errorf("Failed to remove %s: %s", compactedPath, removeErr)
+		}
+		return err
+	}
+
+	// The old data log is closed before it's replaced, since open files
+	// can't be replaced on all platforms
+	err = db.file.Close()
+	if err != nil {
+		log.Errorf("Failed to close the old data log: %s", err)
+	}
+	db.file = compacted
+	renameErr := os.Rename(compactedPath, dataLogPath)
+	if renameErr != nil {
+		// The compacted data log is discarded and the old one is
+		// kept, since its locations are the ones in the index
+		compacted.Close()
+		db.file, err = os.OpenFile(dataLogPath, os.O_RDWR, 0600)
+		if err != nil {
+			db.isClosed = true
+			return errors.Wrapf(err, "could not reopen the data log after a failed compaction")
+		}
+		return errors.Wrapf(renameErr, "could not replace the data log with its compacted version")
+	}
+	db.size = size
+	db.garbage = 0
+
+	i := 0
+	for node := db.index.first(); node != nil; node = node.next[0] {
+		node.location = newLocations[i]
+		i++
+	}
+	return syncDirectory(db.path)
+}
+
+// writeLiveEntries writes the live entries of the index into the given file,
+// and returns their locations in index order along with the size of the file
+func (db *LogDB) writeLiveEntries(file *os.File) ([]valueLocation, int64, error) {
+	_, err := file.Write(dataLogMagic)
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	size := int64(len(dataLogMagic))
+
+	newLocations := make([]valueLocation, 0, db.index.length)
+	batch := &batch{}
+	flush := func() error {
+		record, err := batch.record()
+		if err != nil {
+			return err
+		}
+		payloadOffset := size + recordHeaderLength
+		err = forEachOperation(batch.payload, func(operation *operation) {
+			newLocations = append(newLocations, valueLocation{
+				offset: payloadOffset + int64(operation.valueOffset),
+				length: uint32(operation.valueLength),
+			})
+		})
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(record)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		size += int64(len(record))
+		batch.reset()
+		return nil
+	}
+
+	for node := db.index.first(); node != nil; node = node.next[0] {
+		value, err := db.readValue(node.location)
+		if err != nil {
+			return nil, 0, err
+		}
+		batch.put(node.key, value)
+		if len(batch.payload) >= compactionRecordSize {
+			err := flush()
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	if !batch.isEmpty() {
+		err := flush()
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	err = file.Sync()
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	return newLocations, size, nil
+}
+
+// syncDirectory makes renames inside the given directory durable. Windows
+// doesn't support syncing directories, and renames there are durable anyway.
+func syncDirectory(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	directory, err := os.Open(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer directory.Close()
+	return errors.WithStack(directory.Sync())
+}
@@ -0,0 +1,170 @@
+package logdb
+
+import (
+	"bytes"
+
+	"github.com/ammm56/lings/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// LogDBCursor iterates over the keys of a LogDB index in order.
+//
+// The cursor doesn't block writes. Entries written after the cursor was
+// opened may or may not be visible to it, but removing the entry the cursor
+// is positioned on never stops the iteration.
+type LogDBCursor struct {
+	db     *LogDB
+	bucket *database.Bucket
+	prefix []byte
+
+	node           *indexNode
+	isExhausted    bool
+	currentKey     []byte
+	currentValue   []byte
+	currentReadErr error
+
+	isClosed bool
+}
+
+// Cursor begins a new cursor over the given prefix.
+func (db *LogDB) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	return &LogDBCursor{
+		db:       db,
+		bucket:   bucket,
+		prefix:   bucket.Path(),
+		isClosed: false,
+	}, nil
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted. Panics if the cursor is closed.
+func (c *LogDBCursor) Next() bool {
+	if c.isClosed {
+		panic("cannot call next on a closed cursor")
+	}
+	if c.isExhausted {
+		return false
+	}
+
+	c.db.mutex.RLock()
+	defer c.db.mutex.RUnlock()
+
+	if c.node == nil {
+		return c.moveToGreaterOrEqual(c.prefix)
+	}
+	if c.db.isClosed {
+		return c.moveTo(nil)
+	}
+	return c.moveTo(nextOf(c.node))
+}
+
+// First moves the iterator to the first key/value pair. It returns false if
+// such a pair does not exist. Panics if the cursor is closed.
+func (c *LogDBCursor) First() bool {
+	if c.isClosed {
+		panic("cannot call first on a closed cursor")
+	}
+
+	c.db.mutex.RLock()
+	defer c.db.mutex.RUnlock()
+
+	return c.moveToGreaterOrEqual(c.prefix)
+}
+
+// Seek moves the iterator to the first key/value pair whose key is greater
+// than or equal to the given key. It returns ErrNotFound if such pair does not
+// exist.
+func (c *LogDBCursor) Seek(key *database.Key) error {
+	if c.isClosed {
+		return errors.New("cannot seek a closed cursor")
+	}
+
+	target := key.Bytes()
+	if bytes.Compare(target, c.prefix) < 0 {
+		target = c.prefix
+	}
+
+	c.db.mutex.RLock()
+	found := c.moveToGreaterOrEqual(target)
+	c.db.mutex.RUnlock()
+
+	if !found || !bytes.Equal(c.currentKey, key.Bytes()) {
+		return errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+	return nil
+}
+
+// moveToGreaterOrEqual positions the cursor on the first key that is greater
+// than or equal to the given key. The caller must hold the database lock.
+func (c *LogDBCursor) moveToGreaterOrEqual(key []byte) bool {
+	if c.db.isClosed {
+		return c.moveTo(nil)
+	}
+	return c.moveTo(c.db.index.findGreaterOrEqual(key, nil))
+}
+
+// moveTo positions the cursor on the given node, and reads its value. It
+// returns false and marks the cursor as exhausted if the node is outside the
+// cursor's bucket. The caller must hold the database lock.
+func (c *LogDBCursor) moveTo(node *indexNode) bool {
+	c.currentKey = nil
+	c.currentValue = nil
+	c.currentReadErr = nil
+
+	if node == nil || !bytes.HasPrefix(node.key, c.prefix) {
+		c.isExhausted = true
+		return false
+	}
+
+	c.node = node
+	c.isExhausted = false
+	c.currentKey = node.key
+	c.currentValue, c.currentReadErr = c.db.readValue(node.location)
+	return true
+}
+
+// Key returns the key of the current key/value pair, or ErrNotFound if done.
+// Note that the key is trimmed to not include the prefix the cursor was opened
+// with. The caller should not modify the contents of the returned slice, and
+// its contents may change on the next call to Next.
+func (c *LogDBCursor) Key() (*database.Key, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the key of a closed cursor")
+	}
+	if c.currentKey == nil {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"key of an exhausted cursor")
+	}
+	suffix := bytes.TrimPrefix(c.currentKey, c.prefix)
+	return c.bucket.Key(suffix), nil
+}
+
+// Value returns the value of the current key/value pair, or ErrNotFound if done.
+// The caller should not modify the contents of the returned slice, and its
+// contents may change on the next call to Next.
+func (c *LogDBCursor) Value() ([]byte, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the value of a closed cursor")
+	}
+	if c.currentKey == nil {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"value of an exhausted cursor")
+	}
+	if c.currentReadErr != nil {
+		return nil, c.currentReadErr
+	}
+	return c.currentValue, nil
+}
+
+// Close releases associated resources.
+func (c *LogDBCursor) Close() error {
+	if c.isClosed {
+		return errors.New("cannot close an already closed cursor")
+	}
+	c.isClosed = true
+	c.node = nil
+	c.currentKey = nil
+	c.currentValue = nil
+	c.bucket = nil
+	return nil
+}
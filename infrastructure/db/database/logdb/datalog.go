@@ -0,0 +1,220 @@
+package logdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// The data log starts with dataLogMagic, followed by a sequence of records.
+// Every record holds one committed batch and is laid out as follows:
+//
+//	uint32 payload length | uint32 CRC-32C of the payload | payload
+//
+// The payload is a sequence of operations:
+//
+//	opPut | uvarint key length | key | uvarint value length | value
+//	opDelete | uvarint key length | key
+//
+// A record is only applied once it was read in full and its checksum matches,
+// which makes a batch either fully present or absent after a crash.
+const (
+	dataLogFileName        = "data.log"
+	compactedDataLogSuffix = ".compact"
+	lockFileName           = "LOCK"
+
+	recordHeaderLength = 8
+	maxRecordLength    = 1 << 30
+)
+
+var dataLogMagic = []byte("LINGSLDB")
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+const (
+	opPut    byte = 1
+	opDelete byte = 2
+)
+
+// batch accumulates operations into a data log record payload
+type batch struct {
+	payload []byte
+}
+
+func (b *batch) put(key, value []byte) {
+	b.payload = append(b.payload, opPut)
+	b.payload = appendUvarint(b.payload, uint64(len(key)))
+	b.payload = append(b.payload, key...)
+	b.payload = appendUvarint(b.payload, uint64(len(value)))
+	b.payload = append(b.payload, value...)
+}
+
+func (b *batch) delete(key []byte) {
+	b.payload = append(b.payload, opDelete)
+	b.payload = appendUvarint(b.payload, uint64(len(key)))
+	b.payload = append(b.payload, key...)
+}
+
+func (b *batch) reset() {
+	b.payload = b.payload[:0]
+}
+
+func (b *batch) isEmpty() bool {
+	return len(b.payload) == 0
+}
+
+// record returns the batch serialized as a data log record
+func (b *batch) record() ([]byte, error) {
+	if len(b.payload) > maxRecordLength {
+		return nil, errors.Errorf("batch of %d bytes exceeds the maximum of %d bytes",
+			len(b.payload), maxRecordLength)
+	}
+	record := make([]byte, recordHeaderLength, recordHeaderLength+len(b.payload))
+	binary.LittleEndian.PutUint32(record[:4], uint32(len(b.payload)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.Checksum(b.payload, crc32cTable))
+	return append(record, b.payload...), nil
+}
+
+func appendUvarint(buffer []byte, value uint64) []byte {
+	var encoded [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(encoded[:], value)
+	return append(buffer, encoded[:n]...)
+}
+
+// operation is a single decoded operation of a record. valueOffset is the
+// offset of the value relative to the start of the payload.
+type operation struct {
+	op          byte
+	key         []byte
+	valueOffset int
+	valueLength int
+}
+
+// forEachOperation decodes the given record payload and calls f for every
+// operation in it
+func forEachOperation(payload []byte, f func(operation *operation)) error {
+	position := 0
+	readLength := func() (int, error) {
+		length, n := binary.Uvarint(payload[position:])
+		if n <= 0 || length > uint64(len(payload)-position-n) {
+			return 0, errors.Errorf("malformed length at payload position %d", position)
+		}
+		position += n
+		return int(length), nil
+	}
+
+	for position < len(payload) {
+		operation := &operation{op: payload[position]}
+		position++
+		if operation.op != opPut && operation.op != opDelete {
+			return errors.Errorf("unknown operation %d", operation.op)
+		}
+
+		keyLength, err := readLength()
+		if err != nil {
+			return err
+		}
+		operation.key = payload[position : position+keyLength]
+		position += keyLength
+
+		if operation.op == opPut {
+			operation.valueLength, err = readLength()
+			if err != nil {
+				return err
+			}
+			operation.valueOffset = position
+			position += operation.valueLength
+		}
+		f(operation)
+	}
+	return nil
+}
+
+// replayDataLog reads the records of the data log in order and calls f with
+// the payload of every valid record along with the payload's offset in the
+// file. It returns the offset at which the valid part of the log ends, so that
+// a record that was torn by a crash can be truncated. Only the last record of
+// the log can be torn: a bad record that is followed by more data means that
+// the log is corrupted, and an error is returned.
+func replayDataLog(file *os.File, f func(payload []byte, payloadOffset int64) error) (validLength int64, err error) {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	fileSize := fileInfo.Size()
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	reader := bufio.NewReaderSize(file, 1<<20)
+
+	magic := make([]byte, len(dataLogMagic))
+	_, err = io.ReadFull(reader, magic)
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not read the data log header")
+	}
+	if !bytes.Equal(magic, dataLogMagic) {
+		return 0, errors.Errorf("%s is not a data log", file.Name())
+	}
+
+	offset := int64(len(dataLogMagic))
+	header := make([]byte, recordHeaderLength)
+	var payload []byte
+	for {
+		_, err := io.ReadFull(reader, header)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return offset, nil
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				log.Warnf("Found a partial record header at offset %d of %s", offset, file.Name())
+				return offset, nil
+			}
+			return 0, errors.WithStack(err)
+		}
+		length := binary.LittleEndian.Uint32(header[:4])
+		checksum := binary.LittleEndian.Uint32(header[4:8])
+		recordEnd := offset + recordHeaderLength + int64(length)
+		if length > maxRecordLength {
+			if recordEnd < fileSize {
+				return 0, errors.Errorf("%s is corrupted: the record at offset %d has an invalid length "+
+					"of %d bytes", file.Name(), offset, length)
+			}
+			log.Warnf("Found a record with an invalid length at offset %d of %s", offset, file.Name())
+			return offset, nil
+		}
+
+		if cap(payload) < int(length) {
+			payload = make([]byte, length)
+		}
+		payload = payload[:length]
+		_, err = io.ReadFull(reader, payload)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				log.Warnf("Found a partial record at offset %d of %s", offset, file.Name())
+				return offset, nil
+			}
+			return 0, errors.WithStack(err)
+		}
+		if crc32.Checksum(payload, crc32cTable) != checksum {
+			if recordEnd < fileSize {
+				return 0, errors.Errorf("%s is corrupted: the record at offset %d has a bad checksum",
+					file.Name(), offset)
+			}
+			log.Warnf("Found a record with a bad checksum at offset %d of %s", offset, file.Name())
+			return offset, nil
+		}
+
+		err = f(payload, offset+recordHeaderLength)
+		if err != nil {
+			return 0, err
+		}
+		offset = recordEnd
+	}
+}
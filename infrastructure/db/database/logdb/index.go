@@ -0,0 +1,140 @@
+package logdb
+
+import (
+	"bytes"
+	"math/rand"
+)
+
+const (
+	indexMaxHeight = 24
+	indexBranching = 4
+)
+
+// valueLocation is the location of a value inside the data log
+type valueLocation struct {
+	offset int64
+	length uint32
+}
+
+// indexNode is a single key in the index. Removed nodes keep their forward
+// pointers, so that cursors positioned on them can still advance.
+type indexNode struct {
+	key       []byte
+	location  valueLocation
+	isRemoved bool
+	next      []*indexNode
+}
+
+// index is an ordered in-memory map from keys to the location of their
+// latest value in the data log, implemented as a skip list.
+// It is not safe for concurrent use; LogDB guards it with its lock.
+type index struct {
+	head   *indexNode
+	height int
+	length int
+	random *rand.Rand
+}
+
+func newIndex() *index {
+	return &index{
+		head:   &indexNode{next: make([]*indexNode, indexMaxHeight)},
+		height: 1,
+		random: rand.New(rand.NewSource(0)),
+	}
+}
+
+func (idx *index) randomHeight() int {
+	height := 1
+	for height < indexMaxHeight && idx.random.Intn(indexBranching) == 0 {
+		height++
+	}
+	return height
+}
+
+// findGreaterOrEqual returns the first node whose key is greater than or
+// equal to the given key, or nil if there's none. If previous is not nil,
+// it's filled with the last node before the key at every level.
+func (idx *index) findGreaterOrEqual(key []byte, previous []*indexNode) *indexNode {
+	node := idx.head
+	for level := idx.height - 1; level >= 0; level-- {
+		for node.next[level] != nil && bytes.Compare(node.next[level].key, key) < 0 {
+			node = node.next[level]
+		}
+		if previous != nil {
+			previous[level] = node
+		}
+	}
+	return node.next[0]
+}
+
+func (idx *index) get(key []byte) (*indexNode, bool) {
+	node := idx.findGreaterOrEqual(key, nil)
+	if node == nil || !bytes.Equal(node.key, key) {
+		return nil, false
+	}
+	return node, true
+}
+
+// put sets the location of the given key. It returns the location it
+// replaced, if any.
+func (idx *index) put(key []byte, location valueLocation) (replaced valueLocation, wasReplaced bool) {
+	var previous [indexMaxHeight]*indexNode
+	node := idx.findGreaterOrEqual(key, previous[:])
+	if node != nil && bytes.Equal(node.key, key) {
+		replaced = node.location
+		node.location = location
+		return replaced, true
+	}
+
+	height := idx.randomHeight()
+	if height > idx.height {
+		for level := idx.height; level < height; level++ {
+			previous[level] = idx.head
+		}
+		idx.height = height
+	}
+	node = &indexNode{
+		key:      key,
+		location: location,
+		next:     make([]*indexNode, height),
+	}
+	for level := 0; level < height; level++ {
+		node.next[level] = previous[level].next[level]
+		previous[level].next[level] = node
+	}
+	idx.length++
+	return valueLocation{}, false
+}
+
+// remove removes the given key. It returns the location of the removed
+// value, if the key existed.
+func (idx *index) remove(key []byte) (removed valueLocation, wasRemoved bool) {
+	var previous [indexMaxHeight]*indexNode
+	node := idx.findGreaterOrEqual(key, previous[:])
+	if node == nil || !bytes.Equal(node.key, key) {
+		return valueLocation{}, false
+	}
+	for level := 0; level < len(node.next); level++ {
+		previous[level].next[level] = node.next[level]
+	}
+	for idx.height > 1 && idx.head.next[idx.height-1] == nil {
+		idx.height--
+	}
+	node.isRemoved = true
+	idx.length--
+	return node.location, true
+}
+
+func (idx *index) first() *indexNode {
+	return idx.head.next[0]
+}
+
+// nextOf returns the node following the given node, skipping nodes that were
+// removed after the given node was reached
+func nextOf(node *indexNode) *indexNode {
+	next := node.next[0]
+	for next != nil && next.isRemoved {
+		next = next.next[0]
+	}
+	return next
+}
@@ -0,0 +1,7 @@
+package logdb
+
+import (
+	"github.com/ammm56/lings/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("KSDB")
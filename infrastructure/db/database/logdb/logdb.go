@@ -0,0 +1,291 @@
+package logdb
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ammm56/lings/infrastructure/db/database"
+	"github.com/gofrs/flock"
+	"github.com/pkg/errors"
+)
+
+// compactionThreshold is the minimal size of garbage in the data log, in
+// bytes, for it to be compacted automatically when the database is opened
+const compactionThreshold = 256 << 20
+
+// LogDB is a database.Database that appends every write to a data log and
+// keeps an in-memory index of where the latest value of each key is.
+//
+// Unlike LevelDB, LogDB never compacts in the background, so writes are never
+// stalled by compactions. The price is that the whole key set must fit in
+// memory, and that overwritten values take disk space until the data log is
+// compacted by Compact, or automatically when it is opened.
+type LogDB struct {
+	path string
+	lock *flock.Flock
+
+	mutex      sync.RWMutex
+	file       *os.File
+	size       int64
+	garbage    int64
+	index      *index
+	syncWrites bool
+	isClosed   bool
+}
+
+// NewLogDB opens a LogDB instance defined by the given path. If it doesn't
+// exist, it's created.
+func NewLogDB(path string) (*LogDB, error) {
+	err := os.MkdirAll(path, 0700)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	lock := flock.New(filepath.Join(path, lockFileName))
+	isLocked, err := lock.TryLock()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !isLocked {
+		return nil, errors.Errorf("database %s is already in use", path)
+	}
+
+	db := &LogDB{
+		path:  path,
+		lock:  lock,
+		index: newIndex(),
+	}
+	err = db.load()
+	if err != nil {
+		unlockErr := lock.Unlock()
+		if unlockErr != nil {
+			log.Errorf("Failed to unlock %s: %s", path, unlockErr)
+		}
+		return nil, err
+	}
+
+	if db.garbage >= compactionThreshold && db.garbage > db.size/2 {
+		log.Infof("Compacting %d bytes of garbage out of the %d bytes data log at %s",
+			db.garbage, db.size, path)
+		err := db.Compact()
+		if err != nil {
+			closeErr := db.Close()
+			if closeErr != nil {
+				log.Errorf("Failed to close %s: %s", path, closeErr)
+			}
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// load opens the data log and builds the index by replaying it
+func (db *LogDB) load() error {
+	dataLogPath := filepath.Join(db.path, dataLogFileName)
+
+	// A leftover of a compaction that was interrupted before it was complete
+	err := os.Remove(dataLogPath + compactedDataLogSuffix)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+
+	file, err := os.OpenFile(dataLogPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fileInfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.WithStack(err)
+	}
+	if fileInfo.Size() == 0 {
+		_, err := file.Write(dataLogMagic)
+		if err != nil {
+			file.Close()
+			return errors.WithStack(err)
+		}
+		err = file.Sync()
+		if err != nil {
+			file.Close()
+			return errors.WithStack(err)
+		}
+	}
+
+	validLength, err := replayDataLog(file, db.apply)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if fileInfo.Size() > validLength {
+		log.Warnf("Truncating %d bytes of incomplete writes from the end of %s",
+			fileInfo.Size()-validLength, dataLogPath)
+		err := file.Truncate(validLength)
+		if err != nil {
+			file.Close()
+			return errors.WithStack(err)
+		}
+	}
+
+	db.file = file
+	db.size = validLength
+	return nil
+}
+
+// apply applies the operations in the given record payload to the index.
+// payloadOffset is the offset of the payload in the data log.
+func (db *LogDB) apply(payload []byte, payloadOffset int64) error {
+	return forEachOperation(payload, func(operation *operation) {
+		var replaced valueLocation
+		var wasReplaced bool
+		switch operation.op {
+		case opPut:
+			key := make([]byte, len(operation.key))
+			copy(key, operation.key)
+			location := valueLocation{
+				offset: payloadOffset + int64(operation.valueOffset),
+				length: uint32(operation.valueLength),
+			}
+			replaced, wasReplaced = db.index.put(key, location)
+		case opDelete:
+			replaced, wasReplaced = db.index.remove(operation.key)
+		}
+		if wasReplaced {
+			db.garbage += int64(replaced.length)
+		}
+	})
+}
+
+// write appends the given batch to the data log and applies it to the index
+func (db *LogDB) write(batch *batch) error {
+	if batch.isEmpty() {
+		return nil
+	}
+	record, err := batch.record()
+	if err != nil {
+		return err
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	if db.isClosed {
+		return errors.New("cannot write to a closed database")
+	}
+
+	_, err = db.file.WriteAt(record, db.size)
+	if err == nil && db.syncWrites {
+		err = db.file.Sync()
+	}
+	if err != nil {
+		// Drop whatever part of the record was written, so that the
+		// next record is appended to a valid log
+		truncateErr := db.file.Truncate(db.size)
+		if truncateErr != nil {
+			log.Errorf("Failed to truncate %s after a failed write: %s", db.file.Name(), truncateErr)
+		}
+		return errors.WithStack(err)
+	}
+	err = db.apply(record[recordHeaderLength:], db.size+recordHeaderLength)
+	if err != nil {
+		return err
+	}
+	db.size += int64(len(record))
+	return nil
+}
+
+// SetSyncWrites sets whether every write is synced to disk before it
+// returns. It's safe to call while the database is in use.
+func (db *LogDB) SetSyncWrites(syncWrites bool) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	db.syncWrites = syncWrites
+	return nil
+}
+
+// readValue reads the value at the given location. The caller must hold
+// the database lock.
+func (db *LogDB) readValue(location valueLocation) ([]byte, error) {
+	value := make([]byte, location.length)
+	_, err := db.file.ReadAt(value, location.offset)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return value, nil
+}
+
+// Compact rewrites the data log so that it only contains the latest value of
+// every key. Writes are blocked while the data log is being rewritten.
+func (db *LogDB) Compact() error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	if db.isClosed {
+		return errors.New("cannot compact a closed database")
+	}
+	return db.compact()
+}
+
+// Close closes the LogDB instance.
+func (db *LogDB) Close() error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	if db.isClosed {
+		return errors.New("cannot close an already closed database")
+	}
+	db.isClosed = true
+	db.index = nil
+
+	err := db.file.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(db.lock.Unlock())
+}
+
+// Put sets the value for the given key. It overwrites
+// any previous value for that key.
+func (db *LogDB) Put(key *database.Key, value []byte) error {
+	batch := &batch{}
+	batch.put(key.Bytes(), value)
+	return db.write(batch)
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (db *LogDB) Get(key *database.Key) ([]byte, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	if db.isClosed {
+		return nil, errors.New("cannot get from a closed database")
+	}
+	node, ok := db.index.get(key.Bytes())
+	if !ok {
+		return nil, errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+	return db.readValue(node.location)
+}
+
+// Has returns true if the database does contains the
+// given key.
+func (db *LogDB) Has(key *database.Key) (bool, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	if db.isClosed {
+		return false, errors.New("cannot has from a closed database")
+	}
+	_, ok := db.index.get(key.Bytes())
+	return ok, nil
+}
+
+// Delete deletes the value for the given key. Will not
+// return an error if the key doesn't exist.
+func (db *LogDB) Delete(key *database.Key) error {
+	batch := &batch{}
+	batch.delete(key.Bytes())
+	return db.write(batch)
+}
@@ -0,0 +1,310 @@
+package logdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ammm56/lings/infrastructure/db/database"
+)
+
+func testKey(i int) *database.Key {
+	return database.MakeBucket([]byte("bucket")).Key([]byte(fmt.Sprintf("key%05d", i)))
+}
+
+func testValue(i int, version int) []byte {
+	return []byte(fmt.Sprintf("value%d-%d", i, version))
+}
+
+func openForTest(t *testing.T, path string) *LogDB {
+	db, err := NewLogDB(path)
+	if err != nil {
+		t.Fatalf("NewLogDB: %s", err)
+	}
+	return db
+}
+
+func checkContents(t *testing.T, db *LogDB, entryCount int, version int) {
+	for i := 0; i < entryCount; i++ {
+		value, err := db.Get(testKey(i))
+		if i%2 == 1 {
+			if !errors.Is(err, database.ErrNotFound) {
+				t.Fatalf("expected key %d to be deleted, but got error %v", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Get %d: %s", i, err)
+		}
+		if !bytes.Equal(value, testValue(i, version)) {
+			t.Fatalf("unexpected value for key %d: %s", i, value)
+		}
+	}
+}
+
+// writeForTest writes two versions of every key, and deletes the odd ones
+func writeForTest(t *testing.T, db *LogDB, entryCount int) {
+	for version := 0; version < 2; version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("Begin: %s", err)
+		}
+		for i := 0; i < entryCount; i++ {
+			err := tx.Put(testKey(i), testValue(i, version))
+			if err != nil {
+				t.Fatalf("Put: %s", err)
+			}
+		}
+		err = tx.Commit()
+		if err != nil {
+			t.Fatalf("Commit: %s", err)
+		}
+	}
+	for i := 1; i < entryCount; i += 2 {
+		err := db.Delete(testKey(i))
+		if err != nil {
+			t.Fatalf("Delete: %s", err)
+		}
+	}
+}
+
+func TestReopen(t *testing.T) {
+	path := t.TempDir()
+	const entryCount = 1000
+
+	db := openForTest(t, path)
+	writeForTest(t, db, entryCount)
+	checkContents(t, db, entryCount, 1)
+
+	_, err := NewLogDB(path)
+	if err == nil {
+		t.Fatalf("NewLogDB unexpectedly opened a database that is already in use")
+	}
+
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	db = openForTest(t, path)
+	defer db.Close()
+	checkContents(t, db, entryCount, 1)
+	if db.index.length != entryCount/2 {
+		t.Fatalf("expected %d entries but got %d", entryCount/2, db.index.length)
+	}
+}
+
+func TestTornWrite(t *testing.T) {
+	path := t.TempDir()
+	const entryCount = 100
+
+	db := openForTest(t, path)
+	writeForTest(t, db, entryCount)
+	validSize := db.size
+	err := db.Close()
+	if err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	// Simulate a crash in the middle of appending a record
+	dataLogPath := filepath.Join(path, dataLogFileName)
+	file, err := os.OpenFile(dataLogPath, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatalf("OpenFile: %s", err)
+	}
+	batch := &batch{}
+	batch.put(testKey(0).Bytes(), []byte("lost value"))
+	record, err := batch.record()
+	if err != nil {
+		t.Fatalf("record: %s", err)
+	}
+	_, err = file.Write(record[:len(record)-1])
+	if err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+	file.Close()
+
+	db = openForTest(t, path)
+	defer db.Close()
+	checkContents(t, db, entryCount, 1)
+	if db.size != validSize {
+		t.Fatalf("expected the data log to be truncated to %d bytes, but it's %d bytes", validSize, db.size)
+	}
+
+	// The database must keep working after the torn record was dropped
+	err = db.Put(testKey(0), testValue(0, 1))
+	if err != nil {
+		t.Fatalf("Put: %s", err)
+	}
+	checkContents(t, db, entryCount, 1)
+}
+
+func TestCorruptedRecord(t *testing.T) {
+	path := t.TempDir()
+	const entryCount = 100
+
+	db := openForTest(t, path)
+	writeForTest(t, db, entryCount)
+	validSize := db.size
+	err := db.Close()
+	if err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	dataLogPath := filepath.Join(path, dataLogFileName)
+	contents, err := os.ReadFile(dataLogPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+
+	// A bad checksum in the middle of the log must not cause the records
+	// after it to be dropped
+	corrupted := make([]byte, len(contents))
+	copy(corrupted, contents)
+	corrupted[len(dataLogMagic)+recordHeaderLength] ^= 0xff
+	err = os.WriteFile(dataLogPath, corrupted, 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	_, err = NewLogDB(path)
+	if err == nil {
+		t.Fatalf("NewLogDB unexpectedly opened a corrupted database")
+	}
+	afterOpen, err := os.ReadFile(dataLogPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+	if !bytes.Equal(afterOpen, corrupted) {
+		t.Fatalf("the corrupted data log was modified")
+	}
+
+	// A complete last record with a bad checksum is a torn write
+	batch := &batch{}
+	batch.put(testKey(0).Bytes(), []byte("lost value"))
+	record, err := batch.record()
+	if err != nil {
+		t.Fatalf("record: %s", err)
+	}
+	record[len(record)-1] ^= 0xff
+	err = os.WriteFile(dataLogPath, append(contents, record...), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	db = openForTest(t, path)
+	defer db.Close()
+	checkContents(t, db, entryCount, 1)
+	if db.size != validSize {
+		t.Fatalf("expected the data log to be truncated to %d bytes, but it's %d bytes", validSize, db.size)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	path := t.TempDir()
+	const entryCount = 1000
+
+	db := openForTest(t, path)
+	writeForTest(t, db, entryCount)
+
+	cursor, err := db.Cursor(database.MakeBucket([]byte("bucket")))
+	if err != nil {
+		t.Fatalf("Cursor: %s", err)
+	}
+	defer cursor.Close()
+	if !cursor.First() {
+		t.Fatalf("First unexpectedly returned false")
+	}
+
+	sizeBefore := db.size
+	err = db.Compact()
+	if err != nil {
+		t.Fatalf("Compact: %s", err)
+	}
+	if db.size >= sizeBefore || db.garbage != 0 {
+		t.Fatalf("expected compaction to shrink the data log from %d bytes, but it's %d bytes "+
+			"with %d bytes of garbage", sizeBefore, db.size, db.garbage)
+	}
+	checkContents(t, db, entryCount, 1)
+
+	// Cursors opened before the compaction must keep working
+	count := 1
+	for cursor.Next() {
+		count++
+	}
+	if count != entryCount/2 {
+		t.Fatalf("expected the cursor to iterate over %d entries, but got %d", entryCount/2, count)
+	}
+
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	db = openForTest(t, path)
+	defer db.Close()
+	checkContents(t, db, entryCount, 1)
+}
+
+func TestCursorWithConcurrentDeletes(t *testing.T) {
+	db := openForTest(t, t.TempDir())
+	defer db.Close()
+
+	const entryCount = 100
+	for i := 0; i < entryCount; i++ {
+		err := db.Put(testKey(i), testValue(i, 0))
+		if err != nil {
+			t.Fatalf("Put: %s", err)
+		}
+	}
+
+	cursor, err := db.Cursor(database.MakeBucket([]byte("bucket")))
+	if err != nil {
+		t.Fatalf("Cursor: %s", err)
+	}
+	defer cursor.Close()
+
+	// Delete every entry right after the cursor reached it
+	count := 0
+	for cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			t.Fatalf("Key: %s", err)
+		}
+		err = db.Delete(key)
+		if err != nil {
+			t.Fatalf("Delete: %s", err)
+		}
+		count++
+	}
+	if count != entryCount {
+		t.Fatalf("expected the cursor to iterate over %d entries, but got %d", entryCount, count)
+	}
+}
+
+func BenchmarkCommit(b *testing.B) {
+	db, err := NewLogDB(b.TempDir())
+	if err != nil {
+		b.Fatalf("NewLogDB: %s", err)
+	}
+	defer db.Close()
+
+	value := make([]byte, 200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			b.Fatalf("Begin: %s", err)
+		}
+		for j := 0; j < 100; j++ {
+			err := tx.Put(testKey(i*100+j), value)
+			if err != nil {
+				b.Fatalf("Put: %s", err)
+			}
+		}
+		err = tx.Commit()
+		if err != nil {
+			b.Fatalf("Commit: %s", err)
+		}
+	}
+}
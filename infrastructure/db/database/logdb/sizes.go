@@ -0,0 +1,30 @@
+package logdb
+
+import (
+	"bytes"
+
+	"github.com/ammm56/lings/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// BucketSizes returns the disk space, in bytes, that is taken by the latest
+// values of the entries of each of the given buckets, along with their keys.
+// Overwritten values that weren't compacted yet aren't counted.
+func (db *LogDB) BucketSizes(buckets []*database.Bucket) ([]uint64, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	if db.isClosed {
+		return nil, errors.New("cannot get the bucket sizes of a closed database")
+	}
+
+	sizes := make([]uint64, len(buckets))
+	for i, bucket := range buckets {
+		prefix := bucket.Path()
+		node := db.index.findGreaterOrEqual(prefix, nil)
+		for ; node != nil && bytes.HasPrefix(node.key, prefix); node = node.next[0] {
+			sizes[i] += uint64(len(node.key)) + uint64(node.location.length)
+		}
+	}
+	return sizes, nil
+}
@@ -0,0 +1,9 @@
+package database
+
+// BucketSizer is implemented by databases that can report how much disk space
+// the entries of a bucket take.
+type BucketSizer interface {
+	// BucketSizes returns the approximate disk space, in bytes, that is
+	// taken by the entries of each of the given buckets.
+	BucketSizes(buckets []*Bucket) ([]uint64, error)
+}
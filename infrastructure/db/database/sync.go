@@ -0,0 +1,9 @@
+package database
+
+// WriteSyncer is implemented by databases that can be switched between
+// synced and unsynced writes while they're in use.
+type WriteSyncer interface {
+	// SetSyncWrites sets whether every write is synced to disk before
+	// it returns.
+	SetSyncWrites(syncWrites bool) error
+}
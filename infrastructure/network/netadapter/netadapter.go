@@ -38,11 +38,18 @@ type NetAdapter struct {
 // NewNetAdapter creates and starts a new NetAdapter on the
 // given listeningPort
 func NewNetAdapter(cfg *config.Config) (*NetAdapter, error) {
-	netAdapterID, err := id.GenerateID()
+	p2pServer, err := grpcserver.NewP2PServer(cfg.Listeners)
 	if err != nil {
 		return nil, err
 	}
-	p2pServer, err := grpcserver.NewP2PServer(cfg.Listeners)
+	return NewNetAdapterWithP2PServer(cfg, p2pServer)
+}
+
+// NewNetAdapterWithP2PServer creates a new NetAdapter that uses the given
+// p2p server instead of listening on the configured addresses. This allows
+// tools such as network simulators to replace the transport layer.
+func NewNetAdapterWithP2PServer(cfg *config.Config, p2pServer server.P2PServer) (*NetAdapter, error) {
+	netAdapterID, err := id.GenerateID()
 	if err != nil {
 		return nil, err
 	}
@@ -172,6 +179,13 @@ func (na *NetAdapter) ID() *id.ID {
 	return na.id
 }
 
+// P2PSend sends the given `message` to the peer corresponding to the
+// given netConnection. Unlike P2PBroadcast, it returns router.ErrRouteClosed
+// if the peer has disconnected.
+func (na *NetAdapter) P2PSend(netConnection *NetConnection, message appmessage.Message) error {
+	return netConnection.router.OutgoingRoute().Enqueue(message)
+}
+
 // P2PBroadcast sends the given `message` to every peer corresponding
 // to each NetConnection in the given netConnections
 func (na *NetAdapter) P2PBroadcast(netConnections []*NetConnection, message appmessage.Message) error {
@@ -0,0 +1,38 @@
+package protowire
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func FuzzToAppMessage(f *testing.F) {
+	// Seed the corpus with an empty payload of every type, so that the fuzzer
+	// reaches every converter right from the start
+	payloadFields := (&LingsMessage{}).ProtoReflect().Descriptor().Oneofs().ByName("payload").Fields()
+	for i := 0; i < payloadFields.Len(); i++ {
+		message := (&LingsMessage{}).ProtoReflect()
+		field := payloadFields.Get(i)
+		message.Set(field, message.NewField(field))
+		data, err := proto.Marshal(message.Interface())
+		if err != nil {
+			f.Fatalf("Marshal: %s", err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protoMessage := &LingsMessage{}
+		err := proto.Unmarshal(data, protoMessage)
+		if err != nil {
+			return
+		}
+		appMessage, err := protoMessage.ToAppMessage()
+		if err != nil {
+			return
+		}
+
+		// Converting back may legitimately fail, but it must not panic
+		_, _ = FromAppMessage(appMessage)
+	})
+}
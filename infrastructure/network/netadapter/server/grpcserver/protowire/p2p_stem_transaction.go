@@ -0,0 +1,23 @@
+package protowire
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *LingsMessage_StemTransaction) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_StemTransaction is nil")
+	}
+	msgTx, err := x.StemTransaction.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.MsgStemTransaction{MsgTx: msgTx.(*appmessage.MsgTx)}, nil
+}
+
+func (x *LingsMessage_StemTransaction) fromAppMessage(msgStemTransaction *appmessage.MsgStemTransaction) error {
+	x.StemTransaction = new(TransactionMessage)
+	x.StemTransaction.fromAppMessage(msgStemTransaction.MsgTx)
+	return nil
+}
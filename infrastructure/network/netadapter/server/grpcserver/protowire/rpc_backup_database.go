@@ -0,0 +1,70 @@
+package protowire
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *LingsMessage_BackupDatabaseRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_BackupDatabaseRequest is nil")
+	}
+	return x.BackupDatabaseRequest.toAppMessage()
+}
+
+func (x *LingsMessage_BackupDatabaseRequest) fromAppMessage(message *appmessage.BackupDatabaseRequestMessage) error {
+	x.BackupDatabaseRequest = &BackupDatabaseRequestMessage{
+		Path: message.Path,
+	}
+	return nil
+}
+
+func (x *BackupDatabaseRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "BackupDatabaseRequestMessage is nil")
+	}
+	return &appmessage.BackupDatabaseRequestMessage{
+		Path: x.Path,
+	}, nil
+}
+
+func (x *LingsMessage_BackupDatabaseResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_BackupDatabaseResponse is nil")
+	}
+	return x.BackupDatabaseResponse.toAppMessage()
+}
+
+func (x *LingsMessage_BackupDatabaseResponse) fromAppMessage(message *appmessage.BackupDatabaseResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.BackupDatabaseResponse = &BackupDatabaseResponseMessage{
+		EntryCount: message.EntryCount,
+		Size:       message.Size,
+		Checksum:   message.Checksum,
+
+		Error: err,
+	}
+	return nil
+}
+
+func (x *BackupDatabaseResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "BackupDatabaseResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	return &appmessage.BackupDatabaseResponseMessage{
+		EntryCount: x.EntryCount,
+		Size:       x.Size,
+		Checksum:   x.Checksum,
+
+		Error: rpcErr,
+	}, nil
+}
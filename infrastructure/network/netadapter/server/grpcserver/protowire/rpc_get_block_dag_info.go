@@ -37,16 +37,20 @@ func (x *GetBlockDagInfoResponseMessage) toAppMessage() (appmessage.Message, err
 		return nil, errors.New("GetBlockDagInfoResponseMessage contains both an error and a response")
 	}
 	return &appmessage.GetBlockDAGInfoResponseMessage{
-		NetworkName:         x.NetworkName,
-		BlockCount:          x.BlockCount,
-		HeaderCount:         x.HeaderCount,
-		TipHashes:           x.TipHashes,
-		VirtualParentHashes: x.VirtualParentHashes,
-		Difficulty:          x.Difficulty,
-		PastMedianTime:      x.PastMedianTime,
-		PruningPointHash:    x.PruningPointHash,
-		VirtualDAAScore:     x.VirtualDaaScore,
-		Error:               rpcErr,
+		NetworkName:              x.NetworkName,
+		BlockCount:               x.BlockCount,
+		HeaderCount:              x.HeaderCount,
+		TipHashes:                x.TipHashes,
+		VirtualParentHashes:      x.VirtualParentHashes,
+		Difficulty:               x.Difficulty,
+		PastMedianTime:           x.PastMedianTime,
+		PruningPointHash:         x.PruningPointHash,
+		VirtualDAAScore:          x.VirtualDaaScore,
+		DevFeeAddress:            x.DevFeeAddress,
+		DevFeePercent:            x.DevFeePercent,
+		DevFeeActivationDAAScore: x.DevFeeActivationDaaScore,
+		DevFeeExpiryDAAScore:     x.DevFeeExpiryDaaScore,
+		Error:                    rpcErr,
 	}, nil
 }
 
@@ -56,16 +60,20 @@ func (x *LingsMessage_GetBlockDagInfoResponse) fromAppMessage(message *appmessag
 		err = &RPCError{Message: message.Error.Message}
 	}
 	x.GetBlockDagInfoResponse = &GetBlockDagInfoResponseMessage{
-		NetworkName:         message.NetworkName,
-		BlockCount:          message.BlockCount,
-		HeaderCount:         message.HeaderCount,
-		TipHashes:           message.TipHashes,
-		VirtualParentHashes: message.VirtualParentHashes,
-		Difficulty:          message.Difficulty,
-		PastMedianTime:      message.PastMedianTime,
-		PruningPointHash:    message.PruningPointHash,
-		VirtualDaaScore:     message.VirtualDAAScore,
-		Error:               err,
+		NetworkName:              message.NetworkName,
+		BlockCount:               message.BlockCount,
+		HeaderCount:              message.HeaderCount,
+		TipHashes:                message.TipHashes,
+		VirtualParentHashes:      message.VirtualParentHashes,
+		Difficulty:               message.Difficulty,
+		PastMedianTime:           message.PastMedianTime,
+		PruningPointHash:         message.PruningPointHash,
+		VirtualDaaScore:          message.VirtualDAAScore,
+		DevFeeAddress:            message.DevFeeAddress,
+		DevFeePercent:            message.DevFeePercent,
+		DevFeeActivationDaaScore: message.DevFeeActivationDAAScore,
+		DevFeeExpiryDaaScore:     message.DevFeeExpiryDAAScore,
+		Error:                    err,
 	}
 	return nil
 }
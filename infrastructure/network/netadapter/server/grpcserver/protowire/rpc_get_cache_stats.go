@@ -0,0 +1,94 @@
+package protowire
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *LingsMessage_GetCacheStatsRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_GetCacheStatsRequest is nil")
+	}
+	return x.GetCacheStatsRequest.toAppMessage()
+}
+
+func (x *LingsMessage_GetCacheStatsRequest) fromAppMessage(message *appmessage.GetCacheStatsRequestMessage) error {
+	x.GetCacheStatsRequest = &GetCacheStatsRequestMessage{}
+	return nil
+}
+
+func (x *GetCacheStatsRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetCacheStatsRequestMessage is nil")
+	}
+	return &appmessage.GetCacheStatsRequestMessage{}, nil
+}
+
+func (x *LingsMessage_GetCacheStatsResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_GetCacheStatsResponse is nil")
+	}
+	return x.GetCacheStatsResponse.toAppMessage()
+}
+
+func (x *LingsMessage_GetCacheStatsResponse) fromAppMessage(message *appmessage.GetCacheStatsResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	cacheStats := make([]*CacheStats, len(message.CacheStats))
+	for i, stats := range message.CacheStats {
+		cacheStats[i] = &CacheStats{
+			Name:     stats.Name,
+			Entries:  stats.Entries,
+			Capacity: stats.Capacity,
+			Hits:     stats.Hits,
+			Misses:   stats.Misses,
+		}
+	}
+	x.GetCacheStatsResponse = &GetCacheStatsResponseMessage{
+		MemoryBudgetMiB: message.MemoryBudgetMiB,
+		CacheStats:      cacheStats,
+		Error:           err,
+	}
+	return nil
+}
+
+func (x *GetCacheStatsResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetCacheStatsResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	cacheStats := make([]*appmessage.CacheStats, len(x.CacheStats))
+	for i, stats := range x.CacheStats {
+		appStats, err := stats.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		cacheStats[i] = appStats
+	}
+
+	return &appmessage.GetCacheStatsResponseMessage{
+		MemoryBudgetMiB: x.MemoryBudgetMiB,
+		CacheStats:      cacheStats,
+		Error:           rpcErr,
+	}, nil
+}
+
+func (x *CacheStats) toAppMessage() (*appmessage.CacheStats, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "CacheStats is nil")
+	}
+	return &appmessage.CacheStats{
+		Name:     x.Name,
+		Entries:  x.Entries,
+		Capacity: x.Capacity,
+		Hits:     x.Hits,
+		Misses:   x.Misses,
+	}, nil
+}
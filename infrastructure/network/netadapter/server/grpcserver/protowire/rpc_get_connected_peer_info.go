@@ -37,6 +37,8 @@ func (x *LingsMessage_GetConnectedPeerInfoResponse) fromAppMessage(message *appm
 			AdvertisedProtocolVersion: info.AdvertisedProtocolVersion,
 			TimeConnected:             info.TimeConnected,
 			IsIbdPeer:                 info.IsIBDPeer,
+			Services:                  info.Services,
+			NegotiatedFeatures:        info.NegotiatedFeatures,
 		}
 	}
 	x.GetConnectedPeerInfoResponse = &GetConnectedPeerInfoResponseMessage{
@@ -88,5 +90,7 @@ func (x *GetConnectedPeerInfoMessage) toAppMessage() (*appmessage.GetConnectedPe
 		AdvertisedProtocolVersion: x.AdvertisedProtocolVersion,
 		TimeConnected:             x.TimeOffset,
 		IsIBDPeer:                 x.IsIbdPeer,
+		Services:                  x.Services,
+		NegotiatedFeatures:        x.NegotiatedFeatures,
 	}, nil
 }
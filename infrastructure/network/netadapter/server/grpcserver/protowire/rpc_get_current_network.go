@@ -6,10 +6,14 @@ import (
 )
 
 func (x *LingsMessage_GetCurrentNetworkRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_GetCurrentNetworkRequest is nil")
+	}
 	return &appmessage.GetCurrentNetworkRequestMessage{}, nil
 }
 
 func (x *LingsMessage_GetCurrentNetworkRequest) fromAppMessage(_ *appmessage.GetCurrentNetworkRequestMessage) error {
+	x.GetCurrentNetworkRequest = &GetCurrentNetworkRequestMessage{}
 	return nil
 }
 
@@ -17,7 +21,7 @@ func (x *LingsMessage_GetCurrentNetworkResponse) toAppMessage() (appmessage.Mess
 	if x == nil {
 		return nil, errors.Wrapf(errorNil, "LingsMessage_GetCurrentNetworkResponse is nil")
 	}
-	return x.toAppMessage()
+	return x.GetCurrentNetworkResponse.toAppMessage()
 }
 
 func (x *LingsMessage_GetCurrentNetworkResponse) fromAppMessage(message *appmessage.GetCurrentNetworkResponseMessage) error {
@@ -0,0 +1,71 @@
+package protowire
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *LingsMessage_GetDagGraphRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_GetDagGraphRequest is nil")
+	}
+	return x.GetDagGraphRequest.toAppMessage()
+}
+
+func (x *LingsMessage_GetDagGraphRequest) fromAppMessage(message *appmessage.GetDAGGraphRequestMessage) error {
+	x.GetDagGraphRequest = &GetDagGraphRequestMessage{
+		LowHash:    message.LowHash,
+		HighHash:   message.HighHash,
+		BlockCount: message.BlockCount,
+		Format:     message.Format,
+	}
+	return nil
+}
+
+func (x *GetDagGraphRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetDagGraphRequestMessage is nil")
+	}
+	return &appmessage.GetDAGGraphRequestMessage{
+		LowHash:    x.LowHash,
+		HighHash:   x.HighHash,
+		BlockCount: x.BlockCount,
+		Format:     x.Format,
+	}, nil
+}
+
+func (x *LingsMessage_GetDagGraphResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_GetDagGraphResponse is nil")
+	}
+	return x.GetDagGraphResponse.toAppMessage()
+}
+
+func (x *LingsMessage_GetDagGraphResponse) fromAppMessage(message *appmessage.GetDAGGraphResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.GetDagGraphResponse = &GetDagGraphResponseMessage{
+		Graph:      message.Graph,
+		BlockCount: message.BlockCount,
+		Error:      err,
+	}
+	return nil
+}
+
+func (x *GetDagGraphResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetDagGraphResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.GetDAGGraphResponseMessage{
+		Graph:      x.Graph,
+		BlockCount: x.BlockCount,
+		Error:      rpcErr,
+	}, nil
+}
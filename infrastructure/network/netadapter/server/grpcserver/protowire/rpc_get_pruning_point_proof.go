@@ -0,0 +1,94 @@
+package protowire
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *LingsMessage_GetPruningPointProofRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_GetPruningPointProofRequest is nil")
+	}
+	return &appmessage.GetPruningPointProofRequestMessage{}, nil
+}
+
+func (x *LingsMessage_GetPruningPointProofRequest) fromAppMessage(_ *appmessage.GetPruningPointProofRequestMessage) error {
+	x.GetPruningPointProofRequest = &GetPruningPointProofRequestMessage{}
+	return nil
+}
+
+func (x *LingsMessage_GetPruningPointProofResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_GetPruningPointProofResponse is nil")
+	}
+	return x.GetPruningPointProofResponse.toAppMessage()
+}
+
+func (x *LingsMessage_GetPruningPointProofResponse) fromAppMessage(message *appmessage.GetPruningPointProofResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	levels := make([]*RpcPruningPointProofLevel, len(message.Levels))
+	for i, level := range message.Levels {
+		levels[i] = &RpcPruningPointProofLevel{}
+		levels[i].fromAppMessage(level)
+	}
+	x.GetPruningPointProofResponse = &GetPruningPointProofResponseMessage{
+		PruningPointHash: message.PruningPointHash,
+		Levels:           levels,
+		Error:            err,
+	}
+	return nil
+}
+
+func (x *GetPruningPointProofResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetPruningPointProofResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	levels := make([]*appmessage.RPCPruningPointProofLevel, len(x.Levels))
+	for i, level := range x.Levels {
+		levels[i], err = level.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &appmessage.GetPruningPointProofResponseMessage{
+		PruningPointHash: x.PruningPointHash,
+		Levels:           levels,
+		Error:            rpcErr,
+	}, nil
+}
+
+func (x *RpcPruningPointProofLevel) toAppMessage() (*appmessage.RPCPruningPointProofLevel, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "RpcPruningPointProofLevel is nil")
+	}
+	headers := make([]*appmessage.RPCBlockHeader, len(x.Headers))
+	for i, header := range x.Headers {
+		var err error
+		headers[i], err = header.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &appmessage.RPCPruningPointProofLevel{
+		Headers: headers,
+	}, nil
+}
+
+func (x *RpcPruningPointProofLevel) fromAppMessage(message *appmessage.RPCPruningPointProofLevel) {
+	headers := make([]*RpcBlockHeader, len(message.Headers))
+	for i, header := range message.Headers {
+		headers[i] = &RpcBlockHeader{}
+		headers[i].fromAppMessage(header)
+	}
+	*x = RpcPruningPointProofLevel{
+		Headers: headers,
+	}
+}
@@ -29,6 +29,8 @@ func (x *LingsMessage_GetCoinSupplyResponse) fromAppMessage(message *appmessage.
 	x.GetCoinSupplyResponse = &GetCoinSupplyResponseMessage{
 		MaxSompi:         message.MaxSompi,
 		CirculatingSompi: message.CirculatingSompi,
+		DevFeeAddress:    message.DevFeeAddress,
+		DevFeePercent:    message.DevFeePercent,
 
 		Error: err,
 	}
@@ -48,6 +50,8 @@ func (x *GetCoinSupplyResponseMessage) toAppMessage() (appmessage.Message, error
 	return &appmessage.GetCoinSupplyResponseMessage{
 		MaxSompi:         x.MaxSompi,
 		CirculatingSompi: x.CirculatingSompi,
+		DevFeeAddress:    x.DevFeeAddress,
+		DevFeePercent:    x.DevFeePercent,
 
 		Error: rpcErr,
 	}, nil
@@ -0,0 +1,62 @@
+package protowire
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *LingsMessage_SetDatabaseOptionsRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_SetDatabaseOptionsRequest is nil")
+	}
+	return x.SetDatabaseOptionsRequest.toAppMessage()
+}
+
+func (x *LingsMessage_SetDatabaseOptionsRequest) fromAppMessage(message *appmessage.SetDatabaseOptionsRequestMessage) error {
+	x.SetDatabaseOptionsRequest = &SetDatabaseOptionsRequestMessage{
+		SyncWrites: message.SyncWrites,
+	}
+	return nil
+}
+
+func (x *SetDatabaseOptionsRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SetDatabaseOptionsRequestMessage is nil")
+	}
+	return &appmessage.SetDatabaseOptionsRequestMessage{
+		SyncWrites: x.SyncWrites,
+	}, nil
+}
+
+func (x *LingsMessage_SetDatabaseOptionsResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_SetDatabaseOptionsResponse is nil")
+	}
+	return x.SetDatabaseOptionsResponse.toAppMessage()
+}
+
+func (x *LingsMessage_SetDatabaseOptionsResponse) fromAppMessage(message *appmessage.SetDatabaseOptionsResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.SetDatabaseOptionsResponse = &SetDatabaseOptionsResponseMessage{
+		Error: err,
+	}
+	return nil
+}
+
+func (x *SetDatabaseOptionsResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SetDatabaseOptionsResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	return &appmessage.SetDatabaseOptionsResponseMessage{
+		Error: rpcErr,
+	}, nil
+}
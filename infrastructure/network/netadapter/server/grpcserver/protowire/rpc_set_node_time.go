@@ -0,0 +1,69 @@
+package protowire
+
+import (
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *LingsMessage_SetNodeTimeRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_SetNodeTimeRequest is nil")
+	}
+	return x.SetNodeTimeRequest.toAppMessage()
+}
+
+func (x *LingsMessage_SetNodeTimeRequest) fromAppMessage(message *appmessage.SetNodeTimeRequestMessage) error {
+	x.SetNodeTimeRequest = &SetNodeTimeRequestMessage{
+		TimeInMilliseconds:  message.TimeInMilliseconds,
+		AdvanceMilliseconds: message.AdvanceMilliseconds,
+		Reset_:              message.Reset,
+	}
+	return nil
+}
+
+func (x *SetNodeTimeRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SetNodeTimeRequestMessage is nil")
+	}
+	return &appmessage.SetNodeTimeRequestMessage{
+		TimeInMilliseconds:  x.TimeInMilliseconds,
+		AdvanceMilliseconds: x.AdvanceMilliseconds,
+		Reset:               x.Reset_,
+	}, nil
+}
+
+func (x *LingsMessage_SetNodeTimeResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "LingsMessage_SetNodeTimeResponse is nil")
+	}
+	return x.SetNodeTimeResponse.toAppMessage()
+}
+
+func (x *LingsMessage_SetNodeTimeResponse) fromAppMessage(message *appmessage.SetNodeTimeResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.SetNodeTimeResponse = &SetNodeTimeResponseMessage{
+		TimeInMilliseconds:   message.TimeInMilliseconds,
+		OffsetInMilliseconds: message.OffsetInMilliseconds,
+		Error:                err,
+	}
+	return nil
+}
+
+func (x *SetNodeTimeResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SetNodeTimeResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.SetNodeTimeResponseMessage{
+		TimeInMilliseconds:   x.TimeInMilliseconds,
+		OffsetInMilliseconds: x.OffsetInMilliseconds,
+		Error:                rpcErr,
+	}, nil
+}
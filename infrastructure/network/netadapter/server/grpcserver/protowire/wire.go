@@ -359,6 +359,13 @@ func toP2PPayload(message appmessage.Message) (isLingsMessage_Payload, error) {
 			return nil, err
 		}
 		return payload, nil
+	case *appmessage.MsgStemTransaction:
+		payload := new(LingsMessage_StemTransaction)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
 	default:
 		return nil, nil
 	}
@@ -968,6 +975,104 @@ func toRPCPayload(message appmessage.Message) (isLingsMessage_Payload, error) {
 			return nil, err
 		}
 		return payload, nil
+	case *appmessage.BackupDatabaseRequestMessage:
+		payload := new(LingsMessage_BackupDatabaseRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.BackupDatabaseResponseMessage:
+		payload := new(LingsMessage_BackupDatabaseResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.SetDatabaseOptionsRequestMessage:
+		payload := new(LingsMessage_SetDatabaseOptionsRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.SetDatabaseOptionsResponseMessage:
+		payload := new(LingsMessage_SetDatabaseOptionsResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetCacheStatsRequestMessage:
+		payload := new(LingsMessage_GetCacheStatsRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetCacheStatsResponseMessage:
+		payload := new(LingsMessage_GetCacheStatsResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GenerateBlocksRequestMessage:
+		payload := new(LingsMessage_GenerateBlocksRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GenerateBlocksResponseMessage:
+		payload := new(LingsMessage_GenerateBlocksResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.SetNodeTimeRequestMessage:
+		payload := new(LingsMessage_SetNodeTimeRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.SetNodeTimeResponseMessage:
+		payload := new(LingsMessage_SetNodeTimeResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetDAGGraphRequestMessage:
+		payload := new(LingsMessage_GetDagGraphRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetDAGGraphResponseMessage:
+		payload := new(LingsMessage_GetDagGraphResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetPruningPointProofRequestMessage:
+		payload := new(LingsMessage_GetPruningPointProofRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetPruningPointProofResponseMessage:
+		payload := new(LingsMessage_GetPruningPointProofResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
 	default:
 		return nil, nil
 	}
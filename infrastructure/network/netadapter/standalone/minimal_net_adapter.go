@@ -65,8 +65,22 @@ func (mna *MinimalNetAdapter) Connect(address string) (*Routes, error) {
 		return nil, err
 	}
 
+	return mna.handleNewConnection()
+}
+
+// Accept waits for a peer to connect to one of the listeners given in the
+// config, handles handshake, and returns the routes for this connection.
+// The returned routes are the same as the ones returned by Connect.
+func (mna *MinimalNetAdapter) Accept() (*Routes, error) {
+	mna.lock.Lock()
+	defer mna.lock.Unlock()
+
+	return mna.handleNewConnection()
+}
+
+func (mna *MinimalNetAdapter) handleNewConnection() (*Routes, error) {
 	routes := <-mna.routesChan
-	err = mna.handleHandshake(routes, mna.netAdapter.ID())
+	err := mna.handleHandshake(routes, mna.netAdapter.ID())
 	if err != nil {
 		return nil, errors.Wrap(err, "Error in handshake")
 	}
@@ -81,6 +95,12 @@ func (mna *MinimalNetAdapter) Connect(address string) (*Routes, error) {
 	return routes, nil
 }
 
+// Stop stops the underlying net adapter, closing all of its connections
+// and listeners
+func (mna *MinimalNetAdapter) Stop() error {
+	return mna.netAdapter.Stop()
+}
+
 // handlePingPong makes sure that we are not disconnected due to not responding to pings.
 // However, it only responds to pings, not sending its own, to conform to the minimal-ness
 // of MinimalNetAdapter
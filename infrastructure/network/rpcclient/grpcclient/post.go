@@ -62,3 +62,24 @@ func (c *GRPCClient) Post(request *protowire.LingsMessage) (*protowire.LingsMess
 	}
 	return response, nil
 }
+
+// Send sends the given request to the RPC server without waiting for
+// its response. The response, as well as any notification it subscribes
+// to, should be read with Receive.
+func (c *GRPCClient) Send(request *protowire.LingsMessage) error {
+	err := c.stream.Send(request)
+	if err != nil {
+		return errors.Wrapf(err, "error sending the request to the RPC server")
+	}
+	return nil
+}
+
+// Receive waits for the next message from the RPC server, which is
+// either a response or a notification
+func (c *GRPCClient) Receive() (*protowire.LingsMessage, error) {
+	message, err := c.stream.Recv()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error receiving a message from the RPC server")
+	}
+	return message, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/ammm56/lings/app/appmessage"
+
+// GenerateBlocks sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GenerateBlocks(count uint32, payAddress string, parentHashes []string) (*appmessage.GenerateBlocksResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGenerateBlocksRequestMessage(count, payAddress, parentHashes))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGenerateBlocksResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	generateBlocksResponse := response.(*appmessage.GenerateBlocksResponseMessage)
+	if generateBlocksResponse.Error != nil {
+		return nil, c.convertRPCError(generateBlocksResponse.Error)
+	}
+	return generateBlocksResponse, nil
+}
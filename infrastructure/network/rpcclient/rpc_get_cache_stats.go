@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/ammm56/lings/app/appmessage"
+
+// GetCacheStats sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetCacheStats() (*appmessage.GetCacheStatsResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetCacheStatsRequestMessage())
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetCacheStatsResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getCacheStatsResponse := response.(*appmessage.GetCacheStatsResponseMessage)
+	if getCacheStatsResponse.Error != nil {
+		return nil, c.convertRPCError(getCacheStatsResponse.Error)
+	}
+	return getCacheStatsResponse, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/ammm56/lings/app/appmessage"
+
+// GetDAGGraph sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetDAGGraph(lowHash string, highHash string, blockCount uint32, format string) (*appmessage.GetDAGGraphResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetDAGGraphRequestMessage(lowHash, highHash, blockCount, format))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetDAGGraphResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getDAGGraphResponse := response.(*appmessage.GetDAGGraphResponseMessage)
+	if getDAGGraphResponse.Error != nil {
+		return nil, c.convertRPCError(getDAGGraphResponse.Error)
+	}
+	return getDAGGraphResponse, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/ammm56/lings/app/appmessage"
+
+// GetPruningPointProof sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetPruningPointProof() (*appmessage.GetPruningPointProofResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetPruningPointProofRequestMessage())
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetPruningPointProofResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getPruningPointProofResponse := response.(*appmessage.GetPruningPointProofResponseMessage)
+	if getPruningPointProofResponse.Error != nil {
+		return nil, c.convertRPCError(getPruningPointProofResponse.Error)
+	}
+	return getPruningPointProofResponse, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/ammm56/lings/app/appmessage"
+
+// SetDatabaseOptions sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) SetDatabaseOptions(syncWrites bool) (*appmessage.SetDatabaseOptionsResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewSetDatabaseOptionsRequestMessage(syncWrites))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdSetDatabaseOptionsResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	setDatabaseOptionsResponse := response.(*appmessage.SetDatabaseOptionsResponseMessage)
+	if setDatabaseOptionsResponse.Error != nil {
+		return nil, c.convertRPCError(setDatabaseOptionsResponse.Error)
+	}
+	return setDatabaseOptionsResponse, nil
+}
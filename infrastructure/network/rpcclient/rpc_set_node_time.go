@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/ammm56/lings/app/appmessage"
+
+// SetNodeTime sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) SetNodeTime(timeInMilliseconds int64, advanceMilliseconds int64, reset bool) (*appmessage.SetNodeTimeResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewSetNodeTimeRequestMessage(timeInMilliseconds, advanceMilliseconds, reset))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdSetNodeTimeResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	setNodeTimeResponse := response.(*appmessage.SetNodeTimeResponseMessage)
+	if setNodeTimeResponse.Error != nil {
+		return nil, c.convertRPCError(setNodeTimeResponse.Error)
+	}
+	return setNodeTimeResponse, nil
+}
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"testing"
+)
+
+func TestGenerateBlocks(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	const blockCount = 10
+	response, err := harness.rpcClient.GenerateBlocks(blockCount, harness.miningAddress, nil)
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %s", err)
+	}
+	if len(response.BlockHashes) != blockCount {
+		t.Fatalf("expected %d generated blocks, got %d", blockCount, len(response.BlockHashes))
+	}
+	dagInfo, err := harness.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		t.Fatalf("GetBlockDAGInfo: %s", err)
+	}
+	if dagInfo.BlockCount != blockCount+1 {
+		t.Fatalf("expected %d blocks including the genesis, got %d", blockCount+1, dagInfo.BlockCount)
+	}
+	if len(dagInfo.TipHashes) != 1 || dagInfo.TipHashes[0] != response.BlockHashes[blockCount-1] {
+		t.Fatalf("expected the last generated block to be the only tip, got %v", dagInfo.TipHashes)
+	}
+
+	// Generate a side chain from the genesis, in the anticone of the existing blocks
+	sideChainResponse, err := harness.rpcClient.GenerateBlocks(2, harness.miningAddress, []string{dagInfo.PruningPointHash})
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %s", err)
+	}
+	dagInfo, err = harness.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		t.Fatalf("GetBlockDAGInfo: %s", err)
+	}
+	if len(dagInfo.TipHashes) != 2 {
+		t.Fatalf("expected 2 tips, got %v", dagInfo.TipHashes)
+	}
+
+	// Merge both chains
+	_, err = harness.rpcClient.GenerateBlocks(1, harness.miningAddress,
+		[]string{response.BlockHashes[blockCount-1], sideChainResponse.BlockHashes[1]})
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %s", err)
+	}
+	dagInfo, err = harness.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		t.Fatalf("GetBlockDAGInfo: %s", err)
+	}
+	if len(dagInfo.TipHashes) != 1 {
+		t.Fatalf("expected the chains to be merged, got tips %v", dagInfo.TipHashes)
+	}
+
+	_, err = harness.rpcClient.GenerateBlocks(0, harness.miningAddress, nil)
+	if err == nil {
+		t.Fatalf("expected an error when generating 0 blocks")
+	}
+}
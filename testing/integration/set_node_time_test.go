@@ -0,0 +1,252 @@
+package integration
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/constants"
+	"github.com/ammm56/lings/domain/consensus/utils/testutils"
+	"github.com/ammm56/lings/domain/consensus/utils/transactionid"
+	"github.com/ammm56/lings/domain/consensus/utils/txscript"
+	"github.com/ammm56/lings/domain/consensus/utils/utxo"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/kaspanet/go-secp256k1"
+)
+
+func TestSetNodeTime(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	response, err := harness.rpcClient.SetNodeTime(0, 0, false)
+	if err != nil {
+		t.Fatalf("SetNodeTime: %s", err)
+	}
+	if response.OffsetInMilliseconds != 0 {
+		t.Fatalf("expected no offset before the node time is changed, got %d", response.OffsetInMilliseconds)
+	}
+
+	const advance = 24 * time.Hour
+	response, err = harness.rpcClient.SetNodeTime(0, advance.Milliseconds(), false)
+	if err != nil {
+		t.Fatalf("SetNodeTime: %s", err)
+	}
+	if response.OffsetInMilliseconds != advance.Milliseconds() {
+		t.Fatalf("expected an offset of %d, got %d", advance.Milliseconds(), response.OffsetInMilliseconds)
+	}
+
+	// Blocks generated after the change are timestamped with the new time
+	generateResponse, err := harness.rpcClient.GenerateBlocks(1, harness.miningAddress, nil)
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %s", err)
+	}
+	blockResponse, err := harness.rpcClient.GetBlock(generateResponse.BlockHashes[0], false)
+	if err != nil {
+		t.Fatalf("GetBlock: %s", err)
+	}
+	if blockResponse.Block.Header.Timestamp < response.TimeInMilliseconds {
+		t.Fatalf("expected the block timestamp to be at least %d, got %d",
+			response.TimeInMilliseconds, blockResponse.Block.Header.Timestamp)
+	}
+
+	response, err = harness.rpcClient.SetNodeTime(0, 0, true)
+	if err != nil {
+		t.Fatalf("SetNodeTime: %s", err)
+	}
+	if response.OffsetInMilliseconds != 0 {
+		t.Fatalf("expected no offset after a reset, got %d", response.OffsetInMilliseconds)
+	}
+
+	_, err = harness.rpcClient.SetNodeTime(response.TimeInMilliseconds, advance.Milliseconds(), false)
+	if err == nil {
+		t.Fatalf("expected an error when both setting and advancing the node time")
+	}
+}
+
+// TestSetNodeTimeCheckLockTimeVerify reproduces TestCheckLockTimeVerifyConditionedByAbsoluteTime
+// over RPC: an output locked by CLTV until an absolute time becomes spendable once the node time
+// is advanced past it, without waiting in real time.
+func TestSetNodeTimeCheckLockTimeVerify(t *testing.T) {
+	overrideDAGParams := dagconfig.SimnetParams
+	overrideDAGParams.BlockCoinbaseMaturity = 0
+
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+		utxoIndex:               true,
+		overrideDAGParams:       &overrideDAGParams,
+	})
+	defer teardown()
+
+	_, err := harness.rpcClient.GenerateBlocks(2, harness.miningAddress, nil)
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %s", err)
+	}
+	utxosResponse, err := harness.rpcClient.GetUTXOsByAddresses([]string{harness.miningAddress})
+	if err != nil {
+		t.Fatalf("GetUTXOsByAddresses: %s", err)
+	}
+	if len(utxosResponse.Entries) == 0 {
+		t.Fatalf("expected the generated blocks to pay the mining address")
+	}
+
+	nodeTime, err := harness.rpcClient.SetNodeTime(0, 0, false)
+	if err != nil {
+		t.Fatalf("SetNodeTime: %s", err)
+	}
+	lockTimeTarget := uint64(nodeTime.TimeInMilliseconds + time.Hour.Milliseconds())
+	redeemScript, err := createScriptCLTV(lockTimeTarget)
+	if err != nil {
+		t.Fatalf("createScriptCLTV: %s", err)
+	}
+
+	lockingTransaction, lockingTransactionID := buildCLTVLockingTransaction(t, harness, utxosResponse.Entries[0], redeemScript)
+	_, err = harness.rpcClient.SubmitTransaction(lockingTransaction, lockingTransactionID, false)
+	if err != nil {
+		t.Fatalf("SubmitTransaction: %s", err)
+	}
+	_, err = harness.rpcClient.GenerateBlocks(1, harness.miningAddress, nil)
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %s", err)
+	}
+
+	spendingTransaction, spendingTransactionID := buildCLTVSpendingTransaction(t, lockingTransaction, redeemScript, lockTimeTarget)
+	_, err = harness.rpcClient.SubmitTransaction(spendingTransaction, spendingTransactionID, false)
+	if err == nil || !strings.Contains(err.Error(), "ErrUnfinalizedTx") {
+		t.Fatalf("expected the spending transaction to be rejected with ErrUnfinalizedTx, got: %v", err)
+	}
+
+	// Move past the lock time, and generate enough blocks for the past median time to follow
+	_, err = harness.rpcClient.SetNodeTime(0, 2*time.Hour.Milliseconds(), false)
+	if err != nil {
+		t.Fatalf("SetNodeTime: %s", err)
+	}
+	_, err = harness.rpcClient.GenerateBlocks(uint32(overrideDAGParams.TimestampDeviationTolerance), harness.miningAddress, nil)
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %s", err)
+	}
+
+	_, err = harness.rpcClient.SubmitTransaction(spendingTransaction, spendingTransactionID, false)
+	if err != nil {
+		t.Fatalf("expected the spending transaction to be accepted once the lock time passed, got: %s", err)
+	}
+	generateResponse, err := harness.rpcClient.GenerateBlocks(1, harness.miningAddress, nil)
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %s", err)
+	}
+	blockResponse, err := harness.rpcClient.GetBlock(generateResponse.BlockHashes[0], true)
+	if err != nil {
+		t.Fatalf("GetBlock: %s", err)
+	}
+	isIncluded := false
+	for _, transaction := range blockResponse.Block.Transactions {
+		if transaction.VerboseData.TransactionID == spendingTransactionID {
+			isIncluded = true
+			break
+		}
+	}
+	if !isIncluded {
+		t.Fatalf("expected the spending transaction to be included in block %s", generateResponse.BlockHashes[0])
+	}
+}
+
+func createScriptCLTV(absoluteTimeOrDAAScoreTarget uint64) ([]byte, error) {
+	scriptBuilder := txscript.NewScriptBuilder()
+	scriptBuilder.AddLockTimeNumber(absoluteTimeOrDAAScoreTarget)
+	scriptBuilder.AddOp(txscript.OpCheckLockTimeVerify)
+	scriptBuilder.AddOp(txscript.OpTrue)
+	return scriptBuilder.Script()
+}
+
+func buildCLTVLockingTransaction(t *testing.T, harness *appHarness, entry *appmessage.UTXOsByAddressesEntry,
+	redeemScript []byte) (*appmessage.RPCTransaction, string) {
+
+	transactionIDBytes, err := hex.DecodeString(entry.Outpoint.TransactionID)
+	if err != nil {
+		t.Fatalf("Error decoding transaction ID: %s", err)
+	}
+	transactionID, err := transactionid.FromBytes(transactionIDBytes)
+	if err != nil {
+		t.Fatalf("Error decoding transaction ID: %s", err)
+	}
+	txIns := []*appmessage.TxIn{appmessage.NewTxIn(appmessage.NewOutpoint(transactionID, entry.Outpoint.Index), []byte{}, 0, 1)}
+
+	lockingScript, err := txscript.PayToScriptHashScript(redeemScript)
+	if err != nil {
+		t.Fatalf("Error generating script: %+v", err)
+	}
+	txOuts := []*appmessage.TxOut{appmessage.NewTxOut(entry.UTXOEntry.Amount-1000,
+		&externalapi.ScriptPublicKey{Script: lockingScript, Version: constants.MaxScriptPublicKeyVersion})}
+	msgTx := appmessage.NewNativeMsgTx(constants.MaxTransactionVersion, txIns, txOuts)
+
+	fromScriptCode, err := hex.DecodeString(entry.UTXOEntry.ScriptPublicKey.Script)
+	if err != nil {
+		t.Fatalf("Error decoding script public key: %s", err)
+	}
+	fromScript := &externalapi.ScriptPublicKey{Script: fromScriptCode, Version: 0}
+
+	privateKeyBytes, err := hex.DecodeString(harness.miningAddressPrivateKey)
+	if err != nil {
+		t.Fatalf("Error decoding private key: %+v", err)
+	}
+	privateKey, err := secp256k1.DeserializeSchnorrPrivateKeyFromSlice(privateKeyBytes)
+	if err != nil {
+		t.Fatalf("Error deserializing private key: %+v", err)
+	}
+
+	tx := appmessage.MsgTxToDomainTransaction(msgTx)
+	tx.Inputs[0].UTXOEntry = utxo.NewUTXOEntry(entry.UTXOEntry.Amount, fromScript, entry.UTXOEntry.IsCoinbase,
+		entry.UTXOEntry.BlockDAAScore)
+	signatureScript, err := txscript.SignatureScript(tx, 0, consensushashing.SigHashAll, privateKey,
+		&consensushashing.SighashReusedValues{})
+	if err != nil {
+		t.Fatalf("Error signing transaction: %+v", err)
+	}
+	msgTx.TxIn[0].SignatureScript = signatureScript
+
+	domainTransaction := appmessage.MsgTxToDomainTransaction(msgTx)
+	return appmessage.DomainTransactionToRPCTransaction(domainTransaction), consensushashing.TransactionID(domainTransaction).String()
+}
+
+func buildCLTVSpendingTransaction(t *testing.T, lockingTransaction *appmessage.RPCTransaction, redeemScript []byte,
+	lockTime uint64) (*appmessage.RPCTransaction, string) {
+
+	lockingDomainTransaction, err := appmessage.RPCTransactionToDomainTransaction(lockingTransaction)
+	if err != nil {
+		t.Fatalf("Error converting transaction: %s", err)
+	}
+	signatureScript, err := txscript.PayToScriptHashSignatureScript(redeemScript, []byte{})
+	if err != nil {
+		t.Fatalf("Error generating signature script: %s", err)
+	}
+	scriptPublicKey, _ := testutils.OpTrueScript()
+	domainTransaction := &externalapi.DomainTransaction{
+		Version: constants.MaxTransactionVersion,
+		Inputs: []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: externalapi.DomainOutpoint{
+				TransactionID: *consensushashing.TransactionID(lockingDomainTransaction),
+				Index:         0,
+			},
+			SignatureScript: signatureScript,
+			Sequence:        constants.MaxTxInSequenceNum - 1,
+		}},
+		Outputs: []*externalapi.DomainTransactionOutput{{
+			ScriptPublicKey: scriptPublicKey,
+			Value:           lockingDomainTransaction.Outputs[0].Value - 1000,
+		}},
+		Payload:  []byte{},
+		LockTime: lockTime,
+	}
+	return appmessage.DomainTransactionToRPCTransaction(domainTransaction), consensushashing.TransactionID(domainTransaction).String()
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/ammm56/lings/domain/consensus/utils/constants"
 	"github.com/ammm56/lings/domain/consensus/utils/transactionhelper"
 	"github.com/ammm56/lings/domain/consensus/utils/txscript"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/standalone"
 	"github.com/ammm56/lings/util"
 	"github.com/kaspanet/go-secp256k1"
 )
@@ -175,3 +176,191 @@ func generateTx(t *testing.T, firstBlockCoinbase *externalapi.DomainTransaction,
 
 	return msgTx
 }
+
+// TestStemTransactionRelay makes sure that a transaction submitted to a node with
+// stem relay enabled is only forwarded to its stem peer, is kept out of the mempool
+// and isn't announced to other peers during the stem phase, and is broadcast once
+// its embargo expires, since the stem peer never broadcasts it
+func TestStemTransactionRelay(t *testing.T) {
+	harnesses, teardown := setupHarnesses(t, []*harnessParams{
+		{
+			p2pAddress:              p2pAddress1,
+			rpcAddress:              rpcAddress1,
+			miningAddress:           miningAddress1,
+			miningAddressPrivateKey: miningAddress1PrivateKey,
+			stemRelay:               true,
+		},
+		{
+			p2pAddress:              p2pAddress2,
+			rpcAddress:              rpcAddress2,
+			miningAddress:           miningAddress2,
+			miningAddressPrivateKey: miningAddress2PrivateKey,
+		},
+	})
+	defer teardown()
+	payer, payee := harnesses[0], harnesses[1]
+
+	// The payee is an inbound peer of the payer, so it can't be its stem peer
+	connect(t, payer, payee)
+	stemPeerRoutes, teardownStemPeer := connectToMockStemPeer(t, payer)
+	defer teardownStemPeer()
+
+	secondBlock := mineSpendableBlock(t, payer, payee)
+	txID := submitTransaction(t, payer, generateTx(t, secondBlock.Transactions[transactionhelper.CoinbaseTransactionIndex], payer, payee))
+
+	message, err := stemPeerRoutes.WaitForMessageOfType(appmessage.CmdStemTransaction, defaultTimeout)
+	if err != nil {
+		t.Fatalf("Error waiting for the stem transaction: %+v", err)
+	}
+	stemTransaction := appmessage.MsgTxToDomainTransaction(message.(*appmessage.MsgStemTransaction).MsgTx)
+	if consensushashing.TransactionID(stemTransaction).String() != txID {
+		t.Fatalf("Expected the stem peer to receive transaction %s, but got %s",
+			txID, consensushashing.TransactionID(stemTransaction))
+	}
+
+	// The embargo is at least 10 seconds long, so the transaction is still in the stem phase
+	const stemPhaseCheckDuration = 5 * time.Second
+	stemPhaseCheckEnd := time.Now().Add(stemPhaseCheckDuration)
+	for time.Now().Before(stemPhaseCheckEnd) {
+		if isInMempool(t, payer, txID) {
+			t.Fatalf("Transaction %s was added to the mempool of the payer during the stem phase", txID)
+		}
+		if isInMempool(t, payee, txID) {
+			t.Fatalf("Transaction %s was announced to the payee during the stem phase", txID)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// The embargo is at most 30 seconds long
+	const stemEmbargoTimeout = time.Minute
+	waitForTransactionInMempool(t, payee, txID, stemEmbargoTimeout)
+	if !isInMempool(t, payer, txID) {
+		t.Fatalf("Transaction %s was not added to the mempool of the payer once it was fluffed", txID)
+	}
+}
+
+// TestStemTransactionRelayWithoutStemPeers makes sure that a transaction submitted to a
+// node with stem relay enabled is broadcast right away when none of its peers support
+// stem relay
+func TestStemTransactionRelayWithoutStemPeers(t *testing.T) {
+	harnesses, teardown := setupHarnesses(t, []*harnessParams{
+		{
+			p2pAddress:              p2pAddress1,
+			rpcAddress:              rpcAddress1,
+			miningAddress:           miningAddress1,
+			miningAddressPrivateKey: miningAddress1PrivateKey,
+			stemRelay:               true,
+		},
+		{
+			p2pAddress:              p2pAddress2,
+			rpcAddress:              rpcAddress2,
+			miningAddress:           miningAddress2,
+			miningAddressPrivateKey: miningAddress2PrivateKey,
+		},
+	})
+	defer teardown()
+	payer, payee := harnesses[0], harnesses[1]
+
+	// The payee is an outbound peer of the payer, but doesn't support stem relay
+	connect(t, payee, payer)
+
+	secondBlock := mineSpendableBlock(t, payer, payee)
+	txID := submitTransaction(t, payer, generateTx(t, secondBlock.Transactions[transactionhelper.CoinbaseTransactionIndex], payer, payee))
+
+	// Well below the minimal embargo of 10 seconds
+	const fluffTimeout = 5 * time.Second
+	waitForTransactionInMempool(t, payee, txID, fluffTimeout)
+}
+
+// connectToMockStemPeer makes the given node connect to a mock peer that supports
+// stem relay, which makes it the stem peer of the node, but never relays the
+// transactions it receives
+func connectToMockStemPeer(t *testing.T, harness *appHarness) (routes *standalone.Routes, teardownFunc func()) {
+	cfg := commonConfig()
+	cfg.Listeners = []string{p2pAddress3}
+	cfg.RPCListeners = []string{rpcAddress3}
+	minimalNetAdapter, err := standalone.NewMinimalNetAdapter(cfg)
+	if err != nil {
+		t.Fatalf("Error creating the mock stem peer: %+v", err)
+	}
+
+	err = harness.rpcClient.AddPeer(p2pAddress3, false)
+	if err != nil {
+		t.Fatalf("Error connecting to the mock stem peer: %+v", err)
+	}
+	// The mock peer echoes the services of the node in the handshake, so
+	// stem relay is negotiated
+	routes, err = minimalNetAdapter.Accept()
+	if err != nil {
+		t.Fatalf("Error accepting the connection of the node: %+v", err)
+	}
+
+	return routes, func() {
+		routes.Disconnect()
+		err := minimalNetAdapter.Stop()
+		if err != nil {
+			t.Errorf("Error stopping the mock stem peer: %+v", err)
+		}
+	}
+}
+
+// mineSpendableBlock mines blocks on the payer until the coinbase of the
+// returned block is mature, and waits for the payee to receive all of them
+func mineSpendableBlock(t *testing.T, payer, payee *appHarness) *externalapi.DomainBlock {
+	payeeBlockAddedChan := make(chan *appmessage.RPCBlockHeader)
+	setOnBlockAddedHandler(t, payee, func(notification *appmessage.BlockAddedNotificationMessage) {
+		payeeBlockAddedChan <- notification.Block.Header
+	})
+	// skip the first block because it's paying to genesis script
+	mineNextBlock(t, payer)
+	waitForPayeeToReceiveBlock(t, payeeBlockAddedChan)
+	// use the second block to get money to pay with
+	secondBlock := mineNextBlock(t, payer)
+	waitForPayeeToReceiveBlock(t, payeeBlockAddedChan)
+
+	// Mine BlockCoinbaseMaturity more blocks for our money to mature
+	for i := uint64(0); i < payer.config.ActiveNetParams.BlockCoinbaseMaturity; i++ {
+		mineNextBlock(t, payer)
+		waitForPayeeToReceiveBlock(t, payeeBlockAddedChan)
+	}
+
+	// Sleep for `TransactionIDPropagationInterval` to make sure that our transaction will
+	// be propagated
+	time.Sleep(flowcontext.TransactionIDPropagationInterval)
+
+	return secondBlock
+}
+
+func submitTransaction(t *testing.T, harness *appHarness, msgTx *appmessage.MsgTx) string {
+	domainTransaction := appmessage.MsgTxToDomainTransaction(msgTx)
+	rpcTransaction := appmessage.DomainTransactionToRPCTransaction(domainTransaction)
+	response, err := harness.rpcClient.SubmitTransaction(rpcTransaction, consensushashing.TransactionID(domainTransaction).String(), false)
+	if err != nil {
+		t.Fatalf("Error submitting transaction: %+v", err)
+	}
+	return response.TransactionID
+}
+
+func isInMempool(t *testing.T, harness *appHarness, txID string) bool {
+	getMempoolEntryResponse, err := harness.rpcClient.GetMempoolEntry(txID, true, false)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return false
+		}
+		t.Fatalf("Error getting mempool entry: %+v", err)
+	}
+	if getMempoolEntryResponse.Entry.IsOrphan {
+		t.Fatalf("transaction %s is an orphan, although it shouldn't be", txID)
+	}
+	return true
+}
+
+func waitForTransactionInMempool(t *testing.T, harness *appHarness, txID string, timeout time.Duration) {
+	timeoutTime := time.Now().Add(timeout)
+	for !isInMempool(t, harness, txID) {
+		if time.Now().After(timeoutTime) {
+			t.Fatalf("Timeout waiting for transaction %s to be accepted into mempool", txID)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,39 @@
+package netsim
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/ammm56/lings/util/mstime"
+)
+
+// virtualClock is an mstime.Clock that stands still until the network
+// advances it. Internally it keeps nanosecond precision so that transmission
+// delays of small messages add up correctly, while Now is truncated to the
+// millisecond precision of mstime.
+type virtualClock struct {
+	start   mstime.Time
+	elapsed int64
+}
+
+func newVirtualClock(start mstime.Time) *virtualClock {
+	return &virtualClock{start: start}
+}
+
+// Now returns the current virtual time
+func (c *virtualClock) Now() mstime.Time {
+	return c.start.Add(c.Elapsed().Truncate(time.Millisecond))
+}
+
+// Elapsed returns the virtual time that has passed since the simulation started
+func (c *virtualClock) Elapsed() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.elapsed))
+}
+
+// advanceTo moves the clock forward to the given elapsed time. The clock
+// never moves backward.
+func (c *virtualClock) advanceTo(elapsed time.Duration) {
+	if elapsed > c.Elapsed() {
+		atomic.StoreInt64(&c.elapsed, int64(elapsed))
+	}
+}
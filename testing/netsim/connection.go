@@ -0,0 +1,209 @@
+package netsim
+
+import (
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	routerpkg "github.com/ammm56/lings/infrastructure/network/netadapter/router"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/server"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+)
+
+// p2pServer is a server.P2PServer that connects a node to its peers over the
+// links of the simulated network rather than over sockets
+type p2pServer struct {
+	node               *Node
+	onConnectedHandler server.OnConnectedHandler
+}
+
+func (s *p2pServer) Start() error {
+	if s.onConnectedHandler == nil {
+		return errors.New("onConnectedHandler is nil")
+	}
+	return nil
+}
+
+func (s *p2pServer) Stop() error {
+	return nil
+}
+
+func (s *p2pServer) SetOnConnectedHandler(onConnectedHandler server.OnConnectedHandler) {
+	s.onConnectedHandler = onConnectedHandler
+}
+
+// Connect opens a connection over the link between this node and the node
+// with the given address
+// This is part of the P2PServer interface
+func (s *p2pServer) Connect(address string) (server.Connection, error) {
+	remoteNode, outbound, inbound, err := s.node.network.dial(s.node, address)
+	if err != nil {
+		return nil, err
+	}
+
+	err = remoteNode.server.onConnectedHandler(inbound)
+	if err != nil {
+		return nil, err
+	}
+	err = s.onConnectedHandler(outbound)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("%s connected to %s", s.node, remoteNode)
+	return outbound, nil
+}
+
+// linkDirection holds the transmission state of one direction of a link
+type linkDirection struct {
+	busyUntil    time.Duration
+	lastDelivery time.Duration
+}
+
+// connection is one side of a connection over a link. Messages that are
+// enqueued to its router's outgoing route are scheduled for delivery to the
+// remote side according to the link's properties.
+type connection struct {
+	link       *Link
+	address    *net.TCPAddr
+	isOutbound bool
+	remote     *connection
+	direction  linkDirection
+	router     *routerpkg.Router
+
+	onDisconnectedHandler   server.OnDisconnectedHandler
+	onInvalidMessageHandler server.OnInvalidMessageHandler
+
+	// messageNumber is only accessed by the network's run loop
+	messageNumber uint64
+	isConnected   uint32
+}
+
+func newConnectionPair(link *Link, outboundNode, inboundNode *Node) (outbound, inbound *connection) {
+	outbound = &connection{
+		link:        link,
+		address:     inboundNode.address,
+		isOutbound:  true,
+		isConnected: 1,
+	}
+	inbound = &connection{
+		link:        link,
+		address:     outboundNode.address,
+		isOutbound:  false,
+		isConnected: 1,
+	}
+	outbound.remote = inbound
+	inbound.remote = outbound
+	return outbound, inbound
+}
+
+func (c *connection) Start(router *routerpkg.Router) {
+	if c.onDisconnectedHandler == nil {
+		panic(errors.New("onDisconnectedHandler is nil"))
+	}
+
+	c.router = router
+
+	spawn("connection.sendLoop", c.sendLoop)
+}
+
+func (c *connection) sendLoop() {
+	defer c.Disconnect()
+
+	outgoingRoute := c.router.OutgoingRoute()
+	for c.IsConnected() {
+		message, err := outgoingRoute.Dequeue()
+		if err != nil {
+			return
+		}
+
+		messageProto, err := protowire.FromAppMessage(message)
+		if err != nil {
+			log.Errorf("Error converting outgoing '%s' message to %s: %s", message.Command(), c, err)
+			return
+		}
+		c.link.send(c, messageProto)
+	}
+}
+
+// deliver hands a message that crossed the link to this side's router.
+// A nil message means that the remote side has disconnected.
+func (c *connection) deliver(messageProto *protowire.LingsMessage, receivedAt time.Time) {
+	if !c.IsConnected() {
+		return
+	}
+	if messageProto == nil {
+		c.Disconnect()
+		return
+	}
+
+	message, err := messageProto.ToAppMessage()
+	if err != nil {
+		if c.onInvalidMessageHandler != nil {
+			c.onInvalidMessageHandler(err)
+		}
+		c.Disconnect()
+		return
+	}
+
+	c.messageNumber++
+	message.SetMessageNumber(c.messageNumber)
+	message.SetReceivedAt(receivedAt)
+	c.link.network.countMessage(message.Command())
+
+	err = c.router.EnqueueIncomingMessage(message)
+	if err != nil {
+		// ErrRouteCapacityReached isn't an invalid message error,
+		// but it still disconnects the peer
+		isInvalidMessage := !errors.Is(err, routerpkg.ErrRouteClosed) &&
+			!errors.Is(err, routerpkg.ErrRouteCapacityReached)
+		if isInvalidMessage && c.onInvalidMessageHandler != nil {
+			c.onInvalidMessageHandler(err)
+		}
+		c.Disconnect()
+	}
+}
+
+func (c *connection) String() string {
+	return fmt.Sprintf("sim://%s", c.address)
+}
+
+func (c *connection) IsConnected() bool {
+	return atomic.LoadUint32(&c.isConnected) != 0
+}
+
+func (c *connection) IsOutbound() bool {
+	return c.isOutbound
+}
+
+func (c *connection) SetOnDisconnectedHandler(onDisconnectedHandler server.OnDisconnectedHandler) {
+	c.onDisconnectedHandler = onDisconnectedHandler
+}
+
+func (c *connection) SetOnInvalidMessageHandler(onInvalidMessageHandler server.OnInvalidMessageHandler) {
+	c.onInvalidMessageHandler = onInvalidMessageHandler
+}
+
+// Disconnect disconnects the connection. The remote side notices the
+// disconnection once every message that was sent before it crossed the link.
+// Calling this function a second time doesn't do anything
+//
+// This is part of the Connection interface
+func (c *connection) Disconnect() {
+	if !atomic.CompareAndSwapUint32(&c.isConnected, 1, 0) {
+		return
+	}
+
+	c.link.send(c, nil)
+
+	log.Debugf("Disconnecting from %s", c)
+	if c.onDisconnectedHandler != nil {
+		c.onDisconnectedHandler()
+	}
+}
+
+func (c *connection) Address() *net.TCPAddr {
+	return c.address
+}
@@ -0,0 +1,155 @@
+package netsim
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ammm56/lings/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// LinkConfig describes the properties of a link between two nodes
+type LinkConfig struct {
+	// Latency is the time it takes a message to cross the link once it has
+	// been transmitted
+	Latency time.Duration
+
+	// Bandwidth is the amount of bytes per second that the link transmits in
+	// each direction. Messages that are sent while the link is busy wait for
+	// the messages before them. Zero means unlimited bandwidth.
+	Bandwidth uint64
+}
+
+// Link is a virtual link between two nodes, over which the node that
+// initiated it holds an outbound connection to the other node. Like a TCP
+// connection, a link delivers the messages of each direction in the order
+// they were sent.
+type Link struct {
+	network  *Network
+	from, to *Node
+
+	// The following fields are protected by the network's lock
+	config            LinkConfig
+	isPartitioned     bool
+	outbound, inbound *connection
+}
+
+// From returns the node that initiated the link
+func (l *Link) From() *Node {
+	return l.from
+}
+
+// To returns the node that accepted the link
+func (l *Link) To() *Node {
+	return l.to
+}
+
+func (l *Link) String() string {
+	return fmt.Sprintf("%s->%s", l.from, l.to)
+}
+
+// SetLatency changes the latency of the link. Messages that are already in
+// flight are not affected.
+func (l *Link) SetLatency(latency time.Duration) {
+	l.network.lock.Lock()
+	defer l.network.lock.Unlock()
+
+	l.config.Latency = latency
+}
+
+// SetBandwidth changes the bandwidth of the link, in bytes per second. Zero
+// means unlimited bandwidth. Messages that are already in flight are not
+// affected.
+func (l *Link) SetBandwidth(bandwidth uint64) {
+	l.network.lock.Lock()
+	defer l.network.lock.Unlock()
+
+	l.config.Bandwidth = bandwidth
+}
+
+// IsConnected returns whether the nodes of the link are currently connected
+func (l *Link) IsConnected() bool {
+	l.network.lock.Lock()
+	defer l.network.lock.Unlock()
+
+	return l.outbound != nil && l.outbound.IsConnected() && l.inbound.IsConnected()
+}
+
+// IsPartitioned returns whether the link is currently cut
+func (l *Link) IsPartitioned() bool {
+	l.network.lock.Lock()
+	defer l.network.lock.Unlock()
+
+	return l.isPartitioned
+}
+
+// Partition cuts the link. Both nodes lose the connection right away, as
+// do the messages that were in flight, and no connection can be made over
+// the link until it is healed.
+func (l *Link) Partition() {
+	l.network.lock.Lock()
+	l.isPartitioned = true
+	outbound, inbound := l.outbound, l.inbound
+	l.network.lock.Unlock()
+
+	if outbound != nil {
+		outbound.Disconnect()
+		inbound.Disconnect()
+	}
+}
+
+// Heal restores a cut link and reconnects its nodes, the way a node retries
+// its permanent connections. Healing a link that isn't cut does nothing.
+func (l *Link) Heal() error {
+	l.network.lock.Lock()
+	wasPartitioned := l.isPartitioned
+	l.isPartitioned = false
+	l.network.lock.Unlock()
+
+	if !wasPartitioned {
+		return nil
+	}
+	return l.connect()
+}
+
+func (l *Link) connect() error {
+	err := l.from.netAdapter.P2PConnect(l.to.Address())
+	l.network.markActivity()
+	return err
+}
+
+// send schedules the delivery of the given message to the remote side of
+// the given connection. A nil message notifies the remote side that the
+// connection was closed.
+func (l *Link) send(from *connection, messageProto *protowire.LingsMessage) {
+	size := 0
+	if messageProto != nil {
+		size = proto.Size(messageProto)
+	}
+
+	l.network.lock.Lock()
+	defer l.network.lock.Unlock()
+
+	if l.isPartitioned {
+		return
+	}
+
+	transmissionStart := l.network.clock.Elapsed()
+	if from.direction.busyUntil > transmissionStart {
+		transmissionStart = from.direction.busyUntil
+	}
+	var transmissionTime time.Duration
+	if l.config.Bandwidth > 0 {
+		transmissionTime = time.Duration(uint64(size) * uint64(time.Second) / l.config.Bandwidth)
+	}
+	from.direction.busyUntil = transmissionStart + transmissionTime
+
+	// A latency change must not reorder the messages of a direction
+	deliverAt := from.direction.busyUntil + l.config.Latency
+	if deliverAt < from.direction.lastDelivery {
+		deliverAt = from.direction.lastDelivery
+	}
+	from.direction.lastDelivery = deliverAt
+
+	l.network.schedule(deliverAt, from.remote, messageProto)
+}
@@ -0,0 +1,255 @@
+package netsim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+const maxRunDuration = 10 * time.Minute
+
+// quietPeriod leaves room for the nodes to validate blocks when the tests run
+// with the race detector
+const quietPeriod = 200 * time.Millisecond
+
+func setupNetwork(t *testing.T, nodeNames ...string) (*Network, []*Node, func()) {
+	network, err := NewNetwork(&Config{QuietPeriod: quietPeriod})
+	if err != nil {
+		t.Fatalf("NewNetwork: %+v", err)
+	}
+	nodes := make([]*Node, len(nodeNames))
+	for i, nodeName := range nodeNames {
+		nodes[i], err = network.AddNode(nodeName)
+		if err != nil {
+			t.Fatalf("AddNode: %+v", err)
+		}
+	}
+	return network, nodes, func() {
+		err := network.Close()
+		if err != nil {
+			t.Errorf("Close: %+v", err)
+		}
+	}
+}
+
+func connect(t *testing.T, network *Network, from, to *Node, linkConfig LinkConfig) *Link {
+	link, err := network.Connect(from, to, linkConfig)
+	if err != nil {
+		t.Fatalf("Connect: %+v", err)
+	}
+	return link
+}
+
+func runUntilIdle(t *testing.T, network *Network) {
+	err := network.RunUntilIdle(maxRunDuration)
+	if err != nil {
+		t.Fatalf("RunUntilIdle: %+v", err)
+	}
+}
+
+func mineBlocks(t *testing.T, node *Node, count int) []*externalapi.DomainBlock {
+	blocks, err := node.MineBlocks(count)
+	if err != nil {
+		t.Fatalf("MineBlocks: %+v", err)
+	}
+	return blocks
+}
+
+func requireSameSelectedTip(t *testing.T, expectedTip *externalapi.DomainHash, nodes ...*Node) {
+	for _, node := range nodes {
+		selectedTip, err := node.VirtualSelectedParent()
+		if err != nil {
+			t.Fatalf("VirtualSelectedParent: %+v", err)
+		}
+		if !selectedTip.Equal(expectedTip) {
+			t.Fatalf("Expected the selected tip of %s to be %s, got %s", node, expectedTip, selectedTip)
+		}
+	}
+}
+
+func requireHasBlocks(t *testing.T, node *Node, blocks []*externalapi.DomainBlock) {
+	for _, block := range blocks {
+		hasBlock, err := node.HasBlock(consensushashing.BlockHash(block))
+		if err != nil {
+			t.Fatalf("HasBlock: %+v", err)
+		}
+		if !hasBlock {
+			t.Fatalf("%s is missing block %s", node, consensushashing.BlockHash(block))
+		}
+	}
+}
+
+func messageSize(t *testing.T, message appmessage.Message) uint64 {
+	messageProto, err := protowire.FromAppMessage(message)
+	if err != nil {
+		t.Fatalf("FromAppMessage: %+v", err)
+	}
+	return uint64(proto.Size(messageProto))
+}
+
+// runRelayScenario relays a block over a line of three nodes and returns
+// the resulting report
+func runRelayScenario(t *testing.T) *PropagationReport {
+	network, nodes, teardown := setupNetwork(t, "A", "B", "C")
+	defer teardown()
+
+	connect(t, network, nodes[0], nodes[1], LinkConfig{Latency: 100 * time.Millisecond})
+	connect(t, network, nodes[1], nodes[2], LinkConfig{Latency: 100 * time.Millisecond})
+	runUntilIdle(t, network)
+
+	blocks := mineBlocks(t, nodes[0], 1)
+	runUntilIdle(t, network)
+	requireSameSelectedTip(t, consensushashing.BlockHash(blocks[0]), nodes...)
+
+	return network.PropagationReport()
+}
+
+func TestRelayPropagation(t *testing.T) {
+	report := runRelayScenario(t)
+
+	// Every hop takes an inv, a request and the block itself
+	block := report.Blocks[0]
+	if block.Origin != "A" || len(block.Missing) != 0 {
+		t.Fatalf("Unexpected report:\n%s", report)
+	}
+	if block.Delays["B"] != 300*time.Millisecond || block.Delays["C"] != 600*time.Millisecond {
+		t.Fatalf("Unexpected delays:\n%s", report)
+	}
+	if report.Percentile(50) != 300*time.Millisecond || report.Percentile(100) != 600*time.Millisecond {
+		t.Fatalf("Unexpected percentiles:\n%s", report)
+	}
+
+	// The clock and the mined blocks only depend on the simulation, so the
+	// same scenario must result in the same report
+	secondReport := runRelayScenario(t)
+	if report.String() != secondReport.String() {
+		t.Fatalf("The same scenario resulted in different reports:\n%s\n\n%s", report, secondReport)
+	}
+}
+
+func TestBandwidth(t *testing.T) {
+	network, nodes, teardown := setupNetwork(t, "A", "B")
+	defer teardown()
+
+	const latency = 10 * time.Millisecond
+	const bandwidth = 10_000
+	connect(t, network, nodes[0], nodes[1], LinkConfig{Latency: latency, Bandwidth: bandwidth})
+	runUntilIdle(t, network)
+
+	block := mineBlocks(t, nodes[0], 1)[0]
+	runUntilIdle(t, network)
+
+	blockHash := consensushashing.BlockHash(block)
+	transmissionTime := func(message appmessage.Message) time.Duration {
+		return time.Duration(messageSize(t, message) * uint64(time.Second) / bandwidth)
+	}
+	expectedDelay := 3*latency +
+		transmissionTime(appmessage.NewMsgInvBlock(blockHash)) +
+		transmissionTime(appmessage.NewMsgRequestRelayBlocks([]*externalapi.DomainHash{blockHash})) +
+		transmissionTime(appmessage.DomainBlockToMsgBlock(block))
+
+	report := network.PropagationReport()
+	if report.Blocks[0].Delays["B"] != expectedDelay {
+		t.Fatalf("Expected a delay of %s, got:\n%s", expectedDelay, report)
+	}
+}
+
+func TestPartitionConvergence(t *testing.T) {
+	network, nodes, teardown := setupNetwork(t, "A", "B", "C", "D")
+	defer teardown()
+	nodeA, nodeB, nodeC, nodeD := nodes[0], nodes[1], nodes[2], nodes[3]
+
+	linkConfig := LinkConfig{Latency: 50 * time.Millisecond}
+	connect(t, network, nodeA, nodeB, linkConfig)
+	connect(t, network, nodeB, nodeC, linkConfig)
+	connect(t, network, nodeC, nodeD, linkConfig)
+	connect(t, network, nodeD, nodeA, linkConfig)
+	runUntilIdle(t, network)
+
+	network.Partition([]*Node{nodeA, nodeB}, []*Node{nodeC, nodeD})
+	if !network.Link(nodeB, nodeC).IsPartitioned() || network.Link(nodeA, nodeB).IsPartitioned() {
+		t.Fatalf("Unexpected partition")
+	}
+	runUntilIdle(t, network)
+
+	var shortChain, longChain []*externalapi.DomainBlock
+	for i := 0; i < 6; i++ {
+		if i < 3 {
+			shortChain = append(shortChain, mineBlocks(t, nodeA, 1)...)
+		}
+		longChain = append(longChain, mineBlocks(t, nodeC, 1)...)
+		network.RunFor(time.Second)
+	}
+	runUntilIdle(t, network)
+	requireSameSelectedTip(t, consensushashing.BlockHash(shortChain[len(shortChain)-1]), nodeA, nodeB)
+	requireSameSelectedTip(t, consensushashing.BlockHash(longChain[len(longChain)-1]), nodeC, nodeD)
+
+	err := network.Heal()
+	if err != nil {
+		t.Fatalf("Heal: %+v", err)
+	}
+	runUntilIdle(t, network)
+
+	// A and B reorg to the longer chain, and a block mined on top of
+	// everything reaches all nodes
+	requireSameSelectedTip(t, consensushashing.BlockHash(longChain[len(longChain)-1]), nodes...)
+	for _, node := range nodes {
+		requireHasBlocks(t, node, shortChain)
+		requireHasBlocks(t, node, longChain)
+	}
+
+	mergingBlock := mineBlocks(t, nodeB, 1)[0]
+	if len(mergingBlock.Header.DirectParents()) != 2 {
+		t.Fatalf("Expected the block to merge both chains, got parents %s", mergingBlock.Header.DirectParents())
+	}
+	runUntilIdle(t, network)
+	requireSameSelectedTip(t, consensushashing.BlockHash(mergingBlock), nodes...)
+
+	report := network.PropagationReport()
+	if report.MissingCount() != 0 {
+		t.Fatalf("Expected every block to reach every node:\n%s", report)
+	}
+}
+
+func TestOrphanResolutionAndIBD(t *testing.T) {
+	network, nodes, teardown := setupNetwork(t, "A", "B")
+	defer teardown()
+	nodeA, nodeB := nodes[0], nodes[1]
+
+	link := connect(t, network, nodeA, nodeB, LinkConfig{Latency: 20 * time.Millisecond})
+	runUntilIdle(t, network)
+
+	// A short gap is filled by requesting the missing ancestors of the orphan
+	link.Partition()
+	orphanAncestors := mineBlocks(t, nodeA, 2)
+	runUntilIdle(t, network)
+	err := link.Heal()
+	if err != nil {
+		t.Fatalf("Heal: %+v", err)
+	}
+	runUntilIdle(t, network)
+	requireHasBlocks(t, nodeB, orphanAncestors)
+	if network.MessageCount(appmessage.CmdRequestIBDBlocks) != 0 {
+		t.Fatalf("Expected the orphan to be resolved without IBD")
+	}
+
+	// A long gap requires IBD
+	link.Partition()
+	missingBlocks := mineBlocks(t, nodeA, 100)
+	runUntilIdle(t, network)
+	err = link.Heal()
+	if err != nil {
+		t.Fatalf("Heal: %+v", err)
+	}
+	runUntilIdle(t, network)
+	requireHasBlocks(t, nodeB, missingBlocks)
+	requireSameSelectedTip(t, consensushashing.BlockHash(missingBlocks[len(missingBlocks)-1]), nodeA, nodeB)
+	if network.MessageCount(appmessage.CmdRequestIBDBlocks) == 0 {
+		t.Fatalf("Expected the gap to be filled by IBD")
+	}
+}
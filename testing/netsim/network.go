@@ -0,0 +1,467 @@
+package netsim
+
+import (
+	"container/heap"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ammm56/lings/app/appmessage"
+	"github.com/ammm56/lings/domain/dagconfig"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/ammm56/lings/util/mstime"
+	"github.com/pkg/errors"
+)
+
+// DefaultQuietPeriod is the default for Config.QuietPeriod
+const DefaultQuietPeriod = 50 * time.Millisecond
+
+// Config holds the parameters of a simulated network
+type Config struct {
+	// Params are the DAG parameters that all nodes run with. If nil,
+	// DefaultParams are used.
+	Params *dagconfig.Params
+
+	// QuietPeriod is how long, in real time, the network has to go without
+	// sending or delivering a message before the virtual clock moves on.
+	// It should comfortably exceed the time it takes a node to process a
+	// message, since processing is treated as instantaneous in virtual time.
+	// Zero means DefaultQuietPeriod.
+	QuietPeriod time.Duration
+
+	// DataDir is the directory in which the nodes keep their databases. If
+	// empty, a temporary directory is created and removed on Close.
+	DataDir string
+}
+
+// DefaultParams returns the mainnet parameters without proof of work, so
+// that mining is instant, and with every block kept at the same block
+// version. The block version is tracked in a process-wide variable, so nodes
+// that validate blocks of different versions at the same time would
+// interfere with each other.
+func DefaultParams() *dagconfig.Params {
+	params := dagconfig.MainnetParams
+	params.SkipProofOfWork = true
+	params.POWScores = nil
+	return &params
+}
+
+// Network is a set of nodes that run in a single process and talk to each
+// other over virtual links. Time in the network is virtual: it stands still
+// while nodes process messages, and only moves when RunFor or RunUntilIdle
+// deliver the next message that is in flight. All the nodes share the
+// virtual clock, which is also the clock of their consensus and mempool.
+type Network struct {
+	params        *dagconfig.Params
+	quietPeriod   time.Duration
+	dataDir       string
+	removeDataDir bool
+	clock         *virtualClock
+	defaultPort   int
+
+	lock          sync.Mutex
+	nodes         []*Node
+	nodesByName   map[string]*Node
+	links         []*Link
+	events        eventQueue
+	nextSequence  uint64
+	messageCounts map[appmessage.MessageCommand]uint64
+	minedBlocks   []*minedBlock
+	isClosed      bool
+
+	// activityCount is incremented every time the network sees a node do
+	// anything
+	activityCount uint64
+}
+
+// NewNetwork creates a new empty network
+func NewNetwork(cfg *Config) (*Network, error) {
+	params := cfg.Params
+	if params == nil {
+		params = DefaultParams()
+	}
+	quietPeriod := cfg.QuietPeriod
+	if quietPeriod == 0 {
+		quietPeriod = DefaultQuietPeriod
+	}
+	defaultPort, err := strconv.Atoi(params.DefaultPort)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid default port %s", params.DefaultPort)
+	}
+
+	dataDir := cfg.DataDir
+	removeDataDir := false
+	if dataDir == "" {
+		dataDir, err = ioutil.TempDir("", "netsim")
+		if err != nil {
+			return nil, err
+		}
+		removeDataDir = true
+	}
+
+	genesisTime := mstime.UnixMilliseconds(params.GenesisBlock.Header.TimeInMilliseconds())
+	return &Network{
+		params:        params,
+		quietPeriod:   quietPeriod,
+		dataDir:       dataDir,
+		removeDataDir: removeDataDir,
+		clock:         newVirtualClock(genesisTime),
+		defaultPort:   defaultPort,
+		nodesByName:   make(map[string]*Node),
+		messageCounts: make(map[appmessage.MessageCommand]uint64),
+	}, nil
+}
+
+// Params returns the DAG parameters that the nodes run with
+func (n *Network) Params() *dagconfig.Params {
+	return n.params
+}
+
+// Now returns the current virtual time
+func (n *Network) Now() mstime.Time {
+	return n.clock.Now()
+}
+
+// Elapsed returns the virtual time that has passed since the network was created
+func (n *Network) Elapsed() time.Duration {
+	return n.clock.Elapsed()
+}
+
+// Clock returns the virtual clock of the network
+func (n *Network) Clock() mstime.Clock {
+	return n.clock
+}
+
+// AddNode creates a new node with the given name and adds it to the network
+func (n *Network) AddNode(name string) (*Node, error) {
+	n.lock.Lock()
+	if n.isClosed {
+		n.lock.Unlock()
+		return nil, errors.New("the network is closed")
+	}
+	if _, ok := n.nodesByName[name]; ok {
+		n.lock.Unlock()
+		return nil, errors.Errorf("a node named %s already exists", name)
+	}
+	index := len(n.nodes) + 1
+	address := &net.TCPAddr{IP: net.IPv4(10, 0, byte(index>>8), byte(index)), Port: n.defaultPort}
+	n.lock.Unlock()
+
+	node, err := newNode(n, name, address)
+	if err != nil {
+		return nil, err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.nodes = append(n.nodes, node)
+	n.nodesByName[name] = node
+	return node, nil
+}
+
+// Nodes returns the nodes of the network, in the order they were added
+func (n *Network) Nodes() []*Node {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	nodes := make([]*Node, len(n.nodes))
+	copy(nodes, n.nodes)
+	return nodes
+}
+
+// Node returns the node with the given name, or nil if there's none
+func (n *Network) Node(name string) *Node {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.nodesByName[name]
+}
+
+// Connect creates a link between the given nodes and has `from` open an
+// outbound connection to `to` over it. The handshake takes place as the
+// network runs.
+func (n *Network) Connect(from, to *Node, linkConfig LinkConfig) (*Link, error) {
+	if from == to {
+		return nil, errors.Errorf("cannot connect %s to itself", from)
+	}
+
+	n.lock.Lock()
+	if n.linkBetween(from, to) != nil {
+		n.lock.Unlock()
+		return nil, errors.Errorf("%s and %s are already linked", from, to)
+	}
+	link := &Link{
+		network: n,
+		from:    from,
+		to:      to,
+		config:  linkConfig,
+	}
+	n.links = append(n.links, link)
+	n.lock.Unlock()
+
+	err := link.connect()
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// Link returns the link between the given nodes, in either direction, or
+// nil if they aren't linked
+func (n *Network) Link(a, b *Node) *Link {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.linkBetween(a, b)
+}
+
+// Links returns all the links of the network
+func (n *Network) Links() []*Link {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	links := make([]*Link, len(n.links))
+	copy(links, n.links)
+	return links
+}
+
+func (n *Network) linkBetween(a, b *Node) *Link {
+	for _, link := range n.links {
+		if (link.from == a && link.to == b) || (link.from == b && link.to == a) {
+			return link
+		}
+	}
+	return nil
+}
+
+// Partition cuts every link whose nodes belong to different groups. Links
+// of nodes that aren't in any group are left as they are.
+func (n *Network) Partition(groups ...[]*Node) {
+	groupOf := make(map[*Node]int)
+	for i, group := range groups {
+		for _, node := range group {
+			groupOf[node] = i
+		}
+	}
+
+	for _, link := range n.Links() {
+		fromGroup, fromOK := groupOf[link.from]
+		toGroup, toOK := groupOf[link.to]
+		if fromOK && toOK && fromGroup != toGroup {
+			link.Partition()
+		}
+	}
+}
+
+// Heal heals every cut link in the network
+func (n *Network) Heal() error {
+	for _, link := range n.Links() {
+		err := link.Heal()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dial creates a pair of connected connections over the link between the
+// given node and the node with the given address
+func (n *Network) dial(from *Node, address string) (to *Node, outbound, inbound *connection, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for _, node := range n.nodes {
+		if node.Address() == address {
+			to = node
+			break
+		}
+	}
+	if to == nil {
+		return nil, nil, nil, errors.Errorf("no node has the address %s", address)
+	}
+
+	link := n.linkBetween(from, to)
+	if link == nil {
+		return nil, nil, nil, errors.Errorf("%s has no link to %s", from, to)
+	}
+	if link.isPartitioned {
+		return nil, nil, nil, errors.Errorf("the link between %s and %s is partitioned", from, to)
+	}
+	if link.outbound != nil && link.outbound.IsConnected() && link.inbound.IsConnected() {
+		return nil, nil, nil, errors.Errorf("%s is already connected to %s", from, to)
+	}
+
+	outbound, inbound = newConnectionPair(link, from, to)
+	link.outbound, link.inbound = outbound, inbound
+	return to, outbound, inbound, nil
+}
+
+// RunFor runs the network for the given duration of virtual time,
+// delivering every message that becomes due
+func (n *Network) RunFor(duration time.Duration) {
+	n.runUntil(n.clock.Elapsed()+duration, false)
+}
+
+// RunUntilIdle runs the network until no messages are in flight, and
+// returns an error if that doesn't happen within the given duration of
+// virtual time
+func (n *Network) RunUntilIdle(maxDuration time.Duration) error {
+	isIdle := n.runUntil(n.clock.Elapsed()+maxDuration, true)
+	if !isIdle {
+		return errors.Errorf("the network is still busy after running for %s", maxDuration)
+	}
+	return nil
+}
+
+// runUntil delivers messages in the order of their delivery time until the
+// given elapsed time. If stopWhenIdle is set, it returns as soon as no
+// messages are in flight rather than moving the clock to the deadline.
+// It returns whether the network is idle.
+func (n *Network) runUntil(deadline time.Duration, stopWhenIdle bool) bool {
+	for {
+		n.waitForQuietPeriod()
+
+		n.lock.Lock()
+		isIdle := len(n.events) == 0
+		if isIdle || n.events[0].deliverAt > deadline {
+			if !isIdle || !stopWhenIdle {
+				n.clock.advanceTo(deadline)
+			}
+			n.lock.Unlock()
+			return isIdle
+		}
+
+		deliverAt := n.events[0].deliverAt
+		n.clock.advanceTo(deliverAt)
+		var dueEvents []*event
+		for len(n.events) > 0 && n.events[0].deliverAt == deliverAt {
+			dueEvents = append(dueEvents, heap.Pop(&n.events).(*event))
+		}
+		n.lock.Unlock()
+
+		receivedAt := n.clock.Now().ToNativeTime()
+		for _, dueEvent := range dueEvents {
+			dueEvent.to.deliver(dueEvent.message, receivedAt)
+		}
+		n.markActivity()
+	}
+}
+
+// waitForQuietPeriod blocks until the nodes haven't done anything for the
+// network's quiet period, which means that they are all waiting for messages
+//
+// Activity is counted rather than timestamped, so the network never reads the
+// wall clock: the virtual clock is the only time the simulation observes, and
+// the quiet period is only used to let the nodes' goroutines run.
+func (n *Network) waitForQuietPeriod() {
+	for {
+		activityCount := atomic.LoadUint64(&n.activityCount)
+		time.Sleep(n.quietPeriod)
+		if atomic.LoadUint64(&n.activityCount) == activityCount {
+			return
+		}
+	}
+}
+
+func (n *Network) markActivity() {
+	atomic.AddUint64(&n.activityCount, 1)
+}
+
+// schedule queues a message for delivery. The caller must hold the
+// network's lock.
+func (n *Network) schedule(deliverAt time.Duration, to *connection, message *protowire.LingsMessage) {
+	heap.Push(&n.events, &event{
+		deliverAt: deliverAt,
+		sequence:  n.nextSequence,
+		to:        to,
+		message:   message,
+	})
+	n.nextSequence++
+	n.markActivity()
+}
+
+func (n *Network) countMessage(command appmessage.MessageCommand) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.messageCounts[command]++
+}
+
+// MessageCount returns how many messages of the given command were
+// delivered so far
+func (n *Network) MessageCount(command appmessage.MessageCommand) uint64 {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.messageCounts[command]
+}
+
+// Close stops all the nodes and removes their data, if it was kept in a
+// temporary directory
+func (n *Network) Close() error {
+	n.lock.Lock()
+	if n.isClosed {
+		n.lock.Unlock()
+		return errors.New("the network was already closed")
+	}
+	n.isClosed = true
+	nodes := n.nodes
+	n.lock.Unlock()
+
+	for _, link := range n.Links() {
+		link.Partition()
+	}
+	for _, node := range nodes {
+		err := node.stop()
+		if err != nil {
+			return err
+		}
+	}
+	if n.removeDataDir {
+		return os.RemoveAll(n.dataDir)
+	}
+	return nil
+}
+
+// event is a message that is in flight over a link
+type event struct {
+	deliverAt time.Duration
+	sequence  uint64
+	to        *connection
+	// message is nil when the event notifies that the sender disconnected
+	message *protowire.LingsMessage
+}
+
+// eventQueue is a heap of events ordered by delivery time, and then by the
+// order in which they were sent
+type eventQueue []*event
+
+func (q eventQueue) Len() int {
+	return len(q)
+}
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].deliverAt != q[j].deliverAt {
+		return q[i].deliverAt < q[j].deliverAt
+	}
+	return q[i].sequence < q[j].sequence
+}
+
+func (q eventQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *eventQueue) Push(x interface{}) {
+	*q = append(*q, x.(*event))
+}
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,277 @@
+package netsim
+
+import (
+	"net"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ammm56/lings/app/protocol"
+	"github.com/ammm56/lings/domain"
+	"github.com/ammm56/lings/domain/consensus"
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+	"github.com/ammm56/lings/domain/consensus/utils/consensushashing"
+	"github.com/ammm56/lings/domain/consensus/utils/pow"
+	"github.com/ammm56/lings/domain/consensus/utils/txscript"
+	"github.com/ammm56/lings/domain/miningmanager/mempool"
+	"github.com/ammm56/lings/infrastructure/config"
+	"github.com/ammm56/lings/infrastructure/db/database"
+	"github.com/ammm56/lings/infrastructure/db/database/ldb"
+	"github.com/ammm56/lings/infrastructure/network/addressmanager"
+	"github.com/ammm56/lings/infrastructure/network/connmanager"
+	"github.com/ammm56/lings/infrastructure/network/netadapter"
+	"github.com/ammm56/lings/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// Node is a full node in a simulated network. It runs the real domain and
+// p2p protocol, with the network's virtual clock and virtual links in place
+// of the system clock and sockets.
+type Node struct {
+	name            string
+	network         *Network
+	address         *net.TCPAddr
+	database        database.Database
+	domain          domain.Domain
+	netAdapter      *netadapter.NetAdapter
+	server          *p2pServer
+	protocolManager *protocol.Manager
+	coinbaseData    *externalapi.DomainCoinbaseData
+
+	// arrivals holds the virtual time at which each block was added to the
+	// node's DAG
+	arrivals     map[externalapi.DomainHash]time.Duration
+	arrivalsLock sync.Mutex
+
+	consensusEventsDone chan struct{}
+}
+
+func newNode(network *Network, name string, address *net.TCPAddr) (*Node, error) {
+	cfg := config.DefaultConfig()
+	cfg.ActiveNetParams = network.params
+	cfg.AppDir = filepath.Join(network.dataDir, name)
+	cfg.Listeners = nil
+	cfg.RPCListeners = nil
+	cfg.TargetOutboundPeers = 0
+	cfg.DisableDNSSeed = true
+	cfg.AllowSubmitBlockWhenNotSynced = true
+
+	db, err := ldb.NewLevelDB(filepath.Join(cfg.AppDir, "db"), 8)
+	if err != nil {
+		return nil, err
+	}
+
+	consensusConfig := consensus.Config{
+		Params: *network.params,
+		Clock:  network.clock,
+	}
+	mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+	mempoolConfig.Clock = network.clock
+	nodeDomain, err := domain.New(&consensusConfig, mempoolConfig, db)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &Node{
+		name:     name,
+		network:  network,
+		address:  address,
+		database: db,
+		domain:   nodeDomain,
+		// The coinbase data is unique per node, so that nodes that mine on the
+		// same parents at the same time don't create the same block
+		coinbaseData: &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{Script: []byte{txscript.OpTrue}, Version: 0},
+			ExtraData:       []byte(name),
+		},
+		arrivals:            make(map[externalapi.DomainHash]time.Duration),
+		consensusEventsDone: make(chan struct{}),
+	}
+	node.server = &p2pServer{node: node}
+
+	node.netAdapter, err = netadapter.NewNetAdapterWithP2PServer(cfg, node.server)
+	if err != nil {
+		return nil, err
+	}
+	addressManager, err := addressmanager.New(addressmanager.NewConfig(cfg), db)
+	if err != nil {
+		return nil, err
+	}
+	connectionManager, err := connmanager.New(cfg, node.netAdapter, addressManager)
+	if err != nil {
+		return nil, err
+	}
+	node.protocolManager, err = protocol.NewManager(cfg, nodeDomain, node.netAdapter, addressManager, connectionManager)
+	if err != nil {
+		return nil, err
+	}
+	node.netAdapter.SetRPCRouterInitializer(func(_ *router.Router, _ *netadapter.NetConnection) {})
+
+	// The connection manager isn't started: connections are made by the
+	// network, over the links it was told about
+	err = node.netAdapter.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	spawn("Node.handleConsensusEvents", node.handleConsensusEvents)
+
+	return node, nil
+}
+
+func (node *Node) handleConsensusEvents() {
+	defer close(node.consensusEventsDone)
+
+	for event := range node.domain.ConsensusEventsChannel() {
+		if blockAdded, ok := event.(*externalapi.BlockAdded); ok {
+			blockHash := consensushashing.BlockHash(blockAdded.Block)
+
+			node.arrivalsLock.Lock()
+			if _, ok := node.arrivals[*blockHash]; !ok {
+				node.arrivals[*blockHash] = node.network.clock.Elapsed()
+			}
+			node.arrivalsLock.Unlock()
+		}
+		node.network.markActivity()
+	}
+}
+
+// arrival returns the virtual time at which the given block was added to
+// the node's DAG
+func (node *Node) arrival(blockHash *externalapi.DomainHash) (time.Duration, bool) {
+	node.arrivalsLock.Lock()
+	defer node.arrivalsLock.Unlock()
+
+	arrival, ok := node.arrivals[*blockHash]
+	return arrival, ok
+}
+
+func (node *Node) String() string {
+	return node.name
+}
+
+// Name returns the name of the node
+func (node *Node) Name() string {
+	return node.name
+}
+
+// Address returns the address at which the node can be reached in the network
+func (node *Node) Address() string {
+	return node.address.String()
+}
+
+// Domain returns the domain of the node
+func (node *Node) Domain() domain.Domain {
+	return node.domain
+}
+
+// ProtocolManager returns the p2p protocol manager of the node
+func (node *Node) ProtocolManager() *protocol.Manager {
+	return node.protocolManager
+}
+
+// HasBlock returns whether the node has the given block, including its body
+func (node *Node) HasBlock(blockHash *externalapi.DomainHash) (bool, error) {
+	blockInfo, err := node.domain.Consensus().GetBlockInfo(blockHash)
+	if err != nil {
+		return false, err
+	}
+	return blockInfo.Exists && blockInfo.BlockStatus != externalapi.StatusHeaderOnly, nil
+}
+
+// VirtualSelectedParent returns the selected tip of the node's DAG
+func (node *Node) VirtualSelectedParent() (*externalapi.DomainHash, error) {
+	return node.domain.Consensus().GetVirtualSelectedParent()
+}
+
+// MineBlock mines a block on top of the node's current tips at the current
+// virtual time, adds it to the node's DAG and has the node relay it
+func (node *Node) MineBlock() (*externalapi.DomainBlock, error) {
+	block, _, err := node.domain.MiningManager().GetBlockTemplate(node.coinbaseData)
+	if err != nil {
+		return nil, err
+	}
+	block = solveBlock(block, node.network.params.SkipProofOfWork)
+
+	err = node.protocolManager.AddBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	node.network.addMinedBlock(block, node)
+	return block, nil
+}
+
+// MineBlocks mines the given number of blocks one after the other
+func (node *Node) MineBlocks(count int) ([]*externalapi.DomainBlock, error) {
+	blocks := make([]*externalapi.DomainBlock, 0, count)
+	for i := 0; i < count; i++ {
+		block, err := node.MineBlock()
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func (node *Node) stop() error {
+	for _, netConnection := range node.netAdapter.P2PConnections() {
+		netConnection.Disconnect()
+	}
+	err := node.netAdapter.Stop()
+	if err != nil {
+		return err
+	}
+	node.protocolManager.Close()
+	close(node.domain.ConsensusEventsChannel())
+	<-node.consensusEventsDone
+
+	err = node.database.Close()
+	if err != nil {
+		return errors.Wrapf(err, "error closing the database of %s", node)
+	}
+	return nil
+}
+
+// solveBlock returns a copy of the given block template with a nonce that
+// satisfies its difficulty target, without the UTXO entries that the
+// template's transactions were populated with
+func solveBlock(block *externalapi.DomainBlock, skipProofOfWork bool) *externalapi.DomainBlock {
+	solvedBlock := block.Clone()
+	for _, transaction := range solvedBlock.Transactions {
+		for _, input := range transaction.Inputs {
+			input.UTXOEntry = nil
+		}
+	}
+	if skipProofOfWork {
+		return solvedBlock
+	}
+	header := solvedBlock.Header.ToMutable()
+	state := pow.NewState(header)
+	for !state.CheckProofOfWork() {
+		state.IncrementNonce()
+	}
+	header.SetNonce(state.Nonce)
+	solvedBlock.Header = header.ToImmutable()
+	return solvedBlock
+}
+
+// minedBlock is a block that was mined in the simulation, whose propagation
+// is reported on
+type minedBlock struct {
+	hash    *externalapi.DomainHash
+	origin  *Node
+	minedAt time.Duration
+}
+
+func (n *Network) addMinedBlock(block *externalapi.DomainBlock, origin *Node) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.minedBlocks = append(n.minedBlocks, &minedBlock{
+		hash:    consensushashing.BlockHash(block),
+		origin:  origin,
+		minedAt: n.clock.Elapsed(),
+	})
+	n.markActivity()
+}
@@ -0,0 +1,140 @@
+package netsim
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ammm56/lings/domain/consensus/model/externalapi"
+)
+
+// BlockPropagation describes how a block that was mined in the simulation
+// spread through the network
+type BlockPropagation struct {
+	Hash    *externalapi.DomainHash
+	Origin  string
+	MinedAt time.Duration
+
+	// Delays holds, for every node other than the origin that added the
+	// block to its DAG, how long after the block was mined that happened
+	Delays map[string]time.Duration
+
+	// Missing lists the nodes that haven't added the block to their DAG
+	Missing []string
+}
+
+// PropagationReport describes how the blocks that were mined in the
+// simulation spread through the network
+type PropagationReport struct {
+	Blocks []*BlockPropagation
+}
+
+// PropagationReport returns a report of how every block that was mined so
+// far spread through the network
+func (n *Network) PropagationReport() *PropagationReport {
+	n.lock.Lock()
+	nodes := make([]*Node, len(n.nodes))
+	copy(nodes, n.nodes)
+	minedBlocks := make([]*minedBlock, len(n.minedBlocks))
+	copy(minedBlocks, n.minedBlocks)
+	n.lock.Unlock()
+
+	report := &PropagationReport{Blocks: make([]*BlockPropagation, 0, len(minedBlocks))}
+	for _, block := range minedBlocks {
+		blockPropagation := &BlockPropagation{
+			Hash:    block.hash,
+			Origin:  block.origin.name,
+			MinedAt: block.minedAt,
+			Delays:  make(map[string]time.Duration),
+		}
+		for _, node := range nodes {
+			if node == block.origin {
+				continue
+			}
+			arrival, ok := node.arrival(block.hash)
+			if !ok {
+				blockPropagation.Missing = append(blockPropagation.Missing, node.name)
+				continue
+			}
+			blockPropagation.Delays[node.name] = arrival - block.minedAt
+		}
+		report.Blocks = append(report.Blocks, blockPropagation)
+	}
+	return report
+}
+
+// Delays returns the propagation delays of all blocks to all nodes, sorted
+// from the shortest to the longest
+func (r *PropagationReport) Delays() []time.Duration {
+	var delays []time.Duration
+	for _, block := range r.Blocks {
+		for _, delay := range block.Delays {
+			delays = append(delays, delay)
+		}
+	}
+	sort.Slice(delays, func(i, j int) bool { return delays[i] < delays[j] })
+	return delays
+}
+
+// Percentile returns the smallest delay that at least p percent of the
+// deliveries didn't exceed, or zero if no block was delivered
+func (r *PropagationReport) Percentile(p float64) time.Duration {
+	delays := r.Delays()
+	if len(delays) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(delays))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(delays) {
+		rank = len(delays)
+	}
+	return delays[rank-1]
+}
+
+// MissingCount returns the number of (block, node) pairs in which the node
+// hasn't received the block
+func (r *PropagationReport) MissingCount() int {
+	missingCount := 0
+	for _, block := range r.Blocks {
+		missingCount += len(block.Missing)
+	}
+	return missingCount
+}
+
+func (r *PropagationReport) String() string {
+	builder := strings.Builder{}
+	for _, block := range r.Blocks {
+		nodeNames := make([]string, 0, len(block.Delays))
+		for nodeName := range block.Delays {
+			nodeNames = append(nodeNames, nodeName)
+		}
+		sort.Slice(nodeNames, func(i, j int) bool {
+			return block.Delays[nodeNames[i]] < block.Delays[nodeNames[j]] ||
+				(block.Delays[nodeNames[i]] == block.Delays[nodeNames[j]] && nodeNames[i] < nodeNames[j])
+		})
+
+		delayStrings := make([]string, len(nodeNames))
+		for i, nodeName := range nodeNames {
+			delayStrings[i] = fmt.Sprintf("%s +%s", nodeName, block.Delays[nodeName])
+		}
+		builder.WriteString(fmt.Sprintf("%s mined by %s at %s: %s", block.Hash, block.Origin, block.MinedAt,
+			strings.Join(delayStrings, ", ")))
+		if len(block.Missing) > 0 {
+			builder.WriteString(fmt.Sprintf("; missing from %s", strings.Join(block.Missing, ", ")))
+		}
+		builder.WriteString("\n")
+	}
+
+	delays := r.Delays()
+	if len(delays) == 0 {
+		builder.WriteString(fmt.Sprintf("%d blocks, no deliveries", len(r.Blocks)))
+		return builder.String()
+	}
+	builder.WriteString(fmt.Sprintf("%d blocks, %d deliveries: median %s, 90th percentile %s, max %s, %d missing",
+		len(r.Blocks), len(delays), r.Percentile(50), r.Percentile(90), delays[len(delays)-1], r.MissingCount()))
+	return builder.String()
+}
@@ -5,6 +5,8 @@
 package util
 
 import (
+	"sync"
+
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/blake2b"
 
@@ -52,6 +54,10 @@ const (
 	Bech32PrefixLingsSim
 )
 
+// bech32PrefixesLock protects stringsToBech32Prefixes, which
+// RegisterBech32Prefix adds the prefixes of custom networks to.
+var bech32PrefixesLock sync.RWMutex
+
 // Map from strings to Bech32 address prefix constants for parsing purposes.
 var stringsToBech32Prefixes = map[string]Bech32Prefix{
 	"lings":     Bech32PrefixLings,
@@ -62,6 +68,9 @@ var stringsToBech32Prefixes = map[string]Bech32Prefix{
 
 // ParsePrefix attempts to parse a Bech32 address prefix.
 func ParsePrefix(prefixString string) (Bech32Prefix, error) {
+	bech32PrefixesLock.RLock()
+	defer bech32PrefixesLock.RUnlock()
+
 	prefix, ok := stringsToBech32Prefixes[prefixString]
 	if !ok {
 		return Bech32PrefixUnknown, errors.Errorf("could not parse prefix %s", prefixString)
@@ -70,8 +79,37 @@ func ParsePrefix(prefixString string) (Bech32Prefix, error) {
 	return prefix, nil
 }
 
+// RegisterBech32Prefix registers prefixString as the Bech32 address prefix of
+// a custom network, and returns its Bech32Prefix. Registering a prefix that is
+// already known returns its existing Bech32Prefix. It is safe for concurrent
+// use.
+func RegisterBech32Prefix(prefixString string) (Bech32Prefix, error) {
+	bech32PrefixesLock.Lock()
+	defer bech32PrefixesLock.Unlock()
+
+	if prefix, ok := stringsToBech32Prefixes[prefixString]; ok {
+		return prefix, nil
+	}
+	if prefixString == "" {
+		return Bech32PrefixUnknown, errors.New("the address prefix must not be empty")
+	}
+	for _, c := range prefixString {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return Bech32PrefixUnknown, errors.Errorf("the address prefix %s must only contain lowercase "+
+				"letters and digits", prefixString)
+		}
+	}
+
+	prefix := Bech32Prefix(len(stringsToBech32Prefixes) + 1)
+	stringsToBech32Prefixes[prefixString] = prefix
+	return prefix, nil
+}
+
 // Converts from Bech32 address prefixes to their string values
 func (prefix Bech32Prefix) String() string {
+	bech32PrefixesLock.RLock()
+	defer bech32PrefixesLock.RUnlock()
+
 	for key, value := range stringsToBech32Prefixes {
 		if prefix == value {
 			return key
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	"golang.org/x/crypto/blake2b"
@@ -460,6 +461,50 @@ func TestPrefixToString(t *testing.T) {
 	}
 }
 
+// TestRegisterBech32PrefixConcurrently registers the same prefixes from several
+// goroutines while others parse and print prefixes, and checks that every
+// prefix is registered exactly once. It's meant to be run with -race.
+func TestRegisterBech32PrefixConcurrently(t *testing.T) {
+	const goroutineCount = 8
+	prefixStrings := []string{"lingsconcurrenta", "lingsconcurrentb", "lingsconcurrentc"}
+
+	results := make([][]util.Bech32Prefix, goroutineCount)
+	var waitGroup sync.WaitGroup
+	for i := 0; i < goroutineCount; i++ {
+		waitGroup.Add(1)
+		go func(i int) {
+			defer waitGroup.Done()
+			for _, prefixString := range prefixStrings {
+				prefix, err := util.RegisterBech32Prefix(prefixString)
+				if err != nil {
+					t.Errorf("RegisterBech32Prefix: %s", err)
+					return
+				}
+				results[i] = append(results[i], prefix)
+
+				_, _ = util.ParsePrefix("lings")
+				_ = util.Bech32PrefixLings.String()
+			}
+		}(i)
+	}
+	waitGroup.Wait()
+
+	for i, prefixString := range prefixStrings {
+		prefix, err := util.ParsePrefix(prefixString)
+		if err != nil {
+			t.Fatalf("ParsePrefix: %s", err)
+		}
+		if prefix.String() != prefixString {
+			t.Errorf("expected prefix %d to be %s, but got %s", prefix, prefixString, prefix)
+		}
+		for _, result := range results {
+			if result[i] != prefix {
+				t.Errorf("%s was registered as both %d and %d", prefixString, result[i], prefix)
+			}
+		}
+	}
+}
+
 // createBurnAddress generates a burn address using a predefined identifier.
 func TestCreateBurnAddress(t *testing.T) {
 	identifier := "Lings_NETWORK_BURN_TOKEN_2024"
@@ -46,6 +46,9 @@ func Decode(encoded string) (string, []byte, byte, error) {
 	}
 
 	converted := convertBits(decoded, fiveToEightBits)
+	if len(converted) == 0 {
+		return "", nil, 0, errors.Errorf("decoded data is missing a version byte")
+	}
 	version := converted[0]
 	payload := converted[1:]
 
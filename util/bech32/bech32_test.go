@@ -54,8 +54,15 @@ func TestBech32(t *testing.T) {
 }
 
 func TestDecodeError(t *testing.T) {
-	_, _, _, err := bech32.Decode("™")
-	if err == nil {
-		t.Errorf("decode unexpectedly succeeded")
+	tests := []string{
+		"™",
+		// Has a valid checksum, but no data to hold the version byte
+		"lings:qe6ze740",
+	}
+	for _, test := range tests {
+		_, _, _, err := bech32.Decode(test)
+		if err == nil {
+			t.Errorf("decode of %s unexpectedly succeeded", test)
+		}
 	}
 }
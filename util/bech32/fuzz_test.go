@@ -0,0 +1,27 @@
+package bech32_test
+
+import (
+	"testing"
+
+	"github.com/ammm56/lings/util/bech32"
+)
+
+func FuzzDecode(f *testing.F) {
+	f.Fuzz(func(t *testing.T, encoded string) {
+		prefix, payload, version, err := bech32.Decode(encoded)
+		if err != nil {
+			return
+		}
+
+		// Anything that decodes successfully has to encode back to the same
+		// string, up to case and the padding bits
+		reencoded := bech32.Encode(prefix, payload, version)
+		reencodedPrefix, reencodedPayload, reencodedVersion, err := bech32.Decode(reencoded)
+		if err != nil {
+			t.Fatalf("Decode of %s, which was re-encoded from %s, failed: %s", reencoded, encoded, err)
+		}
+		if reencodedPrefix != prefix || string(reencodedPayload) != string(payload) || reencodedVersion != version {
+			t.Fatalf("Re-encoding %s as %s changed its contents", encoded, reencoded)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/ammm56/lings/util"
+)
+
+func FuzzDecodeAddress(f *testing.F) {
+	f.Fuzz(func(t *testing.T, encoded string) {
+		address, err := util.DecodeAddress(encoded, util.Bech32PrefixUnknown)
+		if err != nil {
+			return
+		}
+
+		decodedAgain, err := util.DecodeAddress(address.EncodeAddress(), address.Prefix())
+		if err != nil {
+			t.Fatalf("Decode of %s, which was re-encoded from %s, failed: %s", address.EncodeAddress(), encoded, err)
+		}
+		if decodedAgain.String() != address.String() {
+			t.Fatalf("Re-encoding %s changed it from %s to %s", encoded, address, decodedAgain)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package mstime
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock is a source of the current time. Components that compare timestamps
+// against the current time take a Clock rather than calling Now, so that the
+// time they observe can be controlled.
+type Clock interface {
+	Now() Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() Time {
+	return Now()
+}
+
+// SystemClock is a Clock that returns the current local time
+var SystemClock Clock = systemClock{}
+
+// AdjustableClock is a Clock that runs at the pace of the system clock, but
+// can be moved to an arbitrary point in time
+type AdjustableClock struct {
+	offsetMilliseconds int64
+}
+
+// NewAdjustableClock returns an AdjustableClock that initially returns the
+// current local time
+func NewAdjustableClock() *AdjustableClock {
+	return &AdjustableClock{}
+}
+
+// Now returns the current local time, shifted by the clock's offset
+func (c *AdjustableClock) Now() Time {
+	return Now().Add(c.Offset())
+}
+
+// Offset returns the difference between the clock's time and the local time
+func (c *AdjustableClock) Offset() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.offsetMilliseconds)) * time.Millisecond
+}
+
+// Set moves the clock so that it currently returns t
+func (c *AdjustableClock) Set(t Time) {
+	atomic.StoreInt64(&c.offsetMilliseconds, t.UnixMilliseconds()-Now().UnixMilliseconds())
+}
+
+// Advance moves the clock forward by d. A negative d moves it backward.
+func (c *AdjustableClock) Advance(d time.Duration) {
+	validateDurationPrecision(d)
+	atomic.AddInt64(&c.offsetMilliseconds, d.Milliseconds())
+}
+
+// Reset makes the clock return the current local time again
+func (c *AdjustableClock) Reset() {
+	atomic.StoreInt64(&c.offsetMilliseconds, 0)
+}
@@ -0,0 +1,33 @@
+package mstime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdjustableClock(t *testing.T) {
+	clock := NewAdjustableClock()
+	if clock.Offset() != 0 {
+		t.Fatalf("expected a new clock to have no offset, got %s", clock.Offset())
+	}
+
+	clock.Advance(time.Hour)
+	clock.Advance(30 * time.Minute)
+	if clock.Offset() != 90*time.Minute {
+		t.Fatalf("expected an offset of 90 minutes, got %s", clock.Offset())
+	}
+	if difference := clock.Now().Sub(Now()); difference < 89*time.Minute || difference > 91*time.Minute {
+		t.Fatalf("expected the clock to be 90 minutes ahead, got %s", difference)
+	}
+
+	target := UnixMilliseconds(1_000_000_000_000)
+	clock.Set(target)
+	if difference := clock.Now().Sub(target); difference < 0 || difference > time.Minute {
+		t.Fatalf("expected the clock to be at %s, got %s", target, clock.Now())
+	}
+
+	clock.Reset()
+	if clock.Offset() != 0 {
+		t.Fatalf("expected a reset clock to have no offset, got %s", clock.Offset())
+	}
+}
@@ -3,6 +3,7 @@ package txmass
 import (
 	"github.com/ammm56/lings/domain/consensus/model/externalapi"
 	"github.com/ammm56/lings/domain/consensus/utils/transactionhelper"
+	"github.com/ammm56/lings/domain/consensus/utils/txscript"
 )
 
 // Calculator exposes methods to calculate the mass of a transaction
@@ -10,14 +11,16 @@ type Calculator struct {
 	massPerTxByte           uint64
 	massPerScriptPubKeyByte uint64
 	massPerSigOp            uint64
+	massPerSpliceOp         uint64
 }
 
 // NewCalculator creates a new instance of Calculator
-func NewCalculator(massPerTxByte, massPerScriptPubKeyByte, massPerSigOp uint64) *Calculator {
+func NewCalculator(massPerTxByte, massPerScriptPubKeyByte, massPerSigOp, massPerSpliceOp uint64) *Calculator {
 	return &Calculator{
 		massPerTxByte:           massPerTxByte,
 		massPerScriptPubKeyByte: massPerScriptPubKeyByte,
 		massPerSigOp:            massPerSigOp,
+		massPerSpliceOp:         massPerSpliceOp,
 	}
 }
 
@@ -30,6 +33,9 @@ func (c *Calculator) MassPerScriptPubKeyByte() uint64 { return c.massPerScriptPu
 // MassPerSigOp returns the mass per SigOp byte configured for this Calculator
 func (c *Calculator) MassPerSigOp() uint64 { return c.massPerSigOp }
 
+// MassPerSpliceOp returns the mass per splice or bitwise operation configured for this Calculator
+func (c *Calculator) MassPerSpliceOp() uint64 { return c.massPerSpliceOp }
+
 // CalculateTransactionMass calculates the mass of the given transaction
 func (c *Calculator) CalculateTransactionMass(transaction *externalapi.DomainTransaction) uint64 {
 	if transactionhelper.IsCoinBase(transaction) {
@@ -59,6 +65,19 @@ func (c *Calculator) CalculateTransactionMass(transaction *externalapi.DomainTra
 	return massForSize + massForScriptPubKey + massForSigOps
 }
 
+// CalculateSpliceOpMass calculates the mass of the splice and bitwise
+// operations in the scripts that are run by the inputs of the given
+// transaction. Unlike the rest of the mass it depends on the script public
+// keys the inputs spend, so the UTXO entries of all inputs must be populated.
+func (c *Calculator) CalculateSpliceOpMass(transaction *externalapi.DomainTransaction) uint64 {
+	totalSpliceOpCount := uint64(0)
+	for _, input := range transaction.Inputs {
+		totalSpliceOpCount += uint64(txscript.GetPreciseSpliceOpCount(
+			input.SignatureScript, input.UTXOEntry.ScriptPublicKey()))
+	}
+	return totalSpliceOpCount * c.massPerSpliceOp
+}
+
 // transactionEstimatedSerializedSize is the estimated size of a transaction in some
 // serialization. This has to be deterministic, but not necessarily accurate, since
 // it's only used as the size component in the transaction and block mass limit